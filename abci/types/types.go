@@ -102,3 +102,22 @@ type ResponseCheckTx struct {
 	Events    []Event `protobuf:"bytes,7,rep,name=events,proto3" json:"events,omitempty"`
 	Codespace string  `protobuf:"bytes,8,opt,name=codespace,proto3" json:"codespace,omitempty"`
 }
+
+// TxResult contains results of executing the transaction.
+//
+// One usage is indexing transaction results.
+type TxResult struct {
+	Height int64        `protobuf:"varint,1,opt,name=height,proto3" json:"height,omitempty"`
+	Index  uint32       `protobuf:"varint,2,opt,name=index,proto3" json:"index,omitempty"`
+	Tx     []byte       `protobuf:"bytes,3,opt,name=tx,proto3" json:"tx,omitempty"`
+	Result ExecTxResult `protobuf:"bytes,4,opt,name=result,proto3" json:"result"`
+}
+
+// ResponseFinalizeBlock is the result of FinalizeBlock, published with the NewBlock events.
+type ResponseFinalizeBlock struct {
+	Events           []Event           `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	TxResults        []*ExecTxResult   `protobuf:"bytes,2,rep,name=tx_results,json=txResults,proto3" json:"tx_results,omitempty"`
+	ValidatorUpdates []ValidatorUpdate `protobuf:"bytes,3,rep,name=validator_updates,json=validatorUpdates,proto3" json:"validator_updates"`
+	//ConsensusParamUpdates *cmtproto.ConsensusParams `protobuf:"bytes,4,opt,name=consensus_param_updates,json=consensusParamUpdates,proto3" json:"consensus_param_updates,omitempty"`
+	AppHash []byte `protobuf:"bytes,5,opt,name=app_hash,json=appHash,proto3" json:"app_hash,omitempty"`
+}
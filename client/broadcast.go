@@ -0,0 +1,277 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	abci "github.com/strangelove-ventures/cometbft-client/abci/types"
+	rpcclient "github.com/strangelove-ventures/cometbft-client/rpc/client"
+	rpchttp "github.com/strangelove-ventures/cometbft-client/rpc/client/http"
+	coretypes "github.com/strangelove-ventures/cometbft-client/rpc/core/types"
+	"github.com/strangelove-ventures/cometbft-client/types"
+)
+
+const (
+	// txSubscriber is the subscriber of the Tx subscription shared by BroadcastAndWait calls.
+	txSubscriber = "BroadcastAndWait"
+	// txEventsCapacity is the capacity of the Tx subscription channel. Events received while
+	// it is full are dropped, and the inclusion of their transaction is found by polling.
+	txEventsCapacity = 100
+	// unsubscribeTimeout is the maximum time the last BroadcastAndWait call waits for the Tx
+	// subscription to be cancelled once it returns.
+	unsubscribeTimeout = 5 * time.Second
+)
+
+// txQuery matches the events of every transaction.
+var txQuery = types.EventQueryTx.String()
+
+// BroadcastOutcome is the final state of a transaction submitted with BroadcastAndWait.
+type BroadcastOutcome byte
+
+const (
+	// BroadcastIncluded - the transaction was included in a block and executed successfully.
+	BroadcastIncluded BroadcastOutcome = iota + 1
+	// BroadcastIncludedFailed - the transaction was included in a block but its execution failed.
+	BroadcastIncludedFailed
+	// BroadcastRejected - the transaction was rejected by CheckTx and never entered the mempool.
+	BroadcastRejected
+	// BroadcastInCache - the node already had the transaction in its cache and it was not seen
+	// in a block before the timeout, e.g. because it was submitted earlier and evicted since.
+	BroadcastInCache
+	// BroadcastTimedOut - the transaction was accepted by CheckTx but not included in a block
+	// before the timeout. It may still be in the mempool.
+	BroadcastTimedOut
+)
+
+func (o BroadcastOutcome) String() string {
+	switch o {
+	case BroadcastIncluded:
+		return "included"
+	case BroadcastIncludedFailed:
+		return "included_failed"
+	case BroadcastRejected:
+		return "rejected"
+	case BroadcastInCache:
+		return "in_cache"
+	case BroadcastTimedOut:
+		return "timed_out"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalJSON encodes the outcome as its name.
+func (o BroadcastOutcome) MarshalJSON() ([]byte, error) {
+	return json.Marshal(o.String())
+}
+
+func (r *TxResponse) setIncluded(tx *TxResponse) {
+	outcome := BroadcastIncluded
+	if !tx.ExecTx.IsOK() {
+		outcome = BroadcastIncludedFailed
+	}
+	checkTx := r.CheckTx
+	*r = *tx
+	r.Outcome, r.CheckTx = outcome, checkTx
+}
+
+// BroadcastOptions configures BroadcastAndWait.
+type BroadcastOptions struct {
+	// Timeout is the maximum time to wait for the transaction to be included in a block.
+	Timeout time.Duration
+	// PollInterval is the delay between two Tx queries. They run alongside the event
+	// subscription, in case it is unavailable or its events are dropped.
+	PollInterval time.Duration
+}
+
+// DefaultBroadcastOptions are the default options used by BroadcastAndWait.
+var DefaultBroadcastOptions = BroadcastOptions{
+	Timeout:      time.Minute,
+	PollInterval: time.Second,
+}
+
+// BroadcastAndWait submits tx with BroadcastTxSync and waits until it is included in a block,
+// as reported by the Tx event subscription of the client or by polling Tx, whichever comes
+// first. The subscription is shared by all the concurrent calls, since nodes limit the number
+// of subscriptions per client; it is only available once the client is started.
+//
+// Unlike BroadcastTxCommit, the node is not kept waiting for the inclusion, so it does not
+// time out under load. An error is returned only if the transaction could not be submitted
+// or ctx is done; every other result is reported by TxResponse.Outcome.
+func (c *Client) BroadcastAndWait(ctx context.Context, tx types.Tx, opts BroadcastOptions) (*TxResponse, error) {
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultBroadcastOptions.Timeout
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = DefaultBroadcastOptions.PollInterval
+	}
+
+	resp := &TxResponse{Hash: tx.Hash()}
+
+	// Wait for the event before broadcasting, so that it can't be missed.
+	included, done := c.txWaiters.wait(ctx, c.rpcClient, resp.Hash)
+	defer done()
+
+	res, err := c.rpcClient.BroadcastTxSync(ctx, tx)
+	switch {
+	case rpchttp.IsErrTxInCache(err):
+		// The transaction may have been included already, in which case no event will follow.
+		if txRes, err := c.Tx(ctx, resp.Hash, false); err == nil {
+			resp.setIncluded(txRes)
+			return resp, nil
+		}
+		resp.Outcome = BroadcastInCache
+	case err != nil:
+		return nil, err
+	default:
+		resp.CheckTx = res
+		if res.Code != abci.CodeTypeOK {
+			resp.Outcome = BroadcastRejected
+			return resp, nil
+		}
+		resp.Outcome = BroadcastTimedOut
+	}
+
+	timeout := time.NewTimer(opts.Timeout)
+	defer timeout.Stop()
+
+	poll := time.NewTicker(opts.PollInterval)
+	defer poll.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+
+		case txRes := <-included:
+			resp.setIncluded(newTxResponse(tx, txRes))
+			return resp, nil
+
+		case <-poll.C:
+			if txRes, err := c.Tx(ctx, resp.Hash, false); err == nil {
+				resp.setIncluded(txRes)
+				return resp, nil
+			}
+
+		case <-timeout.C:
+			// A last check, in case the inclusion happened since the previous poll.
+			if txRes, err := c.Tx(ctx, resp.Hash, false); err == nil {
+				resp.setIncluded(txRes)
+			}
+			return resp, nil
+		}
+	}
+}
+
+// txWaiters routes the events of a single Tx subscription to the BroadcastAndWait calls
+// waiting for them, by transaction hash. The subscription is made by the first waiter and
+// cancelled by the last one.
+type txWaiters struct {
+	mtx     sync.Mutex
+	waiters map[string][]chan abci.TxResult
+	count   int
+	// stop stops the routine routing the events. It is nil if the subscription failed.
+	stop chan struct{}
+}
+
+// wait registers a waiter for the transaction with the given hash. It returns the channel
+// receiving its result, and the function to call once done waiting. If the subscription
+// failed, nothing is ever received.
+func (w *txWaiters) wait(ctx context.Context, rpcClient rpcclient.Client, hash []byte) (<-chan abci.TxResult, func()) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+
+	if w.count == 0 {
+		w.waiters = make(map[string][]chan abci.TxResult)
+		events, err := rpcClient.Subscribe(ctx, txSubscriber, txQuery, txEventsCapacity)
+		if err == nil {
+			w.stop = make(chan struct{})
+			go w.route(events, w.stop)
+		}
+	}
+	w.count++
+
+	key := string(hash)
+	ch := make(chan abci.TxResult, 1)
+	w.waiters[key] = append(w.waiters[key], ch)
+
+	return ch, func() {
+		w.mtx.Lock()
+		defer w.mtx.Unlock()
+
+		chans := w.waiters[key]
+		for i := range chans {
+			if chans[i] == ch {
+				chans = append(chans[:i], chans[i+1:]...)
+				break
+			}
+		}
+		if len(chans) == 0 {
+			delete(w.waiters, key)
+		} else {
+			w.waiters[key] = chans
+		}
+
+		w.count--
+		if w.count > 0 || w.stop == nil {
+			return
+		}
+		close(w.stop)
+		w.stop = nil
+		// The subscription is dropped by the node anyway if the connection is gone.
+		ctx, cancel := context.WithTimeout(context.Background(), unsubscribeTimeout)
+		defer cancel()
+		_ = rpcClient.Unsubscribe(ctx, txSubscriber, txQuery)
+	}
+}
+
+func (w *txWaiters) route(events <-chan coretypes.ResultEvent, stop <-chan struct{}) {
+	for {
+		select {
+		case <-stop:
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			data, ok := event.Data.(types.EventDataTx)
+			if !ok {
+				continue
+			}
+
+			w.mtx.Lock()
+			for _, ch := range w.waiters[string(types.Tx(data.Tx).Hash())] {
+				select {
+				case ch <- data.TxResult:
+				default:
+				}
+			}
+			w.mtx.Unlock()
+		}
+	}
+}
+
+func newTxResponse(tx types.Tx, res abci.TxResult) *TxResponse {
+	return &TxResponse{
+		Hash:   tx.Hash(),
+		Height: res.Height,
+		Index:  res.Index,
+		ExecTx: newExecTxResponse(res.Result),
+		Tx:     tx,
+	}
+}
+
+func newExecTxResponse(res abci.ExecTxResult) ExecTxResponse {
+	return ExecTxResponse{
+		Code:      res.Code,
+		Data:      res.Data,
+		Log:       res.Log,
+		Info:      res.Info,
+		GasWanted: res.GasWanted,
+		GasUsed:   res.GasUsed,
+		Events:    parseEvents(res.Events),
+		Codespace: res.Codespace,
+	}
+}
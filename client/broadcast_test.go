@@ -0,0 +1,255 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	abci "github.com/strangelove-ventures/cometbft-client/abci/types"
+	cmtjson "github.com/strangelove-ventures/cometbft-client/libs/json"
+	rpcclient "github.com/strangelove-ventures/cometbft-client/rpc/client"
+	coretypes "github.com/strangelove-ventures/cometbft-client/rpc/core/types"
+	"github.com/strangelove-ventures/cometbft-client/types"
+)
+
+func TestBroadcastAndWait(t *testing.T) {
+	tx := types.Tx("tx")
+	opts := BroadcastOptions{Timeout: 200 * time.Millisecond, PollInterval: 10 * time.Millisecond}
+
+	testCases := []struct {
+		name       string
+		checkTx    error
+		checkCode  uint32
+		execCode   uint32
+		foundAfter int64 // number of Tx queries before the tx is found, -1 if never
+		outcome    BroadcastOutcome
+	}{
+		{"included", nil, 0, 0, 2, BroadcastIncluded},
+		{"included failed", nil, 0, 5, 0, BroadcastIncludedFailed},
+		{"rejected", nil, 3, 0, -1, BroadcastRejected},
+		{"timed out", nil, 0, 0, -1, BroadcastTimedOut},
+		{"in cache and included", errors.New("tx already exists in cache"), 0, 0, 0, BroadcastIncluded},
+		{"in cache", errors.New("tx already exists in cache"), 0, 0, -1, BroadcastInCache},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			var queries atomic.Int64
+			node := newMockNode(t)
+			node.handle("broadcast_tx_sync", func(map[string]json.RawMessage) (interface{}, error) {
+				if tc.checkTx != nil {
+					return nil, tc.checkTx
+				}
+				return &coretypes.ResultBroadcastTx{Code: tc.checkCode, Hash: tx.Hash()}, nil
+			})
+			node.handle("tx", func(map[string]json.RawMessage) (interface{}, error) {
+				if n := queries.Add(1) - 1; tc.foundAfter < 0 || n < tc.foundAfter {
+					return nil, fmt.Errorf("tx (%X) not found", tx.Hash())
+				}
+				return &coretypes.ResultTx{
+					Hash:     tx.Hash(),
+					Height:   7,
+					TxResult: abci.ExecTxResult{Code: tc.execCode, Codespace: "sdk"},
+					Tx:       tx,
+				}, nil
+			})
+
+			res, err := node.client(t).BroadcastAndWait(context.Background(), tx, opts)
+			require.NoError(t, err)
+			require.Equal(t, tc.outcome, res.Outcome, res.Outcome.String())
+			require.Equal(t, tx.Hash(), []byte(res.Hash))
+
+			switch tc.outcome {
+			case BroadcastIncluded, BroadcastIncludedFailed:
+				require.Equal(t, int64(7), res.Height)
+				require.Equal(t, tc.execCode, res.ExecTx.Code)
+			case BroadcastRejected:
+				require.Equal(t, tc.checkCode, res.CheckTx.Code)
+				require.Zero(t, queries.Load())
+			default:
+				require.Zero(t, res.Height)
+			}
+			if tc.checkTx != nil {
+				require.Nil(t, res.CheckTx)
+			}
+		})
+	}
+}
+
+func TestBroadcastAndWaitError(t *testing.T) {
+	node := newMockNode(t)
+	node.handle("broadcast_tx_sync", func(map[string]json.RawMessage) (interface{}, error) {
+		return nil, errors.New("mempool is full")
+	})
+
+	_, err := node.client(t).BroadcastAndWait(context.Background(), types.Tx("tx"), DefaultBroadcastOptions)
+	require.ErrorContains(t, err, "mempool is full")
+}
+
+// maxSubscriptionsPerClient is the default limit of subscriptions per client of CometBFT nodes.
+const maxSubscriptionsPerClient = 5
+
+// subscriptionClient adds to the client of a mock node the event subscriptions of a CometBFT
+// node, limited to maxSubscriptionsPerClient. If publish is set, broadcasting a transaction
+// publishes its Tx event.
+type subscriptionClient struct {
+	rpcclient.Client
+	publish bool
+
+	mtx     sync.Mutex
+	subs    map[string]chan coretypes.ResultEvent
+	maxSubs int
+}
+
+func (c *subscriptionClient) Subscribe(_ context.Context, _, query string, _ ...int) (<-chan coretypes.ResultEvent, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if _, ok := c.subs[query]; ok {
+		return nil, errors.New("already subscribed")
+	}
+	if len(c.subs) >= maxSubscriptionsPerClient {
+		return nil, fmt.Errorf("max_subscriptions_per_client %d reached", maxSubscriptionsPerClient)
+	}
+	ch := make(chan coretypes.ResultEvent, 100)
+	c.subs[query] = ch
+	c.maxSubs = max(c.maxSubs, len(c.subs))
+	return ch, nil
+}
+
+func (c *subscriptionClient) Unsubscribe(_ context.Context, _, query string) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	delete(c.subs, query)
+	return nil
+}
+
+func (c *subscriptionClient) BroadcastTxSync(ctx context.Context, tx types.Tx) (*coretypes.ResultBroadcastTx, error) {
+	res, err := c.Client.BroadcastTxSync(ctx, tx)
+	if err != nil || !c.publish {
+		return res, err
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	for query, ch := range c.subs {
+		ch <- coretypes.ResultEvent{
+			Query: query,
+			Data:  types.EventDataTx{TxResult: abci.TxResult{Height: 7, Tx: tx}},
+		}
+	}
+	return res, nil
+}
+
+func TestBroadcastAndWaitConcurrent(t *testing.T) {
+	const calls = 2 * maxSubscriptionsPerClient
+	opts := BroadcastOptions{Timeout: 5 * time.Second, PollInterval: 10 * time.Millisecond}
+
+	testCases := []struct {
+		name string
+		// publish is set if the Tx events are published, otherwise the transactions are only
+		// found by polling.
+		publish bool
+		// otherSubs is the number of subscriptions already held by the client.
+		otherSubs int
+	}{
+		{"events", true, 0},
+		{"no events", false, 0},
+		{"subscriptions exhausted", true, maxSubscriptionsPerClient},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			node := newMockNode(t)
+			node.handle("broadcast_tx_sync", func(map[string]json.RawMessage) (interface{}, error) {
+				return &coretypes.ResultBroadcastTx{}, nil
+			})
+			node.handle("tx", func(map[string]json.RawMessage) (interface{}, error) {
+				if tc.publish && tc.otherSubs == 0 {
+					// The inclusion must be reported by the events only.
+					return nil, errors.New("tx not found")
+				}
+				return &coretypes.ResultTx{Height: 7}, nil
+			})
+
+			rpcClient, err := newRPCClient(node.URL, 5*time.Second)
+			require.NoError(t, err)
+			subClient := &subscriptionClient{
+				Client:  rpcClient,
+				publish: tc.publish,
+				subs:    make(map[string]chan coretypes.ResultEvent),
+			}
+			for i := 0; i < tc.otherSubs; i++ {
+				_, err := subClient.Subscribe(context.Background(), "other", fmt.Sprintf("tm.event='Tx' AND n=%d", i))
+				require.NoError(t, err)
+			}
+			c := NewClientFromRPCClient(subClient)
+
+			start := time.Now()
+			var wg sync.WaitGroup
+			results := make([]*TxResponse, calls)
+			errs := make([]error, calls)
+			for i := 0; i < calls; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					tx := types.Tx(fmt.Sprintf("tx%d", i))
+					results[i], errs[i] = c.BroadcastAndWait(context.Background(), tx, opts)
+				}(i)
+			}
+			wg.Wait()
+
+			require.Less(t, time.Since(start), opts.Timeout)
+			for i := range results {
+				require.NoError(t, errs[i])
+				require.Equal(t, BroadcastIncluded, results[i].Outcome, results[i].Outcome.String())
+				require.Equal(t, int64(7), results[i].Height)
+			}
+
+			subClient.mtx.Lock()
+			defer subClient.mtx.Unlock()
+			require.LessOrEqual(t, subClient.maxSubs, max(tc.otherSubs, 1))
+			require.Len(t, subClient.subs, tc.otherSubs)
+		})
+	}
+}
+
+func TestEventDataTxDecoding(t *testing.T) {
+	event := `{
+  "query": "tm.event='Tx' AND tx.hash='8B01023386C371778ECB6368573E539AFC3CC860EC3A2F614E54FE5652F4FC80'",
+  "data": {
+    "type": "tendermint/event/Tx",
+    "value": {
+      "TxResult": {
+        "height": "12",
+        "index": 1,
+        "tx": "dHg=",
+        "result": {"code": 5, "codespace": "sdk", "gas_used": "100"}
+      }
+    }
+  },
+  "events": {"tm.event": ["Tx"]}
+}`
+
+	var res coretypes.ResultEvent
+	require.NoError(t, cmtjson.Unmarshal([]byte(event), &res))
+
+	data, ok := res.Data.(types.EventDataTx)
+	require.True(t, ok)
+
+	txRes := newTxResponse(types.Tx("tx"), data.TxResult)
+	require.Equal(t, int64(12), txRes.Height)
+	require.Equal(t, uint32(1), txRes.Index)
+	require.Equal(t, uint32(5), txRes.ExecTx.Code)
+	require.Equal(t, int64(100), txRes.ExecTx.GasUsed)
+	require.Equal(t, types.Tx("tx").Hash(), []byte(txRes.Hash))
+}
@@ -19,6 +19,7 @@ import (
 // Client is a wrapper around the CometBFT RPC client.
 type Client struct {
 	rpcClient rpcclient.Client
+	txWaiters txWaiters
 }
 
 // NewClient returns a pointer to a new instance of Client.
@@ -28,7 +29,24 @@ func NewClient(addr string, timeout time.Duration) (*Client, error) {
 		return nil, err
 	}
 
-	return &Client{rpcClient}, nil
+	return &Client{rpcClient: rpcClient}, nil
+}
+
+// NewClientFromRPCClient returns a Client using rpcClient, e.g. a rpchttp.HTTP client
+// decorated with the cache of the rpc/client/cache package.
+func NewClientFromRPCClient(rpcClient rpcclient.Client) *Client {
+	return &Client{rpcClient: rpcClient}
+}
+
+// Start starts the websocket connection used for event subscriptions, e.g. by BroadcastAndWait.
+// The other methods of the client do not require it.
+func (c *Client) Start() error {
+	return c.rpcClient.Start()
+}
+
+// Stop closes the websocket connection. The client can't be started again afterwards.
+func (c *Client) Stop() error {
+	return c.rpcClient.Stop()
 }
 
 // BlockResults fetches the block results at a specific height,
@@ -40,40 +58,42 @@ func (c *Client) BlockResults(ctx context.Context, height *int64) (*BlockRespons
 		return nil, err
 	}
 
-	var txRes []*ExecTxResponse
-	for _, tx := range res.TxsResults {
-		txRes = append(txRes, &ExecTxResponse{
-			Code:      tx.Code,
-			Data:      tx.Data,
-			Log:       tx.Log,
-			Info:      tx.Info,
-			GasWanted: tx.GasWanted,
-			GasUsed:   tx.GasUsed,
-			Events:    parseEvents(tx.Events),
-			Codespace: tx.Codespace,
+	return newBlockResponse(res), nil
+}
+
+// BlockResultsStream is like BlockResults, but passes the result of each transaction to onTx
+// instead of storing it in TxResponses. With an HTTP client, the results are decoded one at a
+// time while the response is received, so that the memory used does not depend on the number of
+// transactions of the block.
+func (c *Client) BlockResultsStream(
+	ctx context.Context,
+	height *int64,
+	onTx func(index int, tx *ExecTxResponse) error,
+) (*BlockResponse, error) {
+	if httpClient, ok := c.rpcClient.(*rpchttp.HTTP); ok {
+		res, err := httpClient.BlockResultsStream(ctx, height, func(index int, tx *abci.ExecTxResult) error {
+			txRes := newExecTxResponse(*tx)
+			return onTx(index, &txRes)
 		})
+		if err != nil {
+			return nil, err
+		}
+		return newBlockResponse(res), nil
 	}
 
-	if res.FinalizeBlockEvents != nil && len(res.FinalizeBlockEvents) > 0 {
-		return &BlockResponse{
-			Height:           res.Height,
-			TxResponses:      txRes,
-			Events:           parseEvents(res.FinalizeBlockEvents),
-			ValidatorUpdates: res.ValidatorUpdates,
-			AppHash:          res.AppHash,
-		}, nil
+	res, err := c.rpcClient.BlockResults(ctx, height)
+	if err != nil {
+		return nil, err
 	}
+	for i, tx := range res.TxsResults {
+		txRes := newExecTxResponse(*tx)
+		if err := onTx(i, &txRes); err != nil {
+			return nil, err
+		}
+	}
+	res.TxsResults = nil
 
-	events := res.BeginBlockEvents
-	events = append(events, res.EndBlockEvents...)
-
-	return &BlockResponse{
-		Height:           res.Height,
-		TxResponses:      txRes,
-		Events:           parseEvents(events),
-		ValidatorUpdates: res.ValidatorUpdates,
-		AppHash:          res.AppHash,
-	}, nil
+	return newBlockResponse(res), nil
 }
 
 func (c *Client) Tx(ctx context.Context, hash []byte, prove bool) (*TxResponse, error) {
@@ -82,22 +102,11 @@ func (c *Client) Tx(ctx context.Context, hash []byte, prove bool) (*TxResponse,
 		return nil, err
 	}
 
-	execTx := ExecTxResponse{
-		Code:      res.TxResult.Code,
-		Data:      res.TxResult.Data,
-		Log:       res.TxResult.Log,
-		Info:      res.TxResult.Info,
-		GasWanted: res.TxResult.GasWanted,
-		GasUsed:   res.TxResult.GasUsed,
-		Events:    parseEvents(res.TxResult.Events),
-		Codespace: res.TxResult.Codespace,
-	}
-
 	return &TxResponse{
 		Hash:   res.Hash,
 		Height: res.Height,
 		Index:  res.Index,
-		ExecTx: execTx,
+		ExecTx: newExecTxResponse(res.TxResult),
 		Tx:     res.Tx,
 		Proof:  res.Proof,
 	}, nil
@@ -244,6 +253,33 @@ func (c *Client) BroadcastTxCommit(ctx context.Context, tx types.Tx) (*coretypes
 	return res, nil
 }
 
+func (c *Client) NetInfo(ctx context.Context) (*coretypes.ResultNetInfo, error) {
+	res, err := c.rpcClient.NetInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (c *Client) UnconfirmedTxs(ctx context.Context, limit *int) (*coretypes.ResultUnconfirmedTxs, error) {
+	res, err := c.rpcClient.UnconfirmedTxs(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (c *Client) NumUnconfirmedTxs(ctx context.Context) (*coretypes.ResultUnconfirmedTxs, error) {
+	res, err := c.rpcClient.NumUnconfirmedTxs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
 func (c *Client) ABCIInfo(ctx context.Context) (*coretypes.ResultABCIInfo, error) {
 	res, err := c.rpcClient.ABCIInfo(ctx)
 	if err != nil {
@@ -292,6 +328,43 @@ func newRPCClient(addr string, timeout time.Duration) (*rpchttp.HTTP, error) {
 	return rpcClient, nil
 }
 
+func newBlockResponse(res *coretypes.ResultBlockResults) *BlockResponse {
+	var txRes []*ExecTxResponse
+	for _, tx := range res.TxsResults {
+		txRes = append(txRes, &ExecTxResponse{
+			Code:      tx.Code,
+			Data:      tx.Data,
+			Log:       tx.Log,
+			Info:      tx.Info,
+			GasWanted: tx.GasWanted,
+			GasUsed:   tx.GasUsed,
+			Events:    parseEvents(tx.Events),
+			Codespace: tx.Codespace,
+		})
+	}
+
+	if res.FinalizeBlockEvents != nil && len(res.FinalizeBlockEvents) > 0 {
+		return &BlockResponse{
+			Height:           res.Height,
+			TxResponses:      txRes,
+			Events:           parseEvents(res.FinalizeBlockEvents),
+			ValidatorUpdates: res.ValidatorUpdates,
+			AppHash:          res.AppHash,
+		}
+	}
+
+	events := res.BeginBlockEvents
+	events = append(events, res.EndBlockEvents...)
+
+	return &BlockResponse{
+		Height:           res.Height,
+		TxResponses:      txRes,
+		Events:           parseEvents(events),
+		ValidatorUpdates: res.ValidatorUpdates,
+		AppHash:          res.AppHash,
+	}
+}
+
 // parseEvents returns a slice of sdk.StringEvent objects that are composed from a slice of abci.Event objects.
 // parseEvents will first attempt to base64 decode the abci.Event objects and if an error is encountered it will
 // fall back to the stringifyEvents function.
@@ -7,7 +7,9 @@ import (
 	"testing"
 	"time"
 
+	abci "github.com/strangelove-ventures/cometbft-client/abci/types"
 	"github.com/strangelove-ventures/cometbft-client/libs/bytes"
+	coretypes "github.com/strangelove-ventures/cometbft-client/rpc/core/types"
 	"github.com/stretchr/testify/require"
 )
 
@@ -272,3 +274,38 @@ func TestTxByHash(t *testing.T) {
 func TestTxSearch(t *testing.T) {
 
 }
+
+func TestBlockResultsStream(t *testing.T) {
+	node := newMockNode(t)
+	node.handle("block_results", func(params map[string]json.RawMessage) (interface{}, error) {
+		height, err := paramHeight(params, "height")
+		if err != nil {
+			return nil, err
+		}
+		return &coretypes.ResultBlockResults{
+			Height: height,
+			TxsResults: []*abci.ExecTxResult{
+				{Code: 0, Events: []abci.Event{{Type: "transfer", Attributes: []abci.EventAttribute{{Key: "amount", Value: "1stake"}}}}},
+				{Code: 11, Log: "out of gas"},
+			},
+			FinalizeBlockEvents: []abci.Event{{Type: "block"}},
+		}, nil
+	})
+
+	var txs []*ExecTxResponse
+	height := int64(8)
+	res, err := node.client(t).BlockResultsStream(context.Background(), &height, func(index int, tx *ExecTxResponse) error {
+		require.Equal(t, len(txs), index)
+		txs = append(txs, tx)
+		return nil
+	})
+	require.NoError(t, err)
+	require.EqualValues(t, 8, res.Height)
+	require.Empty(t, res.TxResponses)
+	require.Len(t, res.Events, 1)
+
+	require.Len(t, txs, 2)
+	require.True(t, txs[0].IsOK())
+	require.Equal(t, "transfer", txs[0].Events[0].Type)
+	require.Equal(t, "out of gas", txs[1].Log)
+}
@@ -0,0 +1,325 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/strangelove-ventures/cometbft-client/p2p"
+	rpchttp "github.com/strangelove-ventures/cometbft-client/rpc/client/http"
+	coretypes "github.com/strangelove-ventures/cometbft-client/rpc/core/types"
+	jsonrpc "github.com/strangelove-ventures/cometbft-client/rpc/jsonrpc/client"
+)
+
+// CrawlOptions configures Crawl.
+type CrawlOptions struct {
+	// Concurrency is the maximum number of nodes queried at once.
+	Concurrency int
+	// Timeout is the timeout of every request to a node.
+	Timeout time.Duration
+	// MaxNodes is the maximum number of RPC endpoints queried, the first one included.
+	// 0 means no limit.
+	MaxNodes int
+	// FollowNonRoutable follows the RPC addresses which are not routable, e.g. those of a
+	// local testnet. Otherwise, only the routable addresses are followed.
+	FollowNonRoutable bool
+}
+
+// DefaultCrawlOptions are the default options of Crawl.
+var DefaultCrawlOptions = CrawlOptions{
+	Concurrency: 16,
+	Timeout:     5 * time.Second,
+	MaxNodes:    1000,
+}
+
+// NetworkNode is a node of the graph built by Crawl. The nodes whose RPC was not reached are
+// described by the NodeInfo their peers report.
+type NetworkNode struct {
+	ID      p2p.ID `json:"id"`
+	Moniker string `json:"moniker"`
+	Network string `json:"network"`
+	Version string `json:"version"`
+	// RPCAddress is the RPC address in the NodeInfo of the node, as it listens on it.
+	RPCAddress string `json:"rpc_address"`
+
+	// RPC is the URL the RPC of the node answered at, empty if it was not reached.
+	RPC          string `json:"rpc,omitempty"`
+	LatestHeight int64  `json:"latest_height,omitempty"`
+	CatchingUp   bool   `json:"catching_up,omitempty"`
+	// Error is the error of the last request to the RPC of the node.
+	Error string `json:"error,omitempty"`
+}
+
+// PublicRPC reports whether the RPC of the node answered.
+func (n *NetworkNode) PublicRPC() bool {
+	return n.RPC != ""
+}
+
+// NetworkEdge is a connection between two nodes, reported by either of them.
+type NetworkEdge struct {
+	// From dialed To.
+	From p2p.ID `json:"from"`
+	To   p2p.ID `json:"to"`
+}
+
+// NetworkGraph is the topology of a network, as reported by the NetInfo of its nodes.
+type NetworkGraph struct {
+	// Nodes are sorted by ID.
+	Nodes []*NetworkNode `json:"nodes"`
+	// Edges are sorted by From, then To.
+	Edges []NetworkEdge `json:"edges"`
+}
+
+// WriteJSON writes the graph as indented JSON.
+func (g *NetworkGraph) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(g)
+}
+
+// WriteDOT writes the graph in the DOT language of Graphviz. The nodes with a public RPC are
+// filled in green, with their latest height, and the edges point from the dialing node.
+func (g *NetworkGraph) WriteDOT(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("digraph network {\n")
+	b.WriteString("  node [shape=box, style=filled, fillcolor=lightgrey];\n")
+	for _, n := range g.Nodes {
+		label := fmt.Sprintf("%s\n%s", n.Moniker, n.Version)
+		attrs := ""
+		if n.PublicRPC() {
+			label += fmt.Sprintf("\nheight %d", n.LatestHeight)
+			attrs = ", fillcolor=palegreen"
+		}
+		fmt.Fprintf(&b, "  %s [label=%s%s];\n", dotQuote(string(n.ID)), dotQuote(label), attrs)
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&b, "  %s -> %s;\n", dotQuote(string(e.From)), dotQuote(string(e.To)))
+	}
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// dotQuote returns s as a DOT quoted string, with its newlines as line breaks.
+func dotQuote(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+	return `"` + s + `"`
+}
+
+// Crawl builds the graph of the network of the node whose RPC is at addr. It calls NetInfo
+// on the node, then on each of its peers whose NodeInfo has an RPC address, and so on.
+//
+// An RPC address listening on all interfaces is reached at the IP the peer is connected
+// from. The RPC addresses are followed only if they are routable, unless opts.FollowNonRoutable
+// is set. Crawl fails only if the node at addr does not answer.
+func Crawl(ctx context.Context, addr string, opts CrawlOptions) (*NetworkGraph, error) {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = DefaultCrawlOptions.Concurrency
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultCrawlOptions.Timeout
+	}
+
+	cr := &crawler{
+		opts:   opts,
+		sem:    make(chan struct{}, opts.Concurrency),
+		nodes:  make(map[p2p.ID]*NetworkNode),
+		edges:  make(map[NetworkEdge]struct{}),
+		queued: make(map[p2p.ID]bool),
+	}
+	cr.count = 1
+	if err := cr.visit(ctx, addr, ""); err != nil {
+		return nil, err
+	}
+	cr.wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return cr.graph(), nil
+}
+
+type crawler struct {
+	opts CrawlOptions
+	sem  chan struct{}
+	wg   sync.WaitGroup
+
+	mtx   sync.Mutex
+	nodes map[p2p.ID]*NetworkNode
+	edges map[NetworkEdge]struct{}
+	// queued are the nodes whose RPC is queried, or was.
+	queued map[p2p.ID]bool
+	count  int
+}
+
+// visit queries the RPC at url, expected to be the one of the node id, if known, and follows
+// the RPC addresses of its peers.
+func (cr *crawler) visit(ctx context.Context, url string, id p2p.ID) error {
+	status, netInfo, err := cr.query(ctx, url)
+	if status == nil {
+		if id != "" {
+			cr.mtx.Lock()
+			cr.nodes[id].Error = err.Error()
+			cr.mtx.Unlock()
+		}
+		return err
+	}
+
+	// Resolving the RPC addresses may look up host names, out of the lock.
+	var urls []string
+	if netInfo != nil {
+		urls = make([]string, len(netInfo.Peers))
+		for i, peer := range netInfo.Peers {
+			urls[i] = cr.rpcURL(peer)
+		}
+	}
+
+	cr.mtx.Lock()
+	defer cr.mtx.Unlock()
+
+	node := cr.node(status.NodeInfo)
+	node.RPC = url
+	node.LatestHeight = status.SyncInfo.LatestBlockHeight
+	node.CatchingUp = status.SyncInfo.CatchingUp
+	node.Error = ""
+	if err != nil {
+		node.Error = err.Error()
+	}
+	cr.queued[node.ID] = true
+
+	if netInfo == nil {
+		return nil
+	}
+	for i, peer := range netInfo.Peers {
+		peerNode := cr.node(peer.NodeInfo)
+		edge := NetworkEdge{From: peerNode.ID, To: node.ID}
+		if peer.IsOutbound {
+			edge = NetworkEdge{From: node.ID, To: peerNode.ID}
+		}
+		cr.edges[edge] = struct{}{}
+
+		if urls[i] == "" || cr.queued[peerNode.ID] || cr.opts.MaxNodes > 0 && cr.count >= cr.opts.MaxNodes {
+			continue
+		}
+		cr.queued[peerNode.ID] = true
+		cr.count++
+		cr.wg.Add(1)
+		go func(url string, id p2p.ID) {
+			defer cr.wg.Done()
+			select {
+			case cr.sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			defer func() { <-cr.sem }()
+			_ = cr.visit(ctx, url, id)
+		}(urls[i], peerNode.ID)
+	}
+	return nil
+}
+
+// query returns the status and the NetInfo of the node at url. The status is nil if the node
+// did not answer, and the NetInfo if it failed to.
+func (cr *crawler) query(ctx context.Context, url string) (*coretypes.ResultStatus, *coretypes.ResultNetInfo, error) {
+	httpClient, err := jsonrpc.DefaultHTTPClient(url)
+	if err != nil {
+		return nil, nil, err
+	}
+	httpClient.Timeout = cr.opts.Timeout
+	// Every node is queried once, so its connection is not kept for later.
+	defer httpClient.CloseIdleConnections()
+
+	rpcClient, err := rpchttp.NewWithClient(url, "/websocket", httpClient)
+	if err != nil {
+		return nil, nil, err
+	}
+	c := NewClientFromRPCClient(rpcClient)
+
+	status, err := c.Status(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	netInfo, err := c.NetInfo(ctx)
+	return status, netInfo, err
+}
+
+// node returns the node of info, added to the graph if it is new. The nodes reached over
+// their RPC keep what it reported.
+func (cr *crawler) node(info p2p.DefaultNodeInfo) *NetworkNode {
+	node, ok := cr.nodes[info.ID()]
+	if ok && node.PublicRPC() {
+		return node
+	}
+	if !ok {
+		node = &NetworkNode{ID: info.ID()}
+		cr.nodes[node.ID] = node
+	}
+	node.Moniker = info.Moniker
+	node.Network = info.Network
+	node.Version = info.Version
+	node.RPCAddress = info.Other.RPCAddress
+	return node
+}
+
+// rpcURL returns the URL of the RPC of peer, or "" if it is not to be followed.
+func (cr *crawler) rpcURL(peer coretypes.Peer) string {
+	rpcAddr := peer.NodeInfo.Other.RPCAddress
+	scheme, hostPort, ok := strings.Cut(rpcAddr, "://")
+	if !ok {
+		scheme, hostPort = "tcp", rpcAddr
+	}
+	switch scheme {
+	case "tcp", "http":
+		scheme = "http"
+	case "https":
+	default:
+		// e.g. a unix socket.
+		return ""
+	}
+
+	host, port, err := net.SplitHostPort(hostPort)
+	if err != nil {
+		return ""
+	}
+	if ip := net.ParseIP(host); host == "" || ip != nil && ip.IsUnspecified() {
+		host = peer.RemoteIP
+	}
+	hostPort = net.JoinHostPort(host, port)
+
+	addr, err := p2p.NewNetAddressString(p2p.IDAddressString(peer.NodeInfo.ID(), hostPort))
+	if err != nil || !cr.opts.FollowNonRoutable && !addr.Routable() {
+		return ""
+	}
+	return scheme + "://" + hostPort
+}
+
+func (cr *crawler) graph() *NetworkGraph {
+	cr.mtx.Lock()
+	defer cr.mtx.Unlock()
+
+	g := &NetworkGraph{
+		Nodes: make([]*NetworkNode, 0, len(cr.nodes)),
+		Edges: make([]NetworkEdge, 0, len(cr.edges)),
+	}
+	for _, n := range cr.nodes {
+		g.Nodes = append(g.Nodes, n)
+	}
+	sort.Slice(g.Nodes, func(i, j int) bool { return g.Nodes[i].ID < g.Nodes[j].ID })
+	for e := range cr.edges {
+		g.Edges = append(g.Edges, e)
+	}
+	sort.Slice(g.Edges, func(i, j int) bool {
+		if g.Edges[i].From != g.Edges[j].From {
+			return g.Edges[i].From < g.Edges[j].From
+		}
+		return g.Edges[i].To < g.Edges[j].To
+	})
+	return g
+}
@@ -0,0 +1,157 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/strangelove-ventures/cometbft-client/crypto/ed25519"
+	"github.com/strangelove-ventures/cometbft-client/p2p"
+	coretypes "github.com/strangelove-ventures/cometbft-client/rpc/core/types"
+)
+
+// crawlNode is a node of a mock network, with a public RPC if it has a mock node.
+type crawlNode struct {
+	info  p2p.DefaultNodeInfo
+	node  *mockNode
+	peers []coretypes.Peer
+}
+
+func newCrawlNode(t *testing.T, moniker, version string, public bool) *crawlNode {
+	nodeKey := &p2p.NodeKey{PrivKey: ed25519.GenPrivKey()}
+	n := &crawlNode{info: p2p.DefaultNodeInfo{
+		DefaultNodeID: nodeKey.ID(),
+		Network:       "testing",
+		Version:       version,
+		Moniker:       moniker,
+	}}
+	if public {
+		n.node = newMockNode(t)
+		port := n.node.Listener.Addr().(*net.TCPAddr).Port
+		// The RPC listens on all interfaces, and is reached at the remote IP of the peer.
+		n.info.Other.RPCAddress = "tcp://0.0.0.0:" + strconv.Itoa(port)
+		n.node.handle("status", func(map[string]json.RawMessage) (interface{}, error) {
+			return &coretypes.ResultStatus{
+				NodeInfo: n.info,
+				SyncInfo: coretypes.SyncInfo{LatestBlockHeight: 100},
+			}, nil
+		})
+		n.node.handle("net_info", func(map[string]json.RawMessage) (interface{}, error) {
+			return &coretypes.ResultNetInfo{NPeers: len(n.peers), Peers: n.peers}, nil
+		})
+	}
+	return n
+}
+
+// connect makes from dial to.
+func connect(from, to *crawlNode) {
+	from.peers = append(from.peers, coretypes.Peer{NodeInfo: to.info, IsOutbound: true, RemoteIP: "127.0.0.1"})
+	to.peers = append(to.peers, coretypes.Peer{NodeInfo: from.info, RemoteIP: "127.0.0.1"})
+}
+
+func TestCrawl(t *testing.T) {
+	seed := newCrawlNode(t, "seed", "0.38.2", true)
+	sentry := newCrawlNode(t, "sentry", "0.37.4", true)
+	validator := newCrawlNode(t, "validator", "0.38.2", false)
+	other := newCrawlNode(t, "other", "0.38.2", true)
+	connect(sentry, seed)
+	connect(sentry, validator)
+	connect(other, sentry)
+	// The RPC of other is down.
+	other.node.Close()
+
+	opts := DefaultCrawlOptions
+	opts.FollowNonRoutable = true
+	g, err := Crawl(context.Background(), seed.node.URL, opts)
+	require.NoError(t, err)
+
+	nodes := make(map[p2p.ID]*NetworkNode)
+	for _, n := range g.Nodes {
+		nodes[n.ID] = n
+	}
+	require.Len(t, nodes, 4)
+	assert.Equal(t, seed.node.URL, nodes[seed.info.ID()].RPC)
+	assert.Equal(t, "0.37.4", nodes[sentry.info.ID()].Version)
+	assert.True(t, nodes[sentry.info.ID()].PublicRPC())
+	assert.Equal(t, int64(100), nodes[sentry.info.ID()].LatestHeight)
+	// The validator has no RPC address.
+	assert.False(t, nodes[validator.info.ID()].PublicRPC())
+	assert.Empty(t, nodes[validator.info.ID()].Error)
+	assert.False(t, nodes[other.info.ID()].PublicRPC())
+	assert.NotEmpty(t, nodes[other.info.ID()].Error)
+
+	// Both ends report the connections once.
+	assert.ElementsMatch(t, []NetworkEdge{
+		{From: sentry.info.ID(), To: seed.info.ID()},
+		{From: sentry.info.ID(), To: validator.info.ID()},
+		{From: other.info.ID(), To: sentry.info.ID()},
+	}, g.Edges)
+
+	var buf bytes.Buffer
+	require.NoError(t, g.WriteJSON(&buf))
+	var decoded NetworkGraph
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, g, &decoded)
+
+	buf.Reset()
+	require.NoError(t, g.WriteDOT(&buf))
+	dot := buf.String()
+	assert.True(t, strings.HasPrefix(dot, "digraph network {\n"))
+	assert.Contains(t, dot, `"`+string(seed.info.ID())+`" [label="seed\n0.38.2\nheight 100", fillcolor=palegreen];`)
+	assert.Contains(t, dot, `"`+string(validator.info.ID())+`" [label="validator\n0.38.2"];`)
+	assert.Contains(t, dot, `"`+string(sentry.info.ID())+`" -> "`+string(seed.info.ID())+`";`)
+}
+
+func TestCrawlClosesConnections(t *testing.T) {
+	nodes := make([]*crawlNode, 5)
+	for i := range nodes {
+		nodes[i] = newCrawlNode(t, "node"+strconv.Itoa(i), "0.38.2", true)
+		if i > 0 {
+			connect(nodes[i], nodes[i-1])
+		}
+	}
+
+	opts := DefaultCrawlOptions
+	opts.FollowNonRoutable = true
+	g, err := Crawl(context.Background(), nodes[0].node.URL, opts)
+	require.NoError(t, err)
+	require.Len(t, g.Nodes, len(nodes))
+
+	// No connection is left open once Crawl returns. The servers see them closed shortly after.
+	for _, n := range nodes {
+		require.NotZero(t, n.node.callCount("net_info"))
+		require.Eventually(t, func() bool { return n.node.conns.Load() == 0 }, time.Second, 10*time.Millisecond)
+	}
+}
+
+func TestCrawlRoutable(t *testing.T) {
+	seed := newCrawlNode(t, "seed", "0.38.2", true)
+	sentry := newCrawlNode(t, "sentry", "0.38.2", true)
+	connect(sentry, seed)
+
+	// Loopback addresses are not routable.
+	g, err := Crawl(context.Background(), seed.node.URL, DefaultCrawlOptions)
+	require.NoError(t, err)
+	require.Len(t, g.Nodes, 2)
+	assert.Zero(t, sentry.node.callCount("status"))
+
+	// MaxNodes counts the first node.
+	opts := DefaultCrawlOptions
+	opts.FollowNonRoutable = true
+	opts.MaxNodes = 1
+	_, err = Crawl(context.Background(), seed.node.URL, opts)
+	require.NoError(t, err)
+	assert.Zero(t, sentry.node.callCount("status"))
+
+	seed.node.Close()
+	_, err = Crawl(context.Background(), seed.node.URL, DefaultCrawlOptions)
+	require.Error(t, err)
+}
@@ -0,0 +1,64 @@
+package client
+
+import (
+	"context"
+
+	coretypes "github.com/strangelove-ventures/cometbft-client/rpc/core/types"
+	"github.com/strangelove-ventures/cometbft-client/types"
+)
+
+// Subscribe subscribes to the events matching query over the websocket connection, which must
+// have been started with Start. The events can be decoded with NewEventResponse.
+//
+// The channel is never closed. Events are dropped if it is full, see rpcclient.EventsClient.
+func (c *Client) Subscribe(
+	ctx context.Context,
+	subscriber string,
+	query string,
+	outCapacity ...int,
+) (<-chan coretypes.ResultEvent, error) {
+	return c.rpcClient.Subscribe(ctx, subscriber, query, outCapacity...)
+}
+
+// Unsubscribe cancels the subscription to query.
+func (c *Client) Unsubscribe(ctx context.Context, subscriber, query string) error {
+	return c.rpcClient.Unsubscribe(ctx, subscriber, query)
+}
+
+// UnsubscribeAll cancels every subscription.
+func (c *Client) UnsubscribeAll(ctx context.Context, subscriber string) error {
+	return c.rpcClient.UnsubscribeAll(ctx, subscriber)
+}
+
+// NewEventResponse decodes the data of an event received from a subscription.
+// The type of the event is only known for the event data registered in the types package;
+// it is left empty for the others.
+func NewEventResponse(event coretypes.ResultEvent) *EventResponse {
+	res := &EventResponse{Query: event.Query}
+
+	switch data := event.Data.(type) {
+	case types.EventDataNewBlock:
+		res.Type = types.EventNewBlock
+		if data.Block != nil {
+			res.Height = data.Block.Height
+			res.Header = &data.Block.Header
+		}
+		res.Events = parseEvents(data.ResultFinalizeBlock.Events)
+	case types.EventDataNewBlockHeader:
+		res.Type = types.EventNewBlockHeader
+		res.Height = data.Header.Height
+		res.Header = &data.Header
+	case types.EventDataNewBlockEvents:
+		res.Type = types.EventNewBlockEvents
+		res.Height = data.Height
+		res.Events = parseEvents(data.Events)
+	case types.EventDataTx:
+		res.Type = types.EventTx
+		res.Height = data.Height
+		res.Tx = newTxResponse(data.Tx, data.TxResult)
+	case types.EventDataValidatorSetUpdates:
+		res.Type = types.EventValidatorSetUpdates
+	}
+
+	return res
+}
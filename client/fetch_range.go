@@ -0,0 +1,542 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	cmtos "github.com/strangelove-ventures/cometbft-client/libs/os"
+	rpcclient "github.com/strangelove-ventures/cometbft-client/rpc/client"
+	coretypes "github.com/strangelove-ventures/cometbft-client/rpc/core/types"
+	jsonrpc "github.com/strangelove-ventures/cometbft-client/rpc/jsonrpc/client"
+	"github.com/strangelove-ventures/cometbft-client/types"
+)
+
+// maxBlockMetas is the maximum number of block metas returned by BlockchainInfo.
+const maxBlockMetas = 20
+
+// RangeBlock is a block fetched by FetchRange, with its results.
+type RangeBlock struct {
+	Height  int64
+	Block   *coretypes.ResultBlock
+	Results *BlockResponse
+}
+
+// FetchRangeOptions configures FetchRange.
+type FetchRangeOptions struct {
+	// OnBlock is called with every block of the range, in height order. Returning an error stops FetchRange.
+	OnBlock func(*RangeBlock) error
+	// Concurrency is the maximum number of requests in flight. It is halved whenever the node
+	// answers with a 429 or 5xx status, and raised back by one after as many successful requests.
+	Concurrency int
+	// BatchSize is the maximum number of heights fetched with a single batch request.
+	BatchSize int
+	// BatchBytes is the maximum total size of the blocks fetched with a single batch request,
+	// as reported by their block metas. A larger block is fetched on its own.
+	BatchBytes int
+	// Buffer is the maximum number of heights fetched ahead of OnBlock.
+	Buffer int
+	// MaxRetries is the maximum number of retries of a request answered with a 429 or 5xx status.
+	MaxRetries int
+	// RetryBackoff is the delay before the first retry of a request. It is doubled on every retry.
+	RetryBackoff time.Duration
+	// CheckpointFile is the file where the last height passed to OnBlock is stored, so that
+	// a FetchRange from the same height resumes after it. It is not stored if empty.
+	CheckpointFile string
+}
+
+// DefaultFetchRangeOptions are the default options of FetchRange, without OnBlock.
+var DefaultFetchRangeOptions = FetchRangeOptions{
+	Concurrency:  8,
+	BatchSize:    10,
+	BatchBytes:   8 << 20, // 8 MiB
+	Buffer:       200,
+	MaxRetries:   5,
+	RetryBackoff: 500 * time.Millisecond,
+}
+
+// rangeCheckpoint is the content of FetchRangeOptions.CheckpointFile.
+type rangeCheckpoint struct {
+	From   int64 `json:"from"`
+	Height int64 `json:"height"`
+}
+
+// FetchRange fetches the blocks and block results from height from to height to, inclusive,
+// and passes them to opts.OnBlock in height order.
+//
+// The blocks are planned with the block metas of BlockchainInfo and fetched by batches of
+// heights over concurrent requests, a single batch request each if the RPC client is a
+// rpcclient.BlockBatcher, as rpchttp.HTTP and the cache decorator are. Fetching never gets
+// more than opts.Buffer heights ahead of OnBlock. The requests answered with a 429 or 5xx status are retried with a lower concurrency.
+func (c *Client) FetchRange(ctx context.Context, from, to int64, opts FetchRangeOptions) error {
+	switch {
+	case opts.OnBlock == nil:
+		return errors.New("OnBlock is required")
+	case from < 1:
+		return fmt.Errorf("invalid height %d", from)
+	case to < from:
+		return fmt.Errorf("invalid range %d-%d", from, to)
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = DefaultFetchRangeOptions.Concurrency
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultFetchRangeOptions.BatchSize
+	}
+	if opts.BatchBytes <= 0 {
+		opts.BatchBytes = DefaultFetchRangeOptions.BatchBytes
+	}
+	if opts.Buffer <= 0 {
+		opts.Buffer = DefaultFetchRangeOptions.Buffer
+	}
+	if opts.Buffer < opts.BatchSize {
+		// A batch must fit in the buffer, otherwise it is never dispatched.
+		opts.BatchSize = opts.Buffer
+	}
+	if opts.MaxRetries < 0 {
+		opts.MaxRetries = 0
+	}
+	if opts.RetryBackoff <= 0 {
+		opts.RetryBackoff = DefaultFetchRangeOptions.RetryBackoff
+	}
+
+	start, err := loadRangeCheckpoint(opts.CheckpointFile, from)
+	if err != nil {
+		return err
+	}
+	if start > to {
+		return nil
+	}
+
+	f := &rangeFetcher{
+		c:       c,
+		opts:    opts,
+		from:    from,
+		to:      to,
+		limiter: newAdaptiveLimiter(opts.Concurrency),
+		fetched: make(map[int64]*RangeBlock),
+		ready:   make(chan struct{}, 1),
+		window:  make(chan struct{}, opts.Buffer),
+	}
+	return f.run(ctx, start)
+}
+
+func loadRangeCheckpoint(file string, from int64) (int64, error) {
+	if file == "" {
+		return from, nil
+	}
+
+	bz, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return from, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var cp rangeCheckpoint
+	if err := json.Unmarshal(bz, &cp); err != nil {
+		return 0, fmt.Errorf("invalid checkpoint %s: %w", file, err)
+	}
+	if cp.From != from || cp.Height < from {
+		// The checkpoint is for another range.
+		return from, nil
+	}
+	return cp.Height + 1, nil
+}
+
+// rangeFetcher implements FetchRange. The planner sends batches of heights to the workers,
+// which store the fetched blocks until the emitter passes them to OnBlock in order.
+type rangeFetcher struct {
+	c        *Client
+	opts     FetchRangeOptions
+	from, to int64
+
+	limiter *adaptiveLimiter
+
+	mtx     sync.Mutex
+	fetched map[int64]*RangeBlock
+	err     error
+
+	// ready is signaled when a block is fetched or a worker failed.
+	ready chan struct{}
+	// window holds a token for every height planned and not passed to OnBlock yet.
+	window chan struct{}
+
+	checkpointed int64
+}
+
+func (f *rangeFetcher) run(ctx context.Context, start int64) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	batches := make(chan []*types.BlockMeta)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(batches)
+		if err := f.plan(ctx, start, batches); err != nil {
+			f.fail(err)
+		}
+	}()
+
+	for i := 0; i < f.opts.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range batches {
+				if err := f.fetchBatch(ctx, batch); err != nil {
+					f.fail(err)
+					return
+				}
+			}
+		}()
+	}
+
+	f.checkpointed = start - 1
+	err := f.emit(ctx, start)
+
+	cancel()
+	wg.Wait()
+	return err
+}
+
+// plan sends the heights from start to f.to to the workers, grouped in batches.
+func (f *rangeFetcher) plan(ctx context.Context, start int64, batches chan<- []*types.BlockMeta) error {
+	var (
+		batch      []*types.BlockMeta
+		batchBytes int
+	)
+	send := func() error {
+		// Wait for room in the buffer.
+		for range batch {
+			select {
+			case f.window <- struct{}{}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		select {
+		case batches <- batch:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		batch, batchBytes = nil, 0
+		return nil
+	}
+
+	for height := start; height <= f.to; {
+		end := height + maxBlockMetas - 1
+		if end > f.to {
+			end = f.to
+		}
+
+		var metas []*types.BlockMeta
+		err := f.retry(ctx, func() error {
+			res, err := f.c.rpcClient.BlockchainInfo(ctx, height, end)
+			if err != nil {
+				return err
+			}
+			if res.LastHeight < end {
+				return fmt.Errorf("height %d is not available, the latest height is %d", end, res.LastHeight)
+			}
+			metas = res.BlockMetas
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		sort.Slice(metas, func(i, j int) bool { return metas[i].Header.Height < metas[j].Header.Height })
+		for i, meta := range metas {
+			if meta.Header.Height != height+int64(i) {
+				return fmt.Errorf("missing block meta of height %d", height+int64(i))
+			}
+		}
+		if int64(len(metas)) != end-height+1 {
+			return fmt.Errorf("missing block meta of height %d", height+int64(len(metas)))
+		}
+
+		for _, meta := range metas {
+			if len(batch) > 0 && (len(batch) == f.opts.BatchSize || batchBytes+meta.BlockSize > f.opts.BatchBytes) {
+				if err := send(); err != nil {
+					return err
+				}
+			}
+			batch = append(batch, meta)
+			batchBytes += meta.BlockSize
+		}
+		height = end + 1
+	}
+
+	if len(batch) > 0 {
+		return send()
+	}
+	return nil
+}
+
+// fetchBatch fetches the blocks and block results of the batch and stores them.
+func (f *rangeFetcher) fetchBatch(ctx context.Context, metas []*types.BlockMeta) error {
+	var (
+		blocks  []*coretypes.ResultBlock
+		results []*coretypes.ResultBlockResults
+	)
+	err := f.retry(ctx, func() (err error) {
+		blocks, results, err = f.fetch(ctx, metas)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	rangeBlocks := make([]*RangeBlock, len(metas))
+	for i, meta := range metas {
+		if blocks[i].Block == nil || blocks[i].Block.Height != meta.Header.Height {
+			return fmt.Errorf("node returned another block instead of block %d", meta.Header.Height)
+		}
+		if !bytes.Equal(blocks[i].BlockID.Hash, meta.BlockID.Hash) {
+			return fmt.Errorf("hash %v of block %d does not match the hash %v of its meta",
+				blocks[i].BlockID.Hash, meta.Header.Height, meta.BlockID.Hash)
+		}
+		rangeBlocks[i] = &RangeBlock{
+			Height:  meta.Header.Height,
+			Block:   blocks[i],
+			Results: newBlockResponse(results[i]),
+		}
+	}
+
+	f.mtx.Lock()
+	for _, b := range rangeBlocks {
+		f.fetched[b.Height] = b
+	}
+	f.mtx.Unlock()
+	f.signal()
+
+	return nil
+}
+
+// fetch fetches the blocks and block results with a single batch request, if the client is a
+// rpcclient.BlockBatcher. Otherwise, it sends two requests per height.
+func (f *rangeFetcher) fetch(
+	ctx context.Context,
+	metas []*types.BlockMeta,
+) ([]*coretypes.ResultBlock, []*coretypes.ResultBlockResults, error) {
+	blocks := make([]*coretypes.ResultBlock, len(metas))
+	results := make([]*coretypes.ResultBlockResults, len(metas))
+
+	var batch rpcclient.BlockBatch
+	if batcher, ok := f.c.rpcClient.(rpcclient.BlockBatcher); ok {
+		batch = batcher.NewBlockBatch()
+	}
+	if batch == nil {
+		for i, meta := range metas {
+			height := meta.Header.Height
+			block, err := f.c.rpcClient.Block(ctx, &height)
+			if err != nil {
+				return nil, nil, err
+			}
+			res, err := f.c.rpcClient.BlockResults(ctx, &height)
+			if err != nil {
+				return nil, nil, err
+			}
+			blocks[i], results[i] = block, res
+		}
+		return blocks, results, nil
+	}
+
+	// The results of a batch are filled in once it is sent.
+	for i, meta := range metas {
+		height := meta.Header.Height
+		block, err := batch.Block(ctx, &height)
+		if err != nil {
+			return nil, nil, err
+		}
+		res, err := batch.BlockResults(ctx, &height)
+		if err != nil {
+			return nil, nil, err
+		}
+		blocks[i], results[i] = block, res
+	}
+	res, err := batch.Send(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, r := range res {
+		if r.Err != nil {
+			return nil, nil, r.Err
+		}
+	}
+	return blocks, results, nil
+}
+
+// retry calls fn within the concurrency limit, and retries it while it fails with a temporary error.
+func (f *rangeFetcher) retry(ctx context.Context, fn func() error) error {
+	backoff := f.opts.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		if err := f.limiter.acquire(ctx); err != nil {
+			return err
+		}
+		err := fn()
+		temporary := isTemporaryHTTPError(err)
+		f.limiter.release(temporary)
+
+		if !temporary || attempt == f.opts.MaxRetries {
+			return err
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		backoff *= 2
+	}
+}
+
+// isTemporaryHTTPError returns true if err is due to a 429 or 5xx HTTP status.
+func isTemporaryHTTPError(err error) bool {
+	var statusErr *jsonrpc.HTTPStatusError
+	return errors.As(err, &statusErr) && statusErr.Temporary()
+}
+
+// emit passes the fetched blocks to OnBlock in height order, and checkpoints the progress
+// whenever it has to wait for the next block.
+func (f *rangeFetcher) emit(ctx context.Context, start int64) error {
+	for height := start; height <= f.to; {
+		f.mtx.Lock()
+		block, ok := f.fetched[height]
+		delete(f.fetched, height)
+		err := f.err
+		f.mtx.Unlock()
+
+		if !ok {
+			if err != nil {
+				return errors.Join(err, f.checkpoint(height-1))
+			}
+			if err := f.checkpoint(height - 1); err != nil {
+				return err
+			}
+			select {
+			case <-f.ready:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			continue
+		}
+
+		if err := f.opts.OnBlock(block); err != nil {
+			return errors.Join(err, f.checkpoint(height-1))
+		}
+		<-f.window
+		height++
+	}
+
+	return f.checkpoint(f.to)
+}
+
+func (f *rangeFetcher) checkpoint(height int64) error {
+	if f.opts.CheckpointFile == "" || height <= f.checkpointed {
+		return nil
+	}
+
+	bz, err := json.Marshal(rangeCheckpoint{From: f.from, Height: height})
+	if err != nil {
+		return err
+	}
+	if err := cmtos.WriteFileAtomic(f.opts.CheckpointFile, bz, 0o644); err != nil {
+		return err
+	}
+	f.checkpointed = height
+	return nil
+}
+
+func (f *rangeFetcher) fail(err error) {
+	f.mtx.Lock()
+	if f.err == nil {
+		f.err = err
+	}
+	f.mtx.Unlock()
+	f.signal()
+}
+
+func (f *rangeFetcher) signal() {
+	select {
+	case f.ready <- struct{}{}:
+	default:
+	}
+}
+
+// adaptiveLimiter limits the number of requests in flight. The limit is halved on every
+// temporary failure and raised by one after as many successes as the current limit.
+type adaptiveLimiter struct {
+	mtx       sync.Mutex
+	max       int
+	limit     int
+	inFlight  int
+	successes int
+	// changed is closed and replaced whenever a request completes.
+	changed chan struct{}
+}
+
+func newAdaptiveLimiter(max int) *adaptiveLimiter {
+	return &adaptiveLimiter{
+		max:     max,
+		limit:   max,
+		changed: make(chan struct{}),
+	}
+}
+
+func (l *adaptiveLimiter) acquire(ctx context.Context) error {
+	for {
+		l.mtx.Lock()
+		if l.inFlight < l.limit {
+			l.inFlight++
+			l.mtx.Unlock()
+			return nil
+		}
+		changed := l.changed
+		l.mtx.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (l *adaptiveLimiter) release(temporaryFailure bool) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	l.inFlight--
+	switch {
+	case temporaryFailure:
+		l.limit = (l.limit + 1) / 2
+		l.successes = 0
+	case l.limit < l.max:
+		l.successes++
+		if l.successes >= l.limit {
+			l.limit++
+			l.successes = 0
+		}
+	}
+
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
+
+// current returns the current limit.
+func (l *adaptiveLimiter) current() int {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	return l.limit
+}
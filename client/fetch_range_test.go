@@ -0,0 +1,234 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	abci "github.com/strangelove-ventures/cometbft-client/abci/types"
+	"github.com/strangelove-ventures/cometbft-client/rpc/client/cache"
+	coretypes "github.com/strangelove-ventures/cometbft-client/rpc/core/types"
+	"github.com/strangelove-ventures/cometbft-client/types"
+)
+
+func paramHeight(params map[string]json.RawMessage, key string) (int64, error) {
+	return strconv.ParseInt(strings.Trim(string(params[key]), `"`), 10, 64)
+}
+
+func blockHash(height int64) []byte {
+	return []byte(fmt.Sprintf("hash-%d", height))
+}
+
+// mockChain serves the blocks of a chain of the given height.
+func mockChain(node *mockNode, latest int64) {
+	node.handle("blockchain", func(params map[string]json.RawMessage) (interface{}, error) {
+		minHeight, err := paramHeight(params, "minHeight")
+		if err != nil {
+			return nil, err
+		}
+		maxHeight, err := paramHeight(params, "maxHeight")
+		if err != nil {
+			return nil, err
+		}
+		if maxHeight > latest {
+			maxHeight = latest
+		}
+
+		// The metas are in descending order.
+		res := &coretypes.ResultBlockchainInfo{LastHeight: latest}
+		for h := maxHeight; h >= minHeight; h-- {
+			res.BlockMetas = append(res.BlockMetas, &types.BlockMeta{
+				BlockID:   types.BlockID{Hash: blockHash(h)},
+				BlockSize: 1000,
+				Header:    types.Header{Height: h},
+			})
+		}
+		return res, nil
+	})
+	node.handle("block", func(params map[string]json.RawMessage) (interface{}, error) {
+		height, err := paramHeight(params, "height")
+		if err != nil {
+			return nil, err
+		}
+		return &coretypes.ResultBlock{
+			BlockID: types.BlockID{Hash: blockHash(height)},
+			Block:   &types.Block{Header: types.Header{Height: height}},
+		}, nil
+	})
+	node.handle("block_results", func(params map[string]json.RawMessage) (interface{}, error) {
+		height, err := paramHeight(params, "height")
+		if err != nil {
+			return nil, err
+		}
+		return &coretypes.ResultBlockResults{
+			Height: height,
+			FinalizeBlockEvents: []abci.Event{{
+				Type:       "block",
+				Attributes: []abci.EventAttribute{{Key: "height", Value: strconv.FormatInt(height, 10)}},
+			}},
+		}, nil
+	})
+}
+
+func TestFetchRange(t *testing.T) {
+	node := newMockNode(t)
+	mockChain(node, 100)
+
+	var heights []int64
+	opts := DefaultFetchRangeOptions
+	opts.BatchSize = 4
+	opts.Buffer = 10
+	opts.Concurrency = 3
+	opts.OnBlock = func(b *RangeBlock) error {
+		require.Equal(t, b.Height, b.Block.Block.Height)
+		require.Equal(t, b.Height, b.Results.Height)
+		require.Equal(t, strconv.FormatInt(b.Height, 10), b.Results.Events[0].Attributes[0].Value)
+		heights = append(heights, b.Height)
+		return nil
+	}
+
+	require.NoError(t, node.client(t).FetchRange(context.Background(), 5, 49, opts))
+
+	require.Len(t, heights, 45)
+	for i, h := range heights {
+		require.Equal(t, int64(5+i), h)
+	}
+	require.Equal(t, 3, node.callCount("blockchain"))
+	require.Equal(t, 45, node.callCount("block"))
+	require.Equal(t, 45, node.callCount("block_results"))
+
+	err := node.client(t).FetchRange(context.Background(), 95, 105, opts)
+	require.ErrorContains(t, err, "height 105 is not available")
+}
+
+func TestFetchRangeCache(t *testing.T) {
+	node := newMockNode(t)
+	mockChain(node, 100)
+
+	rpcClient, err := newRPCClient(node.URL, 5*time.Second)
+	require.NoError(t, err)
+	cacheClient, err := cache.New(rpcClient, cache.DefaultOptions)
+	require.NoError(t, err)
+	c := NewClientFromRPCClient(cacheClient)
+
+	var count int
+	opts := DefaultFetchRangeOptions
+	opts.BatchSize = 5
+	opts.OnBlock = func(b *RangeBlock) error {
+		require.Equal(t, b.Height, b.Block.Block.Height)
+		require.Equal(t, b.Height, b.Results.Height)
+		count++
+		return nil
+	}
+
+	// The blocks are still fetched in batches through the cache: one request per 5 heights.
+	require.NoError(t, c.FetchRange(context.Background(), 1, 20, opts))
+	require.Equal(t, 20, count)
+	require.Equal(t, 20, node.callCount("block"))
+	require.Equal(t, int32(1+4), node.requests.Load())
+
+	// Then they are served from the cache, and only the metas are requested.
+	count = 0
+	require.NoError(t, c.FetchRange(context.Background(), 1, 20, opts))
+	require.Equal(t, 20, count)
+	require.Equal(t, 20, node.callCount("block"))
+	require.Equal(t, int32(1+4+1), node.requests.Load())
+}
+
+func TestFetchRangeThrottled(t *testing.T) {
+	node := newMockNode(t)
+	mockChain(node, 100)
+	node.throttle(3)
+
+	var count int
+	opts := DefaultFetchRangeOptions
+	opts.RetryBackoff = time.Millisecond
+	opts.OnBlock = func(b *RangeBlock) error {
+		count++
+		return nil
+	}
+
+	require.NoError(t, node.client(t).FetchRange(context.Background(), 1, 30, opts))
+	require.Equal(t, 30, count)
+
+	node.throttle(100)
+	opts.MaxRetries = 2
+	err := node.client(t).FetchRange(context.Background(), 1, 30, opts)
+	require.ErrorContains(t, err, "429")
+}
+
+func TestFetchRangeCheckpoint(t *testing.T) {
+	node := newMockNode(t)
+	mockChain(node, 100)
+
+	errStop := errors.New("stop")
+	var last atomic.Int64
+	opts := DefaultFetchRangeOptions
+	opts.CheckpointFile = filepath.Join(t.TempDir(), "checkpoint.json")
+	opts.OnBlock = func(b *RangeBlock) error {
+		if b.Height == 20 {
+			return errStop
+		}
+		last.Store(b.Height)
+		return nil
+	}
+
+	err := node.client(t).FetchRange(context.Background(), 1, 50, opts)
+	require.ErrorIs(t, err, errStop)
+	require.Equal(t, int64(19), last.Load())
+
+	// Resumes after the last height passed to OnBlock.
+	var first int64
+	opts.OnBlock = func(b *RangeBlock) error {
+		if first == 0 {
+			first = b.Height
+		}
+		last.Store(b.Height)
+		return nil
+	}
+	require.NoError(t, node.client(t).FetchRange(context.Background(), 1, 50, opts))
+	require.Equal(t, int64(20), first)
+	require.Equal(t, int64(50), last.Load())
+
+	// A completed range is not fetched again, another range is.
+	first = 0
+	require.NoError(t, node.client(t).FetchRange(context.Background(), 1, 50, opts))
+	require.Zero(t, first)
+	require.NoError(t, node.client(t).FetchRange(context.Background(), 10, 12, opts))
+	require.Equal(t, int64(10), first)
+}
+
+func TestAdaptiveLimiter(t *testing.T) {
+	l := newAdaptiveLimiter(8)
+	ctx := context.Background()
+
+	for _, want := range []int{4, 2, 1, 1} {
+		require.NoError(t, l.acquire(ctx))
+		l.release(true)
+		require.Equal(t, want, l.current())
+	}
+
+	// Raised by one after as many successes as the limit.
+	for _, want := range []int{2, 2, 3, 3, 3, 4} {
+		require.NoError(t, l.acquire(ctx))
+		l.release(false)
+		require.Equal(t, want, l.current())
+	}
+
+	// The limit is enforced.
+	for i := 0; i < 4; i++ {
+		require.NoError(t, l.acquire(ctx))
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, l.acquire(ctx), context.DeadlineExceeded)
+}
@@ -0,0 +1,355 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	cmtjson "github.com/strangelove-ventures/cometbft-client/libs/json"
+	"github.com/strangelove-ventures/cometbft-client/types"
+)
+
+// genesisChunkSize is the size of a decoded genesis chunk served by CometBFT.
+const genesisChunkSize = 16 * 1024 * 1024
+
+// GenesisDownloadOptions can be used to provide options for DownloadGenesis
+// other than the DefaultGenesisDownloadOptions.
+type GenesisDownloadOptions struct {
+	// Concurrency is the maximum number of chunks fetched (and held in memory) at once.
+	Concurrency int
+	// MaxRetries is the number of times a failed chunk request is retried.
+	MaxRetries int
+	// RetryDelay is the time to wait between retries of a chunk request.
+	RetryDelay time.Duration
+	// MaxChunkSize is the maximum size of a single decoded chunk.
+	MaxChunkSize int
+	// MaxGenesisSize is the maximum size of the whole decoded genesis document, 0 means no limit.
+	MaxGenesisSize int64
+}
+
+// DefaultGenesisDownloadOptions fetch four chunks at a time, retry every chunk
+// three times and accept chunks of up to 16MB with no limit on the total size.
+var DefaultGenesisDownloadOptions = GenesisDownloadOptions{
+	Concurrency:    4,
+	MaxRetries:     3,
+	RetryDelay:     time.Second,
+	MaxChunkSize:   genesisChunkSize,
+	MaxGenesisSize: 0,
+}
+
+// ErrGenesisTooLarge is returned when the genesis document exceeds the configured size limits.
+var ErrGenesisTooLarge = errors.New("genesis document exceeds size limit")
+
+// DownloadGenesis fetches the genesis document in chunks and writes the decoded bytes to w in order.
+// It returns the number of bytes written.
+func (c *Client) DownloadGenesis(ctx context.Context, w io.Writer) (int64, error) {
+	return c.DownloadGenesisWithOptions(ctx, w, DefaultGenesisDownloadOptions)
+}
+
+// DownloadGenesisWithOptions fetches the genesis document in chunks, using up to opts.Concurrency
+// parallel requests, and writes the decoded bytes to w strictly in chunk order.
+// At most opts.Concurrency decoded chunks are held in memory at any time.
+func (c *Client) DownloadGenesisWithOptions(
+	ctx context.Context,
+	w io.Writer,
+	opts GenesisDownloadOptions,
+) (int64, error) {
+	if opts.Concurrency < 1 {
+		opts.Concurrency = 1
+	}
+	if opts.MaxChunkSize <= 0 {
+		opts.MaxChunkSize = genesisChunkSize
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	first, total, err := c.fetchGenesisChunk(ctx, 0, 0, opts)
+	if err != nil {
+		return 0, err
+	}
+
+	var written int64
+	write := func(data []byte) error {
+		written += int64(len(data))
+		if opts.MaxGenesisSize > 0 && written > opts.MaxGenesisSize {
+			return fmt.Errorf("%w: more than %d bytes", ErrGenesisTooLarge, opts.MaxGenesisSize)
+		}
+
+		_, err := w.Write(data)
+		return err
+	}
+
+	if err := write(first); err != nil {
+		return written, err
+	}
+
+	// Each chunk gets its own result channel which is queued in order, so the writer can
+	// wait on them sequentially while the fetches themselves complete in any order.
+	// The queue capacity, plus the chunk currently awaited by the writer, bounds the number
+	// of chunks in flight.
+	queue := make(chan chan genesisChunkResult, opts.Concurrency-1)
+	go func() {
+		defer close(queue)
+		for i := 1; i < total; i++ {
+			resCh := make(chan genesisChunkResult, 1)
+			select {
+			case queue <- resCh:
+			case <-ctx.Done():
+				return
+			}
+
+			go func(id int) {
+				data, _, err := c.fetchGenesisChunk(ctx, id, total, opts)
+				resCh <- genesisChunkResult{data: data, err: err}
+			}(i)
+		}
+	}()
+
+	for resCh := range queue {
+		var res genesisChunkResult
+		select {
+		case res = <-resCh:
+		case <-ctx.Done():
+			return written, ctx.Err()
+		}
+		if res.err != nil {
+			return written, res.err
+		}
+
+		if err := write(res.data); err != nil {
+			return written, err
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return written, err
+	}
+
+	return written, nil
+}
+
+type genesisChunkResult struct {
+	data []byte
+	err  error
+}
+
+// fetchGenesisChunk fetches and decodes the genesis chunk with the given id, retrying failed requests.
+// If total is greater than zero, the chunk must report the same number of total chunks.
+func (c *Client) fetchGenesisChunk(
+	ctx context.Context,
+	id int,
+	total int,
+	opts GenesisDownloadOptions,
+) ([]byte, int, error) {
+	var err error
+	for attempt := 0; attempt <= opts.MaxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(opts.RetryDelay):
+			case <-ctx.Done():
+				return nil, 0, ctx.Err()
+			}
+		}
+
+		var data []byte
+		var chunkTotal int
+		data, chunkTotal, err = c.getGenesisChunk(ctx, id, total, opts.MaxChunkSize)
+		if err == nil {
+			return data, chunkTotal, nil
+		}
+		if ctx.Err() != nil || errors.Is(err, ErrGenesisTooLarge) {
+			return nil, 0, err
+		}
+	}
+
+	return nil, 0, fmt.Errorf("failed to fetch genesis chunk %d after %d attempts: %w", id, opts.MaxRetries+1, err)
+}
+
+func (c *Client) getGenesisChunk(ctx context.Context, id int, total int, maxSize int) ([]byte, int, error) {
+	res, err := c.rpcClient.GenesisChunked(ctx, uint(id))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if res.ChunkNumber != id {
+		return nil, 0, fmt.Errorf("expected genesis chunk %d, got %d", id, res.ChunkNumber)
+	}
+	if res.TotalChunks < 1 {
+		return nil, 0, fmt.Errorf("invalid total number of genesis chunks: %d", res.TotalChunks)
+	}
+	if total > 0 && res.TotalChunks != total {
+		return nil, 0, fmt.Errorf("total number of genesis chunks changed from %d to %d", total, res.TotalChunks)
+	}
+	if base64.StdEncoding.DecodedLen(len(res.Data)) > maxSize+2 {
+		return nil, 0, fmt.Errorf("%w: chunk %d is larger than %d bytes", ErrGenesisTooLarge, id, maxSize)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(res.Data)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to decode genesis chunk %d: %w", id, err)
+	}
+	if len(data) > maxSize {
+		return nil, 0, fmt.Errorf("%w: chunk %d is larger than %d bytes", ErrGenesisTooLarge, id, maxSize)
+	}
+
+	return data, res.TotalChunks, nil
+}
+
+//-----------------------------------------------------------------------------
+
+// GenesisFile is a genesis document parsed from a file without loading the application state into memory.
+type GenesisFile struct {
+	// Doc holds every field of the genesis document except AppState, which is left empty.
+	Doc *types.GenesisDoc
+
+	appState *io.SectionReader
+}
+
+// AppState returns a reader over the raw JSON of the app_state field.
+// The returned reader is independent of any other reader returned by AppState.
+func (g *GenesisFile) AppState() *io.SectionReader {
+	if g.appState == nil {
+		return io.NewSectionReader(emptyReaderAt{}, 0, 0)
+	}
+	return io.NewSectionReader(g.appState, 0, g.appState.Size())
+}
+
+// ParseGenesisFile parses a genesis document of the given size from r.
+// The app_state field is only scanned to find its bounds, so memory usage does not depend on its size.
+func ParseGenesisFile(r io.ReaderAt, size int64) (*GenesisFile, error) {
+	dec := json.NewDecoder(io.NewSectionReader(r, 0, size))
+
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read genesis document: %w", err)
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return nil, fmt.Errorf("genesis document must be a JSON object, got %v", tok)
+	}
+
+	fields := make(map[string]json.RawMessage)
+	var appState *io.SectionReader
+
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read genesis document: %w", err)
+		}
+
+		key, ok := tok.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected genesis field name, got %v", tok)
+		}
+
+		if key != "app_state" {
+			var raw json.RawMessage
+			if err := dec.Decode(&raw); err != nil {
+				return nil, fmt.Errorf("failed to read genesis field %s: %w", key, err)
+			}
+			fields[key] = raw
+			continue
+		}
+
+		start, err := valueStart(r, dec.InputOffset(), size)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read genesis field %s: %w", key, err)
+		}
+		if err := skipValue(dec); err != nil {
+			return nil, fmt.Errorf("failed to read genesis field %s: %w", key, err)
+		}
+		appState = io.NewSectionReader(r, start, dec.InputOffset()-start)
+	}
+
+	// The remaining fields are small, so they are decoded through the regular Amino JSON path.
+	bz, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := new(types.GenesisDoc)
+	if err := cmtjson.Unmarshal(bz, doc); err != nil {
+		return nil, fmt.Errorf("failed to decode genesis document: %w", err)
+	}
+
+	return &GenesisFile{Doc: doc, appState: appState}, nil
+}
+
+// valueStart returns the offset of the first byte of the JSON value following the object key
+// that ends at offset, skipping whitespace and the name separator.
+func valueStart(r io.ReaderAt, offset int64, size int64) (int64, error) {
+	br := bufio.NewReader(io.NewSectionReader(r, offset, size-offset))
+	for {
+		b, err := br.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+
+		switch b {
+		case ' ', '\t', '\r', '\n', ':':
+			offset++
+		default:
+			return offset, nil
+		}
+	}
+}
+
+// skipValue consumes the next JSON value from dec without keeping it in memory.
+func skipValue(dec *json.Decoder) error {
+	depth := 0
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+
+		if delim, ok := tok.(json.Delim); ok {
+			switch delim {
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+			}
+		}
+
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+type emptyReaderAt struct{}
+
+func (emptyReaderAt) ReadAt([]byte, int64) (int, error) { return 0, io.EOF }
+
+// VerifyAgainstChain checks that genDoc defines the chain served by the node, by comparing it
+// with the header at the genesis initial height. See types.GenesisDoc.VerifyInitialHeader.
+//
+// An error is returned if the node has pruned the initial block, e.g. because it was state synced.
+func (c *Client) VerifyAgainstChain(ctx context.Context, genDoc *types.GenesisDoc) error {
+	initialHeight := genDoc.InitialHeight
+	if initialHeight == 0 {
+		initialHeight = 1
+	}
+
+	status, err := c.rpcClient.Status(ctx)
+	if err != nil {
+		return err
+	}
+	if earliest := status.SyncInfo.EarliestBlockHeight; earliest > initialHeight {
+		return fmt.Errorf("node does not have the initial block at height %d, earliest available height is %d",
+			initialHeight, earliest)
+	}
+
+	res, err := c.rpcClient.Header(ctx, &initialHeight)
+	if err != nil {
+		return err
+	}
+
+	return genDoc.VerifyInitialHeader(res.Header)
+}
@@ -0,0 +1,168 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	coretypes "github.com/strangelove-ventures/cometbft-client/rpc/core/types"
+	"github.com/strangelove-ventures/cometbft-client/types"
+)
+
+const testGenesis = `{
+  "genesis_time": "2023-06-01T00:00:00Z",
+  "chain_id": "test-chain",
+  "initial_height": "5",
+  "validators": [
+    {
+      "address": "",
+      "pub_key": {
+        "type": "tendermint/PubKeyEd25519",
+        "value": "2AHQzoxRF3I2zrLmlbTL8FwgqgzTI24pnzazZDOK81U="
+      },
+      "power": "10",
+      "name": "val0"
+    }
+  ],
+  "app_hash": "",
+  "app_state" : {"bank": {"balances": [{"address": "a", "coins": [1, 2, 3]}]}, "memo": "}]"}
+}`
+
+// serveGenesisChunks registers a genesis_chunked handler that splits genesis into chunks of chunkSize bytes.
+func serveGenesisChunks(node *mockNode, genesis []byte, chunkSize int, fail func(id int) bool) {
+	total := (len(genesis) + chunkSize - 1) / chunkSize
+
+	node.handle("genesis_chunked", func(params map[string]json.RawMessage) (interface{}, error) {
+		id, err := strconv.Atoi(strings.Trim(string(params["chunk"]), `"`))
+		if err != nil {
+			return nil, err
+		}
+		if fail != nil && fail(id) {
+			return nil, errors.New("temporary failure")
+		}
+
+		end := (id + 1) * chunkSize
+		if end > len(genesis) {
+			end = len(genesis)
+		}
+
+		return &coretypes.ResultGenesisChunk{
+			ChunkNumber: id,
+			TotalChunks: total,
+			Data:        base64.StdEncoding.EncodeToString(genesis[id*chunkSize : end]),
+		}, nil
+	})
+}
+
+func TestDownloadGenesis(t *testing.T) {
+	node := newMockNode(t)
+	serveGenesisChunks(node, []byte(testGenesis), 7, nil)
+
+	opts := DefaultGenesisDownloadOptions
+	opts.Concurrency = 3
+
+	var buf bytes.Buffer
+	n, err := node.client(t).DownloadGenesisWithOptions(context.Background(), &buf, opts)
+	require.NoError(t, err)
+	require.Equal(t, int64(len(testGenesis)), n)
+	require.Equal(t, testGenesis, buf.String())
+}
+
+func TestDownloadGenesisRetries(t *testing.T) {
+	node := newMockNode(t)
+
+	var failures atomic.Int32
+	serveGenesisChunks(node, []byte(testGenesis), 16, func(id int) bool {
+		return id == 2 && failures.Add(1) <= 2
+	})
+
+	opts := DefaultGenesisDownloadOptions
+	opts.RetryDelay = time.Millisecond
+
+	var buf bytes.Buffer
+	_, err := node.client(t).DownloadGenesisWithOptions(context.Background(), &buf, opts)
+	require.NoError(t, err)
+	require.Equal(t, testGenesis, buf.String())
+
+	opts.MaxRetries = 1
+	failures.Store(0)
+	buf.Reset()
+	_, err = node.client(t).DownloadGenesisWithOptions(context.Background(), &buf, opts)
+	require.Error(t, err)
+}
+
+func TestDownloadGenesisSizeLimits(t *testing.T) {
+	node := newMockNode(t)
+	serveGenesisChunks(node, []byte(testGenesis), 64, nil)
+
+	opts := DefaultGenesisDownloadOptions
+	opts.MaxChunkSize = 32
+	_, err := node.client(t).DownloadGenesisWithOptions(context.Background(), io.Discard, opts)
+	require.ErrorIs(t, err, ErrGenesisTooLarge)
+
+	opts = DefaultGenesisDownloadOptions
+	opts.MaxGenesisSize = 100
+	_, err = node.client(t).DownloadGenesisWithOptions(context.Background(), io.Discard, opts)
+	require.ErrorIs(t, err, ErrGenesisTooLarge)
+}
+
+func TestParseGenesisFile(t *testing.T) {
+	r := strings.NewReader(testGenesis)
+
+	genesis, err := ParseGenesisFile(r, r.Size())
+	require.NoError(t, err)
+
+	require.Equal(t, "test-chain", genesis.Doc.ChainID)
+	require.Equal(t, int64(5), genesis.Doc.InitialHeight)
+	require.Len(t, genesis.Doc.Validators, 1)
+	require.Equal(t, int64(10), genesis.Doc.Validators[0].Power)
+	require.Empty(t, genesis.Doc.AppState)
+
+	appState, err := io.ReadAll(genesis.AppState())
+	require.NoError(t, err)
+	require.Equal(t, `{"bank": {"balances": [{"address": "a", "coins": [1, 2, 3]}]}, "memo": "}]"}`, string(appState))
+	require.True(t, json.Valid(appState))
+}
+
+func TestVerifyAgainstChain(t *testing.T) {
+	r := strings.NewReader(testGenesis)
+	genesis, err := ParseGenesisFile(r, r.Size())
+	require.NoError(t, err)
+	genDoc := genesis.Doc
+	valHash, err := genDoc.ValidatorHash()
+	require.NoError(t, err)
+
+	var earliest atomic.Int64
+	earliest.Store(5)
+	node := newMockNode(t)
+	node.handle("status", func(map[string]json.RawMessage) (interface{}, error) {
+		return &coretypes.ResultStatus{SyncInfo: coretypes.SyncInfo{EarliestBlockHeight: earliest.Load()}}, nil
+	})
+	node.handle("header", func(params map[string]json.RawMessage) (interface{}, error) {
+		require.Equal(t, `"5"`, string(params["height"]))
+		return &coretypes.ResultHeader{Header: &types.Header{
+			ChainID:        "test-chain",
+			Height:         5,
+			ValidatorsHash: valHash,
+		}}, nil
+	})
+
+	c := node.client(t)
+	require.NoError(t, c.VerifyAgainstChain(context.Background(), genDoc))
+
+	genDoc.ChainID = "other-chain"
+	require.ErrorIs(t, c.VerifyAgainstChain(context.Background(), genDoc), types.ErrGenesisMismatch)
+
+	earliest.Store(100)
+	require.Error(t, c.VerifyAgainstChain(context.Background(), genDoc))
+}
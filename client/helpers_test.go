@@ -0,0 +1,159 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/strangelove-ventures/cometbft-client/rpc/jsonrpc/types"
+)
+
+// rpcHandler serves a single JSON-RPC method of a mock node.
+type rpcHandler func(params map[string]json.RawMessage) (interface{}, error)
+
+// mockNode is a minimal JSON-RPC server that answers the registered methods.
+type mockNode struct {
+	*httptest.Server
+
+	mtx      sync.Mutex
+	handlers map[string]rpcHandler
+	calls    map[string]int
+
+	throttled atomic.Int32
+	// requests is the number of HTTP requests received, a batch counting as one.
+	requests atomic.Int32
+	// conns is the number of open connections.
+	conns atomic.Int32
+}
+
+func newMockNode(t *testing.T) *mockNode {
+	t.Helper()
+
+	n := &mockNode{
+		handlers: make(map[string]rpcHandler),
+		calls:    make(map[string]int),
+	}
+	n.Server = httptest.NewUnstartedServer(http.HandlerFunc(n.serveHTTP))
+	n.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		switch state {
+		case http.StateNew:
+			n.conns.Add(1)
+		case http.StateClosed, http.StateHijacked:
+			n.conns.Add(-1)
+		}
+	}
+	n.Start()
+	t.Cleanup(n.Close)
+
+	return n
+}
+
+// handle registers h as the handler for method.
+func (n *mockNode) handle(method string, h rpcHandler) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	n.handlers[method] = h
+}
+
+// callCount returns the number of requests received for method.
+func (n *mockNode) callCount(method string) int {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	return n.calls[method]
+}
+
+// client returns a Client connected to the mock node.
+func (n *mockNode) client(t *testing.T) *Client {
+	t.Helper()
+
+	c, err := NewClient(n.URL, 5*time.Second)
+	require.NoError(t, err)
+
+	return c
+}
+
+func (n *mockNode) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	n.requests.Add(1)
+	if n.throttled.Load() > 0 && n.throttled.Add(-1) >= 0 {
+		http.Error(w, "too many requests", http.StatusTooManyRequests)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		n.write(w, types.RPCParseError(err))
+		return
+	}
+
+	// A batch of requests is answered with an array of responses.
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '[' {
+		var reqs []types.RPCRequest
+		if err := json.Unmarshal(body, &reqs); err != nil {
+			n.write(w, types.RPCParseError(err))
+			return
+		}
+		resps := make([]types.RPCResponse, len(reqs))
+		for i, req := range reqs {
+			resps[i] = n.serve(req)
+		}
+		n.write(w, resps)
+		return
+	}
+
+	var req types.RPCRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		n.write(w, types.RPCParseError(err))
+		return
+	}
+	n.write(w, n.serve(req))
+}
+
+// throttle answers the next count HTTP requests with 429 Too Many Requests.
+func (n *mockNode) throttle(count int32) {
+	n.throttled.Store(count)
+}
+
+func (n *mockNode) serve(req types.RPCRequest) types.RPCResponse {
+	n.mtx.Lock()
+	h, ok := n.handlers[req.Method]
+	n.calls[req.Method]++
+	n.mtx.Unlock()
+
+	if !ok {
+		return types.RPCMethodNotFoundError(req.ID)
+	}
+
+	params := make(map[string]json.RawMessage)
+	if len(req.Params) > 0 {
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return types.RPCInvalidParamsError(req.ID, err)
+		}
+	}
+
+	res, err := h(params)
+	if err != nil {
+		return types.RPCInternalError(req.ID, err)
+	}
+
+	return types.NewRPCSuccessResponse(req.ID, res)
+}
+
+func (n *mockNode) write(w http.ResponseWriter, resp interface{}) {
+	bz, err := json.Marshal(resp)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(bz)
+}
@@ -0,0 +1,382 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/strangelove-ventures/cometbft-client/libs/service"
+	"github.com/strangelove-ventures/cometbft-client/types"
+)
+
+// TxLeftReason tells why a transaction left the mempool.
+type TxLeftReason byte
+
+const (
+	// TxIncluded - the transaction was included in a block.
+	TxIncluded TxLeftReason = iota + 1
+	// TxEvicted - the transaction was removed without being included in a block,
+	// e.g. because it failed the recheck or the mempool was full.
+	TxEvicted
+)
+
+func (r TxLeftReason) String() string {
+	switch r {
+	case TxIncluded:
+		return "included"
+	case TxEvicted:
+		return "evicted"
+	default:
+		return "unknown"
+	}
+}
+
+// TxEntered is emitted when a transaction is first seen in the mempool.
+type TxEntered struct {
+	Tx   types.Tx
+	Key  types.TxKey
+	Time time.Time
+}
+
+// TxLeft is emitted when a transaction is no longer in the mempool.
+type TxLeft struct {
+	Tx     types.Tx
+	Key    types.TxKey
+	Reason TxLeftReason
+	// Height is the height of the block including the transaction, or the latest height
+	// when the eviction was observed.
+	Height int64
+	Time   time.Time
+	// Duration is the time the transaction was observed in the mempool.
+	Duration time.Duration
+}
+
+// MempoolSample is the size of the mempool at a point in time.
+type MempoolSample struct {
+	Time  time.Time
+	Txs   int
+	Bytes int64
+}
+
+// MempoolTrend is the evolution of the mempool size over the samples kept by the watcher,
+// computed with a least squares fit.
+type MempoolTrend struct {
+	Samples        int
+	Duration       time.Duration
+	TxsPerSecond   float64
+	BytesPerSecond float64
+}
+
+// MempoolWatcherOptions configures a MempoolWatcher.
+type MempoolWatcherOptions struct {
+	// PollInterval is the delay between two polls of the mempool.
+	PollInterval time.Duration
+	// Limit is the maximum number of transactions fetched per poll. Nodes cap it at 100.
+	Limit int
+	// TrendSamples is the number of samples used to compute the trend.
+	TrendSamples int
+
+	// OnTxEntered and OnTxLeft are called synchronously for every event, they must not block.
+	OnTxEntered func(TxEntered)
+	OnTxLeft    func(TxLeft)
+}
+
+// DefaultMempoolWatcherOptions are the default options used by NewMempoolWatcher.
+var DefaultMempoolWatcherOptions = MempoolWatcherOptions{
+	PollInterval: time.Second,
+	Limit:        100,
+	TrendSamples: 60,
+}
+
+// MempoolWatcher polls the mempool of a node and emits an event for every transaction
+// entering or leaving it.
+//
+// Only the first Limit transactions of the mempool are observed. Since transactions are
+// kept in insertion order, a transaction that is no longer returned has left the mempool,
+// while the transactions beyond the limit are seen once the older ones are removed.
+// The transactions in the mempool when the watcher starts do not emit TxEntered.
+type MempoolWatcher struct {
+	service.BaseService
+
+	client *Client
+	opts   MempoolWatcherOptions
+
+	mtx      sync.Mutex
+	txs      map[types.TxKey]mempoolTx
+	height   int64                   // the blocks above it may include the observed transactions
+	blocks   map[int64][]types.TxKey // transactions of the blocks above height already fetched
+	samples  []MempoolSample
+	next     int
+	entered  int64
+	included int64
+	evicted  int64
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+type mempoolTx struct {
+	tx        types.Tx
+	firstSeen time.Time
+}
+
+// MempoolWatcherStats holds the number of events emitted since the watcher started.
+type MempoolWatcherStats struct {
+	Entered  int64
+	Included int64
+	Evicted  int64
+}
+
+// NewMempoolWatcher returns a MempoolWatcher polling the mempool with c.
+func NewMempoolWatcher(c *Client, opts MempoolWatcherOptions) *MempoolWatcher {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = DefaultMempoolWatcherOptions.PollInterval
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = DefaultMempoolWatcherOptions.Limit
+	}
+	if opts.TrendSamples < 2 {
+		opts.TrendSamples = DefaultMempoolWatcherOptions.TrendSamples
+	}
+
+	mw := &MempoolWatcher{
+		client:  c,
+		opts:    opts,
+		samples: make([]MempoolSample, 0, opts.TrendSamples),
+	}
+	mw.BaseService = *service.NewBaseService(nil, "MempoolWatcher", mw)
+
+	return mw
+}
+
+// OnStart implements service.Service by starting to poll the mempool.
+func (mw *MempoolWatcher) OnStart() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	mw.cancel = cancel
+	mw.done = make(chan struct{})
+
+	go mw.pollRoutine(ctx)
+
+	return nil
+}
+
+// OnStop implements service.Service by stopping the polling.
+func (mw *MempoolWatcher) OnStop() {
+	mw.cancel()
+	<-mw.done
+}
+
+// Txs returns the transactions currently observed in the mempool.
+func (mw *MempoolWatcher) Txs() []types.Tx {
+	mw.mtx.Lock()
+	defer mw.mtx.Unlock()
+
+	txs := make([]types.Tx, 0, len(mw.txs))
+	for _, mtx := range mw.txs {
+		txs = append(txs, mtx.tx)
+	}
+	return txs
+}
+
+// Stats returns the number of events emitted since the watcher started.
+func (mw *MempoolWatcher) Stats() MempoolWatcherStats {
+	mw.mtx.Lock()
+	defer mw.mtx.Unlock()
+
+	return MempoolWatcherStats{Entered: mw.entered, Included: mw.included, Evicted: mw.evicted}
+}
+
+// Size returns the last sample of the mempool size. It is the zero value before the first poll.
+func (mw *MempoolWatcher) Size() MempoolSample {
+	mw.mtx.Lock()
+	defer mw.mtx.Unlock()
+
+	if len(mw.samples) == 0 {
+		return MempoolSample{}
+	}
+	return mw.samples[(mw.next+len(mw.samples)-1)%len(mw.samples)]
+}
+
+// Trend returns the evolution of the mempool size over the last TrendSamples polls.
+func (mw *MempoolWatcher) Trend() MempoolTrend {
+	mw.mtx.Lock()
+	defer mw.mtx.Unlock()
+
+	n := len(mw.samples)
+	trend := MempoolTrend{Samples: n}
+	if n < 2 {
+		return trend
+	}
+
+	oldest := mw.samples[mw.next%n]
+	newest := mw.samples[(mw.next+n-1)%n]
+	trend.Duration = newest.Time.Sub(oldest.Time)
+
+	var sumT, sumTT, sumTxs, sumTTxs, sumBytes, sumTBytes float64
+	for _, s := range mw.samples {
+		t := s.Time.Sub(oldest.Time).Seconds()
+		sumT += t
+		sumTT += t * t
+		sumTxs += float64(s.Txs)
+		sumTTxs += t * float64(s.Txs)
+		sumBytes += float64(s.Bytes)
+		sumTBytes += t * float64(s.Bytes)
+	}
+
+	denom := float64(n)*sumTT - sumT*sumT
+	if denom == 0 {
+		return trend
+	}
+	trend.TxsPerSecond = (float64(n)*sumTTxs - sumT*sumTxs) / denom
+	trend.BytesPerSecond = (float64(n)*sumTBytes - sumT*sumBytes) / denom
+
+	return trend
+}
+
+func (mw *MempoolWatcher) pollRoutine(ctx context.Context) {
+	defer close(mw.done)
+
+	ticker := time.NewTicker(mw.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := mw.poll(ctx); err != nil && ctx.Err() == nil {
+			mw.Logger.Error("failed to poll mempool", "err", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (mw *MempoolWatcher) poll(ctx context.Context) error {
+	// The height is fetched before the mempool: the block at this height may be committed
+	// without the mempool being updated yet, but a transaction in the mempool is not included
+	// in a lower block. The next poll looks for it from this height.
+	status, err := mw.client.Status(ctx)
+	if err != nil {
+		return err
+	}
+	before := status.SyncInfo.LatestBlockHeight
+
+	num, err := mw.client.rpcClient.NumUnconfirmedTxs(ctx)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+
+	var txs []types.Tx
+	if num.Total > 0 {
+		limit := mw.opts.Limit
+		res, err := mw.client.rpcClient.UnconfirmedTxs(ctx, &limit)
+		if err != nil {
+			return err
+		}
+		txs = res.Txs
+	}
+
+	// And after: a transaction removed from the mempool after its inclusion is always found
+	// in a block committed by then.
+	status, err = mw.client.Status(ctx)
+	if err != nil {
+		return err
+	}
+	latest := status.SyncInfo.LatestBlockHeight
+
+	mw.mtx.Lock()
+	from := mw.height + 1
+	initialized := mw.txs != nil
+	fetched := mw.blocks
+	mw.mtx.Unlock()
+
+	// The blocks from the height fetched before the last poll's mempool, some of them
+	// fetched by the last poll already.
+	blocks := make(map[int64][]types.TxKey)
+	includedAt := make(map[types.TxKey]int64)
+	if initialized {
+		for h := from; h <= latest; h++ {
+			keys, ok := fetched[h]
+			if !ok {
+				height := h
+				res, err := mw.client.Block(ctx, &height)
+				if err != nil {
+					return err
+				}
+				keys = make([]types.TxKey, len(res.Block.Txs))
+				for i, tx := range res.Block.Txs {
+					keys[i] = tx.Key()
+				}
+			}
+			if h >= before {
+				blocks[h] = keys
+			}
+			for _, key := range keys {
+				includedAt[key] = h
+			}
+		}
+	}
+
+	var (
+		entered []TxEntered
+		left    []TxLeft
+	)
+
+	mw.mtx.Lock()
+	current := make(map[types.TxKey]mempoolTx, len(txs))
+	for _, tx := range txs {
+		key := tx.Key()
+		if mtx, ok := mw.txs[key]; ok {
+			current[key] = mtx
+			continue
+		}
+		current[key] = mempoolTx{tx: tx, firstSeen: now}
+		if initialized {
+			entered = append(entered, TxEntered{Tx: tx, Key: key, Time: now})
+		}
+	}
+	for key, mtx := range mw.txs {
+		if _, ok := current[key]; ok {
+			continue
+		}
+		event := TxLeft{Tx: mtx.tx, Key: key, Reason: TxEvicted, Height: latest, Time: now, Duration: now.Sub(mtx.firstSeen)}
+		if height, ok := includedAt[key]; ok {
+			event.Reason = TxIncluded
+			event.Height = height
+			mw.included++
+		} else {
+			mw.evicted++
+		}
+		left = append(left, event)
+	}
+	mw.entered += int64(len(entered))
+	mw.txs = current
+	mw.height = max(before-1, 0)
+	mw.blocks = blocks
+	mw.addSample(MempoolSample{Time: now, Txs: num.Total, Bytes: num.TotalBytes})
+	mw.mtx.Unlock()
+
+	if mw.opts.OnTxEntered != nil {
+		for _, event := range entered {
+			mw.opts.OnTxEntered(event)
+		}
+	}
+	if mw.opts.OnTxLeft != nil {
+		for _, event := range left {
+			mw.opts.OnTxLeft(event)
+		}
+	}
+
+	return nil
+}
+
+func (mw *MempoolWatcher) addSample(s MempoolSample) {
+	if len(mw.samples) < cap(mw.samples) {
+		mw.samples = append(mw.samples, s)
+		return
+	}
+	mw.samples[mw.next] = s
+	mw.next = (mw.next + 1) % len(mw.samples)
+}
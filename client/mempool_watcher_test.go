@@ -0,0 +1,222 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	coretypes "github.com/strangelove-ventures/cometbft-client/rpc/core/types"
+	"github.com/strangelove-ventures/cometbft-client/types"
+)
+
+// mockMempool serves the mempool and blocks of a mock node.
+type mockMempool struct {
+	mtx    sync.Mutex
+	txs    []types.Tx
+	blocks map[int64]types.Txs
+	height int64
+	// afterUnconfirmed is called with the lock held after the next unconfirmed_txs request.
+	afterUnconfirmed func()
+}
+
+func (m *mockMempool) set(height int64, txs ...string) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	m.height = height
+	m.txs = nil
+	for _, tx := range txs {
+		m.txs = append(m.txs, types.Tx(tx))
+	}
+}
+
+func (m *mockMempool) commit(height int64, txs ...string) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	for _, tx := range txs {
+		m.blocks[height] = append(m.blocks[height], types.Tx(tx))
+	}
+}
+
+func (m *mockMempool) register(node *mockNode) {
+	unconfirmed := func(map[string]json.RawMessage) (interface{}, error) {
+		m.mtx.Lock()
+		defer m.mtx.Unlock()
+
+		var bytes int64
+		for _, tx := range m.txs {
+			bytes += int64(len(tx))
+		}
+		return &coretypes.ResultUnconfirmedTxs{Count: len(m.txs), Total: len(m.txs), TotalBytes: bytes, Txs: m.txs}, nil
+	}
+	node.handle("unconfirmed_txs", func(params map[string]json.RawMessage) (interface{}, error) {
+		res, err := unconfirmed(params)
+
+		m.mtx.Lock()
+		defer m.mtx.Unlock()
+		if m.afterUnconfirmed != nil {
+			m.afterUnconfirmed()
+			m.afterUnconfirmed = nil
+		}
+		return res, err
+	})
+	node.handle("num_unconfirmed_txs", unconfirmed)
+
+	node.handle("status", func(map[string]json.RawMessage) (interface{}, error) {
+		m.mtx.Lock()
+		defer m.mtx.Unlock()
+		return &coretypes.ResultStatus{SyncInfo: coretypes.SyncInfo{LatestBlockHeight: m.height}}, nil
+	})
+	node.handle("block", func(params map[string]json.RawMessage) (interface{}, error) {
+		height, err := strconv.ParseInt(strings.Trim(string(params["height"]), `"`), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		m.mtx.Lock()
+		defer m.mtx.Unlock()
+		return &coretypes.ResultBlock{Block: &types.Block{
+			Header: types.Header{Height: height},
+			Data:   types.Data{Txs: m.blocks[height]},
+		}}, nil
+	})
+}
+
+func TestMempoolWatcher(t *testing.T) {
+	mempool := &mockMempool{blocks: make(map[int64]types.Txs)}
+	node := newMockNode(t)
+	mempool.register(node)
+
+	var (
+		entered []TxEntered
+		left    []TxLeft
+	)
+	opts := DefaultMempoolWatcherOptions
+	opts.OnTxEntered = func(e TxEntered) { entered = append(entered, e) }
+	opts.OnTxLeft = func(e TxLeft) { left = append(left, e) }
+	mw := NewMempoolWatcher(node.client(t), opts)
+	ctx := context.Background()
+
+	// The initial content of the mempool does not emit events.
+	mempool.set(10, "a", "bb")
+	require.NoError(t, mw.poll(ctx))
+	require.Empty(t, entered)
+	require.Len(t, mw.Txs(), 2)
+
+	mempool.commit(11, "x", "a")
+	mempool.set(11, "bb", "ccc")
+	require.NoError(t, mw.poll(ctx))
+	require.Len(t, entered, 1)
+	require.Equal(t, types.Tx("ccc"), entered[0].Tx)
+	require.Equal(t, types.Tx("ccc").Key(), entered[0].Key)
+	require.Len(t, left, 1)
+	require.Equal(t, types.Tx("a"), left[0].Tx)
+	require.Equal(t, TxIncluded, left[0].Reason)
+	require.Equal(t, int64(11), left[0].Height)
+
+	mempool.set(12, "ccc")
+	require.NoError(t, mw.poll(ctx))
+	require.Len(t, left, 2)
+	require.Equal(t, types.Tx("bb"), left[1].Tx)
+	require.Equal(t, TxEvicted, left[1].Reason)
+	require.Equal(t, int64(12), left[1].Height)
+
+	mempool.set(12)
+	require.NoError(t, mw.poll(ctx))
+	require.Len(t, left, 3)
+	require.Empty(t, mw.Txs())
+
+	require.Equal(t, MempoolWatcherStats{Entered: 1, Included: 1, Evicted: 2}, mw.Stats())
+	require.Equal(t, 0, mw.Size().Txs)
+	require.Equal(t, 4, mw.Trend().Samples)
+}
+
+func TestMempoolWatcherCommitDuringPoll(t *testing.T) {
+	mempool := &mockMempool{blocks: make(map[int64]types.Txs)}
+	node := newMockNode(t)
+	mempool.register(node)
+
+	var left []TxLeft
+	opts := DefaultMempoolWatcherOptions
+	opts.OnTxLeft = func(e TxLeft) { left = append(left, e) }
+	mw := NewMempoolWatcher(node.client(t), opts)
+	ctx := context.Background()
+
+	mempool.set(10, "a")
+	require.NoError(t, mw.poll(ctx))
+
+	// Block 11 includes a, and is committed after the mempool is fetched.
+	mempool.afterUnconfirmed = func() {
+		mempool.blocks[11] = types.Txs{types.Tx("a")}
+		mempool.height = 11
+	}
+	require.NoError(t, mw.poll(ctx))
+	require.Empty(t, left)
+
+	mempool.set(12)
+	require.NoError(t, mw.poll(ctx))
+	require.Len(t, left, 1)
+	require.Equal(t, TxIncluded, left[0].Reason)
+	require.Equal(t, int64(11), left[0].Height)
+	// Blocks 10 to 12 were fetched once each.
+	require.Equal(t, 3, node.callCount("block"))
+}
+
+func TestMempoolWatcherTrend(t *testing.T) {
+	opts := DefaultMempoolWatcherOptions
+	opts.TrendSamples = 3
+	mw := NewMempoolWatcher(nil, opts)
+
+	require.Equal(t, MempoolTrend{}, mw.Trend())
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		mw.addSample(MempoolSample{
+			Time:  start.Add(time.Duration(i) * time.Second),
+			Txs:   10 * i,
+			Bytes: int64(1000 - 100*i),
+		})
+	}
+
+	require.Equal(t, 40, mw.Size().Txs)
+
+	trend := mw.Trend()
+	require.Equal(t, 3, trend.Samples)
+	require.Equal(t, 2*time.Second, trend.Duration)
+	require.InDelta(t, 10, trend.TxsPerSecond, 1e-9)
+	require.InDelta(t, -100, trend.BytesPerSecond, 1e-9)
+}
+
+func TestMempoolWatcherService(t *testing.T) {
+	mempool := &mockMempool{blocks: make(map[int64]types.Txs)}
+	node := newMockNode(t)
+	mempool.register(node)
+	mempool.set(1, "a")
+
+	left := make(chan TxLeft, 1)
+	opts := DefaultMempoolWatcherOptions
+	opts.PollInterval = 10 * time.Millisecond
+	opts.OnTxLeft = func(e TxLeft) { left <- e }
+
+	mw := NewMempoolWatcher(node.client(t), opts)
+	require.NoError(t, mw.Start())
+	t.Cleanup(func() { _ = mw.Stop() })
+
+	require.Eventually(t, func() bool { return len(mw.Txs()) == 1 }, 5*time.Second, 10*time.Millisecond)
+	mempool.commit(2, "a")
+	mempool.set(2)
+
+	select {
+	case e := <-left:
+		require.Equal(t, TxIncluded, e.Reason)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for TxLeft")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	abci "github.com/strangelove-ventures/cometbft-client/abci/types"
 	"github.com/strangelove-ventures/cometbft-client/libs/bytes"
+	coretypes "github.com/strangelove-ventures/cometbft-client/rpc/core/types"
 	"github.com/strangelove-ventures/cometbft-client/types"
 )
 
@@ -43,4 +44,24 @@ type TxResponse struct {
 	ExecTx ExecTxResponse
 	Tx     types.Tx
 	Proof  types.TxProof
+	// Outcome and CheckTx are only set by BroadcastAndWait, in which case the fields above
+	// except Hash are only set if Outcome is BroadcastIncluded or BroadcastIncludedFailed.
+	Outcome BroadcastOutcome `json:",omitempty"`
+	// CheckTx is the result of BroadcastTxSync. It is nil if the transaction was already in the cache.
+	CheckTx *coretypes.ResultBroadcastTx `json:",omitempty"`
+}
+
+// EventResponse is used in place of the CometBFT type ResultEvent.
+// This allows us to handle the decoding of events internally so that we can return events to consumers as raw strings.
+type EventResponse struct {
+	Query string
+	// Type is the type of the event, i.e. the value of tm.event.
+	Type   string
+	Height int64
+	// Header is set for NewBlock and NewBlockHeader events.
+	Header *types.Header
+	// Tx is set for Tx events.
+	Tx *TxResponse
+	// Events are the finalize block events of NewBlock and NewBlockEvents events.
+	Events sdk.StringEvents
 }
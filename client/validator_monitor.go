@@ -0,0 +1,616 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	cmtos "github.com/strangelove-ventures/cometbft-client/libs/os"
+	"github.com/strangelove-ventures/cometbft-client/libs/service"
+	"github.com/strangelove-ventures/cometbft-client/types"
+)
+
+// SigningStatus is the outcome of a validator's vote at a height, as recorded in the commit.
+type SigningStatus byte
+
+const (
+	// SigningStatusSigned - the validator precommitted the committed block.
+	SigningStatusSigned SigningStatus = iota + 1
+	// SigningStatusNil - the validator precommitted nil.
+	SigningStatusNil
+	// SigningStatusMissed - no precommit from the validator is included in the commit.
+	SigningStatusMissed
+)
+
+func (s SigningStatus) String() string {
+	switch s {
+	case SigningStatusSigned:
+		return "signed"
+	case SigningStatusNil:
+		return "nil"
+	case SigningStatusMissed:
+		return "missed"
+	default:
+		return "unknown"
+	}
+}
+
+// ValidatorSigningInfo summarizes the signing activity of a validator.
+// Signed, Nil and Missed count the heights of the sliding window only, all other fields
+// cover every height observed by the monitor.
+type ValidatorSigningInfo struct {
+	Address types.Address `json:"address"`
+
+	// Window is the number of heights in the sliding window, at most ValidatorMonitorOptions.WindowSize.
+	Window int `json:"window"`
+	Signed int `json:"signed"`
+	Nil    int `json:"nil"`
+	Missed int `json:"missed"`
+
+	// MissedStreak is the number of consecutive heights missed up to the last observed height.
+	MissedStreak    int `json:"missed_streak"`
+	MaxMissedStreak int `json:"max_missed_streak"`
+
+	FirstMissedHeight int64 `json:"first_missed_height"`
+	LastMissedHeight  int64 `json:"last_missed_height"`
+	LastSignedHeight  int64 `json:"last_signed_height"`
+	LastHeight        int64 `json:"last_height"`
+}
+
+// Uptime returns the fraction of heights in the sliding window at which the validator voted,
+// either for the block or for nil. It returns 1 if the window is empty.
+func (vi ValidatorSigningInfo) Uptime() float64 {
+	if vi.Window == 0 {
+		return 1
+	}
+	return float64(vi.Signed+vi.Nil) / float64(vi.Window)
+}
+
+// ValidatorAlertKind identifies the condition reported by a ValidatorAlert.
+type ValidatorAlertKind byte
+
+const (
+	// ValidatorAlertMissedStreak - the missed streak reached ValidatorMonitorOptions.MissedStreakThreshold.
+	ValidatorAlertMissedStreak ValidatorAlertKind = iota + 1
+	// ValidatorAlertMissedWindow - the missed heights in the window reached ValidatorMonitorOptions.MissedWindowThreshold.
+	ValidatorAlertMissedWindow
+	// ValidatorAlertRecovered - the validator is back below every threshold after an alert.
+	ValidatorAlertRecovered
+)
+
+func (k ValidatorAlertKind) String() string {
+	switch k {
+	case ValidatorAlertMissedStreak:
+		return "missed_streak"
+	case ValidatorAlertMissedWindow:
+		return "missed_window"
+	case ValidatorAlertRecovered:
+		return "recovered"
+	default:
+		return "unknown"
+	}
+}
+
+// ValidatorAlert is raised when a threshold is crossed. Alerts are edge triggered: an alert
+// of a given kind is raised again only after the validator has recovered.
+type ValidatorAlert struct {
+	Kind   ValidatorAlertKind
+	Height int64
+	Info   ValidatorSigningInfo
+}
+
+// ValidatorMonitorOptions configures a ValidatorMonitor.
+type ValidatorMonitorOptions struct {
+	// WindowSize is the number of heights kept in the per-validator sliding window.
+	WindowSize int
+	// PollInterval is the delay between two checks for new heights.
+	PollInterval time.Duration
+	// StartHeight is the first height to process. If 0, the monitor starts at the latest height.
+	// It is ignored when resuming from a checkpoint.
+	StartHeight int64
+	// Addresses restricts the monitor to the given validators. If empty, every validator is tracked.
+	Addresses []types.Address
+
+	// MissedStreakThreshold raises ValidatorAlertMissedStreak, 0 disables it.
+	MissedStreakThreshold int
+	// MissedWindowThreshold raises ValidatorAlertMissedWindow, 0 disables it.
+	MissedWindowThreshold int
+	// OnAlert is called synchronously for every alert, it must not block.
+	OnAlert func(ValidatorAlert)
+
+	// CheckpointFile is the path of the file the state is persisted to and resumed from.
+	// If empty, the state is not persisted.
+	CheckpointFile string
+	// CheckpointInterval is the number of heights between two checkpoints.
+	CheckpointInterval int64
+}
+
+// DefaultValidatorMonitorOptions are the default options used by NewValidatorMonitor.
+var DefaultValidatorMonitorOptions = ValidatorMonitorOptions{
+	WindowSize:            100,
+	PollInterval:          time.Second,
+	MissedStreakThreshold: 5,
+	MissedWindowThreshold: 50,
+	CheckpointInterval:    10,
+}
+
+// ValidatorMonitor tracks the signing activity of validators from the commits of a node.
+//
+// Once started, it fetches the commit of every new height. Alternatively, commits can be
+// fed with ProcessCommit, e.g. the LastCommit of blocks received from NewBlock events,
+// without starting the service.
+type ValidatorMonitor struct {
+	service.BaseService
+
+	client *Client
+	opts   ValidatorMonitorOptions
+	filter map[string]struct{}
+
+	mtx        sync.Mutex
+	height     int64 // last processed height
+	validators map[string]*validatorWindow
+	valset     *types.ValidatorSet // cached validator set, matched by hash
+	valsetHash []byte
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewValidatorMonitor returns a ValidatorMonitor fetching commits with c.
+func NewValidatorMonitor(c *Client, opts ValidatorMonitorOptions) *ValidatorMonitor {
+	if opts.WindowSize <= 0 {
+		opts.WindowSize = DefaultValidatorMonitorOptions.WindowSize
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = DefaultValidatorMonitorOptions.PollInterval
+	}
+	if opts.CheckpointInterval <= 0 {
+		opts.CheckpointInterval = DefaultValidatorMonitorOptions.CheckpointInterval
+	}
+
+	vm := &ValidatorMonitor{
+		client:     c,
+		opts:       opts,
+		validators: make(map[string]*validatorWindow),
+	}
+	if len(opts.Addresses) > 0 {
+		vm.filter = make(map[string]struct{}, len(opts.Addresses))
+		for _, addr := range opts.Addresses {
+			vm.filter[string(addr)] = struct{}{}
+		}
+	}
+	vm.BaseService = *service.NewBaseService(nil, "ValidatorMonitor", vm)
+
+	return vm
+}
+
+// OnStart implements service.Service by resuming from the checkpoint, if any, and starting
+// to poll the node for new heights.
+func (vm *ValidatorMonitor) OnStart() error {
+	if err := vm.loadCheckpoint(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	vm.cancel = cancel
+	vm.done = make(chan struct{})
+
+	go vm.pollRoutine(ctx)
+
+	return nil
+}
+
+// OnStop implements service.Service by stopping the polling and writing a last checkpoint.
+func (vm *ValidatorMonitor) OnStop() {
+	vm.cancel()
+	<-vm.done
+
+	if err := vm.Checkpoint(); err != nil {
+		vm.Logger.Error("failed to write checkpoint", "err", err)
+	}
+}
+
+// Height returns the last processed height.
+func (vm *ValidatorMonitor) Height() int64 {
+	vm.mtx.Lock()
+	defer vm.mtx.Unlock()
+	return vm.height
+}
+
+// SigningInfo returns the signing info of the validator with the given address.
+// The second return value is false if the validator has not been observed.
+func (vm *ValidatorMonitor) SigningInfo(addr types.Address) (ValidatorSigningInfo, bool) {
+	vm.mtx.Lock()
+	defer vm.mtx.Unlock()
+
+	w, ok := vm.validators[string(addr)]
+	if !ok {
+		return ValidatorSigningInfo{}, false
+	}
+	return w.info, true
+}
+
+// AllSigningInfo returns the signing info of every observed validator, sorted by address.
+func (vm *ValidatorMonitor) AllSigningInfo() []ValidatorSigningInfo {
+	vm.mtx.Lock()
+	defer vm.mtx.Unlock()
+
+	infos := make([]ValidatorSigningInfo, 0, len(vm.validators))
+	for _, w := range vm.validators {
+		infos = append(infos, w.info)
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return bytes.Compare(infos[i].Address, infos[j].Address) < 0
+	})
+	return infos
+}
+
+// ProcessCommit records the votes of commit, whose signatures must be ordered as the
+// validators of valset, the validator set at the commit height.
+// Commits at or below the last processed height are ignored.
+func (vm *ValidatorMonitor) ProcessCommit(commit *types.Commit, valset *types.ValidatorSet) error {
+	if commit == nil {
+		return errors.New("nil commit")
+	}
+	if valset.IsNilOrEmpty() {
+		return errors.New("nil or empty validator set")
+	}
+	if len(commit.Signatures) != valset.Size() {
+		return fmt.Errorf("commit at height %d has %d signatures, validator set has %d validators",
+			commit.Height, len(commit.Signatures), valset.Size())
+	}
+
+	statuses := make([]SigningStatus, len(commit.Signatures))
+	for i, sig := range commit.Signatures {
+		val := valset.Validators[i]
+
+		switch sig.BlockIDFlag {
+		case types.BlockIDFlagAbsent:
+			statuses[i] = SigningStatusMissed
+			continue
+		case types.BlockIDFlagCommit:
+			statuses[i] = SigningStatusSigned
+		case types.BlockIDFlagNil:
+			statuses[i] = SigningStatusNil
+		default:
+			return fmt.Errorf("unknown block ID flag %d for validator #%d at height %d",
+				sig.BlockIDFlag, i, commit.Height)
+		}
+
+		if !bytes.Equal(sig.ValidatorAddress, val.Address) {
+			return fmt.Errorf("signature #%d at height %d is from %v, expected validator %v",
+				i, commit.Height, sig.ValidatorAddress, val.Address)
+		}
+	}
+
+	var alerts []ValidatorAlert
+
+	vm.mtx.Lock()
+	if commit.Height <= vm.height {
+		vm.mtx.Unlock()
+		return nil
+	}
+	for i, val := range valset.Validators {
+		if vm.filter != nil {
+			if _, ok := vm.filter[string(val.Address)]; !ok {
+				continue
+			}
+		}
+
+		w, ok := vm.validators[string(val.Address)]
+		if !ok {
+			w = newValidatorWindow(val.Address, vm.opts.WindowSize)
+			vm.validators[string(val.Address)] = w
+		}
+		w.add(commit.Height, statuses[i])
+		alerts = append(alerts, w.checkAlerts(commit.Height, vm.opts)...)
+	}
+	vm.height = commit.Height
+	vm.mtx.Unlock()
+
+	if vm.opts.OnAlert != nil {
+		for _, alert := range alerts {
+			vm.opts.OnAlert(alert)
+		}
+	}
+
+	return nil
+}
+
+func (vm *ValidatorMonitor) pollRoutine(ctx context.Context) {
+	defer close(vm.done)
+
+	ticker := time.NewTicker(vm.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := vm.poll(ctx); err != nil && ctx.Err() == nil {
+			vm.Logger.Error("failed to process new heights", "err", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll processes every height up to the latest one with a canonical commit.
+func (vm *ValidatorMonitor) poll(ctx context.Context) error {
+	status, err := vm.client.Status(ctx)
+	if err != nil {
+		return err
+	}
+	latest := status.SyncInfo.LatestBlockHeight
+
+	next := vm.Height() + 1
+	if next == 1 {
+		next = vm.opts.StartHeight
+		if next <= 0 {
+			next = latest
+		}
+	}
+	if earliest := status.SyncInfo.EarliestBlockHeight; next < earliest {
+		vm.Logger.Info("skipping pruned heights", "from", next, "to", earliest-1)
+		next = earliest
+	}
+
+	for h := next; h <= latest; h++ {
+		height := h
+		res, err := vm.client.Commit(ctx, &height)
+		if err != nil {
+			return err
+		}
+		// The commit of the latest height is not final until the next block is committed.
+		if !res.CanonicalCommit {
+			return nil
+		}
+
+		valset, err := vm.validatorSet(ctx, res.Header)
+		if err != nil {
+			return err
+		}
+		if err := vm.ProcessCommit(res.Commit, valset); err != nil {
+			return err
+		}
+
+		if height%vm.opts.CheckpointInterval == 0 {
+			if err := vm.Checkpoint(); err != nil {
+				vm.Logger.Error("failed to write checkpoint", "height", height, "err", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validatorSet returns the validator set of header, fetching it only if it differs from the cached one.
+func (vm *ValidatorMonitor) validatorSet(ctx context.Context, header *types.Header) (*types.ValidatorSet, error) {
+	if vm.valset != nil && bytes.Equal(vm.valsetHash, header.ValidatorsHash) {
+		return vm.valset, nil
+	}
+
+	var (
+		height  = header.Height
+		perPage = 100
+		vals    []*types.Validator
+	)
+	for page := 1; ; page++ {
+		res, err := vm.client.Validators(ctx, &height, &page, &perPage)
+		if err != nil {
+			return nil, err
+		}
+		vals = append(vals, res.Validators...)
+		if len(vals) >= res.Total || len(res.Validators) == 0 {
+			break
+		}
+	}
+
+	valset, err := types.NewValidatorSet(vals)
+	if err != nil {
+		return nil, fmt.Errorf("invalid validator set at height %d: %w", height, err)
+	}
+	hash, err := valset.Hash()
+	if err != nil {
+		return nil, fmt.Errorf("invalid validator set at height %d: %w", height, err)
+	}
+	if !bytes.Equal(hash, header.ValidatorsHash) {
+		return nil, fmt.Errorf("validator set at height %d does not match the header validators hash", height)
+	}
+
+	vm.valset, vm.valsetHash = valset, hash
+	return valset, nil
+}
+
+//-----------------------------------------------------------------------------
+
+// validatorWindow is the sliding window of a single validator.
+type validatorWindow struct {
+	info ValidatorSigningInfo
+
+	ring []SigningStatus
+	next int
+
+	missedStreakAlert bool
+	missedWindowAlert bool
+}
+
+func newValidatorWindow(addr types.Address, size int) *validatorWindow {
+	return &validatorWindow{
+		info: ValidatorSigningInfo{Address: addr},
+		ring: make([]SigningStatus, size),
+	}
+}
+
+func (w *validatorWindow) add(height int64, status SigningStatus) {
+	w.count(w.ring[w.next], -1)
+	w.ring[w.next] = status
+	w.next = (w.next + 1) % len(w.ring)
+	w.count(status, 1)
+
+	w.info.LastHeight = height
+	switch status {
+	case SigningStatusMissed:
+		w.info.MissedStreak++
+		if w.info.MissedStreak > w.info.MaxMissedStreak {
+			w.info.MaxMissedStreak = w.info.MissedStreak
+		}
+		if w.info.FirstMissedHeight == 0 {
+			w.info.FirstMissedHeight = height
+		}
+		w.info.LastMissedHeight = height
+	case SigningStatusSigned:
+		w.info.LastSignedHeight = height
+		w.info.MissedStreak = 0
+	case SigningStatusNil:
+		w.info.MissedStreak = 0
+	}
+}
+
+func (w *validatorWindow) count(status SigningStatus, delta int) {
+	switch status {
+	case SigningStatusSigned:
+		w.info.Signed += delta
+	case SigningStatusNil:
+		w.info.Nil += delta
+	case SigningStatusMissed:
+		w.info.Missed += delta
+	default:
+		return
+	}
+	w.info.Window += delta
+}
+
+// statuses returns the window content from the oldest to the most recent height.
+func (w *validatorWindow) statuses() []SigningStatus {
+	statuses := make([]SigningStatus, 0, w.info.Window)
+	for i := 0; i < len(w.ring); i++ {
+		if s := w.ring[(w.next+i)%len(w.ring)]; s != 0 {
+			statuses = append(statuses, s)
+		}
+	}
+	return statuses
+}
+
+func (w *validatorWindow) checkAlerts(height int64, opts ValidatorMonitorOptions) []ValidatorAlert {
+	var alerts []ValidatorAlert
+	alerting := w.missedStreakAlert || w.missedWindowAlert
+
+	if t := opts.MissedStreakThreshold; t > 0 && !w.missedStreakAlert && w.info.MissedStreak >= t {
+		w.missedStreakAlert = true
+		alerts = append(alerts, ValidatorAlert{Kind: ValidatorAlertMissedStreak, Height: height, Info: w.info})
+	}
+	if t := opts.MissedWindowThreshold; t > 0 && !w.missedWindowAlert && w.info.Missed >= t {
+		w.missedWindowAlert = true
+		alerts = append(alerts, ValidatorAlert{Kind: ValidatorAlertMissedWindow, Height: height, Info: w.info})
+	}
+
+	if alerting && w.info.MissedStreak == 0 &&
+		(opts.MissedWindowThreshold <= 0 || w.info.Missed < opts.MissedWindowThreshold) {
+		w.missedStreakAlert = false
+		w.missedWindowAlert = false
+		alerts = append(alerts, ValidatorAlert{Kind: ValidatorAlertRecovered, Height: height, Info: w.info})
+	}
+
+	return alerts
+}
+
+//-----------------------------------------------------------------------------
+
+type validatorMonitorCheckpoint struct {
+	Height     int64                      `json:"height"`
+	Validators []validatorCheckpointEntry `json:"validators"`
+}
+
+type validatorCheckpointEntry struct {
+	Info              ValidatorSigningInfo `json:"info"`
+	Window            []SigningStatus      `json:"window"`
+	MissedStreakAlert bool                 `json:"missed_streak_alert"`
+	MissedWindowAlert bool                 `json:"missed_window_alert"`
+}
+
+// Checkpoint writes the state of the monitor to ValidatorMonitorOptions.CheckpointFile.
+// It is a no-op if no checkpoint file is configured.
+func (vm *ValidatorMonitor) Checkpoint() error {
+	if vm.opts.CheckpointFile == "" {
+		return nil
+	}
+
+	vm.mtx.Lock()
+	cp := validatorMonitorCheckpoint{
+		Height:     vm.height,
+		Validators: make([]validatorCheckpointEntry, 0, len(vm.validators)),
+	}
+	for _, w := range vm.validators {
+		cp.Validators = append(cp.Validators, validatorCheckpointEntry{
+			Info:              w.info,
+			Window:            w.statuses(),
+			MissedStreakAlert: w.missedStreakAlert,
+			MissedWindowAlert: w.missedWindowAlert,
+		})
+	}
+	vm.mtx.Unlock()
+
+	bz, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+
+	return cmtos.WriteFileAtomic(vm.opts.CheckpointFile, bz, 0o600)
+}
+
+func (vm *ValidatorMonitor) loadCheckpoint() error {
+	if vm.opts.CheckpointFile == "" {
+		return nil
+	}
+
+	bz, err := os.ReadFile(vm.opts.CheckpointFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var cp validatorMonitorCheckpoint
+	if err := json.Unmarshal(bz, &cp); err != nil {
+		return fmt.Errorf("invalid checkpoint %s: %w", vm.opts.CheckpointFile, err)
+	}
+
+	vm.mtx.Lock()
+	defer vm.mtx.Unlock()
+
+	vm.height = cp.Height
+	vm.validators = make(map[string]*validatorWindow, len(cp.Validators))
+	for _, entry := range cp.Validators {
+		w := newValidatorWindow(entry.Info.Address, vm.opts.WindowSize)
+
+		// The window size may have changed since the checkpoint, so the counts are rebuilt from the window.
+		window := entry.Window
+		if len(window) > len(w.ring) {
+			window = window[len(window)-len(w.ring):]
+		}
+		for _, status := range window {
+			w.ring[w.next] = status
+			w.next = (w.next + 1) % len(w.ring)
+			w.count(status, 1)
+		}
+
+		info := entry.Info
+		info.Window, info.Signed, info.Nil, info.Missed = w.info.Window, w.info.Signed, w.info.Nil, w.info.Missed
+		w.info = info
+		w.missedStreakAlert = entry.MissedStreakAlert
+		w.missedWindowAlert = entry.MissedWindowAlert
+
+		vm.validators[string(info.Address)] = w
+	}
+
+	return nil
+}
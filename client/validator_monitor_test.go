@@ -0,0 +1,232 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/strangelove-ventures/cometbft-client/crypto/ed25519"
+	ce "github.com/strangelove-ventures/cometbft-client/crypto/encoding"
+	"github.com/strangelove-ventures/cometbft-client/crypto/sr25519"
+	cmtjson "github.com/strangelove-ventures/cometbft-client/libs/json"
+	coretypes "github.com/strangelove-ventures/cometbft-client/rpc/core/types"
+	"github.com/strangelove-ventures/cometbft-client/types"
+)
+
+func testValidatorSet(t *testing.T, n int) *types.ValidatorSet {
+	t.Helper()
+
+	vals := make([]*types.Validator, n)
+	for i := range vals {
+		pk := ed25519.GenPrivKeyFromSecret([]byte("val" + strconv.Itoa(i))).PubKey()
+		vals[i] = types.NewValidator(pk, int64(10*(n-i)))
+	}
+
+	valset, err := types.NewValidatorSet(vals)
+	require.NoError(t, err)
+	return valset
+}
+
+// testCommit returns a commit at height signed by valset, where flags has one character per validator:
+// 'x' for a signature, 'n' for a nil vote and '_' for an absent vote.
+func testCommit(valset *types.ValidatorSet, height int64, flags string) *types.Commit {
+	commit := &types.Commit{Height: height}
+	for i, f := range flags {
+		sig := types.CommitSig{BlockIDFlag: types.BlockIDFlagAbsent}
+		if f != '_' {
+			sig.ValidatorAddress = valset.Validators[i].Address
+			sig.BlockIDFlag = types.BlockIDFlagCommit
+			if f == 'n' {
+				sig.BlockIDFlag = types.BlockIDFlagNil
+			}
+		}
+		commit.Signatures = append(commit.Signatures, sig)
+	}
+	return commit
+}
+
+func TestValidatorMonitorProcessCommit(t *testing.T) {
+	valset := testValidatorSet(t, 3)
+
+	var alerts []ValidatorAlert
+	opts := DefaultValidatorMonitorOptions
+	opts.WindowSize = 4
+	opts.MissedStreakThreshold = 2
+	opts.MissedWindowThreshold = 3
+	opts.OnAlert = func(alert ValidatorAlert) { alerts = append(alerts, alert) }
+	vm := NewValidatorMonitor(nil, opts)
+
+	for h, flags := range []string{"xx_", "xn_", "x__", "xx_", "xxx", "xxx"} {
+		require.NoError(t, vm.ProcessCommit(testCommit(valset, int64(h+1), flags), valset))
+	}
+	require.Equal(t, int64(6), vm.Height())
+
+	// Commits at processed heights are ignored.
+	require.NoError(t, vm.ProcessCommit(testCommit(valset, 6, "___"), valset))
+
+	info, ok := vm.SigningInfo(valset.Validators[0].Address)
+	require.True(t, ok)
+	require.Equal(t, 4, info.Window)
+	require.Equal(t, 4, info.Signed)
+	require.Equal(t, 1.0, info.Uptime())
+
+	info, _ = vm.SigningInfo(valset.Validators[1].Address)
+	require.Equal(t, 3, info.Signed)
+	require.Equal(t, 0, info.Nil) // the nil vote at height 2 left the window
+	require.Equal(t, 1, info.Missed)
+	require.Equal(t, int64(3), info.FirstMissedHeight)
+	require.Equal(t, int64(3), info.LastMissedHeight)
+	require.Equal(t, 1, info.MaxMissedStreak)
+
+	info, _ = vm.SigningInfo(valset.Validators[2].Address)
+	require.Equal(t, 2, info.Missed)
+	require.Equal(t, 0, info.MissedStreak)
+	require.Equal(t, 4, info.MaxMissedStreak)
+	require.Equal(t, int64(1), info.FirstMissedHeight)
+	require.Equal(t, int64(4), info.LastMissedHeight)
+	require.Equal(t, int64(6), info.LastSignedHeight)
+	require.Equal(t, 0.5, info.Uptime())
+
+	require.Len(t, vm.AllSigningInfo(), 3)
+
+	require.Len(t, alerts, 3)
+	require.Equal(t, ValidatorAlertMissedStreak, alerts[0].Kind)
+	require.Equal(t, int64(2), alerts[0].Height)
+	require.Equal(t, ValidatorAlertMissedWindow, alerts[1].Kind)
+	require.Equal(t, int64(3), alerts[1].Height)
+	require.Equal(t, ValidatorAlertRecovered, alerts[2].Kind)
+	require.Equal(t, int64(6), alerts[2].Height)
+	for _, alert := range alerts {
+		require.Equal(t, valset.Validators[2].Address, alert.Info.Address)
+	}
+}
+
+func TestValidatorMonitorProcessCommitErrors(t *testing.T) {
+	valset := testValidatorSet(t, 2)
+	vm := NewValidatorMonitor(nil, DefaultValidatorMonitorOptions)
+
+	require.Error(t, vm.ProcessCommit(testCommit(valset, 1, "x"), valset))
+
+	commit := testCommit(valset, 1, "xx")
+	commit.Signatures[0].ValidatorAddress = valset.Validators[1].Address
+	require.Error(t, vm.ProcessCommit(commit, valset))
+
+	require.Equal(t, int64(0), vm.Height())
+}
+
+func TestValidatorMonitorAddresses(t *testing.T) {
+	valset := testValidatorSet(t, 3)
+
+	opts := DefaultValidatorMonitorOptions
+	opts.Addresses = []types.Address{valset.Validators[1].Address}
+	vm := NewValidatorMonitor(nil, opts)
+
+	require.NoError(t, vm.ProcessCommit(testCommit(valset, 1, "x_x"), valset))
+
+	infos := vm.AllSigningInfo()
+	require.Len(t, infos, 1)
+	require.Equal(t, valset.Validators[1].Address, infos[0].Address)
+	require.Equal(t, 1, infos[0].Missed)
+}
+
+func TestValidatorMonitorService(t *testing.T) {
+	valset := testValidatorSet(t, 2)
+	flags := []string{"xx", "x_", "x_", "xx", "xx"}
+
+	var latest atomic.Int64
+	latest.Store(3)
+	node := newMockNode(t)
+	node.handle("status", func(map[string]json.RawMessage) (interface{}, error) {
+		return &coretypes.ResultStatus{SyncInfo: coretypes.SyncInfo{
+			EarliestBlockHeight: 1,
+			LatestBlockHeight:   latest.Load(),
+		}}, nil
+	})
+	node.handle("commit", func(params map[string]json.RawMessage) (interface{}, error) {
+		height, err := strconv.ParseInt(strings.Trim(string(params["height"]), `"`), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		hash, err := valset.Hash()
+		if err != nil {
+			return nil, err
+		}
+		header := &types.Header{Height: height, ValidatorsHash: hash}
+		return &coretypes.ResultCommit{
+			SignedHeader:    types.SignedHeader{Header: header, Commit: testCommit(valset, height, flags[height-1])},
+			CanonicalCommit: height < latest.Load(),
+		}, nil
+	})
+	node.handle("validators", func(map[string]json.RawMessage) (interface{}, error) {
+		bz, err := cmtjson.Marshal(&coretypes.ResultValidators{
+			Validators: valset.Validators,
+			Count:      valset.Size(),
+			Total:      valset.Size(),
+		})
+		return json.RawMessage(bz), err
+	})
+
+	opts := DefaultValidatorMonitorOptions
+	opts.PollInterval = 10 * time.Millisecond
+	opts.StartHeight = 1
+	opts.CheckpointFile = filepath.Join(t.TempDir(), "checkpoint.json")
+
+	vm := NewValidatorMonitor(node.client(t), opts)
+	require.NoError(t, vm.Start())
+	require.Eventually(t, func() bool { return vm.Height() == 2 }, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, vm.Stop())
+
+	// The validator set is fetched once, as it does not change.
+	require.Equal(t, 1, node.callCount("validators"))
+
+	// A new monitor resumes from the checkpoint, ignoring the start height.
+	latest.Store(5)
+	opts.StartHeight = 3
+	vm = NewValidatorMonitor(node.client(t), opts)
+	require.NoError(t, vm.Start())
+	t.Cleanup(func() { _ = vm.Stop() })
+	require.Eventually(t, func() bool { return vm.Height() == 4 }, 5*time.Second, 10*time.Millisecond)
+
+	info, ok := vm.SigningInfo(valset.Validators[1].Address)
+	require.True(t, ok)
+	require.Equal(t, 4, info.Window)
+	require.Equal(t, 2, info.Missed)
+	require.Equal(t, 2, info.MaxMissedStreak)
+	require.Equal(t, int64(4), info.LastSignedHeight)
+}
+
+func TestValidatorMonitorUnhashableValidatorSet(t *testing.T) {
+	// The RPC decodes sr25519 keys, which have no protobuf encoding to hash the set with.
+	valset, err := types.NewValidatorSet([]*types.Validator{
+		types.NewValidator(sr25519.GenPrivKeyFromSecret([]byte("val0")).PubKey(), 10),
+	})
+	require.NoError(t, err)
+
+	node := newMockNode(t)
+	node.handle("status", func(map[string]json.RawMessage) (interface{}, error) {
+		return &coretypes.ResultStatus{SyncInfo: coretypes.SyncInfo{EarliestBlockHeight: 1, LatestBlockHeight: 2}}, nil
+	})
+	node.handle("commit", func(map[string]json.RawMessage) (interface{}, error) {
+		return &coretypes.ResultCommit{
+			SignedHeader:    types.SignedHeader{Header: &types.Header{Height: 1}, Commit: testCommit(valset, 1, "x")},
+			CanonicalCommit: true,
+		}, nil
+	})
+	node.handle("validators", func(map[string]json.RawMessage) (interface{}, error) {
+		bz, err := cmtjson.Marshal(&coretypes.ResultValidators{Validators: valset.Validators, Count: 1, Total: 1})
+		return json.RawMessage(bz), err
+	})
+
+	opts := DefaultValidatorMonitorOptions
+	opts.StartHeight = 1
+	vm := NewValidatorMonitor(node.client(t), opts)
+	require.ErrorIs(t, vm.poll(context.Background()), ce.ErrUnsupportedKeyType)
+	require.Equal(t, int64(0), vm.Height())
+}
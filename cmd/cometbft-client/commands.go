@@ -0,0 +1,489 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/strangelove-ventures/cometbft-client/client"
+	rpcclient "github.com/strangelove-ventures/cometbft-client/rpc/client"
+	coretypes "github.com/strangelove-ventures/cometbft-client/rpc/core/types"
+	"github.com/strangelove-ventures/cometbft-client/types"
+)
+
+// maxPerPage is the maximum page size accepted by the nodes.
+const maxPerPage = 100
+
+func statusCmd(cfg *config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Show the node status",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			c, err := cfg.client()
+			if err != nil {
+				return err
+			}
+			res, err := c.Status(cmd.Context())
+			if err != nil {
+				return err
+			}
+			return cfg.print(cmd, res)
+		},
+	}
+}
+
+func blockCmd(cfg *config) *cobra.Command {
+	var height int64
+	cmd := &cobra.Command{
+		Use:   "block",
+		Short: "Show a block",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			c, err := cfg.client()
+			if err != nil {
+				return err
+			}
+			res, err := c.Block(cmd.Context(), heightPtr(height))
+			if err != nil {
+				return err
+			}
+			return cfg.print(cmd, res)
+		},
+	}
+	addHeightFlag(cmd, &height)
+	return cmd
+}
+
+func blockResultsCmd(cfg *config) *cobra.Command {
+	var height int64
+	cmd := &cobra.Command{
+		Use:   "block-results",
+		Short: "Show the results of the transactions and the events of a block",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			c, err := cfg.client()
+			if err != nil {
+				return err
+			}
+			res, err := c.BlockResults(cmd.Context(), heightPtr(height))
+			if err != nil {
+				return err
+			}
+			return cfg.print(cmd, res)
+		},
+	}
+	addHeightFlag(cmd, &height)
+	return cmd
+}
+
+func txCmd(cfg *config) *cobra.Command {
+	var prove bool
+	cmd := &cobra.Command{
+		Use:   "tx <hash>",
+		Short: "Show a transaction by its hex encoded hash",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			hash, err := hex.DecodeString(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid hash: %w", err)
+			}
+			c, err := cfg.client()
+			if err != nil {
+				return err
+			}
+			res, err := c.Tx(cmd.Context(), hash, prove)
+			if err != nil {
+				return err
+			}
+			return cfg.print(cmd, res)
+		},
+	}
+	cmd.Flags().BoolVar(&prove, flagProve, false, "include the inclusion proof of the transaction")
+	return cmd
+}
+
+// searchFlags are the flags shared by the search commands.
+type searchFlags struct {
+	orderBy string
+	page    int
+	perPage int
+	limit   int
+}
+
+func addSearchFlags(cmd *cobra.Command, f *searchFlags) {
+	cmd.Flags().StringVar(&f.orderBy, "order", "asc", "order of the results by height: asc or desc")
+	cmd.Flags().IntVar(&f.page, "page", 0, "fetch a single page, every page is fetched if 0")
+	cmd.Flags().IntVar(&f.perPage, "per-page", maxPerPage, "number of results per page")
+	cmd.Flags().IntVar(&f.limit, "limit", 0, "maximum number of results when fetching every page, unlimited if 0")
+}
+
+// paginate calls fetch for every page, or only for f.page if set. fetch returns the number of results
+// of the page and the total number of results, or -1 if the total is unknown.
+// It stops at the first page which is not full or once f.limit results have been fetched.
+// The nodes cap the page size at maxPerPage, so a larger one is rejected rather than mistaken
+// for the end of the results.
+func (f searchFlags) paginate(fetch func(page, perPage int) (n int, total int, err error)) error {
+	if f.perPage < 1 || f.perPage > maxPerPage {
+		return fmt.Errorf("--per-page must be between 1 and %d, got %d", maxPerPage, f.perPage)
+	}
+
+	if f.page > 0 {
+		_, _, err := fetch(f.page, f.perPage)
+		return err
+	}
+
+	fetched := 0
+	for page := 1; ; page++ {
+		n, total, err := fetch(page, f.perPage)
+		if err != nil {
+			// Without the total count, a last full page is only detected by the next one being out of range.
+			if page > 1 && total < 0 && isErrPageOutOfRange(err) {
+				return nil
+			}
+			return err
+		}
+
+		fetched += n
+		switch {
+		case n < f.perPage,
+			total >= 0 && fetched >= total,
+			f.limit > 0 && fetched >= f.limit:
+			return nil
+		}
+	}
+}
+
+func isErrPageOutOfRange(err error) bool {
+	return strings.Contains(err.Error(), "page should be within")
+}
+
+func txSearchCmd(cfg *config) *cobra.Command {
+	var (
+		prove bool
+		flags searchFlags
+	)
+	cmd := &cobra.Command{
+		Use:   "tx-search <query>",
+		Short: "Search transactions by events",
+		Long: `Search transactions by events, e.g. "tx.height=5" or "message.sender='cosmos1...'".
+Every page is fetched unless --page is set.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := cfg.client()
+			if err != nil {
+				return err
+			}
+
+			var txs []*client.TxResponse
+			err = flags.paginate(func(page, perPage int) (int, int, error) {
+				res, err := c.TxSearch(cmd.Context(), args[0], prove, &page, &perPage, flags.orderBy)
+				if err != nil {
+					return 0, -1, err
+				}
+				txs = append(txs, res...)
+				return len(res), -1, nil
+			})
+			if err != nil {
+				return err
+			}
+
+			if flags.limit > 0 && len(txs) > flags.limit {
+				txs = txs[:flags.limit]
+			}
+			if txs == nil {
+				txs = []*client.TxResponse{}
+			}
+			return cfg.print(cmd, txs)
+		},
+	}
+	cmd.Flags().BoolVar(&prove, flagProve, false, "include the inclusion proofs of the transactions")
+	addSearchFlags(cmd, &flags)
+	return cmd
+}
+
+func blockSearchCmd(cfg *config) *cobra.Command {
+	var flags searchFlags
+	cmd := &cobra.Command{
+		Use:   "block-search <query>",
+		Short: "Search blocks by events",
+		Long: `Search blocks by finalize block events, e.g. "block.height > 5".
+Every page is fetched unless --page is set.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := cfg.client()
+			if err != nil {
+				return err
+			}
+
+			result := &coretypes.ResultBlockSearch{Blocks: []*coretypes.ResultBlock{}}
+			err = flags.paginate(func(page, perPage int) (int, int, error) {
+				res, err := c.BlockSearch(cmd.Context(), args[0], &page, &perPage, flags.orderBy)
+				if err != nil {
+					return 0, -1, err
+				}
+				result.Blocks = append(result.Blocks, res.Blocks...)
+				result.TotalCount = res.TotalCount
+				return len(res.Blocks), res.TotalCount, nil
+			})
+			if err != nil {
+				return err
+			}
+
+			if flags.limit > 0 && len(result.Blocks) > flags.limit {
+				result.Blocks = result.Blocks[:flags.limit]
+			}
+			return cfg.print(cmd, result)
+		},
+	}
+	addSearchFlags(cmd, &flags)
+	return cmd
+}
+
+func validatorsCmd(cfg *config) *cobra.Command {
+	var height int64
+	cmd := &cobra.Command{
+		Use:   "validators",
+		Short: "Show the validator set",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			c, err := cfg.client()
+			if err != nil {
+				return err
+			}
+
+			var result *coretypes.ResultValidators
+			perPage := maxPerPage
+			for page := 1; ; page++ {
+				res, err := c.Validators(cmd.Context(), heightPtr(height), &page, &perPage)
+				if err != nil {
+					return err
+				}
+				if result == nil {
+					result = res
+					// Pin the height, in case a block is committed between two pages.
+					height = res.BlockHeight
+				} else {
+					result.Validators = append(result.Validators, res.Validators...)
+				}
+				if len(result.Validators) >= res.Total || len(res.Validators) == 0 {
+					break
+				}
+			}
+			result.Count = len(result.Validators)
+
+			return cfg.print(cmd, result)
+		},
+	}
+	addHeightFlag(cmd, &height)
+	return cmd
+}
+
+func commitCmd(cfg *config) *cobra.Command {
+	var height int64
+	cmd := &cobra.Command{
+		Use:   "commit",
+		Short: "Show the signed header of a block",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			c, err := cfg.client()
+			if err != nil {
+				return err
+			}
+			res, err := c.Commit(cmd.Context(), heightPtr(height))
+			if err != nil {
+				return err
+			}
+			return cfg.print(cmd, res)
+		},
+	}
+	addHeightFlag(cmd, &height)
+	return cmd
+}
+
+func genesisCmd(cfg *config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "genesis",
+		Short: "Download the genesis document",
+		Long: `Download the genesis document in chunks. With the JSON output, the document is written
+as served by the node without being buffered, which is recommended for large genesis files.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			c, err := cfg.client()
+			if err != nil {
+				return err
+			}
+
+			if cfg.output == outputJSON {
+				_, err := c.DownloadGenesis(cmd.Context(), cmd.OutOrStdout())
+				return err
+			}
+
+			var buf bytes.Buffer
+			if _, err := c.DownloadGenesis(cmd.Context(), &buf); err != nil {
+				return err
+			}
+			return writeJSONOutput(cmd.OutOrStdout(), cfg.output, buf.Bytes())
+		},
+	}
+}
+
+func netInfoCmd(cfg *config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "net-info",
+		Short: "Show the peers of the node",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			c, err := cfg.client()
+			if err != nil {
+				return err
+			}
+			res, err := c.NetInfo(cmd.Context())
+			if err != nil {
+				return err
+			}
+			return cfg.print(cmd, res)
+		},
+	}
+}
+
+func mempoolCmd(cfg *config) *cobra.Command {
+	var (
+		limit     int
+		countOnly bool
+	)
+	cmd := &cobra.Command{
+		Use:   "mempool",
+		Short: "Show the unconfirmed transactions",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			c, err := cfg.client()
+			if err != nil {
+				return err
+			}
+
+			var res *coretypes.ResultUnconfirmedTxs
+			if countOnly {
+				res, err = c.NumUnconfirmedTxs(cmd.Context())
+			} else {
+				res, err = c.UnconfirmedTxs(cmd.Context(), &limit)
+			}
+			if err != nil {
+				return err
+			}
+			return cfg.print(cmd, res)
+		},
+	}
+	cmd.Flags().IntVar(&limit, "limit", 30, "maximum number of transactions, at most 100")
+	cmd.Flags().BoolVar(&countOnly, "count-only", false, "only show the number and size of the transactions")
+	return cmd
+}
+
+func abciQueryCmd(cfg *config) *cobra.Command {
+	var (
+		height int64
+		prove  bool
+	)
+	cmd := &cobra.Command{
+		Use:   "abci-query <path> [hex-data]",
+		Short: "Query the application",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var data []byte
+			if len(args) == 2 {
+				var err error
+				if data, err = hex.DecodeString(args[1]); err != nil {
+					return fmt.Errorf("invalid data: %w", err)
+				}
+			}
+
+			c, err := cfg.client()
+			if err != nil {
+				return err
+			}
+			res, err := c.ABCIQueryWithOptions(cmd.Context(), args[0], data,
+				rpcclient.ABCIQueryOptions{Height: height, Prove: prove})
+			if err != nil {
+				return err
+			}
+			return cfg.print(cmd, res)
+		},
+	}
+	addHeightFlag(cmd, &height)
+	cmd.Flags().BoolVar(&prove, flagProve, false, "include the merkle proof of the result")
+	return cmd
+}
+
+const (
+	broadcastModeAsync  = "async"
+	broadcastModeSync   = "sync"
+	broadcastModeCommit = "commit"
+	broadcastModeWait   = "wait"
+)
+
+func broadcastCmd(cfg *config) *cobra.Command {
+	var (
+		mode        string
+		isBase64    bool
+		waitTimeout time.Duration
+	)
+	cmd := &cobra.Command{
+		Use:   "broadcast <tx>",
+		Short: "Broadcast a hex or base64 encoded transaction",
+		Long: `Broadcast a transaction. The modes are:
+  async:  return without waiting for CheckTx
+  sync:   return the result of CheckTx
+  commit: wait on the node for the inclusion in a block
+  wait:   return the result of CheckTx, then poll for the inclusion in a block (see --wait-timeout)`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var (
+				tx  types.Tx
+				err error
+			)
+			if isBase64 {
+				tx, err = base64.StdEncoding.DecodeString(args[0])
+			} else {
+				tx, err = hex.DecodeString(args[0])
+			}
+			if err != nil {
+				return fmt.Errorf("invalid transaction: %w", err)
+			}
+
+			c, err := cfg.client()
+			if err != nil {
+				return err
+			}
+
+			var res interface{}
+			switch mode {
+			case broadcastModeAsync:
+				res, err = c.BroadcastTxAsync(cmd.Context(), tx)
+			case broadcastModeSync:
+				res, err = c.BroadcastTxSync(cmd.Context(), tx)
+			case broadcastModeCommit:
+				res, err = c.BroadcastTxCommit(cmd.Context(), tx)
+			case broadcastModeWait:
+				opts := client.DefaultBroadcastOptions
+				opts.Timeout = waitTimeout
+				res, err = c.BroadcastAndWait(cmd.Context(), tx, opts)
+			default:
+				return fmt.Errorf("invalid --mode %q", mode)
+			}
+			if err != nil {
+				return err
+			}
+			return cfg.print(cmd, res)
+		},
+	}
+	cmd.Flags().StringVar(&mode, "mode", broadcastModeSync, "broadcast mode: async, sync, commit or wait")
+	cmd.Flags().BoolVar(&isBase64, "base64", false, "the transaction is base64 encoded instead of hex encoded")
+	cmd.Flags().DurationVar(&waitTimeout, "wait-timeout", client.DefaultBroadcastOptions.Timeout,
+		"maximum time to wait for the inclusion in wait mode")
+	return cmd
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+
+	coretypes "github.com/strangelove-ventures/cometbft-client/rpc/core/types"
+	rpctypes "github.com/strangelove-ventures/cometbft-client/rpc/jsonrpc/types"
+	"github.com/strangelove-ventures/cometbft-client/types"
+)
+
+// testNode is a mock node serving JSON-RPC requests over HTTP and websocket.
+type testNode struct {
+	// handlers answer the requests, indexed by method.
+	handlers map[string]func(params map[string]string) (interface{}, error)
+	// events are sent to the websocket subscribers of their query.
+	events map[string][]coretypes.ResultEvent
+}
+
+func newTestNode(t *testing.T, handlers map[string]func(params map[string]string) (interface{}, error)) string {
+	t.Helper()
+	return (&testNode{handlers: handlers}).start(t)
+}
+
+// start starts the node and returns its address.
+func (n *testNode) start(t *testing.T) string {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		var req rpctypes.RPCRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(n.handle(req))
+	})
+	mux.HandleFunc("/websocket", func(w http.ResponseWriter, r *http.Request) {
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		var (
+			mtx           sync.Mutex
+			subscriptions time.Duration
+		)
+		write := func(v interface{}) {
+			mtx.Lock()
+			defer mtx.Unlock()
+			_ = conn.WriteJSON(v)
+		}
+		for {
+			var req rpctypes.RPCRequest
+			if err := conn.ReadJSON(&req); err != nil {
+				return
+			}
+			write(rpctypes.NewRPCSuccessResponse(req.ID, struct{}{}))
+			if req.Method != "subscribe" {
+				continue
+			}
+
+			var params struct{ Query string }
+			_ = json.Unmarshal(req.Params, &params)
+			id := req.ID
+			subscriptions++
+			delay := subscriptions * 50 * time.Millisecond
+			go func() {
+				// The client registers the subscription after sending the request. The events of
+				// the later subscriptions are sent after the ones of the earlier subscriptions.
+				time.Sleep(delay)
+				for _, event := range n.events[params.Query] {
+					write(rpctypes.NewRPCSuccessResponse(id, event))
+				}
+			}()
+		}
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	return srv.URL
+}
+
+func (n *testNode) handle(req rpctypes.RPCRequest) rpctypes.RPCResponse {
+	var raw map[string]json.RawMessage
+	_ = json.Unmarshal(req.Params, &raw)
+	params := make(map[string]string, len(raw))
+	for k, v := range raw {
+		var value string
+		if err := json.Unmarshal(v, &value); err != nil {
+			value = string(v)
+		}
+		params[k] = value
+	}
+
+	h, ok := n.handlers[req.Method]
+	if !ok {
+		return rpctypes.RPCMethodNotFoundError(req.ID)
+	}
+	res, err := h(params)
+	if err != nil {
+		return rpctypes.RPCInternalError(req.ID, err)
+	}
+	return rpctypes.NewRPCSuccessResponse(req.ID, res)
+}
+
+func runCmd(t *testing.T, node string, args ...string) (string, error) {
+	t.Helper()
+
+	var out bytes.Buffer
+	cmd := NewRootCmd()
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs(append([]string{"--node", node}, args...))
+
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestTxSearchPagination(t *testing.T) {
+	const total = 6
+	var pages []string
+
+	node := newTestNode(t, map[string]func(map[string]string) (interface{}, error){
+		"tx_search": func(params map[string]string) (interface{}, error) {
+			pages = append(pages, params["page"])
+			page, _ := strconv.Atoi(params["page"])
+			perPage, _ := strconv.Atoi(params["per_page"])
+			if (page-1)*perPage >= total {
+				return nil, fmt.Errorf("page should be within [1, %d] range, given %d", total/perPage, page)
+			}
+
+			res := &coretypes.ResultTxSearch{TotalCount: total}
+			for i := (page - 1) * perPage; i < page*perPage && i < total; i++ {
+				tx := types.Tx(strconv.Itoa(i))
+				res.Txs = append(res.Txs, &coretypes.ResultTx{Hash: tx.Hash(), Height: int64(i + 1), Tx: tx})
+			}
+			return res, nil
+		},
+	})
+
+	out, err := runCmd(t, node, "tx-search", "tx.height>0", "--per-page", "3")
+	require.NoError(t, err)
+	require.Equal(t, []string{"1", "2", "3"}, pages)
+
+	var txs []map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(out), &txs))
+	require.Len(t, txs, total)
+
+	pages = nil
+	out, err = runCmd(t, node, "tx-search", "tx.height>0", "--per-page", "3", "--limit", "4", "-o", "table")
+	require.NoError(t, err)
+	require.Equal(t, []string{"1", "2"}, pages)
+	require.Len(t, strings.Split(strings.TrimSpace(out), "\n"), 5) // header and 4 rows
+
+	pages = nil
+	_, err = runCmd(t, node, "tx-search", "tx.height>0", "--per-page", "3", "--page", "2")
+	require.NoError(t, err)
+	require.Equal(t, []string{"2"}, pages)
+
+	pages = nil
+	_, err = runCmd(t, node, "tx-search", "tx.height>0", "--per-page", "101")
+	require.ErrorContains(t, err, "--per-page")
+	require.Empty(t, pages)
+}
+
+func TestValidatorsPagination(t *testing.T) {
+	var heights []string
+
+	node := newTestNode(t, map[string]func(map[string]string) (interface{}, error){
+		"validators": func(params map[string]string) (interface{}, error) {
+			heights = append(heights, params["height"])
+			page, _ := strconv.Atoi(params["page"])
+
+			// The validators are left empty, since encoding their public keys requires the Amino JSON encoding.
+			res := &coretypes.ResultValidators{BlockHeight: 42, Total: 150}
+			for i := 0; i < 100 && (page-1)*100+i < 150; i++ {
+				res.Validators = append(res.Validators, &types.Validator{VotingPower: 1})
+			}
+			res.Count = len(res.Validators)
+			return res, nil
+		},
+	})
+
+	out, err := runCmd(t, node, "validators", "-o", "yaml")
+	require.NoError(t, err)
+	require.Equal(t, []string{"", "42"}, heights)
+	require.Contains(t, out, "count: \"150\"\n")
+}
+
+func TestInvalidOutput(t *testing.T) {
+	_, err := runCmd(t, "http://localhost:1", "status", "-o", "xml")
+	require.ErrorContains(t, err, "invalid --output")
+}
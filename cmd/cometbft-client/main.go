@@ -0,0 +1,24 @@
+// Command cometbft-client queries CometBFT nodes over JSON-RPC.
+//
+// Usage:
+//
+//	cometbft-client [command] [flags]
+//
+// Run cometbft-client help for the list of commands.
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := NewRootCmd().ExecuteContext(ctx); err != nil {
+		os.Exit(1)
+	}
+}
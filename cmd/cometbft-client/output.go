@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"sigs.k8s.io/yaml"
+
+	cmtjson "github.com/strangelove-ventures/cometbft-client/libs/json"
+)
+
+const (
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+	outputTable = "table"
+
+	// maxCellWidth is the maximum width of a table cell, longer values are truncated.
+	maxCellWidth = 80
+)
+
+// writeOutput writes v to w in the given format. Values are first encoded with the Amino JSON
+// encoding used by the nodes, so every format shows the same fields as the RPC responses.
+func writeOutput(w io.Writer, format string, v interface{}) error {
+	bz, err := cmtjson.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeJSONOutput(w, format, bz)
+}
+
+// writeJSONOutput writes the JSON document bz to w in the given format.
+func writeJSONOutput(w io.Writer, format string, bz []byte) error {
+	switch format {
+	case outputJSON:
+		if _, err := w.Write(bz); err != nil {
+			return err
+		}
+		_, err := io.WriteString(w, "\n")
+		return err
+
+	case outputYAML:
+		y, err := yaml.JSONToYAML(bz)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(y)
+		return err
+
+	case outputTable:
+		dec := json.NewDecoder(bytes.NewReader(bz))
+		dec.UseNumber()
+		var v interface{}
+		if err := dec.Decode(&v); err != nil {
+			return err
+		}
+		return writeTable(w, v)
+
+	default:
+		return fmt.Errorf("unknown output format %q", format)
+	}
+}
+
+// writeTable prints a decoded JSON value as a table. Objects are printed as key/value pairs,
+// with nested fields flattened into dotted keys, except for the lists of objects, such as the
+// validators of a validator set, which are printed as separate tables with one row per item.
+func writeTable(w io.Writer, v interface{}) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	switch v := v.(type) {
+	case []interface{}:
+		writeRows(tw, v)
+
+	case map[string]interface{}:
+		var lists []string
+		for _, k := range sortedKeys(v) {
+			if isObjectList(v[k]) {
+				lists = append(lists, k)
+				continue
+			}
+			for _, cell := range flatten(k, v[k], true) {
+				fmt.Fprintf(tw, "%s\t%s\n", cell.key, cell.value)
+			}
+		}
+		for _, k := range lists {
+			fmt.Fprintf(tw, "\n%s:\n", k)
+			writeRows(tw, v[k].([]interface{}))
+		}
+
+	default:
+		fmt.Fprintln(tw, formatScalar(v))
+	}
+
+	return tw.Flush()
+}
+
+// writeRows prints a list with one row per item and one column per flattened field.
+func writeRows(w io.Writer, items []interface{}) {
+	if len(items) == 0 {
+		fmt.Fprintln(w, "(none)")
+		return
+	}
+
+	var (
+		columns []string
+		seen    = make(map[string]bool)
+		rows    = make([]map[string]string, len(items))
+	)
+	for i, item := range items {
+		rows[i] = make(map[string]string)
+		for _, cell := range flatten("", item, false) {
+			if !seen[cell.key] {
+				seen[cell.key] = true
+				columns = append(columns, cell.key)
+			}
+			rows[i][cell.key] = cell.value
+		}
+	}
+
+	header := make([]string, len(columns))
+	for i, c := range columns {
+		header[i] = strings.ToUpper(c)
+	}
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+
+	for _, row := range rows {
+		cells := make([]string, len(columns))
+		for i, c := range columns {
+			cells[i] = row[c]
+		}
+		fmt.Fprintln(w, strings.Join(cells, "\t"))
+	}
+}
+
+type cell struct {
+	key   string
+	value string
+}
+
+// flatten returns the scalar fields of v with dotted keys. Lists of scalars are joined. Lists of
+// objects are expanded with the item index in the key if expandLists is set, and summarized otherwise.
+func flatten(prefix string, v interface{}, expandLists bool) []cell {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if len(v) == 0 {
+			return []cell{{prefix, ""}}
+		}
+		var cells []cell
+		for _, k := range sortedKeys(v) {
+			cells = append(cells, flatten(joinKey(prefix, k), v[k], expandLists)...)
+		}
+		return cells
+
+	case []interface{}:
+		if !isObjectList(v) {
+			values := make([]string, len(v))
+			for i, item := range v {
+				values[i] = formatScalar(item)
+			}
+			return []cell{{prefix, truncate(strings.Join(values, ","))}}
+		}
+		if !expandLists {
+			return []cell{{prefix, fmt.Sprintf("[%d items]", len(v))}}
+		}
+		var cells []cell
+		for i, item := range v {
+			cells = append(cells, flatten(joinKey(prefix, fmt.Sprint(i)), item, expandLists)...)
+		}
+		return cells
+
+	default:
+		return []cell{{prefix, truncate(formatScalar(v))}}
+	}
+}
+
+func formatScalar(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+// isObjectList returns true if v is a non-empty list whose items are all objects.
+func isObjectList(v interface{}) bool {
+	items, ok := v.([]interface{})
+	if !ok || len(items) == 0 {
+		return false
+	}
+	for _, item := range items {
+		if _, ok := item.(map[string]interface{}); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func truncate(s string) string {
+	if r := []rune(s); len(r) > maxCellWidth {
+		return string(r[:maxCellWidth-3]) + "..."
+	}
+	return s
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testOutput struct {
+	Height     int64            `json:"height"`
+	Hash       string           `json:"hash"`
+	Tags       []string         `json:"tags"`
+	Validators []testOutputItem `json:"validators"`
+}
+
+type testOutputItem struct {
+	Address string            `json:"address"`
+	Power   int64             `json:"power"`
+	Extra   []testOutputExtra `json:"extra"`
+}
+
+type testOutputExtra struct {
+	X int `json:"x"`
+}
+
+var testValue = testOutput{
+	Height: 10,
+	Hash:   strings.Repeat("AB", 50),
+	Tags:   []string{"a", "b"},
+	Validators: []testOutputItem{
+		{Address: "A1", Power: 5, Extra: []testOutputExtra{{X: 1}}},
+		{Address: "B2", Power: 3},
+	},
+}
+
+func TestWriteOutput(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeOutput(&buf, outputJSON, testValue))
+	require.Contains(t, buf.String(), `"height": "10"`)
+
+	buf.Reset()
+	require.NoError(t, writeOutput(&buf, outputYAML, testValue))
+	require.Contains(t, buf.String(), "height: \"10\"\n")
+	require.Contains(t, buf.String(), "- address: A1\n")
+
+	buf.Reset()
+	require.NoError(t, writeOutput(&buf, outputTable, testValue))
+	require.Equal(t, `hash    ABABABABABABABABABABABABABABABABABABABABABABABABABABABABABABABABABABABABABABA...
+height  10
+tags    a,b
+
+validators:
+ADDRESS  EXTRA      POWER
+A1       [1 items]  5
+B2                  3
+`, buf.String())
+
+	require.Error(t, writeOutput(&buf, "xml", testValue))
+}
+
+func TestWriteTableList(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeOutput(&buf, outputTable, []testOutputItem{}))
+	require.Equal(t, "(none)\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, writeOutput(&buf, outputTable, testValue.Validators))
+	require.True(t, strings.HasPrefix(buf.String(), "ADDRESS  EXTRA      POWER\n"), buf.String())
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/strangelove-ventures/cometbft-client/client"
+)
+
+const (
+	flagNode    = "node"
+	flagTimeout = "timeout"
+	flagOutput  = "output"
+	flagHeight  = "height"
+	flagProve   = "prove"
+)
+
+// config holds the values of the persistent flags.
+type config struct {
+	node    string
+	timeout time.Duration
+	output  string
+}
+
+// NewRootCmd returns the root command of the CLI.
+func NewRootCmd() *cobra.Command {
+	cfg := &config{}
+
+	rootCmd := &cobra.Command{
+		Use:          "cometbft-client",
+		Short:        "Query CometBFT nodes over JSON-RPC",
+		SilenceUsage: true,
+		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+			switch cfg.output {
+			case outputJSON, outputYAML, outputTable:
+				return nil
+			default:
+				return fmt.Errorf("invalid --%s %q, must be one of %s, %s or %s",
+					flagOutput, cfg.output, outputJSON, outputYAML, outputTable)
+			}
+		},
+	}
+
+	rootCmd.PersistentFlags().StringVar(&cfg.node, flagNode, "http://localhost:26657", "RPC address of the node")
+	rootCmd.PersistentFlags().DurationVar(&cfg.timeout, flagTimeout, 10*time.Second, "timeout of every request")
+	rootCmd.PersistentFlags().StringVarP(&cfg.output, flagOutput, "o", outputJSON, "output format: json, yaml or table")
+
+	rootCmd.AddCommand(
+		statusCmd(cfg),
+		blockCmd(cfg),
+		blockResultsCmd(cfg),
+		txCmd(cfg),
+		txSearchCmd(cfg),
+		blockSearchCmd(cfg),
+		validatorsCmd(cfg),
+		commitCmd(cfg),
+		genesisCmd(cfg),
+		netInfoCmd(cfg),
+		mempoolCmd(cfg),
+		abciQueryCmd(cfg),
+		broadcastCmd(cfg),
+		watchCmd(cfg),
+	)
+
+	return rootCmd
+}
+
+func (cfg *config) client() (*client.Client, error) {
+	return client.NewClient(cfg.node, cfg.timeout)
+}
+
+// print writes v to the command output in the configured format.
+func (cfg *config) print(cmd *cobra.Command, v interface{}) error {
+	return writeOutput(cmd.OutOrStdout(), cfg.output, v)
+}
+
+// addHeightFlag registers the --height flag. A height of 0 selects the latest height.
+func addHeightFlag(cmd *cobra.Command, height *int64) {
+	cmd.Flags().Int64Var(height, flagHeight, 0, "height to query, the latest height if 0")
+}
+
+// heightPtr returns nil for the latest height, as expected by the client.
+func heightPtr(height int64) *int64 {
+	if height == 0 {
+		return nil
+	}
+	return &height
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/strangelove-ventures/cometbft-client/client"
+	"github.com/strangelove-ventures/cometbft-client/libs/pubsub/query"
+	"github.com/strangelove-ventures/cometbft-client/libs/pubsub/query/syntax"
+	coretypes "github.com/strangelove-ventures/cometbft-client/rpc/core/types"
+	"github.com/strangelove-ventures/cometbft-client/types"
+)
+
+const (
+	watchSubscriber = "cometbft-client"
+	// watchCapacity is the capacity of the subscriptions, large enough to buffer the live events
+	// during the replay of the history.
+	watchCapacity = 1000
+)
+
+// errWatchDone stops the replay of the history once enough events have been printed.
+var errWatchDone = errors.New("done")
+
+func watchCmd(cfg *config) *cobra.Command {
+	w := &watcher{cfg: cfg}
+	cmd := &cobra.Command{
+		Use:   "watch <query>",
+		Short: "Print the events matching a query as they happen",
+		Long: `Subscribe to the events matching a query, e.g. "tm.event='Tx' AND message.action='send'",
+and print them until interrupted, --count events were printed or --until-height is passed.
+
+With --since, the indexed events from that height are replayed first with tx-search or
+block-search. This requires the query to select Tx or NewBlock events.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			q, err := query.New(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid query: %w", err)
+			}
+			w.cmd = cmd
+			w.query = args[0]
+			w.conditions = q.Syntax()
+			w.eventType = eventTypeOf(w.conditions)
+
+			if w.since > 0 && w.eventType != types.EventTx && w.eventType != types.EventNewBlock {
+				return fmt.Errorf("--since requires a query on %s='%s' or %s='%s' events",
+					types.EventTypeKey, types.EventTx, types.EventTypeKey, types.EventNewBlock)
+			}
+			if w.until > 0 && w.since > w.until {
+				return fmt.Errorf("--since %d is after --until-height %d", w.since, w.until)
+			}
+
+			return w.run(cmd.Context())
+		},
+	}
+	cmd.Flags().Int64Var(&w.since, "since", 0, "replay the indexed events from this height first")
+	cmd.Flags().IntVar(&w.count, "count", 0, "exit after printing this number of events, unlimited if 0")
+	cmd.Flags().Int64Var(&w.until, "until-height", 0, "exit once the chain is past this height, unlimited if 0")
+	return cmd
+}
+
+// watcher prints the events of a subscription, after the replayed history if requested.
+type watcher struct {
+	cfg *config
+	cmd *cobra.Command
+	c   *client.Client
+
+	query      string
+	conditions syntax.Query
+	eventType  string
+
+	since int64
+	count int
+	until int64
+
+	printed int
+}
+
+func (w *watcher) run(ctx context.Context) error {
+	c, err := w.cfg.client()
+	if err != nil {
+		return err
+	}
+	if err := c.Start(); err != nil {
+		return err
+	}
+	defer func() { _ = c.Stop() }()
+	w.c = c
+
+	// Subscribe before replaying the history, so that no event is missed in between.
+	events, err := c.Subscribe(ctx, watchSubscriber, w.query, watchCapacity)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = c.UnsubscribeAll(context.Background(), watchSubscriber) }()
+
+	// With a single event per block, the end is detected with the events themselves.
+	// Otherwise, the headers tell when every event of the last height was received.
+	perBlock := w.eventType == types.EventNewBlock ||
+		w.eventType == types.EventNewBlockHeader ||
+		w.eventType == types.EventNewBlockEvents
+	var headers <-chan coretypes.ResultEvent
+	if w.until > 0 && !perBlock {
+		headers, err = c.Subscribe(ctx, watchSubscriber, types.QueryForEvent(types.EventNewBlockHeader).String(), watchCapacity)
+		if err != nil {
+			return err
+		}
+	}
+
+	var replayed int64
+	if w.since > 0 {
+		status, err := c.Status(ctx)
+		if err != nil {
+			return err
+		}
+		replayed = status.SyncInfo.LatestBlockHeight
+		if w.until > 0 && w.until < replayed {
+			replayed = w.until
+		}
+
+		if err := w.replay(ctx, replayed); err != nil {
+			if errors.Is(err, errWatchDone) {
+				return nil
+			}
+			return err
+		}
+		if w.until > 0 && replayed >= w.until {
+			return nil
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			// Interrupted.
+			return nil
+
+		case event := <-events:
+			res := client.NewEventResponse(event)
+			if res.Height != 0 && res.Height <= replayed {
+				continue
+			}
+			if w.until > 0 && res.Height > w.until {
+				return nil
+			}
+
+			done, err := w.print(res)
+			if err != nil || done {
+				return err
+			}
+			if w.until > 0 && perBlock && res.Height >= w.until {
+				return nil
+			}
+
+		case header := <-headers:
+			if res := client.NewEventResponse(header); res.Height > w.until {
+				return w.drain(events, replayed)
+			}
+		}
+	}
+}
+
+// drain prints the events already received up to --until-height, since the events of
+// the different subscriptions are not ordered with each other.
+func (w *watcher) drain(events <-chan coretypes.ResultEvent, replayed int64) error {
+	for {
+		select {
+		case event := <-events:
+			res := client.NewEventResponse(event)
+			if res.Height <= replayed || res.Height > w.until {
+				continue
+			}
+			if done, err := w.print(res); err != nil || done {
+				return err
+			}
+		default:
+			return nil
+		}
+	}
+}
+
+// print prints an event and returns true once --count events were printed.
+func (w *watcher) print(res *client.EventResponse) (bool, error) {
+	if err := w.cfg.print(w.cmd, res); err != nil {
+		return false, err
+	}
+	w.printed++
+	return w.count > 0 && w.printed >= w.count, nil
+}
+
+// replay prints the indexed events from --since to the given height.
+// It returns errWatchDone once --count events were printed.
+func (w *watcher) replay(ctx context.Context, to int64) error {
+	flags := searchFlags{orderBy: "asc", perPage: maxPerPage}
+
+	if w.eventType == types.EventTx {
+		q := w.searchQuery(types.TxHeightKey, to)
+		return flags.paginate(func(page, perPage int) (int, int, error) {
+			txs, err := w.c.TxSearch(ctx, q, false, &page, &perPage, flags.orderBy)
+			if err != nil {
+				return 0, -1, err
+			}
+			for _, tx := range txs {
+				done, err := w.print(&client.EventResponse{
+					Query:  w.query,
+					Type:   types.EventTx,
+					Height: tx.Height,
+					Tx:     tx,
+				})
+				if err != nil {
+					return 0, -1, err
+				}
+				if done {
+					return 0, -1, errWatchDone
+				}
+			}
+			return len(txs), -1, nil
+		})
+	}
+
+	q := w.searchQuery(types.BlockHeightKey, to)
+	return flags.paginate(func(page, perPage int) (int, int, error) {
+		res, err := w.c.BlockSearch(ctx, q, &page, &perPage, flags.orderBy)
+		if err != nil {
+			return 0, -1, err
+		}
+		for _, block := range res.Blocks {
+			results, err := w.c.BlockResults(ctx, &block.Block.Height)
+			if err != nil {
+				return 0, -1, err
+			}
+			done, err := w.print(&client.EventResponse{
+				Query:  w.query,
+				Type:   types.EventNewBlock,
+				Height: block.Block.Height,
+				Header: &block.Block.Header,
+				Events: results.Events,
+			})
+			if err != nil {
+				return 0, -1, err
+			}
+			if done {
+				return 0, -1, errWatchDone
+			}
+		}
+		return len(res.Blocks), res.TotalCount, nil
+	})
+}
+
+// searchQuery returns the query of the indexed events between --since and to. The event type
+// condition is dropped, since the indexers do not index it.
+func (w *watcher) searchQuery(heightKey string, to int64) string {
+	var conds []string
+	for _, cond := range w.conditions {
+		if cond.Tag != types.EventTypeKey {
+			conds = append(conds, cond.String())
+		}
+	}
+	conds = append(conds,
+		fmt.Sprintf("%s >= %d", heightKey, w.since),
+		fmt.Sprintf("%s <= %d", heightKey, to),
+	)
+	return strings.Join(conds, " AND ")
+}
+
+// eventTypeOf returns the event type selected by the query, or "" if it does not select one.
+func eventTypeOf(q syntax.Query) string {
+	for _, cond := range q {
+		if cond.Tag == types.EventTypeKey && cond.Op == syntax.TEq {
+			return cond.Arg.Value()
+		}
+	}
+	return ""
+}
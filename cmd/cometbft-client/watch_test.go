@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	abci "github.com/strangelove-ventures/cometbft-client/abci/types"
+	coretypes "github.com/strangelove-ventures/cometbft-client/rpc/core/types"
+	"github.com/strangelove-ventures/cometbft-client/types"
+)
+
+func txEvent(query string, height int64, tx string) coretypes.ResultEvent {
+	return coretypes.ResultEvent{
+		Query: query,
+		Data: types.EventDataTx{TxResult: abci.TxResult{
+			Height: height,
+			Tx:     []byte(tx),
+			Result: abci.ExecTxResult{Events: []abci.Event{{
+				Type:       "transfer",
+				Attributes: []abci.EventAttribute{{Key: "amount", Value: "10stake"}},
+			}}},
+		}},
+	}
+}
+
+func headerEvent(height int64) coretypes.ResultEvent {
+	return coretypes.ResultEvent{
+		Query: types.QueryForEvent(types.EventNewBlockHeader).String(),
+		Data:  types.EventDataNewBlockHeader{Header: types.Header{Height: height}},
+	}
+}
+
+// decodeWatchOutput returns the heights and types of the printed events.
+func decodeWatchOutput(t *testing.T, out string) (heights []int64, txs []string) {
+	t.Helper()
+
+	dec := json.NewDecoder(strings.NewReader(out))
+	for dec.More() {
+		var event struct {
+			Type   string
+			Height string
+			Tx     *struct{ Tx []byte }
+		}
+		require.NoError(t, dec.Decode(&event))
+		require.Equal(t, types.EventTx, event.Type)
+
+		height, err := strconv.ParseInt(event.Height, 10, 64)
+		require.NoError(t, err)
+		heights = append(heights, height)
+		txs = append(txs, string(event.Tx.Tx))
+	}
+	return heights, txs
+}
+
+func TestWatchSinceUntil(t *testing.T) {
+	const query = "tm.event='Tx' AND transfer.amount EXISTS"
+
+	var searchQuery string
+	node := &testNode{
+		handlers: map[string]func(map[string]string) (interface{}, error){
+			"status": func(map[string]string) (interface{}, error) {
+				return &coretypes.ResultStatus{SyncInfo: coretypes.SyncInfo{LatestBlockHeight: 6}}, nil
+			},
+			"tx_search": func(params map[string]string) (interface{}, error) {
+				searchQuery = params["query"]
+				return &coretypes.ResultTxSearch{TotalCount: 2, Txs: []*coretypes.ResultTx{
+					{Height: 5, Tx: types.Tx("a")},
+					{Height: 6, Tx: types.Tx("b")},
+				}}, nil
+			},
+		},
+		events: map[string][]coretypes.ResultEvent{
+			query:                {txEvent(query, 6, "b"), txEvent(query, 7, "c"), txEvent(query, 8, "d")},
+			headerEvent(0).Query: {headerEvent(7), headerEvent(8)},
+		},
+	}
+
+	out, err := runCmd(t, node.start(t), "watch", query, "--since", "5", "--until-height", "7")
+	require.NoError(t, err)
+	require.Equal(t, "transfer.amount EXISTS AND tx.height >= 5 AND tx.height <= 6", searchQuery)
+
+	heights, txs := decodeWatchOutput(t, out)
+	require.Equal(t, []int64{5, 6, 7}, heights)
+	require.Equal(t, []string{"a", "b", "c"}, txs)
+	require.Contains(t, out, `"key": "amount"`)
+}
+
+func TestWatchCount(t *testing.T) {
+	const query = "tm.event='Tx'"
+
+	node := &testNode{
+		events: map[string][]coretypes.ResultEvent{
+			query: {txEvent(query, 1, "a"), txEvent(query, 2, "b"), txEvent(query, 3, "c")},
+		},
+	}
+
+	out, err := runCmd(t, node.start(t), "watch", query, "--count", "2")
+	require.NoError(t, err)
+
+	heights, _ := decodeWatchOutput(t, out)
+	require.Equal(t, []int64{1, 2}, heights)
+}
+
+func TestWatchInvalid(t *testing.T) {
+	_, err := runCmd(t, "http://localhost:1", "watch", "tm.event=")
+	require.ErrorContains(t, err, "invalid query")
+
+	_, err = runCmd(t, "http://localhost:1", "watch", "tm.event='Vote'", "--since", "1")
+	require.ErrorContains(t, err, "--since requires")
+}
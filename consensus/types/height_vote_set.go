@@ -0,0 +1,283 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/strangelove-ventures/cometbft-client/libs/bits"
+	cmtbytes "github.com/strangelove-ventures/cometbft-client/libs/bytes"
+	"github.com/strangelove-ventures/cometbft-client/types"
+)
+
+// HeightVoteSet holds the votes a node has received for every round of the
+// current height, as reported in the consensus state dumps.
+type HeightVoteSet []RoundVotes
+
+// Round returns the votes of the given round, or nil if the node has none.
+func (hvs HeightVoteSet) Round(round int32) *RoundVotes {
+	for i := range hvs {
+		if hvs[i].Round == round {
+			return &hvs[i]
+		}
+	}
+	return nil
+}
+
+// RoundVotes holds the prevotes and precommits of a single round.
+type RoundVotes struct {
+	Round      int32    `json:"round"`
+	Prevotes   *VoteSet `json:"prevotes"`
+	Precommits *VoteSet `json:"precommits"`
+}
+
+type roundVotesJSON struct {
+	Round              jsonInt  `json:"round"`
+	Prevotes           []string `json:"prevotes"`
+	PrevotesBitArray   string   `json:"prevotes_bit_array"`
+	Precommits         []string `json:"precommits"`
+	PrecommitsBitArray string   `json:"precommits_bit_array"`
+}
+
+// UnmarshalJSON decodes the votes of a round, parsing the string summaries of
+// the votes and bit arrays.
+func (rv *RoundVotes) UnmarshalJSON(bz []byte) error {
+	var aux roundVotesJSON
+	if err := json.Unmarshal(bz, &aux); err != nil {
+		return err
+	}
+
+	prevotes, err := newVoteSet(aux.Prevotes, aux.PrevotesBitArray)
+	if err != nil {
+		return fmt.Errorf("invalid prevotes in round %d: %w", aux.Round, err)
+	}
+	precommits, err := newVoteSet(aux.Precommits, aux.PrecommitsBitArray)
+	if err != nil {
+		return fmt.Errorf("invalid precommits in round %d: %w", aux.Round, err)
+	}
+
+	*rv = RoundVotes{
+		Round:      int32(aux.Round),
+		Prevotes:   prevotes,
+		Precommits: precommits,
+	}
+	return nil
+}
+
+// PrevotesBitArray returns the validators that prevoted in the round.
+// It is safe to call on a nil RoundVotes.
+func (rv *RoundVotes) PrevotesBitArray() *bits.BitArray {
+	if rv == nil {
+		return nil
+	}
+	return rv.Prevotes.BitArray()
+}
+
+// PrecommitsBitArray returns the validators that precommitted in the round.
+// It is safe to call on a nil RoundVotes.
+func (rv *RoundVotes) PrecommitsBitArray() *bits.BitArray {
+	if rv == nil {
+		return nil
+	}
+	return rv.Precommits.BitArray()
+}
+
+// MissingVoters returns the validators of valset from which no prevote and
+// no precommit respectively has been received in the round.
+// If rv is nil, every validator is missing.
+func (rv *RoundVotes) MissingVoters(valset *types.ValidatorSet) (prevotes, precommits []*types.Validator) {
+	if rv == nil {
+		return missingVoters(nil, valset), missingVoters(nil, valset)
+	}
+	return rv.Prevotes.MissingVoters(valset), rv.Precommits.MissingVoters(valset)
+}
+
+//-----------------------------------------------------------------------------
+
+// VoteSet is the set of votes of a single type received for a round, indexed
+// by the validator index in the validator set of the height.
+type VoteSet struct {
+	// Votes has one entry per validator; the entry is nil if no vote was received.
+	Votes []*Vote
+	// VotedPower is the voting power of the received votes, or -1 if unknown.
+	VotedPower int64
+	// TotalPower is the total voting power of the validator set, or -1 if unknown.
+	TotalPower int64
+
+	bitArray *bits.BitArray
+}
+
+type lastCommitJSON struct {
+	Votes         []string `json:"votes"`
+	VotesBitArray string   `json:"votes_bit_array"`
+}
+
+// UnmarshalJSON decodes the last commit of a round state, which is encoded as
+// a vote set with a votes list and a bit array summary.
+func (vs *VoteSet) UnmarshalJSON(bz []byte) error {
+	var aux lastCommitJSON
+	if err := json.Unmarshal(bz, &aux); err != nil {
+		return err
+	}
+
+	voteSet, err := newVoteSet(aux.Votes, aux.VotesBitArray)
+	if err != nil {
+		return err
+	}
+	*vs = *voteSet
+	return nil
+}
+
+// voteSetBitArrayRe matches the output of VoteSet.BitArrayString in CometBFT,
+// e.g. "BA{4:xx_x} 30/40 = 0.75".
+var voteSetBitArrayRe = regexp.MustCompile(`^BA\{(\d+):([x_ \n]*)\}(?: (\d+)/(\d+) = [\d.]+)?$`)
+
+func newVoteSet(votes []string, bitArray string) (*VoteSet, error) {
+	vs := &VoteSet{
+		Votes:      make([]*Vote, len(votes)),
+		VotedPower: -1,
+		TotalPower: -1,
+	}
+
+	for i, s := range votes {
+		vote, err := ParseVote(s)
+		if err != nil {
+			return nil, err
+		}
+		vs.Votes[i] = vote
+	}
+
+	match := voteSetBitArrayRe.FindStringSubmatch(bitArray)
+	if match == nil {
+		// Fall back to the votes list if the summary is missing or its format is unknown.
+		vs.bitArray = bits.NewBitArray(len(vs.Votes))
+		for i, vote := range vs.Votes {
+			vs.bitArray.SetIndex(i, vote != nil)
+		}
+		return vs, nil
+	}
+
+	bA, err := bits.Parse(match[2])
+	if err != nil {
+		return nil, err
+	}
+	vs.bitArray = bA
+
+	if match[3] != "" {
+		vs.VotedPower, _ = strconv.ParseInt(match[3], 10, 64)
+		vs.TotalPower, _ = strconv.ParseInt(match[4], 10, 64)
+	}
+
+	return vs, nil
+}
+
+// BitArray returns the validators from which a vote was received.
+// It is safe to call on a nil VoteSet.
+func (vs *VoteSet) BitArray() *bits.BitArray {
+	if vs == nil {
+		return nil
+	}
+	return vs.bitArray.Copy()
+}
+
+// HasVote returns true if a vote was received from the validator with the given index.
+func (vs *VoteSet) HasVote(valIndex int) bool {
+	if vs == nil {
+		return false
+	}
+	if vs.bitArray != nil {
+		return vs.bitArray.GetIndex(valIndex)
+	}
+	return valIndex < len(vs.Votes) && vs.Votes[valIndex] != nil
+}
+
+// MissingVoters returns the validators of valset from which no vote was
+// received. valset must be the validator set of the height of the votes.
+func (vs *VoteSet) MissingVoters(valset *types.ValidatorSet) []*types.Validator {
+	return missingVoters(vs, valset)
+}
+
+func missingVoters(vs *VoteSet, valset *types.ValidatorSet) []*types.Validator {
+	if valset == nil {
+		return nil
+	}
+
+	var missing []*types.Validator
+	for i, val := range valset.Validators {
+		if !vs.HasVote(i) {
+			missing = append(missing, val.Copy())
+		}
+	}
+	return missing
+}
+
+//-----------------------------------------------------------------------------
+
+// Vote is a vote as summarized in the consensus state dumps.
+// Addresses and hashes are truncated to their first 6 bytes.
+type Vote struct {
+	ValidatorIndex   int32
+	ValidatorAddress cmtbytes.HexBytes
+	Height           int64
+	Round            int32
+	Type             string
+	BlockHash        cmtbytes.HexBytes
+	Timestamp        time.Time
+}
+
+// voteRe matches the output of Vote.String in CometBFT and Tendermint, e.g.
+// "Vote{0:B5D3B5EBB6C4 100/00/SIGNED_MSG_TYPE_PREVOTE(Prevote) 8B01023386C3 7D1B9D5E5E0C 000000000000 @ 2023-06-01T00:00:00.000000000Z}".
+// Versions before v0.38 have no vote extension fingerprint.
+var voteRe = regexp.MustCompile(
+	`^Vote\{(\d+):([0-9A-F]*) (\d+)/(\d+)/[^(]*\((\w+)\) ([0-9A-F]*) [0-9A-F]*(?: [0-9A-F]*)? @ (\S+)\}$`,
+)
+
+// ParseVote parses the string summary of a vote. It returns nil for votes that
+// have not been received ("nil-Vote").
+func ParseVote(s string) (*Vote, error) {
+	if s == "nil-Vote" || s == "" {
+		return nil, nil
+	}
+
+	match := voteRe.FindStringSubmatch(s)
+	if match == nil {
+		return nil, fmt.Errorf("invalid vote %q", s)
+	}
+
+	index, _ := strconv.ParseInt(match[1], 10, 32)
+	address, _ := cmtbytesFromHex(match[2])
+	height, _ := strconv.ParseInt(match[3], 10, 64)
+	round, _ := strconv.ParseInt(match[4], 10, 32)
+	blockHash, _ := cmtbytesFromHex(match[6])
+
+	timestamp, err := time.Parse(time.RFC3339Nano, match[7])
+	if err != nil {
+		return nil, fmt.Errorf("invalid vote timestamp in %q: %w", s, err)
+	}
+
+	return &Vote{
+		ValidatorIndex:   int32(index),
+		ValidatorAddress: address,
+		Height:           height,
+		Round:            int32(round),
+		Type:             match[5],
+		BlockHash:        blockHash,
+		Timestamp:        timestamp,
+	}, nil
+}
+
+// IsForNil returns true if the vote is for nil rather than for a block.
+func (v *Vote) IsForNil() bool {
+	return len(bytes.Trim(v.BlockHash, "\x00")) == 0
+}
+
+func cmtbytesFromHex(s string) (cmtbytes.HexBytes, error) {
+	var bz cmtbytes.HexBytes
+	if err := bz.UnmarshalJSON([]byte(`"` + s + `"`)); err != nil {
+		return nil, err
+	}
+	return bz, nil
+}
@@ -0,0 +1,133 @@
+package types
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/strangelove-ventures/cometbft-client/libs/bits"
+	"github.com/strangelove-ventures/cometbft-client/types"
+)
+
+// PeerRoundState contains the known state of a peer, as seen by the node.
+type PeerRoundState struct {
+	Height int64         `json:"height"` // Height peer is at
+	Round  int32         `json:"round"`  // Round peer is at, -1 if unknown.
+	Step   RoundStepType `json:"step"`   // Step peer is at
+
+	// Estimated start of round 0 at this height
+	StartTime time.Time `json:"start_time"`
+
+	// True if peer has proposal for this round
+	Proposal                   bool                `json:"proposal"`
+	ProposalBlockPartSetHeader types.PartSetHeader `json:"proposal_block_part_set_header"`
+	ProposalBlockParts         *bits.BitArray      `json:"proposal_block_parts"`
+	// Proposal's POL round. -1 if none.
+	ProposalPOLRound int32 `json:"proposal_pol_round"`
+
+	// nil until ProposalPOLMessage received.
+	ProposalPOL     *bits.BitArray `json:"proposal_pol"`
+	Prevotes        *bits.BitArray `json:"prevotes"`          // All votes peer has for this round
+	Precommits      *bits.BitArray `json:"precommits"`        // All precommits peer has for this round
+	LastCommitRound int32          `json:"last_commit_round"` // Round of commit for last height. -1 if none.
+	LastCommit      *bits.BitArray `json:"last_commit"`       // All commit precommits of commit for last height.
+
+	// Round that we have commit for. Not necessarily unique. -1 if none.
+	CatchupCommitRound int32 `json:"catchup_commit_round"`
+
+	// All commit precommits peer has for this height & CatchupCommitRound
+	CatchupCommit *bits.BitArray `json:"catchup_commit"`
+}
+
+type peerRoundStateJSON struct {
+	Height                     jsonInt            `json:"height"`
+	Round                      jsonInt            `json:"round"`
+	Step                       RoundStepType      `json:"step"`
+	StartTime                  time.Time          `json:"start_time"`
+	Proposal                   bool               `json:"proposal"`
+	ProposalBlockPartSetHeader *partSetHeaderJSON `json:"proposal_block_part_set_header"`
+	ProposalBlockPartsHeader   *partSetHeaderJSON `json:"proposal_block_parts_header"` // Tendermint v0.34 and earlier
+	ProposalBlockParts         *bits.BitArray     `json:"proposal_block_parts"`
+	ProposalPOLRound           jsonInt            `json:"proposal_pol_round"`
+	ProposalPOL                *bits.BitArray     `json:"proposal_pol"`
+	Prevotes                   *bits.BitArray     `json:"prevotes"`
+	Precommits                 *bits.BitArray     `json:"precommits"`
+	LastCommitRound            jsonInt            `json:"last_commit_round"`
+	LastCommit                 *bits.BitArray     `json:"last_commit"`
+	CatchupCommitRound         jsonInt            `json:"catchup_commit_round"`
+	CatchupCommit              *bits.BitArray     `json:"catchup_commit"`
+}
+
+type partSetHeaderJSON struct {
+	Total jsonInt         `json:"total"`
+	Hash  json.RawMessage `json:"hash"`
+}
+
+// UnmarshalJSON decodes the peer round state as emitted by CometBFT and older
+// Tendermint versions.
+func (prs *PeerRoundState) UnmarshalJSON(bz []byte) error {
+	var aux peerRoundStateJSON
+	if err := json.Unmarshal(bz, &aux); err != nil {
+		return err
+	}
+
+	psh := aux.ProposalBlockPartSetHeader
+	if psh == nil {
+		psh = aux.ProposalBlockPartsHeader
+	}
+
+	*prs = PeerRoundState{
+		Height:             int64(aux.Height),
+		Round:              int32(aux.Round),
+		Step:               aux.Step,
+		StartTime:          aux.StartTime,
+		Proposal:           aux.Proposal,
+		ProposalBlockParts: aux.ProposalBlockParts,
+		ProposalPOLRound:   int32(aux.ProposalPOLRound),
+		ProposalPOL:        aux.ProposalPOL,
+		Prevotes:           aux.Prevotes,
+		Precommits:         aux.Precommits,
+		LastCommitRound:    int32(aux.LastCommitRound),
+		LastCommit:         aux.LastCommit,
+		CatchupCommitRound: int32(aux.CatchupCommitRound),
+		CatchupCommit:      aux.CatchupCommit,
+	}
+
+	if psh != nil {
+		prs.ProposalBlockPartSetHeader.Total = uint32(psh.Total)
+		if len(psh.Hash) > 0 && string(psh.Hash) != "null" {
+			if err := prs.ProposalBlockPartSetHeader.Hash.UnmarshalJSON(psh.Hash); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// PeerState is the consensus state of a peer, as returned in the peers list
+// of the dump_consensus_state endpoint.
+type PeerState struct {
+	RoundState PeerRoundState `json:"round_state"`
+	Stats      PeerStateStats `json:"stats"`
+}
+
+// PeerStateStats holds internal statistics for a peer.
+type PeerStateStats struct {
+	Votes      int `json:"votes"`
+	BlockParts int `json:"block_parts"`
+}
+
+// UnmarshalJSON decodes the peer statistics, which are encoded as strings.
+func (pss *PeerStateStats) UnmarshalJSON(bz []byte) error {
+	var aux struct {
+		Votes      jsonInt `json:"votes"`
+		BlockParts jsonInt `json:"block_parts"`
+	}
+	if err := json.Unmarshal(bz, &aux); err != nil {
+		return err
+	}
+
+	pss.Votes = int(aux.Votes)
+	pss.BlockParts = int(aux.BlockParts)
+	return nil
+}
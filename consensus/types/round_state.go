@@ -0,0 +1,348 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/strangelove-ventures/cometbft-client/libs/bits"
+	cmtbytes "github.com/strangelove-ventures/cometbft-client/libs/bytes"
+	cmtjson "github.com/strangelove-ventures/cometbft-client/libs/json"
+	"github.com/strangelove-ventures/cometbft-client/types"
+)
+
+//-----------------------------------------------------------------------------
+// RoundStepType enum type
+
+// RoundStepType enumerates the state of the consensus state machine
+type RoundStepType uint8 // These must be numeric, ordered.
+
+// RoundStepType
+const (
+	RoundStepNewHeight     = RoundStepType(0x01) // Wait til CommitTime + timeoutCommit
+	RoundStepNewRound      = RoundStepType(0x02) // Setup new round and go to RoundStepPropose
+	RoundStepPropose       = RoundStepType(0x03) // Did propose, gossip proposal
+	RoundStepPrevote       = RoundStepType(0x04) // Did prevote, gossip prevotes
+	RoundStepPrevoteWait   = RoundStepType(0x05) // Did receive any +2/3 prevotes, start timeout
+	RoundStepPrecommit     = RoundStepType(0x06) // Did precommit, gossip precommits
+	RoundStepPrecommitWait = RoundStepType(0x07) // Did receive any +2/3 precommits, start timeout
+	RoundStepCommit        = RoundStepType(0x08) // Entered commit state machine
+	// NOTE: RoundStepNewHeight acts as RoundStepCommitWait.
+
+	// NOTE: Update IsValid method if you change this!
+)
+
+var roundStepNames = map[RoundStepType]string{
+	RoundStepNewHeight:     "RoundStepNewHeight",
+	RoundStepNewRound:      "RoundStepNewRound",
+	RoundStepPropose:       "RoundStepPropose",
+	RoundStepPrevote:       "RoundStepPrevote",
+	RoundStepPrevoteWait:   "RoundStepPrevoteWait",
+	RoundStepPrecommit:     "RoundStepPrecommit",
+	RoundStepPrecommitWait: "RoundStepPrecommitWait",
+	RoundStepCommit:        "RoundStepCommit",
+}
+
+// IsValid returns true if the step is valid, false if unknown/undefined.
+func (rs RoundStepType) IsValid() bool {
+	return uint8(rs) >= 0x01 && uint8(rs) <= 0x08
+}
+
+// String returns a string
+func (rs RoundStepType) String() string {
+	if name, ok := roundStepNames[rs]; ok {
+		return name
+	}
+	return "RoundStepUnknown" // Cannot panic.
+}
+
+// UnmarshalJSON accepts the step as a number, as emitted by CometBFT, or as a
+// (numeric or named) string, as emitted by some older Tendermint versions.
+func (rs *RoundStepType) UnmarshalJSON(bz []byte) error {
+	var n uint8
+	if err := json.Unmarshal(bz, &n); err == nil {
+		*rs = RoundStepType(n)
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(bz, &s); err != nil {
+		return fmt.Errorf("invalid round step %s", bz)
+	}
+	return rs.parse(s)
+}
+
+func (rs *RoundStepType) parse(s string) error {
+	if n, err := strconv.ParseUint(s, 10, 8); err == nil {
+		*rs = RoundStepType(n)
+		return nil
+	}
+	for step, name := range roundStepNames {
+		if s == name || "RoundStep"+s == name {
+			*rs = step
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown round step %q", s)
+}
+
+//-----------------------------------------------------------------------------
+
+// RoundState is the internal consensus state of a node, as returned by the
+// dump_consensus_state endpoint.
+//
+// Blocks and proposals are kept as raw JSON, since they are rarely needed to
+// diagnose a stalled round and their encoding differs between versions.
+type RoundState struct {
+	Height     int64               `json:"height"` // Height we are working on
+	Round      int32               `json:"round"`
+	Step       RoundStepType       `json:"step"`
+	StartTime  time.Time           `json:"start_time"`
+	CommitTime time.Time           `json:"commit_time"` // Subjective time when +2/3 precommits for Block at Round were found
+	Validators *types.ValidatorSet `json:"validators"`
+
+	Proposal           json.RawMessage `json:"proposal"`
+	ProposalBlock      json.RawMessage `json:"proposal_block"`
+	ProposalBlockParts json.RawMessage `json:"proposal_block_parts"`
+	LockedRound        int32           `json:"locked_round"`
+	LockedBlock        json.RawMessage `json:"locked_block"`
+	LockedBlockParts   json.RawMessage `json:"locked_block_parts"`
+
+	// The variables below starting with "Valid..." derive their name from
+	// the algorithm presented in this paper:
+	// [The latest gossip on BFT consensus](https://arxiv.org/abs/1807.04938).
+	// Therefore, "Valid...":
+	//   * means 'block' or 'value' for the purpose of this paper.
+	//   * doesn't mean 'valid' in the sense of 'block that passes the application's checks'.
+	ValidRound      int32           `json:"valid_round"`
+	ValidBlock      json.RawMessage `json:"valid_block"`
+	ValidBlockParts json.RawMessage `json:"valid_block_parts"`
+
+	Votes                     HeightVoteSet       `json:"votes"`
+	CommitRound               int32               `json:"commit_round"`
+	LastCommit                *VoteSet            `json:"last_commit"`
+	LastValidators            *types.ValidatorSet `json:"last_validators"`
+	TriggeredTimeoutPrecommit bool                `json:"triggered_timeout_precommit"`
+}
+
+type roundStateJSON struct {
+	Height     jsonInt         `json:"height"`
+	Round      jsonInt         `json:"round"`
+	Step       RoundStepType   `json:"step"`
+	StartTime  time.Time       `json:"start_time"`
+	CommitTime time.Time       `json:"commit_time"`
+	Validators json.RawMessage `json:"validators"`
+
+	Proposal           json.RawMessage `json:"proposal"`
+	ProposalBlock      json.RawMessage `json:"proposal_block"`
+	ProposalBlockParts json.RawMessage `json:"proposal_block_parts"`
+	LockedRound        jsonInt         `json:"locked_round"`
+	LockedBlock        json.RawMessage `json:"locked_block"`
+	LockedBlockParts   json.RawMessage `json:"locked_block_parts"`
+	ValidRound         jsonInt         `json:"valid_round"`
+	ValidBlock         json.RawMessage `json:"valid_block"`
+	ValidBlockParts    json.RawMessage `json:"valid_block_parts"`
+
+	Votes                     HeightVoteSet   `json:"votes"`
+	CommitRound               jsonInt         `json:"commit_round"`
+	LastCommit                *VoteSet        `json:"last_commit"`
+	LastValidators            json.RawMessage `json:"last_validators"`
+	TriggeredTimeoutPrecommit bool            `json:"triggered_timeout_precommit"`
+}
+
+// UnmarshalJSON decodes the round state as emitted by CometBFT and older
+// Tendermint versions.
+func (rs *RoundState) UnmarshalJSON(bz []byte) error {
+	var aux roundStateJSON
+	if err := json.Unmarshal(bz, &aux); err != nil {
+		return err
+	}
+
+	validators, err := decodeValidatorSet(aux.Validators)
+	if err != nil {
+		return fmt.Errorf("failed to decode validators: %w", err)
+	}
+	lastValidators, err := decodeValidatorSet(aux.LastValidators)
+	if err != nil {
+		return fmt.Errorf("failed to decode last validators: %w", err)
+	}
+
+	*rs = RoundState{
+		Height:                    int64(aux.Height),
+		Round:                     int32(aux.Round),
+		Step:                      aux.Step,
+		StartTime:                 aux.StartTime,
+		CommitTime:                aux.CommitTime,
+		Validators:                validators,
+		Proposal:                  aux.Proposal,
+		ProposalBlock:             aux.ProposalBlock,
+		ProposalBlockParts:        aux.ProposalBlockParts,
+		LockedRound:               int32(aux.LockedRound),
+		LockedBlock:               aux.LockedBlock,
+		LockedBlockParts:          aux.LockedBlockParts,
+		ValidRound:                int32(aux.ValidRound),
+		ValidBlock:                aux.ValidBlock,
+		ValidBlockParts:           aux.ValidBlockParts,
+		Votes:                     aux.Votes,
+		CommitRound:               int32(aux.CommitRound),
+		LastCommit:                aux.LastCommit,
+		LastValidators:            lastValidators,
+		TriggeredTimeoutPrecommit: aux.TriggeredTimeoutPrecommit,
+	}
+
+	return nil
+}
+
+// PrevotesBitArray returns the validators that prevoted in the current round,
+// or nil if the node has no prevotes for it.
+func (rs *RoundState) PrevotesBitArray() *bits.BitArray {
+	return rs.Votes.Round(rs.Round).PrevotesBitArray()
+}
+
+// PrecommitsBitArray returns the validators that precommitted in the current
+// round, or nil if the node has no precommits for it.
+func (rs *RoundState) PrecommitsBitArray() *bits.BitArray {
+	return rs.Votes.Round(rs.Round).PrecommitsBitArray()
+}
+
+// MissingVoters returns the validators of valset from which the node has not
+// received a prevote and a precommit respectively in the current round.
+// If valset is nil, the validator set of the round state is used.
+func (rs *RoundState) MissingVoters(valset *types.ValidatorSet) (prevotes, precommits []*types.Validator) {
+	if valset == nil {
+		valset = rs.Validators
+	}
+	return rs.Votes.Round(rs.Round).MissingVoters(valset)
+}
+
+//-----------------------------------------------------------------------------
+
+// RoundStateSimple is the summary of the consensus state of a node, as
+// returned by the consensus_state endpoint.
+type RoundStateSimple struct {
+	Height            int64             `json:"height"`
+	Round             int32             `json:"round"`
+	Step              RoundStepType     `json:"step"`
+	StartTime         time.Time         `json:"start_time"`
+	ProposalBlockHash cmtbytes.HexBytes `json:"proposal_block_hash"`
+	LockedBlockHash   cmtbytes.HexBytes `json:"locked_block_hash"`
+	ValidBlockHash    cmtbytes.HexBytes `json:"valid_block_hash"`
+	Votes             HeightVoteSet     `json:"height_vote_set"`
+	Proposer          ProposerInfo      `json:"proposer"`
+}
+
+// ProposerInfo identifies the proposer of the current round.
+type ProposerInfo struct {
+	Address cmtbytes.HexBytes `json:"address"`
+	Index   int32             `json:"index"`
+}
+
+type roundStateSimpleJSON struct {
+	HeightRoundStep   string            `json:"height/round/step"`
+	StartTime         time.Time         `json:"start_time"`
+	ProposalBlockHash cmtbytes.HexBytes `json:"proposal_block_hash"`
+	LockedBlockHash   cmtbytes.HexBytes `json:"locked_block_hash"`
+	ValidBlockHash    cmtbytes.HexBytes `json:"valid_block_hash"`
+	Votes             HeightVoteSet     `json:"height_vote_set"`
+	Proposer          struct {
+		Address cmtbytes.HexBytes `json:"address"`
+		Index   jsonInt           `json:"index"`
+	} `json:"proposer"`
+}
+
+// UnmarshalJSON decodes the round state summary, splitting the combined
+// "height/round/step" field.
+func (rs *RoundStateSimple) UnmarshalJSON(bz []byte) error {
+	var aux roundStateSimpleJSON
+	if err := json.Unmarshal(bz, &aux); err != nil {
+		return err
+	}
+
+	parts := strings.Split(aux.HeightRoundStep, "/")
+	if len(parts) != 3 {
+		return fmt.Errorf("invalid height/round/step %q", aux.HeightRoundStep)
+	}
+	height, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid height in %q: %w", aux.HeightRoundStep, err)
+	}
+	round, err := strconv.ParseInt(parts[1], 10, 32)
+	if err != nil {
+		return fmt.Errorf("invalid round in %q: %w", aux.HeightRoundStep, err)
+	}
+	var step RoundStepType
+	if err := step.parse(parts[2]); err != nil {
+		return err
+	}
+
+	*rs = RoundStateSimple{
+		Height:            height,
+		Round:             int32(round),
+		Step:              step,
+		StartTime:         aux.StartTime,
+		ProposalBlockHash: aux.ProposalBlockHash,
+		LockedBlockHash:   aux.LockedBlockHash,
+		ValidBlockHash:    aux.ValidBlockHash,
+		Votes:             aux.Votes,
+		Proposer: ProposerInfo{
+			Address: aux.Proposer.Address,
+			Index:   int32(aux.Proposer.Index),
+		},
+	}
+
+	return nil
+}
+
+// PrevotesBitArray returns the validators that prevoted in the current round,
+// or nil if the node has no prevotes for it.
+func (rs *RoundStateSimple) PrevotesBitArray() *bits.BitArray {
+	return rs.Votes.Round(rs.Round).PrevotesBitArray()
+}
+
+// PrecommitsBitArray returns the validators that precommitted in the current
+// round, or nil if the node has no precommits for it.
+func (rs *RoundStateSimple) PrecommitsBitArray() *bits.BitArray {
+	return rs.Votes.Round(rs.Round).PrecommitsBitArray()
+}
+
+// MissingVoters returns the validators of valset from which the node has not
+// received a prevote and a precommit respectively in the current round.
+// valset must be the validator set at rs.Height.
+func (rs *RoundStateSimple) MissingVoters(valset *types.ValidatorSet) (prevotes, precommits []*types.Validator) {
+	return rs.Votes.Round(rs.Round).MissingVoters(valset)
+}
+
+//-----------------------------------------------------------------------------
+
+// decodeValidatorSet decodes a validator set with the Amino JSON codec, which
+// is required to decode the public keys.
+func decodeValidatorSet(bz json.RawMessage) (*types.ValidatorSet, error) {
+	if len(bz) == 0 || string(bz) == "null" {
+		return nil, nil
+	}
+	vals := new(types.ValidatorSet)
+	if err := cmtjson.Unmarshal(bz, vals); err != nil {
+		return nil, err
+	}
+	return vals, nil
+}
+
+// jsonInt decodes integers encoded either as JSON numbers or as strings, since
+// the encoding of 32-bit and 64-bit integers has changed between versions.
+type jsonInt int64
+
+func (i *jsonInt) UnmarshalJSON(bz []byte) error {
+	s := strings.Trim(string(bz), `"`)
+	if s == "" || s == "null" {
+		*i = 0
+		return nil
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid integer %s: %w", bz, err)
+	}
+	*i = jsonInt(n)
+	return nil
+}
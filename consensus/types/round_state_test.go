@@ -0,0 +1,261 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	_ "github.com/strangelove-ventures/cometbft-client/crypto/encoding"
+	cmtjson "github.com/strangelove-ventures/cometbft-client/libs/json"
+	"github.com/strangelove-ventures/cometbft-client/types"
+)
+
+const testValidators = `{
+  "validators": [
+    {
+      "address": "0A4C1E0D1F3A6E8B7C2D5E4F6A7B8C9D0E1F2A3B",
+      "pub_key": {"type": "tendermint/PubKeyEd25519", "value": "2AHQzoxRF3I2zrLmlbTL8FwgqgzTI24pnzazZDOK81U="},
+      "voting_power": "30",
+      "proposer_priority": "-10"
+    },
+    {
+      "address": "1B5D2F1E2A4B7F9C8D3E6F5A7B8C9DAE1F2A3B4C",
+      "pub_key": {"type": "tendermint/PubKeyEd25519", "value": "Hd3xXr0EgXqz0gEO12kN/eBSW2B8r7wRJWEv8cfjZ2A="},
+      "voting_power": "20",
+      "proposer_priority": "5"
+    },
+    {
+      "address": "2C6E3A2F3B5C8A0D9E4F7A6B8C9DAEBF2A3B4C5D",
+      "pub_key": {"type": "tendermint/PubKeyEd25519", "value": "qQKe1UDkWaDnHEMUEiUwKljv0mxATRgFxytiBErnlmI="},
+      "voting_power": "10",
+      "proposer_priority": "5"
+    }
+  ],
+  "proposer": {
+    "address": "0A4C1E0D1F3A6E8B7C2D5E4F6A7B8C9D0E1F2A3B",
+    "pub_key": {"type": "tendermint/PubKeyEd25519", "value": "2AHQzoxRF3I2zrLmlbTL8FwgqgzTI24pnzazZDOK81U="},
+    "voting_power": "30",
+    "proposer_priority": "-10"
+  }
+}`
+
+// testRoundState is a dump_consensus_state round state from CometBFT v0.38.
+var testRoundState = `{
+  "height": "100",
+  "round": 1,
+  "step": 6,
+  "start_time": "2023-06-01T00:00:05.123456789Z",
+  "commit_time": "2023-06-01T00:00:00.5Z",
+  "validators": ` + testValidators + `,
+  "proposal": null,
+  "proposal_block": null,
+  "proposal_block_parts": null,
+  "locked_round": -1,
+  "locked_block": null,
+  "locked_block_parts": null,
+  "valid_round": -1,
+  "valid_block": null,
+  "valid_block_parts": null,
+  "votes": [
+    {
+      "round": 0,
+      "prevotes": ["nil-Vote", "nil-Vote", "nil-Vote"],
+      "prevotes_bit_array": "BA{3:___} 0/60 = 0.00",
+      "precommits": ["nil-Vote", "nil-Vote", "nil-Vote"],
+      "precommits_bit_array": "BA{3:___} 0/60 = 0.00"
+    },
+    {
+      "round": 1,
+      "prevotes": [
+        "Vote{0:0A4C1E0D1F3A 100/01/SIGNED_MSG_TYPE_PREVOTE(Prevote) 8B01023386C3 7D1B9D5E5E0C 000000000000 @ 2023-06-01T00:00:06.000000000Z}",
+        "nil-Vote",
+        "Vote{2:2C6E3A2F3B5C 100/01/SIGNED_MSG_TYPE_PREVOTE(Prevote) 000000000000 1A2B3C4D5E6F 000000000000 @ 2023-06-01T00:00:06.100000000Z}"
+      ],
+      "prevotes_bit_array": "BA{3:x_x} 40/60 = 0.67",
+      "precommits": [
+        "Vote{0:0A4C1E0D1F3A 100/01/SIGNED_MSG_TYPE_PRECOMMIT(Precommit) 8B01023386C3 7D1B9D5E5E0C 000000000000 @ 2023-06-01T00:00:07.000000000Z}",
+        "nil-Vote",
+        "nil-Vote"
+      ],
+      "precommits_bit_array": "BA{3:x__} 30/60 = 0.50"
+    }
+  ],
+  "commit_round": -1,
+  "last_commit": {
+    "votes": [
+      "Vote{0:0A4C1E0D1F3A 99/00/SIGNED_MSG_TYPE_PRECOMMIT(Precommit) 5F1E2D3C4B5A 7D1B9D5E5E0C 000000000000 @ 2023-06-01T00:00:00.000000000Z}",
+      "Vote{1:1B5D2F1E2A4B 99/00/SIGNED_MSG_TYPE_PRECOMMIT(Precommit) 5F1E2D3C4B5A 7D1B9D5E5E0C 000000000000 @ 2023-06-01T00:00:00.000000000Z}",
+      "nil-Vote"
+    ],
+    "votes_bit_array": "BA{3:xx_} 50/60 = 0.83",
+    "peer_maj_23s": {}
+  },
+  "last_validators": ` + testValidators + `,
+  "triggered_timeout_precommit": false
+}`
+
+func TestRoundStateUnmarshal(t *testing.T) {
+	var rs RoundState
+	require.NoError(t, json.Unmarshal([]byte(testRoundState), &rs))
+
+	assert.Equal(t, int64(100), rs.Height)
+	assert.Equal(t, int32(1), rs.Round)
+	assert.Equal(t, RoundStepPrecommit, rs.Step)
+	assert.Equal(t, int32(-1), rs.LockedRound)
+	require.Equal(t, 3, rs.Validators.Size())
+	require.Len(t, rs.Votes, 2)
+
+	assert.Equal(t, "BA{3:x_x}", rs.PrevotesBitArray().String())
+	assert.Equal(t, "BA{3:x__}", rs.PrecommitsBitArray().String())
+
+	prevotes := rs.Votes.Round(1).Prevotes
+	assert.Equal(t, int64(40), prevotes.VotedPower)
+	assert.Equal(t, int64(60), prevotes.TotalPower)
+	require.NotNil(t, prevotes.Votes[0])
+	assert.Equal(t, int32(0), prevotes.Votes[0].ValidatorIndex)
+	assert.Equal(t, "0A4C1E0D1F3A", prevotes.Votes[0].ValidatorAddress.String())
+	assert.Equal(t, "Prevote", prevotes.Votes[0].Type)
+	assert.False(t, prevotes.Votes[0].IsForNil())
+	assert.Nil(t, prevotes.Votes[1])
+	assert.True(t, prevotes.Votes[2].IsForNil())
+
+	missingPrevotes, missingPrecommits := rs.MissingVoters(nil)
+	require.Len(t, missingPrevotes, 1)
+	assert.Equal(t, rs.Validators.Validators[1].Address, missingPrevotes[0].Address)
+	require.Len(t, missingPrecommits, 2)
+
+	require.NotNil(t, rs.LastCommit)
+	assert.Equal(t, "BA{3:xx_}", rs.LastCommit.BitArray().String())
+	assert.Len(t, rs.LastCommit.MissingVoters(rs.LastValidators), 1)
+
+	// Rounds the node has no votes for have every validator missing.
+	missingPrevotes, _ = rs.Votes.Round(5).MissingVoters(rs.Validators)
+	assert.Len(t, missingPrevotes, 3)
+}
+
+// TestRoundStateUnmarshalLegacy checks the encoding of Tendermint v0.33 and
+// earlier, where 32-bit integers were strings and votes had no extensions.
+func TestRoundStateUnmarshalLegacy(t *testing.T) {
+	legacy := `{
+  "height": "100",
+  "round": "0",
+  "step": 4,
+  "start_time": "2020-06-01T00:00:05Z",
+  "commit_time": "2020-06-01T00:00:00Z",
+  "validators": ` + testValidators + `,
+  "locked_round": "-1",
+  "valid_round": "-1",
+  "votes": [
+    {
+      "round": "0",
+      "prevotes": [
+        "Vote{0:0A4C1E0D1F3A 100/00/1(Prevote) 8B01023386C3 7D1B9D5E5E0C @ 2020-06-01T00:00:06.000000000Z}",
+        "nil-Vote",
+        "nil-Vote"
+      ],
+      "prevotes_bit_array": "BA{3:x__} 30/60 = 0.50",
+      "precommits": ["nil-Vote", "nil-Vote", "nil-Vote"],
+      "precommits_bit_array": "BA{3:___} 0/60 = 0.00"
+    }
+  ],
+  "commit_round": "-1",
+  "last_commit": null,
+  "last_validators": null
+}`
+
+	var rs RoundState
+	require.NoError(t, json.Unmarshal([]byte(legacy), &rs))
+
+	assert.Equal(t, int32(0), rs.Round)
+	assert.Equal(t, RoundStepPrevote, rs.Step)
+	assert.Nil(t, rs.LastCommit)
+	assert.Nil(t, rs.LastValidators)
+	assert.Equal(t, "Prevote", rs.Votes.Round(0).Prevotes.Votes[0].Type)
+
+	missingPrevotes, missingPrecommits := rs.MissingVoters(nil)
+	assert.Len(t, missingPrevotes, 2)
+	assert.Len(t, missingPrecommits, 3)
+}
+
+func TestRoundStateSimpleUnmarshal(t *testing.T) {
+	simple := `{
+  "height/round/step": "100/1/6",
+  "start_time": "2023-06-01T00:00:05.123456789Z",
+  "proposal_block_hash": "8B01023386C371778ECB6368573E539AFC3CC860EC3A2F614E54FE5652F4FC80",
+  "locked_block_hash": "",
+  "valid_block_hash": "",
+  "height_vote_set": [
+    {
+      "round": 1,
+      "prevotes": ["nil-Vote", "nil-Vote", "nil-Vote"],
+      "prevotes_bit_array": "BA{3:_xx} 30/60 = 0.50",
+      "precommits": ["nil-Vote", "nil-Vote", "nil-Vote"],
+      "precommits_bit_array": "BA{3:___} 0/60 = 0.00"
+    }
+  ],
+  "proposer": {"address": "0A4C1E0D1F3A6E8B7C2D5E4F6A7B8C9D0E1F2A3B", "index": 0}
+}`
+
+	var rs RoundStateSimple
+	require.NoError(t, json.Unmarshal([]byte(simple), &rs))
+
+	assert.Equal(t, int64(100), rs.Height)
+	assert.Equal(t, int32(1), rs.Round)
+	assert.Equal(t, RoundStepPrecommit, rs.Step)
+	assert.Equal(t, "0A4C1E0D1F3A6E8B7C2D5E4F6A7B8C9D0E1F2A3B", rs.Proposer.Address.String())
+	assert.Equal(t, "BA{3:_xx}", rs.PrevotesBitArray().String())
+
+	var valset types.ValidatorSet
+	require.NoError(t, cmtjson.Unmarshal([]byte(testValidators), &valset))
+
+	missingPrevotes, missingPrecommits := rs.MissingVoters(&valset)
+	require.Len(t, missingPrevotes, 1)
+	assert.Equal(t, valset.Validators[0].Address, missingPrevotes[0].Address)
+	assert.Len(t, missingPrecommits, 3)
+}
+
+func TestPeerStateUnmarshal(t *testing.T) {
+	for name, psh := range map[string]string{
+		"cometbft":   `"proposal_block_part_set_header": {"total": 2, "hash": "8B01023386C3"}`,
+		"tendermint": `"proposal_block_parts_header": {"total": "2", "hash": "8B01023386C3"}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			peerState := `{
+  "round_state": {
+    "height": "100",
+    "round": 1,
+    "step": 4,
+    "start_time": "2023-06-01T00:00:05.123456789Z",
+    "proposal": true,
+    ` + psh + `,
+    "proposal_block_parts": "x_",
+    "proposal_pol_round": -1,
+    "proposal_pol": "___",
+    "prevotes": "x_x",
+    "precommits": null,
+    "last_commit_round": 0,
+    "last_commit": "xx_",
+    "catchup_commit_round": -1,
+    "catchup_commit": null
+  },
+  "stats": {"votes": "1234", "block_parts": "56"}
+}`
+
+			var ps PeerState
+			require.NoError(t, json.Unmarshal([]byte(peerState), &ps))
+
+			prs := ps.RoundState
+			assert.Equal(t, int64(100), prs.Height)
+			assert.Equal(t, RoundStepPrevote, prs.Step)
+			assert.True(t, prs.Proposal)
+			assert.Equal(t, uint32(2), prs.ProposalBlockPartSetHeader.Total)
+			assert.Equal(t, "8B01023386C3", prs.ProposalBlockPartSetHeader.Hash.String())
+			assert.Equal(t, []int{0, 2}, prs.Prevotes.GetTrueIndices())
+			assert.Nil(t, prs.Precommits)
+			assert.Equal(t, 1234, ps.Stats.Votes)
+			assert.Equal(t, 56, ps.Stats.BlockParts)
+		})
+	}
+}
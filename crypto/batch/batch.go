@@ -0,0 +1,159 @@
+package batch
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+
+	"github.com/strangelove-ventures/cometbft-client/crypto"
+	"github.com/strangelove-ventures/cometbft-client/crypto/ed25519"
+	"github.com/strangelove-ventures/cometbft-client/crypto/sr25519"
+)
+
+// CreateBatchVerifier checks if a key type implements the batch verifier interface.
+// Currently only ed25519 & sr25519 supports batch verification.
+func CreateBatchVerifier(pk crypto.PubKey) (crypto.BatchVerifier, bool) {
+	switch pk.Type() {
+	case ed25519.KeyType:
+		return ed25519.NewBatchVerifier(), true
+	case sr25519.KeyType:
+		return sr25519.NewBatchVerifier(), true
+	}
+
+	// case where the key does not support batch verification
+	return nil, false
+}
+
+// SupportsBatchVerifier checks if a key type implements the batch verifier
+// interface.
+func SupportsBatchVerifier(pk crypto.PubKey) bool {
+	switch pk.Type() {
+	case ed25519.KeyType, sr25519.KeyType:
+		return true
+	}
+
+	return false
+}
+
+// chunkSize is the number of signatures verified by a goroutine. Above it, the signatures of
+// a key type are split between several batches, verified in parallel.
+const chunkSize = 64
+
+var _ crypto.BatchVerifier = &Verifier{}
+
+// Verifier is a crypto.BatchVerifier which accepts keys of any type, such as those of the
+// signatures of a commit. The signatures of the key types which support batch verification
+// are verified in batches of their type, and the others one at a time. Large batches are
+// verified in parallel.
+type Verifier struct {
+	n int
+	// batches are the open batch of each key type. A batch is closed once it is full.
+	batches map[string]*chunk
+	chunks  []*chunk
+	// singles are the signatures verified one at a time, with the last chunk still open.
+	singles *chunk
+}
+
+// chunk is a set of signatures verified by one goroutine, either as a batch or one at a time.
+type chunk struct {
+	verifier crypto.BatchVerifier // nil for signatures verified one at a time
+	entries  []entry
+}
+
+type entry struct {
+	index          int
+	key            crypto.PubKey
+	msg, signature []byte
+}
+
+// NewVerifier returns an empty Verifier.
+func NewVerifier() *Verifier {
+	return &Verifier{batches: make(map[string]*chunk)}
+}
+
+// Add appends an entry into the Verifier. It returns the error of the batch verifier of the
+// key type, and then the entry is not added.
+func (v *Verifier) Add(key crypto.PubKey, msg, signature []byte) error {
+	if key == nil {
+		return errors.New("batch: nil public key")
+	}
+	e := entry{index: v.n, key: key, msg: msg, signature: signature}
+
+	if !SupportsBatchVerifier(key) {
+		if v.singles == nil {
+			v.singles = &chunk{}
+			v.chunks = append(v.chunks, v.singles)
+		}
+		v.singles.entries = append(v.singles.entries, e)
+		if len(v.singles.entries) == chunkSize {
+			v.singles = nil
+		}
+		v.n++
+		return nil
+	}
+
+	c := v.batches[key.Type()]
+	if c == nil {
+		bv, _ := CreateBatchVerifier(key)
+		c = &chunk{verifier: bv}
+	}
+	if err := c.verifier.Add(key, msg, signature); err != nil {
+		return err
+	}
+	if len(c.entries) == 0 {
+		v.chunks = append(v.chunks, c)
+	}
+	c.entries = append(c.entries, e)
+	if len(c.entries) == chunkSize {
+		delete(v.batches, key.Type())
+	} else {
+		v.batches[key.Type()] = c
+	}
+	v.n++
+	return nil
+}
+
+// Verify verifies all the entries in the Verifier, and returns if every signature is valid,
+// and a vector of bools indicating the validity of each signature, in the order they were
+// added. The chunks are verified by at most GOMAXPROCS goroutines.
+func (v *Verifier) Verify() (bool, []bool) {
+	valid := make([]bool, v.n)
+	if len(v.chunks) == 1 {
+		v.chunks[0].verify(valid)
+	} else {
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, runtime.GOMAXPROCS(0))
+		for _, c := range v.chunks {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(c *chunk) {
+				defer wg.Done()
+				c.verify(valid)
+				<-sem
+			}(c)
+		}
+		wg.Wait()
+	}
+
+	ok := v.n > 0
+	for _, b := range valid {
+		ok = ok && b
+	}
+	return ok, valid
+}
+
+// verify sets the validity of the entries of the chunk, which each goroutine writes at
+// distinct indexes.
+func (c *chunk) verify(valid []bool) {
+	if c.verifier == nil {
+		for _, e := range c.entries {
+			valid[e.index] = e.key.VerifySignature(e.msg, e.signature)
+		}
+		return
+	}
+
+	ok, chunkValid := c.verifier.Verify()
+	for i, e := range c.entries {
+		valid[e.index] = ok || i < len(chunkValid) && chunkValid[i]
+	}
+}
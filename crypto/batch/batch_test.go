@@ -0,0 +1,104 @@
+package batch_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/strangelove-ventures/cometbft-client/crypto"
+	"github.com/strangelove-ventures/cometbft-client/crypto/batch"
+	"github.com/strangelove-ventures/cometbft-client/crypto/bls12381"
+	"github.com/strangelove-ventures/cometbft-client/crypto/ed25519"
+	"github.com/strangelove-ventures/cometbft-client/crypto/secp256k1"
+	"github.com/strangelove-ventures/cometbft-client/crypto/sr25519"
+)
+
+func TestCreateBatchVerifier(t *testing.T) {
+	for _, tc := range []struct {
+		key      crypto.PubKey
+		supports bool
+	}{
+		{ed25519.GenPrivKey().PubKey(), true},
+		{sr25519.GenPrivKey().PubKey(), true},
+		{secp256k1.GenPrivKey().PubKey(), false},
+		{bls12381.PubKey(make([]byte, bls12381.PubKeySize)), false},
+	} {
+		assert.Equal(t, tc.supports, batch.SupportsBatchVerifier(tc.key), tc.key.Type())
+		bv, ok := batch.CreateBatchVerifier(tc.key)
+		assert.Equal(t, tc.supports, ok, tc.key.Type())
+		assert.Equal(t, tc.supports, bv != nil, tc.key.Type())
+	}
+}
+
+type signed struct {
+	key      crypto.PubKey
+	msg, sig []byte
+}
+
+func sign(t *testing.T, priv crypto.PrivKey, i int) signed {
+	msg := []byte(fmt.Sprintf("vote %d", i))
+	sig, err := priv.Sign(msg)
+	require.NoError(t, err)
+	return signed{priv.PubKey(), msg, sig}
+}
+
+func TestVerifierMixedKeys(t *testing.T) {
+	// As in a commit of 150 validators, in more than one chunk per key type.
+	var sigs []signed
+	for i := 0; i < 150; i++ {
+		var priv crypto.PrivKey
+		switch i % 3 {
+		case 0, 1:
+			priv = ed25519.GenPrivKey()
+		case 2:
+			priv = sr25519.GenPrivKey()
+		}
+		if i%10 == 0 {
+			priv = secp256k1.GenPrivKey()
+		}
+		sigs = append(sigs, sign(t, priv, i))
+	}
+	// BLS12-381 signatures are slow to verify.
+	sigs = append(sigs, sign(t, bls12381.GenPrivKey(), 150))
+
+	v := batch.NewVerifier()
+	for _, s := range sigs {
+		require.NoError(t, v.Add(s.key, s.msg, s.sig))
+	}
+	ok, valid := v.Verify()
+	assert.True(t, ok)
+	require.Len(t, valid, len(sigs))
+	for i, b := range valid {
+		assert.True(t, b, i)
+	}
+
+	// The invalid signatures are reported at their index, whatever their key type.
+	invalid := map[int]bool{3: true, 20: true, 149: true, 150: true}
+	v = batch.NewVerifier()
+	for i, s := range sigs {
+		msg := s.msg
+		if invalid[i] {
+			msg = []byte("forged")
+		}
+		require.NoError(t, v.Add(s.key, msg, s.sig))
+	}
+	ok, valid = v.Verify()
+	assert.False(t, ok)
+	require.Len(t, valid, len(sigs))
+	for i, b := range valid {
+		assert.Equal(t, !invalid[i], b, i)
+	}
+}
+
+func TestVerifierAdd(t *testing.T) {
+	v := batch.NewVerifier()
+	require.Error(t, v.Add(nil, []byte("msg"), nil))
+	// ed25519 rejects a signature of the wrong size.
+	require.Error(t, v.Add(ed25519.GenPrivKey().PubKey(), []byte("msg"), []byte("sig")))
+
+	ok, valid := v.Verify()
+	assert.False(t, ok)
+	assert.Empty(t, valid)
+}
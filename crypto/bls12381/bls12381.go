@@ -0,0 +1,226 @@
+// Package bls12381 implements the BLS12-381 keys of CometBFT v1, which sign with the minimal
+// public key size variant of the BLS signature scheme and the proof of possession ciphersuite.
+//
+// It is a pure Go implementation, which does not need cgo as CometBFT's does, but is slow:
+// a signature takes about a quarter of a second to verify.
+package bls12381
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"golang.org/x/crypto/hkdf"
+
+	"github.com/strangelove-ventures/cometbft-client/crypto"
+	"github.com/strangelove-ventures/cometbft-client/crypto/tmhash"
+	cmtjson "github.com/strangelove-ventures/cometbft-client/libs/json"
+)
+
+var (
+	_ crypto.PrivKey = PrivKey{}
+	_ crypto.PubKey  = PubKey{}
+)
+
+const (
+	PrivKeyName = "cometbft/PrivKeyBls12_381"
+	PubKeyName  = "cometbft/PubKeyBls12_381"
+	// PrivKeySize is the size, in bytes, of private keys as used in this package.
+	PrivKeySize = 32
+	// PubKeySize is the size, in bytes, of compressed public keys, which are points of G1.
+	PubKeySize = 48
+	// SignatureLength is the size, in bytes, of compressed signatures, which are points of G2.
+	SignatureLength = 96
+	// MaxMsgLen is the length above which the SHA-256 of a message is signed instead of the
+	// message itself.
+	MaxMsgLen = 32
+
+	KeyType = "bls12_381"
+)
+
+// dst is the domain separation tag of the proof of possession ciphersuite.
+var dst = []byte("BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_")
+
+func init() {
+	cmtjson.RegisterType(PubKey{}, PubKeyName)
+	cmtjson.RegisterType(PrivKey{}, PrivKeyName)
+}
+
+// ErrInvalidPrivKey is returned by NewPrivateKeyFromBytes for an invalid secret key.
+var ErrInvalidPrivKey = errors.New("bls12381: invalid private key")
+
+// PrivKey implements crypto.PrivKey. It is the big-endian secret scalar.
+type PrivKey []byte
+
+// NewPrivateKeyFromBytes checks that bz is a valid secret key and returns it as a PrivKey.
+func NewPrivateKeyFromBytes(bz []byte) (PrivKey, error) {
+	if len(bz) != PrivKeySize {
+		return nil, ErrInvalidPrivKey
+	}
+	sk := new(big.Int).SetBytes(bz)
+	if sk.Sign() == 0 || sk.Cmp(groupR) >= 0 {
+		return nil, ErrInvalidPrivKey
+	}
+	return PrivKey(bytes.Clone(bz)), nil
+}
+
+// Bytes returns the privkey byte format.
+func (privKey PrivKey) Bytes() []byte {
+	return []byte(privKey)
+}
+
+// Sign produces a signature on the provided message. If msg is longer than MaxMsgLen, its
+// SHA-256 is signed instead.
+func (privKey PrivKey) Sign(msg []byte) ([]byte, error) {
+	if len(privKey) != PrivKeySize {
+		return nil, ErrInvalidPrivKey
+	}
+	sig := g2.mul(hashToG2(signedMsg(msg), dst), new(big.Int).SetBytes(privKey))
+	return g2.encode(sig), nil
+}
+
+// PubKey gets the corresponding public key from the private key.
+//
+// Panics if the private key is not initialized.
+func (privKey PrivKey) PubKey() crypto.PubKey {
+	if len(privKey) != PrivKeySize {
+		panic("bls12381: uninitialized private key")
+	}
+	return PubKey(g1.encode(g1.mul(g1.gen, new(big.Int).SetBytes(privKey))))
+}
+
+// Equals - you probably don't need to use this.
+// Runs in constant time based on length of the keys.
+func (privKey PrivKey) Equals(other crypto.PrivKey) bool {
+	if otherBls, ok := other.(PrivKey); ok {
+		return subtle.ConstantTimeCompare(privKey[:], otherBls[:]) == 1
+	}
+	return false
+}
+
+func (privKey PrivKey) Type() string {
+	return KeyType
+}
+
+// GenPrivKey generates a new BLS12-381 private key.
+// It uses OS randomness to generate the private key.
+func GenPrivKey() PrivKey {
+	return genPrivKey(crypto.CReader())
+}
+
+// genPrivKey generates a new BLS12-381 private key from the keying material of rand.
+func genPrivKey(rand io.Reader) PrivKey {
+	ikm := make([]byte, 32)
+	if _, err := io.ReadFull(rand, ikm); err != nil {
+		panic(err)
+	}
+	return keyGen(ikm)
+}
+
+// GenPrivKeyFromSecret hashes the secret with SHA2, and uses
+// that 32 byte output to create the private key.
+// NOTE: secret should be the output of a KDF like bcrypt,
+// if it's derived from user input.
+func GenPrivKeyFromSecret(secret []byte) PrivKey {
+	return keyGen(crypto.Sha256(secret)) // Not Ripemd160 because we want 32 bytes.
+}
+
+// keyGen derives a secret key from the keying material ikm, with the KeyGen procedure of the
+// IRTF draft of BLS signatures.
+func keyGen(ikm []byte) PrivKey {
+	salt := []byte("BLS-SIG-KEYGEN-SALT-")
+	ikm = append(bytes.Clone(ikm), 0)
+	// L = ceil((3 * ceil(log2(r))) / 16)
+	const l = 48
+	sk := new(big.Int)
+	for sk.Sign() == 0 {
+		h := sha256.Sum256(salt)
+		salt = h[:]
+		okm := make([]byte, l)
+		if _, err := io.ReadFull(hkdf.New(sha256.New, ikm, salt, []byte{0, l}), okm); err != nil {
+			panic(err)
+		}
+		sk.SetBytes(okm).Mod(sk, groupR)
+	}
+	return PrivKey(sk.FillBytes(make([]byte, PrivKeySize)))
+}
+
+// PubKey implements crypto.PubKey. It is a compressed point of G1.
+type PubKey []byte
+
+// Address is the SHA256-20 of the raw pubkey bytes.
+func (pubKey PubKey) Address() crypto.Address {
+	if len(pubKey) != PubKeySize {
+		panic("pubkey is incorrect size")
+	}
+	return crypto.Address(tmhash.SumTruncated(pubKey))
+}
+
+// Bytes returns the PubKey byte format.
+func (pubKey PubKey) Bytes() []byte {
+	return []byte(pubKey)
+}
+
+// Validate checks that the public key is a point of G1 other than the point at infinity.
+func (pubKey PubKey) Validate() error {
+	_, err := pubKey.point()
+	return err
+}
+
+func (pubKey PubKey) point() (point, error) {
+	p, err := g1.decode(pubKey)
+	if err != nil {
+		return point{}, fmt.Errorf("bls12381: invalid public key: %w", err)
+	}
+	if p.inf {
+		return point{}, errors.New("bls12381: public key is the point at infinity")
+	}
+	return p, nil
+}
+
+// VerifySignature verifies the signature of msg, hashed as by PrivKey.Sign.
+func (pubKey PubKey) VerifySignature(msg []byte, sig []byte) bool {
+	if len(sig) != SignatureLength {
+		return false
+	}
+	pk, err := pubKey.point()
+	if err != nil {
+		return false
+	}
+	s, err := g2.decode(sig)
+	if err != nil || s.inf {
+		return false
+	}
+	// e(pk, H(msg)) == e(g1, sig)
+	return pairingCheck(
+		[]point{pk, g1.neg(g1.gen)},
+		[]point{hashToG2(signedMsg(msg), dst), s},
+	)
+}
+
+func signedMsg(msg []byte) []byte {
+	if len(msg) > MaxMsgLen {
+		h := sha256.Sum256(msg)
+		return h[:]
+	}
+	return msg
+}
+
+func (pubKey PubKey) String() string {
+	return fmt.Sprintf("PubKeyBls12_381{%X}", []byte(pubKey))
+}
+
+func (pubKey PubKey) Type() string {
+	return KeyType
+}
+
+func (pubKey PubKey) Equals(other crypto.PubKey) bool {
+	if otherBls, ok := other.(PubKey); ok {
+		return bytes.Equal(pubKey[:], otherBls[:])
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+package bls12381_test
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/strangelove-ventures/cometbft-client/crypto"
+	"github.com/strangelove-ventures/cometbft-client/crypto/bls12381"
+	cmtjson "github.com/strangelove-ventures/cometbft-client/libs/json"
+)
+
+func mustDecodeHex(t *testing.T, s string) []byte {
+	bz, err := hex.DecodeString(s)
+	require.NoError(t, err)
+	return bz
+}
+
+// The vectors are those of the Ethereum consensus specs, which use the same ciphersuite.
+func TestSignVectors(t *testing.T) {
+	privKey, err := bls12381.NewPrivateKeyFromBytes(mustDecodeHex(t, "263dbd792f5b1be47ed85f8938c0f29586af0d3ac7b977f21c278fe1462040e3"))
+	require.NoError(t, err)
+	pubKey := privKey.PubKey()
+	assert.Equal(t,
+		"a491d1b0ecd9bb917989f0e74f0dea0422eac4a873e5e2644f368dffb9a6e20fd6e10c1b77654d067c0618f6e5a7f79a",
+		hex.EncodeToString(pubKey.Bytes()))
+
+	msg := mustDecodeHex(t, "5656565656565656565656565656565656565656565656565656565656565656")
+	sig, err := privKey.Sign(msg)
+	require.NoError(t, err)
+	assert.Equal(t,
+		"882730e5d03f6b42c3abc26d3372625034e1d871b65a8a6b900a56dae22da98abbe1b68f85e49fe7652a55ec3d0591c2"+
+			"0767677e33e5cbb1207315c41a9ac03be39c2e7668edc043d6cb1d9fd93033caa8a1c5b0e84bedaeb6c64972503a43eb",
+		hex.EncodeToString(sig))
+	assert.True(t, pubKey.VerifySignature(msg, sig))
+
+	other, err := bls12381.NewPrivateKeyFromBytes(mustDecodeHex(t, "47b8192d77bf871b62e87859d653922725724a5c031afeabc60bcef5ff665138"))
+	require.NoError(t, err)
+	assert.Equal(t,
+		"b301803f8b5ac4a1133581fc676dfedc60d891dd5fa99028805e5ea5b08d3491af75d0707adab3b70c6a6a580217bf81",
+		hex.EncodeToString(other.PubKey().Bytes()))
+	assert.False(t, other.PubKey().VerifySignature(msg, sig))
+}
+
+func TestSignAndValidateBls12381(t *testing.T) {
+	privKey := bls12381.GenPrivKey()
+	pubKey := privKey.PubKey()
+	require.NoError(t, pubKey.(bls12381.PubKey).Validate())
+	assert.Len(t, pubKey.Address(), crypto.AddressSize)
+
+	// Longer than MaxMsgLen, so that its hash is signed.
+	msg := crypto.CRandBytes(128)
+	sig, err := privKey.Sign(msg)
+	require.NoError(t, err)
+	assert.Len(t, sig, bls12381.SignatureLength)
+
+	// Test the signature
+	assert.True(t, pubKey.VerifySignature(msg, sig))
+
+	// Mutate the signature, just one bit.
+	sig[7] ^= byte(0x01)
+	assert.False(t, pubKey.VerifySignature(msg, sig))
+}
+
+func TestGenPrivKeyFromSecret(t *testing.T) {
+	a := bls12381.GenPrivKeyFromSecret([]byte("secret"))
+	b := bls12381.GenPrivKeyFromSecret([]byte("secret"))
+	c := bls12381.GenPrivKeyFromSecret([]byte("other secret"))
+	assert.True(t, a.Equals(b))
+	assert.False(t, a.Equals(c))
+
+	_, err := bls12381.NewPrivateKeyFromBytes(a.Bytes())
+	require.NoError(t, err)
+}
+
+func TestInvalidKeys(t *testing.T) {
+	// Zero, and the group order.
+	for _, sk := range []string{
+		"0000000000000000000000000000000000000000000000000000000000000000",
+		"73eda753299d7d483339d80809a1d80553bda402fffe5bfeffffffff00000001",
+	} {
+		_, err := bls12381.NewPrivateKeyFromBytes(mustDecodeHex(t, sk))
+		assert.ErrorIs(t, err, bls12381.ErrInvalidPrivKey, sk)
+	}
+
+	pubKey := bls12381.GenPrivKey().PubKey().(bls12381.PubKey)
+	for name, pk := range map[string][]byte{
+		"short":        pubKey[1:],
+		"uncompressed": append([]byte{pubKey[0] &^ 0x80}, pubKey[1:]...),
+		"infinity":     append([]byte{0xc0}, make([]byte, bls12381.PubKeySize-1)...),
+		// x = 0 is not on the curve, as 4 is not a square.
+		"not on curve": append([]byte{0x80}, make([]byte, bls12381.PubKeySize-1)...),
+	} {
+		assert.Error(t, bls12381.PubKey(pk).Validate(), name)
+	}
+}
+
+func TestPubKeyJSON(t *testing.T) {
+	pubKey := bls12381.GenPrivKey().PubKey()
+
+	bz, err := cmtjson.Marshal(pubKey)
+	require.NoError(t, err)
+	assert.Contains(t, string(bz), bls12381.PubKeyName)
+
+	var decoded crypto.PubKey
+	require.NoError(t, cmtjson.Unmarshal(bz, &decoded))
+	assert.Equal(t, pubKey, decoded)
+}
@@ -0,0 +1,198 @@
+package bls12381
+
+import (
+	"errors"
+	"math/big"
+)
+
+// point is an affine point of G1 or G2. The coordinates of the points of G1 are in the base
+// field, that is fe2 with c1 = 0.
+type point struct {
+	x, y fe2
+	inf  bool
+}
+
+// curve is y^2 = x^3 + b, over the base field for G1 and over fe2 for G2.
+type curve struct {
+	b   fe2
+	gen point
+}
+
+var (
+	g1 = curve{
+		b: fe2FromInt(4, 0),
+		gen: point{
+			x: fe2FromHex("17f1d3a73197d7942695638c4fa9ac0fc3688c4f9774b905a14e3a3f171bac586c55e83ff97a1aeffb3af00adb22c6bb", "0"),
+			y: fe2FromHex("08b3f481e3aaa0f1a09e30ed741d8ae4fcf5e095d5d00af600db18cb2c04b3edd03cc744a2888ae40caa232946c5e7e1", "0"),
+		},
+	}
+	g2 = curve{
+		b: fe2FromInt(4, 4),
+		gen: point{
+			x: fe2FromHex(
+				"024aa2b2f08f0a91260805272dc51051c6e47ad4fa403b02b4510b647ae3d1770bac0326a805bbefd48056c8c121bdb8",
+				"13e02b6052719f607dacd3a088274f65596bd0d09920b61ab5da61bbdc7f5049334cf11213945d57e5ac7d055d042b7e",
+			),
+			y: fe2FromHex(
+				"0ce5d527727d6e118cc9cdc6da2e351aadfd9baa8cbdd3a76d429a695160d12c923ac9cc3baca289e193548608b82801",
+				"0606c4a02ea734cc32acd2b02bc28b99cb3e287e85a763af267492ab572e99ab3f370d275cec1da1aaa9075ff05f79be",
+			),
+		},
+	}
+
+	infinity = point{x: fe2Zero, y: fe2Zero, inf: true}
+)
+
+func (c curve) onCurve(p point) bool {
+	if p.inf {
+		return true
+	}
+	return p.y.square().equal(p.x.square().mul(p.x).add(c.b))
+}
+
+// inSubgroup reports whether p is in the subgroup of order r.
+func (c curve) inSubgroup(p point) bool {
+	return c.mul(p, groupR).inf
+}
+
+func (c curve) neg(p point) point {
+	if p.inf {
+		return p
+	}
+	return point{x: p.x, y: p.y.neg()}
+}
+
+func (c curve) double(p point) point {
+	if p.inf || p.y.isZero() {
+		return infinity
+	}
+	// lambda = 3x^2 / 2y
+	l := p.x.square().mulFp(big.NewInt(3)).mul(p.y.add(p.y).inv())
+	x := l.square().sub(p.x).sub(p.x)
+	return point{x: x, y: l.mul(p.x.sub(x)).sub(p.y)}
+}
+
+func (c curve) add(p, q point) point {
+	switch {
+	case p.inf:
+		return q
+	case q.inf:
+		return p
+	case p.x.equal(q.x):
+		if p.y.equal(q.y) {
+			return c.double(p)
+		}
+		return infinity
+	}
+	l := q.y.sub(p.y).mul(q.x.sub(p.x).inv())
+	x := l.square().sub(p.x).sub(q.x)
+	return point{x: x, y: l.mul(p.x.sub(x)).sub(p.y)}
+}
+
+// mul returns k*p, for a non-negative k.
+func (c curve) mul(p point, k *big.Int) point {
+	z := infinity
+	for i := k.BitLen() - 1; i >= 0; i-- {
+		z = c.double(z)
+		if k.Bit(i) == 1 {
+			z = c.add(z, p)
+		}
+	}
+	return z
+}
+
+// The points are encoded compressed, in the format of ZCash which blst also uses: the
+// big-endian x coordinate, with c1 before c0 for G2, and three flags in the most significant
+// bits of the first byte.
+const (
+	flagCompressed = 0x80
+	flagInfinity   = 0x40
+	flagSign       = 0x20
+	flagsMask      = flagCompressed | flagInfinity | flagSign
+
+	fpSize = 48
+)
+
+var (
+	errInvalidPoint = errors.New("invalid point encoding")
+	errNotOnCurve   = errors.New("point is not on the curve")
+	errNotInGroup   = errors.New("point is not in the subgroup")
+)
+
+// largest reports whether y is lexicographically larger than -y.
+func largest(y fe2) bool {
+	if y.c1.Sign() != 0 {
+		return y.c1.Cmp(halfP) > 0
+	}
+	return y.c0.Cmp(halfP) > 0
+}
+
+func (c curve) size() int {
+	if c.b.c1.Sign() == 0 {
+		return fpSize
+	}
+	return 2 * fpSize
+}
+
+func (c curve) encode(p point) []byte {
+	bz := make([]byte, c.size())
+	if p.inf {
+		bz[0] = flagCompressed | flagInfinity
+		return bz
+	}
+	if c.size() == fpSize {
+		p.x.c0.FillBytes(bz)
+	} else {
+		p.x.c1.FillBytes(bz[:fpSize])
+		p.x.c0.FillBytes(bz[fpSize:])
+	}
+	bz[0] |= flagCompressed
+	if largest(p.y) {
+		bz[0] |= flagSign
+	}
+	return bz
+}
+
+// decode decodes a compressed point, and checks that it is in the subgroup of order r.
+func (c curve) decode(bz []byte) (point, error) {
+	if len(bz) != c.size() || bz[0]&flagCompressed == 0 {
+		return point{}, errInvalidPoint
+	}
+	flags := bz[0] & flagsMask
+	bz = append([]byte{bz[0] &^ flagsMask}, bz[1:]...)
+
+	if flags&flagInfinity != 0 {
+		if flags&flagSign != 0 {
+			return point{}, errInvalidPoint
+		}
+		for _, b := range bz {
+			if b != 0 {
+				return point{}, errInvalidPoint
+			}
+		}
+		return infinity, nil
+	}
+
+	var x fe2
+	if c.size() == fpSize {
+		x = fe2{new(big.Int).SetBytes(bz), new(big.Int)}
+	} else {
+		x = fe2{new(big.Int).SetBytes(bz[fpSize:]), new(big.Int).SetBytes(bz[:fpSize])}
+	}
+	if x.c0.Cmp(fieldP) >= 0 || x.c1.Cmp(fieldP) >= 0 {
+		return point{}, errInvalidPoint
+	}
+
+	y, ok := x.square().mul(x).add(c.b).sqrt()
+	if !ok || c.size() == fpSize && y.c1.Sign() != 0 {
+		return point{}, errNotOnCurve
+	}
+	if largest(y) != (flags&flagSign != 0) {
+		y = y.neg()
+	}
+	p := point{x: x, y: y}
+	if !c.inSubgroup(p) {
+		return point{}, errNotInGroup
+	}
+	return p, nil
+}
@@ -0,0 +1,295 @@
+package bls12381
+
+import (
+	"math/big"
+)
+
+// The arithmetic uses math/big, which is simple to check but slow. It is meant for verifying
+// the occasional signature, not for the hot path of a validator.
+
+var (
+	// fieldP is the modulus of the base field.
+	fieldP = hexInt("1a0111ea397fe69a4b1ba7b6434bacd764774b84f38512bf6730d2a0f6b0f6241eabfffeb153ffffb9feffffffffaaab")
+	// groupR is the order of G1 and G2.
+	groupR = hexInt("73eda753299d7d483339d80809a1d80553bda402fffe5bfeffffffff00000001")
+	// halfP is (p-1)/2, above which an element of the base field is "lexicographically largest".
+	halfP = new(big.Int).Rsh(fieldP, 1)
+)
+
+func hexInt(s string) *big.Int {
+	z, ok := new(big.Int).SetString(s, 16)
+	if !ok {
+		panic("bls12381: invalid constant " + s)
+	}
+	return z
+}
+
+func fpMod(z *big.Int) *big.Int {
+	return z.Mod(z, fieldP)
+}
+
+// fe2 is an element c0 + c1*u of the quadratic extension of the base field, where u^2 = -1.
+// The elements of the base field are those with c1 = 0.
+type fe2 struct {
+	c0, c1 *big.Int
+}
+
+func newFe2(c0, c1 *big.Int) fe2 {
+	return fe2{fpMod(new(big.Int).Set(c0)), fpMod(new(big.Int).Set(c1))}
+}
+
+func fe2FromInt(c0, c1 int64) fe2 {
+	return newFe2(big.NewInt(c0), big.NewInt(c1))
+}
+
+func fe2FromHex(c0, c1 string) fe2 {
+	return newFe2(hexInt(c0), hexInt(c1))
+}
+
+var (
+	fe2Zero = fe2FromInt(0, 0)
+	fe2One  = fe2FromInt(1, 0)
+)
+
+func (a fe2) isZero() bool {
+	return a.c0.Sign() == 0 && a.c1.Sign() == 0
+}
+
+func (a fe2) equal(b fe2) bool {
+	return a.c0.Cmp(b.c0) == 0 && a.c1.Cmp(b.c1) == 0
+}
+
+func (a fe2) add(b fe2) fe2 {
+	return fe2{fpMod(new(big.Int).Add(a.c0, b.c0)), fpMod(new(big.Int).Add(a.c1, b.c1))}
+}
+
+func (a fe2) sub(b fe2) fe2 {
+	return fe2{fpMod(new(big.Int).Sub(a.c0, b.c0)), fpMod(new(big.Int).Sub(a.c1, b.c1))}
+}
+
+func (a fe2) neg() fe2 {
+	return fe2Zero.sub(a)
+}
+
+func (a fe2) mul(b fe2) fe2 {
+	t0 := new(big.Int).Mul(a.c0, b.c0)
+	t1 := new(big.Int).Mul(a.c1, b.c1)
+	s := new(big.Int).Mul(new(big.Int).Add(a.c0, a.c1), new(big.Int).Add(b.c0, b.c1))
+	s.Sub(s, t0).Sub(s, t1)
+	return fe2{fpMod(t0.Sub(t0, t1)), fpMod(s)}
+}
+
+func (a fe2) square() fe2 {
+	return a.mul(a)
+}
+
+// mulFp multiplies a by an element of the base field.
+func (a fe2) mulFp(b *big.Int) fe2 {
+	return fe2{fpMod(new(big.Int).Mul(a.c0, b)), fpMod(new(big.Int).Mul(a.c1, b))}
+}
+
+// mulXi multiplies a by xi = 1 + u, the non-residue of the sextic extension.
+func (a fe2) mulXi() fe2 {
+	return fe2{fpMod(new(big.Int).Sub(a.c0, a.c1)), fpMod(new(big.Int).Add(a.c0, a.c1))}
+}
+
+// inv returns the inverse of a, or zero if a is zero.
+func (a fe2) inv() fe2 {
+	n := new(big.Int).Mul(a.c0, a.c0)
+	n.Add(n, new(big.Int).Mul(a.c1, a.c1))
+	if n.ModInverse(fpMod(n), fieldP) == nil {
+		return fe2Zero
+	}
+	return fe2{fpMod(new(big.Int).Mul(a.c0, n)), fpMod(new(big.Int).Neg(new(big.Int).Mul(a.c1, n)))}
+}
+
+func (a fe2) exp(e *big.Int) fe2 {
+	z := fe2One
+	for i := e.BitLen() - 1; i >= 0; i-- {
+		z = z.square()
+		if e.Bit(i) == 1 {
+			z = z.mul(a)
+		}
+	}
+	return z
+}
+
+// sqrt returns a square root of a, and false if a is not a square.
+func (a fe2) sqrt() (fe2, bool) {
+	if a.c1.Sign() == 0 {
+		if r := new(big.Int).ModSqrt(a.c0, fieldP); r != nil {
+			return fe2{r, new(big.Int)}, true
+		}
+		// a = -(c*c)*u^2 for a square c.
+		r := new(big.Int).ModSqrt(fpMod(new(big.Int).Neg(a.c0)), fieldP)
+		if r == nil {
+			return fe2Zero, false
+		}
+		return fe2{new(big.Int), r}, true
+	}
+
+	// With x = x0 + x1*u, x^2 = a means x0^2 - x1^2 = a0 and 2*x0*x1 = a1, so that x0^2 is a
+	// root of t^2 - a0*t - a1^2/4, which are (a0 +- sqrt(a0^2 + a1^2)) / 2.
+	n := new(big.Int).Mul(a.c0, a.c0)
+	n.Add(n, new(big.Int).Mul(a.c1, a.c1))
+	s := new(big.Int).ModSqrt(fpMod(n), fieldP)
+	if s == nil {
+		return fe2Zero, false
+	}
+	half := new(big.Int).ModInverse(big.NewInt(2), fieldP)
+	t := fpMod(new(big.Int).Mul(new(big.Int).Add(a.c0, s), half))
+	x0 := new(big.Int).ModSqrt(t, fieldP)
+	if x0 == nil {
+		t = fpMod(new(big.Int).Mul(new(big.Int).Sub(a.c0, s), half))
+		if x0 = new(big.Int).ModSqrt(t, fieldP); x0 == nil {
+			return fe2Zero, false
+		}
+	}
+	x1 := new(big.Int).ModInverse(new(big.Int).Lsh(x0, 1), fieldP)
+	x := fe2{x0, fpMod(x1.Mul(x1, a.c1))}
+	if !x.square().equal(a) {
+		return fe2Zero, false
+	}
+	return x, true
+}
+
+// sgn0 is the sign of a, as defined by RFC 9380.
+func (a fe2) sgn0() uint {
+	sign0 := a.c0.Bit(0)
+	sign1 := a.c1.Bit(0)
+	if a.c0.Sign() == 0 {
+		return sign1
+	}
+	return sign0
+}
+
+// fe12 is an element of the extension of degree 12 of the base field, as a polynomial of
+// degree 5 in w over fe2, where w^6 = xi.
+type fe12 [6]fe2
+
+func fe12One() fe12 {
+	return fe12{fe2One, fe2Zero, fe2Zero, fe2Zero, fe2Zero, fe2Zero}
+}
+
+func (a fe12) isOne() bool {
+	if !a[0].equal(fe2One) {
+		return false
+	}
+	for _, c := range a[1:] {
+		if !c.isZero() {
+			return false
+		}
+	}
+	return true
+}
+
+func (a fe12) mul(b fe12) fe12 {
+	var t [11]fe2
+	for i := range t {
+		t[i] = fe2Zero
+	}
+	for i := 0; i < 6; i++ {
+		if a[i].isZero() {
+			continue
+		}
+		for j := 0; j < 6; j++ {
+			if b[j].isZero() {
+				continue
+			}
+			t[i+j] = t[i+j].add(a[i].mul(b[j]))
+		}
+	}
+	var z fe12
+	for i := 0; i < 6; i++ {
+		z[i] = t[i]
+		if i+6 < len(t) {
+			z[i] = z[i].add(t[i+6].mulXi())
+		}
+	}
+	return z
+}
+
+func (a fe12) square() fe12 {
+	return a.mul(a)
+}
+
+func (a fe12) exp(e *big.Int) fe12 {
+	z := fe12One()
+	for i := e.BitLen() - 1; i >= 0; i-- {
+		z = z.square()
+		if e.Bit(i) == 1 {
+			z = z.mul(a)
+		}
+	}
+	return z
+}
+
+// conj returns a^(p^6), which negates the odd powers of w as w^(p^6) = -w.
+func (a fe12) conj() fe12 {
+	z := a
+	for i := 1; i < 6; i += 2 {
+		z[i] = z[i].neg()
+	}
+	return z
+}
+
+// frob2Coeffs are the powers of xi^((p^2-1)/6), where w^(p^2) = xi^((p^2-1)/6) * w.
+var frob2Coeffs = func() [6]fe2 {
+	e := new(big.Int).Mul(fieldP, fieldP)
+	e.Sub(e, big.NewInt(1)).Div(e, big.NewInt(6))
+	gamma := fe2One.mulXi().exp(e)
+	var c [6]fe2
+	c[0] = fe2One
+	for i := 1; i < 6; i++ {
+		c[i] = c[i-1].mul(gamma)
+	}
+	return c
+}()
+
+// frob2 returns a^(p^2). The coefficients are left unchanged, as c^(p^2) = c in fe2.
+func (a fe12) frob2() fe12 {
+	var z fe12
+	for i := range a {
+		z[i] = a[i].mul(frob2Coeffs[i])
+	}
+	return z
+}
+
+// inv returns the inverse of a. With a = A + w*B, where A and B are polynomials in v = w^2,
+// 1/a = (A - w*B) / (A^2 - v*B^2).
+func (a fe12) inv() fe12 {
+	A := fe6{a[0], a[2], a[4]}
+	B := fe6{a[1], a[3], a[5]}
+	d := A.mul(A).sub(B.mul(B).mulV()).inv()
+	x := A.mul(d)
+	y := B.mul(d)
+	return fe12{x[0], y[0].neg(), x[1], y[1].neg(), x[2], y[2].neg()}
+}
+
+// fe6 is a polynomial of degree 2 in v over fe2, where v^3 = xi.
+type fe6 [3]fe2
+
+func (a fe6) sub(b fe6) fe6 {
+	return fe6{a[0].sub(b[0]), a[1].sub(b[1]), a[2].sub(b[2])}
+}
+
+func (a fe6) mul(b fe6) fe6 {
+	// (a0 + a1 v + a2 v^2)(b0 + b1 v + b2 v^2) with v^3 = xi.
+	c0 := a[0].mul(b[0]).add(a[1].mul(b[2]).add(a[2].mul(b[1])).mulXi())
+	c1 := a[0].mul(b[1]).add(a[1].mul(b[0])).add(a[2].mul(b[2]).mulXi())
+	c2 := a[0].mul(b[2]).add(a[1].mul(b[1])).add(a[2].mul(b[0]))
+	return fe6{c0, c1, c2}
+}
+
+// mulV multiplies a by v.
+func (a fe6) mulV() fe6 {
+	return fe6{a[2].mulXi(), a[0], a[1]}
+}
+
+func (a fe6) inv() fe6 {
+	t0 := a[0].square().sub(a[1].mul(a[2]).mulXi())
+	t1 := a[2].square().mulXi().sub(a[0].mul(a[1]))
+	t2 := a[1].square().sub(a[0].mul(a[2]))
+	n := a[0].mul(t0).add(a[2].mul(t1).mulXi()).add(a[1].mul(t2).mulXi()).inv()
+	return fe6{t0.mul(n), t1.mul(n), t2.mul(n)}
+}
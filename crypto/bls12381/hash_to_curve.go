@@ -0,0 +1,161 @@
+package bls12381
+
+import (
+	"crypto/sha256"
+	"math/big"
+)
+
+// hashToG2 implements the hash_to_curve suite BLS12381G2_XMD:SHA-256_SSWU_RO_ of RFC 9380.
+func hashToG2(msg, dst []byte) point {
+	u := hashToField(msg, dst)
+	q0 := isoMap(mapToCurve(u[0]))
+	q1 := isoMap(mapToCurve(u[1]))
+	return g2.mul(g2.add(q0, q1), hEff)
+}
+
+// hashToField hashes msg to two elements of fe2.
+func hashToField(msg, dst []byte) [2]fe2 {
+	const l = 64
+	bz := expandMessageXMD(msg, dst, 4*l)
+	var u [2]fe2
+	for i := range u {
+		e := bz[2*i*l:]
+		u[i] = newFe2(new(big.Int).SetBytes(e[:l]), new(big.Int).SetBytes(e[l:2*l]))
+	}
+	return u
+}
+
+func expandMessageXMD(msg, dst []byte, n int) []byte {
+	dstPrime := append(append([]byte{}, dst...), byte(len(dst)))
+
+	h := sha256.New()
+	h.Write(make([]byte, h.BlockSize()))
+	h.Write(msg)
+	h.Write([]byte{byte(n >> 8), byte(n), 0})
+	h.Write(dstPrime)
+	b0 := h.Sum(nil)
+
+	out := make([]byte, 0, n+sha256.Size)
+	bi := make([]byte, sha256.Size)
+	for i := 1; len(out) < n; i++ {
+		for j := range bi {
+			bi[j] ^= b0[j]
+		}
+		h.Reset()
+		h.Write(bi)
+		h.Write([]byte{byte(i)})
+		h.Write(dstPrime)
+		bi = h.Sum(nil)
+		out = append(out, bi...)
+	}
+	return out[:n]
+}
+
+// The simplified SWU map targets E2': y^2 = x^3 + A'x + B', which is 3-isogenous to G2.
+var (
+	sswuA = fe2FromInt(0, 240)
+	sswuB = fe2FromInt(1012, 1012)
+	sswuZ = fe2FromInt(-2, -1)
+)
+
+// mapToCurve maps u to E2' with the simplified SWU map.
+func mapToCurve(u fe2) point {
+	zu2 := sswuZ.mul(u.square())
+	tv1 := zu2.square().add(zu2).inv()
+	var x1 fe2
+	if tv1.isZero() {
+		x1 = sswuB.mul(sswuZ.mul(sswuA).inv())
+	} else {
+		x1 = sswuB.neg().mul(sswuA.inv()).mul(fe2One.add(tv1))
+	}
+	x := x1
+	y, ok := sswuG(x1).sqrt()
+	if !ok {
+		x = zu2.mul(x1)
+		y, _ = sswuG(x).sqrt()
+	}
+	if u.sgn0() != y.sgn0() {
+		y = y.neg()
+	}
+	return point{x: x, y: y}
+}
+
+func sswuG(x fe2) fe2 {
+	return x.square().mul(x).add(sswuA.mul(x)).add(sswuB)
+}
+
+// The coefficients of the 3-isogeny from E2' to G2, from appendix E.3 of RFC 9380.
+var (
+	isoXNum = []fe2{
+		fe2FromHex(
+			"5c759507e8e333ebb5b7a9a47d7ed8532c52d39fd3a042a88b58423c50ae15d5c2638e343d9c71c6238aaaaaaaa97d6",
+			"5c759507e8e333ebb5b7a9a47d7ed8532c52d39fd3a042a88b58423c50ae15d5c2638e343d9c71c6238aaaaaaaa97d6",
+		),
+		fe2FromHex(
+			"0",
+			"11560bf17baa99bc32126fced787c88f984f87adf7ae0c7f9a208c6b4f20a4181472aaa9cb8d555526a9ffffffffc71a",
+		),
+		fe2FromHex(
+			"11560bf17baa99bc32126fced787c88f984f87adf7ae0c7f9a208c6b4f20a4181472aaa9cb8d555526a9ffffffffc71e",
+			"8ab05f8bdd54cde190937e76bc3e447cc27c3d6fbd7063fcd104635a790520c0a395554e5c6aaaa9354ffffffffe38d",
+		),
+		fe2FromHex(
+			"171d6541fa38ccfaed6dea691f5fb614cb14b4e7f4e810aa22d6108f142b85757098e38d0f671c7188e2aaaaaaaa5ed1",
+			"0",
+		),
+	}
+	isoXDen = []fe2{
+		fe2FromInt(0, -72),
+		fe2FromInt(12, -12),
+		fe2One,
+	}
+	isoYNum = []fe2{
+		fe2FromHex(
+			"1530477c7ab4113b59a4c18b076d11930f7da5d4a07f649bf54439d87d27e500fc8c25ebf8c92f6812cfc71c71c6d706",
+			"1530477c7ab4113b59a4c18b076d11930f7da5d4a07f649bf54439d87d27e500fc8c25ebf8c92f6812cfc71c71c6d706",
+		),
+		fe2FromHex(
+			"0",
+			"5c759507e8e333ebb5b7a9a47d7ed8532c52d39fd3a042a88b58423c50ae15d5c2638e343d9c71c6238aaaaaaaa97be",
+		),
+		fe2FromHex(
+			"11560bf17baa99bc32126fced787c88f984f87adf7ae0c7f9a208c6b4f20a4181472aaa9cb8d555526a9ffffffffc71c",
+			"8ab05f8bdd54cde190937e76bc3e447cc27c3d6fbd7063fcd104635a790520c0a395554e5c6aaaa9354ffffffffe38f",
+		),
+		fe2FromHex(
+			"124c9ad43b6cf79bfbf7043de3811ad0761b0f37a1e26286b0e977c69aa274524e79097a56dc4bd9e1b371c71c718b10",
+			"0",
+		),
+	}
+	isoYDen = []fe2{
+		fe2FromInt(-432, -432),
+		fe2FromInt(0, -216),
+		fe2FromInt(18, -18),
+		fe2One,
+	}
+)
+
+// isoMap maps a point of E2' to G2.
+func isoMap(p point) point {
+	xDen := evalPoly(isoXDen, p.x)
+	yDen := evalPoly(isoYDen, p.x)
+	if xDen.isZero() || yDen.isZero() {
+		return infinity
+	}
+	return point{
+		x: evalPoly(isoXNum, p.x).mul(xDen.inv()),
+		y: p.y.mul(evalPoly(isoYNum, p.x)).mul(yDen.inv()),
+	}
+}
+
+// evalPoly evaluates the polynomial with the coefficients k, of increasing degree, at x.
+func evalPoly(k []fe2, x fe2) fe2 {
+	z := k[len(k)-1]
+	for i := len(k) - 2; i >= 0; i-- {
+		z = z.mul(x).add(k[i])
+	}
+	return z
+}
+
+// hEff is the scalar which clears the cofactor of G2, as in RFC 9380.
+var hEff = hexInt("bc69f08f2ee75b3584c6a0ea91b352888e2a8e9145ad7689986ff031508ffe1329c2f178731db956d82bf015d1212b02ec0ec69d7477c1ae954cbc06689f6a359894c0adebbf6b4e8020005aaa95551")
@@ -0,0 +1,21 @@
+package bls12381
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// The vector of appendix J.10.1 of RFC 9380, for the empty message.
+func TestHashToG2Vector(t *testing.T) {
+	p := hashToG2(nil, []byte("QUUX-V01-CS02-with-BLS12381G2_XMD:SHA-256_SSWU_RO_"))
+	assert.Equal(t,
+		"0141ebfbdca40eb85b87142e130ab689c673cf60f1a3e98d69335266f30d9b8d4ac44c1038e9dcdd5393faf5c41fb78a",
+		hex.EncodeToString(p.x.c0.FillBytes(make([]byte, fpSize))))
+	assert.Equal(t,
+		"05cb8437535e20ecffaef7752baddf98034139c38452458baeefab379ba13dff5bf5dd71b72418717047f5b0f37da03d",
+		hex.EncodeToString(p.x.c1.FillBytes(make([]byte, fpSize))))
+	assert.True(t, g2.onCurve(p))
+	assert.True(t, g2.inSubgroup(p))
+}
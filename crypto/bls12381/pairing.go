@@ -0,0 +1,76 @@
+package bls12381
+
+import (
+	"math/big"
+)
+
+// curveX is the absolute value of the parameter x of the curve, which is negative.
+var curveX = hexInt("d201000000010000")
+
+// finalExpHard is (p^4 - p^2 + 1) / r, the hard part of the final exponentiation.
+var finalExpHard = func() *big.Int {
+	p2 := new(big.Int).Mul(fieldP, fieldP)
+	e := new(big.Int).Mul(p2, p2)
+	e.Sub(e, p2).Add(e, big.NewInt(1))
+	q, m := new(big.Int).DivMod(e, groupR, new(big.Int))
+	if m.Sign() != 0 {
+		panic("bls12381: r does not divide p^4 - p^2 + 1")
+	}
+	return q
+}()
+
+// line evaluates at p the line of slope l through t, a point of G2, after untwisting t.
+//
+// The twist maps (x', y') of G2 to (x'/w^2, y'/w^3), so that the line is
+// yp - y'/w^3 - (l/w)(xp - x'/w^2). Scaled by w^3, which the final exponentiation cancels,
+// it is (l*x' - y') - l*xp*w^2 + yp*w^3.
+func line(l fe2, t, p point) fe12 {
+	return fe12{
+		l.mul(t.x).sub(t.y),
+		fe2Zero,
+		l.mulFp(p.x.c0).neg(),
+		p.y,
+		fe2Zero,
+		fe2Zero,
+	}
+}
+
+// millerLoop computes the Miller loop of the optimal ate pairing of p in G1 and q in G2,
+// neither of which is the point at infinity.
+func millerLoop(p, q point) fe12 {
+	f := fe12One()
+	t := q
+	for i := curveX.BitLen() - 2; i >= 0; i-- {
+		l := t.x.square().mulFp(big.NewInt(3)).mul(t.y.add(t.y).inv())
+		f = f.square().mul(line(l, t, p))
+		t = g2.double(t)
+		if curveX.Bit(i) == 1 {
+			l = q.y.sub(t.y).mul(q.x.sub(t.x).inv())
+			f = f.mul(line(l, t, p))
+			t = g2.add(t, q)
+		}
+	}
+	// x is negative.
+	return f.conj()
+}
+
+// finalExp raises f to (p^12 - 1) / r.
+func finalExp(f fe12) fe12 {
+	// f^(p^6 - 1)
+	f = f.conj().mul(f.inv())
+	// f^(p^2 + 1)
+	f = f.frob2().mul(f)
+	return f.exp(finalExpHard)
+}
+
+// pairingCheck reports whether the product of the pairings of the pairs ps[i], qs[i] is one.
+func pairingCheck(ps, qs []point) bool {
+	f := fe12One()
+	for i := range ps {
+		if ps[i].inf || qs[i].inf {
+			continue
+		}
+		f = f.mul(millerLoop(ps[i], qs[i]))
+	}
+	return finalExp(f).isOne()
+}
@@ -1,12 +1,90 @@
+// Package encoding converts public keys to and from their protobuf encoding, which is the one
+// validators are hashed with and ABCI validator updates carry.
+//
+// Ed25519, secp256k1 and bls12-381 keys are supported. Sr25519 keys have no protobuf encoding in
+// any release of CometBFT, so, as there, they are only encoded in Amino JSON and the conversions
+// fail with ErrUnsupportedKeyType.
 package encoding
 
 import (
+	"errors"
+	"fmt"
+
+	"github.com/strangelove-ventures/cometbft-client/crypto"
+	"github.com/strangelove-ventures/cometbft-client/crypto/bls12381"
+	"github.com/strangelove-ventures/cometbft-client/crypto/ed25519"
+	"github.com/strangelove-ventures/cometbft-client/crypto/secp256k1"
 	"github.com/strangelove-ventures/cometbft-client/libs/json"
 	pc "github.com/strangelove-ventures/cometbft-client/proto/tendermint/crypto"
 )
 
+// ErrUnsupportedKeyType is the error of the conversion of a key which has no protobuf encoding,
+// such as an sr25519 key.
+var ErrUnsupportedKeyType = errors.New("key type is not supported")
+
 func init() {
 	json.RegisterType((*pc.PublicKey)(nil), "tendermint.crypto.PublicKey")
 	json.RegisterType((*pc.PublicKey_Ed25519)(nil), "tendermint.crypto.PublicKey_Ed25519")
 	json.RegisterType((*pc.PublicKey_Secp256K1)(nil), "tendermint.crypto.PublicKey_Secp256K1")
+	json.RegisterType((*pc.PublicKey_Bls12381)(nil), "tendermint.crypto.PublicKey_Bls12381")
+}
+
+// PubKeyToProto takes crypto.PubKey and transforms it to a protobuf Pubkey
+func PubKeyToProto(k crypto.PubKey) (pc.PublicKey, error) {
+	var kp pc.PublicKey
+	switch k := k.(type) {
+	case ed25519.PubKey:
+		kp = pc.PublicKey{
+			Sum: &pc.PublicKey_Ed25519{
+				Ed25519: k,
+			},
+		}
+	case secp256k1.PubKey:
+		kp = pc.PublicKey{
+			Sum: &pc.PublicKey_Secp256K1{
+				Secp256K1: k,
+			},
+		}
+	case bls12381.PubKey:
+		kp = pc.PublicKey{
+			Sum: &pc.PublicKey_Bls12381{
+				Bls12381: k,
+			},
+		}
+	default:
+		return kp, fmt.Errorf("toproto: %w: %T", ErrUnsupportedKeyType, k)
+	}
+	return kp, nil
+}
+
+// PubKeyFromProto takes a protobuf Pubkey and transforms it to a crypto.Pubkey
+func PubKeyFromProto(k pc.PublicKey) (crypto.PubKey, error) {
+	switch k := k.Sum.(type) {
+	case *pc.PublicKey_Ed25519:
+		if len(k.Ed25519) != ed25519.PubKeySize {
+			return nil, fmt.Errorf("invalid size for PubKeyEd25519. Got %d, expected %d",
+				len(k.Ed25519), ed25519.PubKeySize)
+		}
+		pk := make(ed25519.PubKey, ed25519.PubKeySize)
+		copy(pk, k.Ed25519)
+		return pk, nil
+	case *pc.PublicKey_Secp256K1:
+		if len(k.Secp256K1) != secp256k1.PubKeySize {
+			return nil, fmt.Errorf("invalid size for PubKeySecp256k1. Got %d, expected %d",
+				len(k.Secp256K1), secp256k1.PubKeySize)
+		}
+		pk := make(secp256k1.PubKey, secp256k1.PubKeySize)
+		copy(pk, k.Secp256K1)
+		return pk, nil
+	case *pc.PublicKey_Bls12381:
+		if len(k.Bls12381) != bls12381.PubKeySize {
+			return nil, fmt.Errorf("invalid size for PubKeyBls12_381. Got %d, expected %d",
+				len(k.Bls12381), bls12381.PubKeySize)
+		}
+		pk := make(bls12381.PubKey, bls12381.PubKeySize)
+		copy(pk, k.Bls12381)
+		return pk, nil
+	default:
+		return nil, fmt.Errorf("fromproto: %w: %T", ErrUnsupportedKeyType, k)
+	}
 }
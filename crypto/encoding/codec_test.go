@@ -0,0 +1,84 @@
+package encoding
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/strangelove-ventures/cometbft-client/crypto"
+	"github.com/strangelove-ventures/cometbft-client/crypto/bls12381"
+	"github.com/strangelove-ventures/cometbft-client/crypto/ed25519"
+	"github.com/strangelove-ventures/cometbft-client/crypto/secp256k1"
+	"github.com/strangelove-ventures/cometbft-client/crypto/sr25519"
+	"github.com/strangelove-ventures/cometbft-client/libs/json"
+	pc "github.com/strangelove-ventures/cometbft-client/proto/tendermint/crypto"
+)
+
+func TestPubKeyProto(t *testing.T) {
+	for _, pk := range []crypto.PubKey{
+		ed25519.GenPrivKey().PubKey(),
+		secp256k1.GenPrivKey().PubKey(),
+		bls12381.GenPrivKey().PubKey(),
+	} {
+		t.Run(pk.Type(), func(t *testing.T) {
+			proto, err := PubKeyToProto(pk)
+			require.NoError(t, err)
+
+			bz, err := proto.Marshal()
+			require.NoError(t, err)
+			var decoded pc.PublicKey
+			require.NoError(t, decoded.Unmarshal(bz))
+			assert.Equal(t, proto, decoded)
+
+			got, err := PubKeyFromProto(decoded)
+			require.NoError(t, err)
+			assert.Equal(t, pk, got)
+			assert.Equal(t, pk.Address(), got.Address())
+
+			// The oneof is registered, as in the validator updates of the block results.
+			js, err := json.Marshal(proto)
+			require.NoError(t, err)
+			var fromJSON pc.PublicKey
+			require.NoError(t, json.Unmarshal(js, &fromJSON))
+			assert.Equal(t, proto, fromJSON)
+		})
+	}
+}
+
+func TestPubKeyFromProtoInvalidSize(t *testing.T) {
+	for _, proto := range []pc.PublicKey{
+		{Sum: &pc.PublicKey_Ed25519{Ed25519: make([]byte, 31)}},
+		{Sum: &pc.PublicKey_Secp256K1{Secp256K1: make([]byte, 32)}},
+		{Sum: &pc.PublicKey_Bls12381{Bls12381: make([]byte, 96)}},
+		{},
+	} {
+		_, err := PubKeyFromProto(proto)
+		assert.Error(t, err)
+	}
+}
+
+// As in CometBFT, sr25519 keys have no protobuf encoding, only the Amino JSON one.
+func TestSr25519PubKeyToProto(t *testing.T) {
+	pk := sr25519.GenPrivKey().PubKey()
+	_, err := PubKeyToProto(pk)
+	assert.ErrorIs(t, err, ErrUnsupportedKeyType)
+	_, err = PubKeyFromProto(pc.PublicKey{})
+	assert.ErrorIs(t, err, ErrUnsupportedKeyType)
+
+	js, err := json.Marshal(pk)
+	require.NoError(t, err)
+	var decoded crypto.PubKey
+	require.NoError(t, json.Unmarshal(js, &decoded))
+	assert.Equal(t, pk, decoded)
+}
+
+// A BLS12-381 key is field 3, as in CometBFT v1.
+func TestBls12381ProtoField(t *testing.T) {
+	pk := bls12381.GenPrivKey().PubKey()
+	proto, err := PubKeyToProto(pk)
+	require.NoError(t, err)
+	bz, err := proto.Marshal()
+	require.NoError(t, err)
+	assert.Equal(t, append([]byte{0x1a, bls12381.PubKeySize}, pk.Bytes()...), bz)
+}
@@ -0,0 +1,242 @@
+package keystore
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/cosmos/cosmos-sdk/crypto/keys/bcrypt"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20poly1305"
+
+	"github.com/strangelove-ventures/cometbft-client/crypto"
+	"github.com/strangelove-ventures/cometbft-client/crypto/armor"
+	"github.com/strangelove-ventures/cometbft-client/crypto/bls12381"
+	"github.com/strangelove-ventures/cometbft-client/crypto/ed25519"
+	"github.com/strangelove-ventures/cometbft-client/crypto/secp256k1"
+	"github.com/strangelove-ventures/cometbft-client/crypto/sr25519"
+	"github.com/strangelove-ventures/cometbft-client/crypto/xsalsa20symmetric"
+)
+
+const (
+	blockTypePrivKey = "TENDERMINT PRIVATE KEY"
+
+	// defaultAlgo is the type of the keys armored without a type header.
+	defaultAlgo = "secp256k1"
+
+	headerKDF  = "kdf"
+	headerSalt = "salt"
+	headerType = "type"
+
+	// KDFBcrypt derives the key with bcrypt, and encrypts with xsalsa20symmetric.
+	KDFBcrypt = "bcrypt"
+	// KDFArgon2 derives the key with argon2id, and encrypts with chacha20poly1305, as the
+	// Cosmos SDK does since v0.50.
+	KDFArgon2 = "argon2"
+
+	saltSize = 16
+
+	argon2Time    = 1
+	argon2Memory  = 64 * 1024
+	argon2Threads = 4
+)
+
+// BcryptSecurityParameter is the cost of bcrypt. The Cosmos SDK decrypts the keys with this
+// cost, whatever they were encrypted with, so that it must not be changed but in tests.
+var BcryptSecurityParameter uint32 = 12
+
+// ErrWrongPassphrase is returned when a key cannot be decrypted with the passphrase.
+var ErrWrongPassphrase = errors.New("keystore: wrong passphrase")
+
+// EncryptArmorPrivKey encrypts privKey with a key derived from passphrase by kdf, and armors
+// it as the Cosmos SDK does, with the type of the key in the type header.
+func EncryptArmorPrivKey(privKey crypto.PrivKey, passphrase, kdf string) (string, error) {
+	bz, err := marshalPrivKey(privKey)
+	if err != nil {
+		return "", err
+	}
+
+	salt := crypto.CRandBytes(saltSize)
+	var encrypted []byte
+	switch kdf {
+	case KDFBcrypt:
+		key, err := bcryptKey(salt, passphrase)
+		if err != nil {
+			return "", err
+		}
+		encrypted = xsalsa20symmetric.EncryptSymmetric(bz, key)
+	case KDFArgon2:
+		aead, err := chacha20poly1305.New(argon2Key(salt, passphrase))
+		if err != nil {
+			return "", err
+		}
+		// The nonce is zero, as the key is derived from a new salt every time.
+		encrypted = aead.Seal(nil, make([]byte, aead.NonceSize()), bz, nil)
+	default:
+		return "", fmt.Errorf("keystore: unknown key derivation function %q", kdf)
+	}
+
+	header := map[string]string{
+		headerKDF:  kdf,
+		headerSalt: fmt.Sprintf("%X", salt),
+		headerType: privKey.Type(),
+	}
+	return armor.EncodeArmor(blockTypePrivKey, header, encrypted), nil
+}
+
+// UnarmorDecryptPrivKey decrypts a private key armored by EncryptArmorPrivKey, or by the
+// Cosmos SDK, and returns it with its type.
+func UnarmorDecryptPrivKey(armorStr, passphrase string) (crypto.PrivKey, string, error) {
+	blockType, header, encrypted, err := armor.DecodeArmor(armorStr)
+	if err != nil {
+		return nil, "", err
+	}
+	if blockType != blockTypePrivKey {
+		return nil, "", fmt.Errorf("keystore: unrecognized armor type %q", blockType)
+	}
+	if header[headerSalt] == "" {
+		return nil, "", errors.New("keystore: missing salt bytes")
+	}
+	salt, err := hex.DecodeString(header[headerSalt])
+	if err != nil {
+		return nil, "", fmt.Errorf("keystore: error decoding salt: %w", err)
+	}
+
+	var bz []byte
+	switch header[headerKDF] {
+	case KDFBcrypt:
+		key, err := bcryptKey(salt, passphrase)
+		if err != nil {
+			return nil, "", err
+		}
+		if bz, err = xsalsa20symmetric.DecryptSymmetric(encrypted, key); err != nil {
+			return nil, "", ErrWrongPassphrase
+		}
+	case KDFArgon2:
+		aead, err := chacha20poly1305.New(argon2Key(salt, passphrase))
+		if err != nil {
+			return nil, "", err
+		}
+		if bz, err = aead.Open(nil, make([]byte, aead.NonceSize()), encrypted, nil); err != nil {
+			return nil, "", ErrWrongPassphrase
+		}
+	default:
+		return nil, "", fmt.Errorf("keystore: unrecognized key derivation function %q", header[headerKDF])
+	}
+
+	privKey, err := unmarshalPrivKey(bz)
+	if err != nil {
+		return nil, "", err
+	}
+	algo := header[headerType]
+	if algo == "" {
+		algo = defaultAlgo
+	}
+	return privKey, algo, nil
+}
+
+func bcryptKey(salt []byte, passphrase string) ([]byte, error) {
+	key, err := bcrypt.GenerateFromPassword(salt, []byte(passphrase), BcryptSecurityParameter)
+	if err != nil {
+		return nil, fmt.Errorf("keystore: error generating bcrypt key from passphrase: %w", err)
+	}
+	return crypto.Sha256(key), nil // get 32 bytes
+}
+
+func argon2Key(salt []byte, passphrase string) []byte {
+	return argon2.IDKey([]byte(passphrase), salt, argon2Time, argon2Memory, argon2Threads, chacha20poly1305.KeySize)
+}
+
+// The private keys are encrypted in their Amino binary encoding, as the Cosmos SDK does: the
+// prefix of the name of their type, followed by the length-prefixed bytes of the key.
+
+var privKeyNames = []string{
+	ed25519.PrivKeyName,
+	secp256k1.PrivKeyName,
+	sr25519.PrivKeyName,
+	bls12381.PrivKeyName,
+}
+
+// aminoPrefix returns the prefix bytes of the Amino encoding of the registered type name.
+func aminoPrefix(name string) []byte {
+	bz := sha256.Sum256([]byte(name))
+	h := bz[:]
+	for h[0] == 0 {
+		h = h[1:]
+	}
+	// Skip the disambiguation bytes.
+	h = h[3:]
+	for h[0] == 0 {
+		h = h[1:]
+	}
+	return h[:4]
+}
+
+func marshalPrivKey(privKey crypto.PrivKey) ([]byte, error) {
+	var name string
+	switch privKey.(type) {
+	case ed25519.PrivKey:
+		name = ed25519.PrivKeyName
+	case secp256k1.PrivKey:
+		name = secp256k1.PrivKeyName
+	case sr25519.PrivKey:
+		name = sr25519.PrivKeyName
+	case bls12381.PrivKey:
+		name = bls12381.PrivKeyName
+	default:
+		return nil, fmt.Errorf("keystore: key type %T is not supported", privKey)
+	}
+	key := privKey.Bytes()
+	bz := aminoPrefix(name)
+	bz = binary.AppendUvarint(bz, uint64(len(key)))
+	return append(bz, key...), nil
+}
+
+func unmarshalPrivKey(bz []byte) (crypto.PrivKey, error) {
+	if len(bz) < 4 {
+		return nil, errors.New("keystore: private key is too short")
+	}
+	name := ""
+	for _, n := range privKeyNames {
+		if string(aminoPrefix(n)) == string(bz[:4]) {
+			name = n
+		}
+	}
+	if name == "" {
+		return nil, fmt.Errorf("keystore: unknown private key prefix %X", bz[:4])
+	}
+	l, n := binary.Uvarint(bz[4:])
+	if n <= 0 || uint64(len(bz)-4-n) != l {
+		return nil, errors.New("keystore: invalid private key length")
+	}
+	key := append([]byte(nil), bz[4+n:]...)
+
+	switch name {
+	case ed25519.PrivKeyName:
+		if len(key) != ed25519.PrivateKeySize {
+			return nil, fmt.Errorf("keystore: invalid size for PrivKeyEd25519: %d", len(key))
+		}
+		return ed25519.PrivKey(key), nil
+	case secp256k1.PrivKeyName:
+		if len(key) != secp256k1.PrivKeySize {
+			return nil, fmt.Errorf("keystore: invalid size for PrivKeySecp256k1: %d", len(key))
+		}
+		return secp256k1.PrivKey(key), nil
+	case sr25519.PrivKeyName:
+		// The key is only decoded from JSON.
+		js, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		var privKey sr25519.PrivKey
+		if err := privKey.UnmarshalJSON(js); err != nil {
+			return nil, fmt.Errorf("keystore: invalid PrivKeySr25519: %w", err)
+		}
+		return privKey, nil
+	default:
+		return bls12381.NewPrivateKeyFromBytes(key)
+	}
+}
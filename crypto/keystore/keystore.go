@@ -0,0 +1,259 @@
+// Package keystore stores private keys in a directory, each encrypted with a passphrase in an
+// ASCII-armored file, in the format of the Cosmos SDK's exported private keys.
+package keystore
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/strangelove-ventures/cometbft-client/crypto"
+	"github.com/strangelove-ventures/cometbft-client/crypto/armor"
+	"github.com/strangelove-ventures/cometbft-client/crypto/bls12381"
+	"github.com/strangelove-ventures/cometbft-client/crypto/ed25519"
+	"github.com/strangelove-ventures/cometbft-client/crypto/secp256k1"
+	"github.com/strangelove-ventures/cometbft-client/crypto/sr25519"
+	cmtos "github.com/strangelove-ventures/cometbft-client/libs/os"
+)
+
+// fileExt is the extension of the files of the keys.
+const fileExt = ".armor"
+
+var (
+	// ErrKeyNotFound is returned for a key which is not in the store.
+	ErrKeyNotFound = errors.New("keystore: key not found")
+	// ErrKeyExists is returned when saving a key under the name of another.
+	ErrKeyExists = errors.New("keystore: key already exists")
+
+	reName = regexp.MustCompile(`^[A-Za-z0-9_-][A-Za-z0-9_.-]*$`)
+)
+
+// Options configures the key store.
+type Options struct {
+	// KDF is the key derivation function of the keys written by the store, KDFBcrypt or
+	// KDFArgon2. The store reads the keys of both.
+	KDF string
+}
+
+// DefaultOptions are the default options of the key store.
+var DefaultOptions = Options{
+	KDF: KDFBcrypt,
+}
+
+// KeyInfo describes a key of the store, which is listed without its passphrase.
+type KeyInfo struct {
+	Name string
+	// Type is the type of the key, such as ed25519.KeyType.
+	Type string
+}
+
+// Keystore is a directory of encrypted private keys. It is safe for concurrent use, but not
+// by several processes.
+type Keystore struct {
+	dir  string
+	opts Options
+
+	mtx sync.Mutex
+}
+
+// New returns the key store of dir, which is created if it does not exist.
+func New(dir string, opts Options) (*Keystore, error) {
+	if opts.KDF != KDFBcrypt && opts.KDF != KDFArgon2 {
+		return nil, fmt.Errorf("keystore: unknown key derivation function %q", opts.KDF)
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &Keystore{dir: dir, opts: opts}, nil
+}
+
+// List returns the keys of the store, sorted by name.
+func (ks *Keystore) List() ([]KeyInfo, error) {
+	ks.mtx.Lock()
+	defer ks.mtx.Unlock()
+
+	entries, err := os.ReadDir(ks.dir)
+	if err != nil {
+		return nil, err
+	}
+	var infos []KeyInfo
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), fileExt)
+		if !ok || e.IsDir() || !reName.MatchString(name) {
+			continue
+		}
+		armorStr, err := ks.read(name)
+		if err != nil {
+			return nil, err
+		}
+		_, header, _, err := armor.DecodeArmor(armorStr)
+		if err != nil {
+			return nil, fmt.Errorf("keystore: key %s: %w", name, err)
+		}
+		algo := header[headerType]
+		if algo == "" {
+			algo = defaultAlgo
+		}
+		infos = append(infos, KeyInfo{Name: name, Type: algo})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos, nil
+}
+
+// Save encrypts privKey with passphrase and stores it under name. It returns ErrKeyExists if
+// the store has a key of this name.
+func (ks *Keystore) Save(name string, privKey crypto.PrivKey, passphrase string) error {
+	ks.mtx.Lock()
+	defer ks.mtx.Unlock()
+
+	if err := ks.checkNew(name); err != nil {
+		return err
+	}
+	return ks.encryptAndWrite(name, privKey, passphrase)
+}
+
+// Load decrypts the key stored under name with passphrase.
+func (ks *Keystore) Load(name, passphrase string) (crypto.PrivKey, error) {
+	ks.mtx.Lock()
+	defer ks.mtx.Unlock()
+
+	return ks.load(name, passphrase)
+}
+
+// Delete removes the key stored under name.
+func (ks *Keystore) Delete(name string) error {
+	ks.mtx.Lock()
+	defer ks.mtx.Unlock()
+
+	if _, err := ks.read(name); err != nil {
+		return err
+	}
+	return os.Remove(ks.path(name))
+}
+
+// Import stores under name a key armored by EncryptArmorPrivKey or exported by the Cosmos SDK.
+// It is kept as it is, encrypted with passphrase, which is checked first.
+func (ks *Keystore) Import(name, armorStr, passphrase string) error {
+	ks.mtx.Lock()
+	defer ks.mtx.Unlock()
+
+	if err := ks.checkNew(name); err != nil {
+		return err
+	}
+	if _, _, err := UnarmorDecryptPrivKey(armorStr, passphrase); err != nil {
+		return err
+	}
+	return cmtos.WriteFileAtomic(ks.path(name), []byte(armorStr), 0o600)
+}
+
+// Export returns the key stored under name, armored and encrypted with exportPassphrase, as
+// the Cosmos SDK imports it.
+func (ks *Keystore) Export(name, passphrase, exportPassphrase string) (string, error) {
+	ks.mtx.Lock()
+	defer ks.mtx.Unlock()
+
+	privKey, err := ks.load(name, passphrase)
+	if err != nil {
+		return "", err
+	}
+	return EncryptArmorPrivKey(privKey, exportPassphrase, ks.opts.KDF)
+}
+
+// ChangePassphrase encrypts the key stored under name with newPassphrase instead.
+func (ks *Keystore) ChangePassphrase(name, passphrase, newPassphrase string) error {
+	ks.mtx.Lock()
+	defer ks.mtx.Unlock()
+
+	privKey, err := ks.load(name, passphrase)
+	if err != nil {
+		return err
+	}
+	return ks.encryptAndWrite(name, privKey, newPassphrase)
+}
+
+// Rotate replaces the key stored under name with a new key of the same type, encrypted with
+// the same passphrase, and returns it. The previous key is kept, under the name followed by
+// a dot and its address.
+func (ks *Keystore) Rotate(name, passphrase string) (crypto.PrivKey, error) {
+	ks.mtx.Lock()
+	defer ks.mtx.Unlock()
+
+	old, err := ks.load(name, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	var privKey crypto.PrivKey
+	switch old.(type) {
+	case ed25519.PrivKey:
+		privKey = ed25519.GenPrivKey()
+	case secp256k1.PrivKey:
+		privKey = secp256k1.GenPrivKey()
+	case sr25519.PrivKey:
+		privKey = sr25519.GenPrivKey()
+	case bls12381.PrivKey:
+		privKey = bls12381.GenPrivKey()
+	default:
+		return nil, fmt.Errorf("keystore: key type %T is not supported", old)
+	}
+
+	backup := fmt.Sprintf("%s.%s", name, old.PubKey().Address())
+	if err := ks.checkNew(backup); err != nil {
+		return nil, err
+	}
+	if err := cmtos.CopyFile(ks.path(name), ks.path(backup)); err != nil {
+		return nil, err
+	}
+	if err := ks.encryptAndWrite(name, privKey, passphrase); err != nil {
+		return nil, err
+	}
+	return privKey, nil
+}
+
+func (ks *Keystore) path(name string) string {
+	return filepath.Join(ks.dir, name+fileExt)
+}
+
+func (ks *Keystore) checkNew(name string) error {
+	if !reName.MatchString(name) {
+		return fmt.Errorf("keystore: invalid key name %q", name)
+	}
+	if _, err := os.Stat(ks.path(name)); err == nil {
+		return ErrKeyExists
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (ks *Keystore) read(name string) (string, error) {
+	if !reName.MatchString(name) {
+		return "", fmt.Errorf("keystore: invalid key name %q", name)
+	}
+	bz, err := os.ReadFile(ks.path(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", ErrKeyNotFound
+	}
+	return string(bz), err
+}
+
+func (ks *Keystore) load(name, passphrase string) (crypto.PrivKey, error) {
+	armorStr, err := ks.read(name)
+	if err != nil {
+		return nil, err
+	}
+	privKey, _, err := UnarmorDecryptPrivKey(armorStr, passphrase)
+	return privKey, err
+}
+
+func (ks *Keystore) encryptAndWrite(name string, privKey crypto.PrivKey, passphrase string) error {
+	armorStr, err := EncryptArmorPrivKey(privKey, passphrase, ks.opts.KDF)
+	if err != nil {
+		return err
+	}
+	return cmtos.WriteFileAtomic(ks.path(name), []byte(armorStr), 0o600)
+}
@@ -0,0 +1,150 @@
+package keystore
+
+import (
+	"testing"
+
+	sdkcrypto "github.com/cosmos/cosmos-sdk/crypto"
+	sdked25519 "github.com/cosmos/cosmos-sdk/crypto/keys/ed25519"
+	sdksecp256k1 "github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/strangelove-ventures/cometbft-client/crypto"
+	"github.com/strangelove-ventures/cometbft-client/crypto/bls12381"
+	"github.com/strangelove-ventures/cometbft-client/crypto/ed25519"
+	"github.com/strangelove-ventures/cometbft-client/crypto/secp256k1"
+	"github.com/strangelove-ventures/cometbft-client/crypto/sr25519"
+)
+
+func init() {
+	// Keep the tests fast. The Cosmos SDK must decrypt with the same cost.
+	BcryptSecurityParameter = 4
+	sdkcrypto.BcryptSecurityParameter = 4
+}
+
+func TestAminoPrefix(t *testing.T) {
+	// The well-known prefixes of the public keys.
+	assert.Equal(t, []byte{0x16, 0x24, 0xde, 0x64}, aminoPrefix(ed25519.PubKeyName))
+	assert.Equal(t, []byte{0xeb, 0x5a, 0xe9, 0x87}, aminoPrefix(secp256k1.PubKeyName))
+}
+
+func TestEncryptArmorPrivKey(t *testing.T) {
+	for _, kdf := range []string{KDFBcrypt, KDFArgon2} {
+		for _, privKey := range []crypto.PrivKey{
+			ed25519.GenPrivKey(),
+			secp256k1.GenPrivKey(),
+			sr25519.GenPrivKey(),
+			bls12381.GenPrivKey(),
+		} {
+			armorStr, err := EncryptArmorPrivKey(privKey, "passphrase", kdf)
+			require.NoError(t, err)
+
+			got, algo, err := UnarmorDecryptPrivKey(armorStr, "passphrase")
+			require.NoError(t, err, "%s %s", kdf, privKey.Type())
+			assert.Equal(t, privKey.Type(), algo)
+			assert.True(t, privKey.Equals(got), "%s %s", kdf, privKey.Type())
+
+			_, _, err = UnarmorDecryptPrivKey(armorStr, "wrong")
+			assert.ErrorIs(t, err, ErrWrongPassphrase)
+		}
+	}
+}
+
+func TestCosmosSDKCompatibility(t *testing.T) {
+	// Exported by the Cosmos SDK, which encrypts with argon2.
+	sdkSecp := sdksecp256k1.GenPrivKey()
+	armorStr := sdkcrypto.EncryptArmorPrivKey(sdkSecp, "passphrase", "secp256k1")
+	got, algo, err := UnarmorDecryptPrivKey(armorStr, "passphrase")
+	require.NoError(t, err)
+	assert.Equal(t, secp256k1.KeyType, algo)
+	assert.Equal(t, sdkSecp.Bytes(), got.Bytes())
+	assert.Equal(t, sdkSecp.PubKey().Bytes(), got.PubKey().Bytes())
+
+	sdkEd := sdked25519.GenPrivKey()
+	armorStr = sdkcrypto.EncryptArmorPrivKey(sdkEd, "passphrase", "ed25519")
+	got, algo, err = UnarmorDecryptPrivKey(armorStr, "passphrase")
+	require.NoError(t, err)
+	assert.Equal(t, ed25519.KeyType, algo)
+	assert.Equal(t, sdkEd.Bytes(), got.Bytes())
+
+	// Imported by the Cosmos SDK, with both key derivation functions.
+	for _, kdf := range []string{KDFBcrypt, KDFArgon2} {
+		for _, privKey := range []crypto.PrivKey{ed25519.GenPrivKey(), secp256k1.GenPrivKey()} {
+			armorStr, err := EncryptArmorPrivKey(privKey, "passphrase", kdf)
+			require.NoError(t, err)
+			sdkKey, algo, err := sdkcrypto.UnarmorDecryptPrivKey(armorStr, "passphrase")
+			require.NoError(t, err, "%s %s", kdf, privKey.Type())
+			assert.Equal(t, privKey.Type(), algo)
+			assert.Equal(t, privKey.Bytes(), sdkKey.Bytes())
+		}
+	}
+}
+
+func TestKeystore(t *testing.T) {
+	ks, err := New(t.TempDir(), DefaultOptions)
+	require.NoError(t, err)
+
+	edKey := ed25519.GenPrivKey()
+	srKey := sr25519.GenPrivKey()
+	require.NoError(t, ks.Save("validator", edKey, "passphrase"))
+	require.NoError(t, ks.Save("node", srKey, "other"))
+	assert.ErrorIs(t, ks.Save("validator", edKey, "passphrase"), ErrKeyExists)
+	assert.Error(t, ks.Save("../escape", edKey, "passphrase"))
+
+	infos, err := ks.List()
+	require.NoError(t, err)
+	assert.Equal(t, []KeyInfo{{"node", sr25519.KeyType}, {"validator", ed25519.KeyType}}, infos)
+
+	got, err := ks.Load("validator", "passphrase")
+	require.NoError(t, err)
+	assert.True(t, edKey.Equals(got))
+	_, err = ks.Load("validator", "other")
+	assert.ErrorIs(t, err, ErrWrongPassphrase)
+	_, err = ks.Load("missing", "passphrase")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	// Export with another passphrase, and import under another name.
+	exported, err := ks.Export("validator", "passphrase", "export")
+	require.NoError(t, err)
+	assert.ErrorIs(t, ks.Import("copy", exported, "passphrase"), ErrWrongPassphrase)
+	require.NoError(t, ks.Import("copy", exported, "export"))
+	got, err = ks.Load("copy", "export")
+	require.NoError(t, err)
+	assert.True(t, edKey.Equals(got))
+
+	require.NoError(t, ks.ChangePassphrase("copy", "export", "changed"))
+	_, err = ks.Load("copy", "export")
+	assert.ErrorIs(t, err, ErrWrongPassphrase)
+	got, err = ks.Load("copy", "changed")
+	require.NoError(t, err)
+	assert.True(t, edKey.Equals(got))
+
+	require.NoError(t, ks.Delete("copy"))
+	assert.ErrorIs(t, ks.Delete("copy"), ErrKeyNotFound)
+}
+
+func TestKeystoreRotate(t *testing.T) {
+	ks, err := New(t.TempDir(), Options{KDF: KDFArgon2})
+	require.NoError(t, err)
+
+	old := secp256k1.GenPrivKey()
+	require.NoError(t, ks.Save("signer", old, "passphrase"))
+
+	rotated, err := ks.Rotate("signer", "passphrase")
+	require.NoError(t, err)
+	assert.Equal(t, secp256k1.KeyType, rotated.Type())
+	assert.False(t, old.Equals(rotated))
+
+	got, err := ks.Load("signer", "passphrase")
+	require.NoError(t, err)
+	assert.True(t, rotated.Equals(got))
+
+	backup := "signer." + old.PubKey().Address().String()
+	got, err = ks.Load(backup, "passphrase")
+	require.NoError(t, err)
+	assert.True(t, old.Equals(got))
+
+	infos, err := ks.List()
+	require.NoError(t, err)
+	assert.Equal(t, []KeyInfo{{"signer", secp256k1.KeyType}, {backup, secp256k1.KeyType}}, infos)
+}
@@ -0,0 +1,303 @@
+package bits
+
+import (
+	"encoding/binary"
+	"fmt"
+	"regexp"
+	"strings"
+
+	cmtsync "github.com/strangelove-ventures/cometbft-client/libs/sync"
+)
+
+// BitArray is a thread-safe implementation of a bit array.
+type BitArray struct {
+	mtx   cmtsync.Mutex
+	Bits  int      `json:"bits"`  // NOTE: persisted via reflect, must be exported
+	Elems []uint64 `json:"elems"` // NOTE: persisted via reflect, must be exported
+}
+
+// NewBitArray returns a new bit array.
+// It returns nil if the number of bits is zero.
+func NewBitArray(bits int) *BitArray {
+	if bits <= 0 {
+		return nil
+	}
+	return &BitArray{
+		Bits:  bits,
+		Elems: make([]uint64, numElems(bits)),
+	}
+}
+
+// Size returns the number of bits in the bitarray
+func (bA *BitArray) Size() int {
+	if bA == nil {
+		return 0
+	}
+	return bA.Bits
+}
+
+// GetIndex returns the bit at index i within the bit array.
+// The behavior is undefined if i >= bA.Bits
+func (bA *BitArray) GetIndex(i int) bool {
+	if bA == nil {
+		return false
+	}
+	bA.mtx.Lock()
+	defer bA.mtx.Unlock()
+	return bA.getIndex(i)
+}
+
+func (bA *BitArray) getIndex(i int) bool {
+	if i < 0 || i >= bA.Bits {
+		return false
+	}
+	return bA.Elems[i/64]&(uint64(1)<<uint(i%64)) > 0
+}
+
+// SetIndex sets the bit at index i within the bit array.
+// The behavior is undefined if i >= bA.Bits
+func (bA *BitArray) SetIndex(i int, v bool) bool {
+	if bA == nil {
+		return false
+	}
+	bA.mtx.Lock()
+	defer bA.mtx.Unlock()
+	return bA.setIndex(i, v)
+}
+
+func (bA *BitArray) setIndex(i int, v bool) bool {
+	if i < 0 || i >= bA.Bits {
+		return false
+	}
+	if v {
+		bA.Elems[i/64] |= (uint64(1) << uint(i%64))
+	} else {
+		bA.Elems[i/64] &= ^(uint64(1) << uint(i%64))
+	}
+	return true
+}
+
+// Copy returns a copy of the provided bit array.
+func (bA *BitArray) Copy() *BitArray {
+	if bA == nil {
+		return nil
+	}
+	bA.mtx.Lock()
+	defer bA.mtx.Unlock()
+	return bA.copy()
+}
+
+func (bA *BitArray) copy() *BitArray {
+	c := make([]uint64, len(bA.Elems))
+	copy(c, bA.Elems)
+	return &BitArray{
+		Bits:  bA.Bits,
+		Elems: c,
+	}
+}
+
+// Not returns a bit array resulting from a bitwise Not of the provided bit array.
+func (bA *BitArray) Not() *BitArray {
+	if bA == nil {
+		return nil // Degenerate
+	}
+	bA.mtx.Lock()
+	defer bA.mtx.Unlock()
+	c := bA.copy()
+	for i := 0; i < len(c.Elems); i++ {
+		c.Elems[i] = ^c.Elems[i]
+	}
+	// Clear the bits past the end of the array.
+	if rem := uint(c.Bits % 64); rem != 0 {
+		c.Elems[len(c.Elems)-1] &= (uint64(1) << rem) - 1
+	}
+	return c
+}
+
+// IsEmpty returns true iff all bits in the bit array are 0
+func (bA *BitArray) IsEmpty() bool {
+	if bA == nil {
+		return true // should this be opposite?
+	}
+	bA.mtx.Lock()
+	defer bA.mtx.Unlock()
+	for _, e := range bA.Elems {
+		if e > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// IsFull returns true iff all bits in the bit array are 1.
+func (bA *BitArray) IsFull() bool {
+	if bA == nil {
+		return true
+	}
+	return bA.Not().IsEmpty()
+}
+
+// GetTrueIndices returns the list of indices that have a true value
+func (bA *BitArray) GetTrueIndices() []int {
+	if bA == nil {
+		return nil
+	}
+	bA.mtx.Lock()
+	defer bA.mtx.Unlock()
+	trueIndices := make([]int, 0, bA.Bits)
+	for i := 0; i < bA.Bits; i++ {
+		if bA.getIndex(i) {
+			trueIndices = append(trueIndices, i)
+		}
+	}
+	return trueIndices
+}
+
+// String returns a string representation of BitArray: BA{<bit-string>},
+// where <bit-string> is a sequence of 'x' (1) and '_' (0).
+// The <bit-string> includes spaces and newlines to help people.
+// For a simple sequence of 'x' and '_' characters with no spaces or newlines,
+// see the MarshalJSON() method.
+// Example: "BA{_x_}" or "nil-BitArray" for nil.
+func (bA *BitArray) String() string {
+	return bA.StringIndented("")
+}
+
+// StringIndented returns the same thing as String(), but applies the indent
+// at every 10th bit, and twice at every 50th bit.
+func (bA *BitArray) StringIndented(indent string) string {
+	if bA == nil {
+		return "nil-BitArray"
+	}
+	bA.mtx.Lock()
+	defer bA.mtx.Unlock()
+	return bA.stringIndented(indent)
+}
+
+func (bA *BitArray) stringIndented(indent string) string {
+	lines := []string{}
+	bits := ""
+	for i := 0; i < bA.Bits; i++ {
+		if bA.getIndex(i) {
+			bits += "x"
+		} else {
+			bits += "_"
+		}
+		if i%100 == 99 {
+			lines = append(lines, bits)
+			bits = ""
+		}
+		if i%10 == 9 {
+			bits += indent
+		}
+		if i%50 == 49 {
+			bits += indent
+		}
+	}
+	if len(bits) > 0 {
+		lines = append(lines, bits)
+	}
+	return fmt.Sprintf("BA{%v:%v}", bA.Bits, strings.Join(lines, indent))
+}
+
+// Bytes returns the byte representation of the bits within the bitarray.
+func (bA *BitArray) Bytes() []byte {
+	bA.mtx.Lock()
+	defer bA.mtx.Unlock()
+
+	numBytes := (bA.Bits + 7) / 8
+	bytes := make([]byte, numBytes)
+	for i := 0; i < len(bA.Elems); i++ {
+		elemBytes := [8]byte{}
+		binary.LittleEndian.PutUint64(elemBytes[:], bA.Elems[i])
+		copy(bytes[i*8:], elemBytes[:])
+	}
+	return bytes
+}
+
+// MarshalJSON implements json.Marshaler interface by marshaling bit array
+// using a custom format: a string of '-' or 'x' where 'x' denotes the 1 bit.
+func (bA *BitArray) MarshalJSON() ([]byte, error) {
+	if bA == nil {
+		return []byte("null"), nil
+	}
+
+	bA.mtx.Lock()
+	defer bA.mtx.Unlock()
+
+	bits := `"`
+	for i := 0; i < bA.Bits; i++ {
+		if bA.getIndex(i) {
+			bits += `x`
+		} else {
+			bits += `_`
+		}
+	}
+	bits += `"`
+	return []byte(bits), nil
+}
+
+var bitArrayJSONRegexp = regexp.MustCompile(`\A"([_x]*)"\z`)
+
+// UnmarshalJSON implements json.Unmarshaler interface by unmarshaling a custom
+// JSON description.
+func (bA *BitArray) UnmarshalJSON(bz []byte) error {
+	b := string(bz)
+	if b == "null" {
+		// This is required e.g. for encoding/json when decoding
+		// into a pointer with pre-allocated BitArray.
+		bA.Bits = 0
+		bA.Elems = nil
+		return nil
+	}
+
+	// Validate 'b'.
+	match := bitArrayJSONRegexp.FindStringSubmatch(b)
+	if match == nil {
+		return fmt.Errorf("bitArray in JSON should be a string of format %q but got %s", bitArrayJSONRegexp.String(), b)
+	}
+
+	bA2, err := Parse(match[1])
+	if err != nil {
+		return err
+	}
+	if bA2 == nil {
+		bA.Bits = 0
+		bA.Elems = nil
+		return nil
+	}
+
+	bA.Bits = bA2.Bits
+	bA.Elems = bA2.Elems
+	return nil
+}
+
+// Parse parses a sequence of 'x' (1) and '_' (0) characters into a BitArray.
+// Spaces and newlines, as emitted by StringIndented, are ignored.
+// It returns nil for an empty sequence.
+func Parse(bits string) (*BitArray, error) {
+	bits = strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\t', '\n', '\r':
+			return -1
+		}
+		return r
+	}, bits)
+
+	bA := NewBitArray(len(bits))
+	for i, c := range bits {
+		switch c {
+		case 'x':
+			bA.setIndex(i, true)
+		case '_':
+		default:
+			return nil, fmt.Errorf("invalid bit %q at index %d", c, i)
+		}
+	}
+
+	return bA, nil
+}
+
+func numElems(bits int) int {
+	return (bits + 63) / 64
+}
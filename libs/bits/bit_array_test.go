@@ -0,0 +1,92 @@
+package bits
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		in      string
+		indices []int
+		size    int
+		expErr  bool
+	}{
+		{"", nil, 0, false},
+		{"x", []int{0}, 1, false},
+		{"_x_x", []int{1, 3}, 4, false},
+		{"xxxxxxxxxx xxxxxxxxxx", []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19}, 20, false},
+		{"x-x", nil, 0, true},
+	}
+
+	for _, tc := range testCases {
+		bA, err := Parse(tc.in)
+		if tc.expErr {
+			assert.Error(t, err, tc.in)
+			continue
+		}
+		require.NoError(t, err, tc.in)
+		assert.Equal(t, tc.size, bA.Size(), tc.in)
+		assert.Equal(t, tc.indices, bA.GetTrueIndices(), tc.in)
+	}
+}
+
+func TestStringRoundTrip(t *testing.T) {
+	bA := NewBitArray(130)
+	for _, i := range []int{0, 9, 63, 64, 99, 100, 129} {
+		bA.SetIndex(i, true)
+	}
+
+	s := bA.StringIndented(" ")
+	require.Regexp(t, `^BA\{130:`, s)
+
+	parsed, err := Parse(s[len("BA{130:") : len(s)-1])
+	require.NoError(t, err)
+	assert.Equal(t, bA.GetTrueIndices(), parsed.GetTrueIndices())
+	assert.Equal(t, bA.Bytes(), parsed.Bytes())
+}
+
+func TestNot(t *testing.T) {
+	bA, err := Parse("x_x__")
+	require.NoError(t, err)
+
+	assert.Equal(t, []int{1, 3, 4}, bA.Not().GetTrueIndices())
+	assert.False(t, bA.IsFull())
+	assert.True(t, bA.Not().Not().Not().Not().GetIndex(0))
+
+	full, err := Parse("xxx")
+	require.NoError(t, err)
+	assert.True(t, full.IsFull())
+	assert.True(t, full.Not().IsEmpty())
+}
+
+func TestJSONMarshalUnmarshal(t *testing.T) {
+	bA, err := Parse("x_xx_")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		bA         *BitArray
+		marshalled string
+	}{
+		{nil, "null"},
+		{NewBitArray(1), `"_"`},
+		{bA, `"x_xx_"`},
+	}
+
+	for _, tc := range testCases {
+		bz, err := json.Marshal(tc.bA)
+		require.NoError(t, err)
+		assert.Equal(t, tc.marshalled, string(bz))
+
+		var unmarshalled *BitArray
+		require.NoError(t, json.Unmarshal(bz, &unmarshalled))
+		assert.Equal(t, tc.bA.GetTrueIndices(), unmarshalled.GetTrueIndices())
+		assert.Equal(t, tc.bA.Size(), unmarshalled.Size())
+	}
+
+	var invalid BitArray
+	assert.Error(t, json.Unmarshal([]byte(`"x-x"`), &invalid))
+}
@@ -0,0 +1,150 @@
+package json_test
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	abci "github.com/strangelove-ventures/cometbft-client/abci/types"
+	"github.com/strangelove-ventures/cometbft-client/libs/json"
+	ctypes "github.com/strangelove-ventures/cometbft-client/rpc/core/types"
+	"github.com/strangelove-ventures/cometbft-client/types"
+)
+
+// benchBlock returns a block with numTxs transactions of 300 bytes, and a commit of
+// numSigs signatures, as returned by the block RPC method.
+func benchBlock(numTxs, numSigs int) *ctypes.ResultBlock {
+	hash := make([]byte, 32)
+	for i := range hash {
+		hash[i] = byte(i)
+	}
+	blockID := types.BlockID{Hash: hash, PartSetHeader: types.PartSetHeader{Total: 1, Hash: hash}}
+
+	txs := make(types.Txs, numTxs)
+	for i := range txs {
+		tx := make([]byte, 300)
+		for j := range tx {
+			tx[j] = byte(i + j)
+		}
+		txs[i] = tx
+	}
+
+	sigs := make([]types.CommitSig, numSigs)
+	for i := range sigs {
+		sigs[i] = types.CommitSig{
+			BlockIDFlag:      types.BlockIDFlagCommit,
+			ValidatorAddress: hash[:20],
+			Timestamp:        time.Date(2024, 1, 2, 3, 4, 5, i, time.UTC),
+			Signature:        make([]byte, 64),
+		}
+	}
+
+	return &ctypes.ResultBlock{
+		BlockID: blockID,
+		Block: &types.Block{
+			Header: types.Header{
+				ChainID:            "cosmoshub-4",
+				Height:             18000000,
+				Time:               time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC),
+				LastBlockID:        blockID,
+				LastCommitHash:     hash,
+				DataHash:           hash,
+				ValidatorsHash:     hash,
+				NextValidatorsHash: hash,
+				ConsensusHash:      hash,
+				AppHash:            hash,
+				LastResultsHash:    hash,
+				EvidenceHash:       hash,
+				ProposerAddress:    hash[:20],
+			},
+			Data:       types.Data{Txs: txs},
+			LastCommit: &types.Commit{Height: 17999999, BlockID: blockID, Signatures: sigs},
+		},
+	}
+}
+
+// benchBlockResults returns the results of a block with numTxs transactions, each emitting
+// a few events, as returned by the block_results RPC method.
+func benchBlockResults(numTxs int) *ctypes.ResultBlockResults {
+	events := func(n int) []abci.Event {
+		events := make([]abci.Event, n)
+		for i := range events {
+			attrs := make([]abci.EventAttribute, 5)
+			for j := range attrs {
+				attrs[j] = abci.EventAttribute{
+					Key:   "attribute_" + strconv.Itoa(j),
+					Value: "cosmos1qypqxpq9qcrsszg2pvxq6rs0zqg3yyc5lzv7xu" + strconv.Itoa(i),
+					Index: true,
+				}
+			}
+			events[i] = abci.Event{Type: "transfer", Attributes: attrs}
+		}
+		return events
+	}
+
+	txs := make([]*abci.ExecTxResult, numTxs)
+	for i := range txs {
+		txs[i] = &abci.ExecTxResult{
+			Code:      0,
+			Data:      []byte("data"),
+			Log:       "[]",
+			GasWanted: 200000,
+			GasUsed:   123456,
+			Events:    events(6),
+		}
+	}
+	return &ctypes.ResultBlockResults{
+		Height:              18000000,
+		TxsResults:          txs,
+		FinalizeBlockEvents: events(10),
+		AppHash:             []byte("apphash"),
+	}
+}
+
+func benchmarkMarshal(b *testing.B, v interface{}) {
+	bz, err := json.Marshal(v)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.SetBytes(int64(len(bz)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := json.Marshal(v); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func benchmarkUnmarshal[T any](b *testing.B, v *T) {
+	bz, err := json.Marshal(v)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.SetBytes(int64(len(bz)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if err := json.Unmarshal(bz, new(T)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMarshalResultBlock(b *testing.B) {
+	benchmarkMarshal(b, benchBlock(300, 150))
+}
+
+func BenchmarkUnmarshalResultBlock(b *testing.B) {
+	benchmarkUnmarshal(b, benchBlock(300, 150))
+}
+
+func BenchmarkMarshalResultBlockResults(b *testing.B) {
+	benchmarkMarshal(b, benchBlockResults(300))
+}
+
+func BenchmarkUnmarshalResultBlockResults(b *testing.B) {
+	benchmarkUnmarshal(b, benchBlockResults(300))
+}
@@ -2,12 +2,19 @@ package json
 
 import (
 	"bytes"
+	"encoding"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"reflect"
+	"strconv"
+	"strings"
+	"sync"
 )
 
+var textUnmarshalerType = reflect.TypeOf(new(encoding.TextUnmarshaler)).Elem()
+
 // Unmarshal unmarshals JSON into the given value, using Amino-compatible JSON encoding (strings
 // for 64-bit numbers, and type wrappers for registered types).
 func Unmarshal(bz []byte, v interface{}) error {
@@ -23,198 +30,480 @@ func decode(bz []byte, v interface{}) error {
 	if rv.Kind() != reflect.Ptr {
 		return errors.New("must decode into a pointer")
 	}
+	if rv.IsNil() {
+		return errors.New("cannot decode into a nil pointer")
+	}
 	rv = rv.Elem()
 
+	d := &decodeState{data: bz}
+	d.skipWS()
+
 	// If this is a registered type, defer to interface decoder regardless of whether the input is
 	// an interface or a bare value. This retains Amino's behavior, but is inconsistent with
 	// behavior in structs where an interface field will get the type wrapper while a bare value
 	// field will not.
+	var err error
 	if typeRegistry.name(rv.Type()) != "" {
-		return decodeReflectInterface(bz, rv)
+		err = decodeInterface(d, rv)
+	} else {
+		err = d.value(rv, typeDecoder(rv.Type()))
+	}
+	if err != nil {
+		return err
 	}
 
-	return decodeReflect(bz, rv)
+	d.skipWS()
+	if d.off < len(d.data) {
+		return d.syntaxError("end of input after top-level value")
+	}
+	return nil
 }
 
-func decodeReflect(bz []byte, rv reflect.Value) error {
-	if !rv.CanAddr() {
-		return errors.New("value is not addressable")
+// decoderFunc decodes the value at d.off into rv, which must be addressable. It is not given
+// null values, which value handles for all types.
+type decoderFunc func(d *decodeState, rv reflect.Value) error
+
+// decoderCache caches the decoderFunc of each type.
+var decoderCache sync.Map // map[reflect.Type]decoderFunc
+
+// typeDecoder returns the decoder of a type, compiling it on first use.
+func typeDecoder(rt reflect.Type) decoderFunc {
+	if f, ok := decoderCache.Load(rt); ok {
+		return f.(decoderFunc)
+	}
+
+	// Recursive types refer to their own decoder while it is compiled, so we store an
+	// indirect decoder first, which waits for the compiled one.
+	var (
+		wg sync.WaitGroup
+		f  decoderFunc
+	)
+	wg.Add(1)
+	fi, loaded := decoderCache.LoadOrStore(rt, decoderFunc(func(d *decodeState, rv reflect.Value) error {
+		wg.Wait()
+		return f(d, rv)
+	}))
+	if loaded {
+		return fi.(decoderFunc)
 	}
 
-	// Handle null for slices, interfaces, and pointers
-	if bytes.Equal(bz, []byte("null")) {
-		rv.Set(reflect.Zero(rv.Type()))
+	f = newTypeDecoder(rt)
+	wg.Done()
+	decoderCache.Store(rt, f)
+	return f
+}
+
+// value decodes the value at d.off into rv with f, setting rv to its zero value if null.
+func (d *decodeState) value(rv reflect.Value, f decoderFunc) error {
+	if d.off >= len(d.data) {
+		return d.syntaxError("beginning of value")
+	}
+	if d.literal("null") {
+		rv.SetZero()
 		return nil
 	}
+	return f(d, rv)
+}
 
+func newTypeDecoder(rt reflect.Type) decoderFunc {
 	// Dereference-and-construct pointers, to handle nested pointers.
-	for rv.Kind() == reflect.Ptr {
-		if rv.IsNil() {
-			rv.Set(reflect.New(rv.Type().Elem()))
-		}
-		rv = rv.Elem()
+	if rt.Kind() == reflect.Ptr {
+		return newPtrDecoder(rt)
 	}
 
 	// Times must be UTC and end with Z
-	if rv.Type() == timeType {
-		switch {
-		case len(bz) < 2 || bz[0] != '"' || bz[len(bz)-1] != '"':
-			return fmt.Errorf("JSON time must be an RFC3339 string, but got %q", bz)
-		case bz[len(bz)-2] != 'Z':
-			return fmt.Errorf("JSON time must be UTC and end with 'Z', but got %q", bz)
-		}
+	if rt == timeType {
+		return decodeTime
 	}
 
 	// If value implements json.Umarshaler, call it.
-	if rv.Addr().Type().Implements(jsonUnmarshalerType) {
-		return rv.Addr().Interface().(json.Unmarshaler).UnmarshalJSON(bz)
+	if reflect.PointerTo(rt).Implements(jsonUnmarshalerType) {
+		return decodeUnmarshaler
 	}
 
-	switch rv.Type().Kind() {
+	switch rt.Kind() {
 	// Decode complex types recursively.
 	case reflect.Slice, reflect.Array:
-		return decodeReflectList(bz, rv)
+		if rt.Elem().Kind() == reflect.Uint8 {
+			return newBytesDecoder(rt)
+		}
+		return newListDecoder(rt)
 
 	case reflect.Map:
-		return decodeReflectMap(bz, rv)
+		return newMapDecoder(rt)
 
 	case reflect.Struct:
-		return decodeReflectStruct(bz, rv)
+		return newStructDecoder(rt)
 
 	case reflect.Interface:
-		return decodeReflectInterface(bz, rv)
+		return decodeInterface
 
-	// For 64-bit integers, unwrap expected string and defer to stdlib for integer decoding.
+	// For 64-bit integers, unwrap expected string.
 	case reflect.Int64, reflect.Int, reflect.Uint64, reflect.Uint:
-		if bz[0] != '"' || bz[len(bz)-1] != '"' {
-			return fmt.Errorf("invalid 64-bit integer encoding %q, expected string", string(bz))
-		}
-		bz = bz[1 : len(bz)-1]
-		fallthrough
+		return decodeQuotedInt
+	}
+
+	// Types implementing encoding.TextUnmarshaler are decoded by the stdlib.
+	if reflect.PointerTo(rt).Implements(textUnmarshalerType) {
+		return decodeStdlib
+	}
+	switch rt.Kind() {
+	case reflect.Bool:
+		return decodeBool
+	case reflect.String:
+		return decodeString
+	case reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Float32, reflect.Float64:
+		return decodeNumber
+	}
 
 	// Anything else we defer to the stdlib.
-	default:
-		return decodeStdlib(bz, rv)
+	return decodeStdlib
+}
+
+func newPtrDecoder(rt reflect.Type) decoderFunc {
+	elem := rt.Elem()
+	elemDecoder := typeDecoder(elem)
+	return func(d *decodeState, rv reflect.Value) error {
+		if rv.IsNil() {
+			rv.Set(reflect.New(elem))
+		}
+		return elemDecoder(d, rv.Elem())
 	}
 }
 
-func decodeReflectList(bz []byte, rv reflect.Value) error {
-	if !rv.CanAddr() {
-		return errors.New("list value is not addressable")
+func decodeTime(d *decodeState, rv reflect.Value) error {
+	bz, err := d.skipValue()
+	if err != nil {
+		return err
+	}
+	switch {
+	case len(bz) < 2 || bz[0] != '"' || bz[len(bz)-1] != '"':
+		return fmt.Errorf("JSON time must be an RFC3339 string, but got %q", bz)
+	case bz[len(bz)-2] != 'Z':
+		return fmt.Errorf("JSON time must be UTC and end with 'Z', but got %q", bz)
 	}
+	return rv.Addr().Interface().(json.Unmarshaler).UnmarshalJSON(bz)
+}
 
-	switch rv.Type().Elem().Kind() {
-	// Decode base64-encoded bytes using stdlib decoder, via byte slice for arrays.
-	case reflect.Uint8:
-		if rv.Type().Kind() == reflect.Array {
-			var buf []byte
-			if err := json.Unmarshal(bz, &buf); err != nil {
-				return err
-			}
-			if len(buf) != rv.Len() {
-				return fmt.Errorf("got %v bytes, expected %v", len(buf), rv.Len())
-			}
-			reflect.Copy(rv, reflect.ValueOf(buf))
+func decodeUnmarshaler(d *decodeState, rv reflect.Value) error {
+	bz, err := d.skipValue()
+	if err != nil {
+		return err
+	}
+	return rv.Addr().Interface().(json.Unmarshaler).UnmarshalJSON(bz)
+}
 
-		} else if err := decodeStdlib(bz, rv); err != nil {
-			return err
+func newBytesDecoder(rt reflect.Type) decoderFunc {
+	// Decode base64-encoded bytes ourselves, unless the stdlib would decode them differently.
+	if rt.Kind() == reflect.Slice {
+		elemPtr := reflect.PointerTo(rt.Elem())
+		if reflect.PointerTo(rt).Implements(textUnmarshalerType) ||
+			elemPtr.Implements(jsonUnmarshalerType) || elemPtr.Implements(textUnmarshalerType) {
+			return decodeStdlib
 		}
+	}
 
-	// Decode anything else into a raw JSON slice, and decode values recursively.
-	default:
-		var rawSlice []json.RawMessage
-		if err := json.Unmarshal(bz, &rawSlice); err != nil {
+	return func(d *decodeState, rv reflect.Value) error {
+		bz, err := d.skipValue()
+		if err != nil {
 			return err
 		}
-		if rv.Type().Kind() == reflect.Slice {
-			rv.Set(reflect.MakeSlice(reflect.SliceOf(rv.Type().Elem()), len(rawSlice), len(rawSlice)))
-		}
-		if rv.Len() != len(rawSlice) { // arrays of wrong size
-			return fmt.Errorf("got list of %v elements, expected %v", len(rawSlice), rv.Len())
+		buf, err := decodeBase64(bz)
+		if err != nil {
+			return err
 		}
-		for i, bz := range rawSlice {
-			if err := decodeReflect(bz, rv.Index(i)); err != nil {
-				return err
+
+		if rv.Kind() == reflect.Array {
+			if len(buf) != rv.Len() {
+				return fmt.Errorf("got %v bytes, expected %v", len(buf), rv.Len())
 			}
+			for i, b := range buf {
+				rv.Index(i).SetUint(uint64(b))
+			}
+			return nil
 		}
-	}
 
-	// Replace empty slices with nil slices, for Amino compatibility
-	if rv.Type().Kind() == reflect.Slice && rv.Len() == 0 {
-		rv.Set(reflect.Zero(rv.Type()))
+		// Replace empty slices with nil slices, for Amino compatibility
+		if len(buf) == 0 {
+			rv.SetZero()
+		} else {
+			rv.SetBytes(buf)
+		}
+		return nil
 	}
+}
 
-	return nil
+// decodeBase64 decodes a base64-encoded JSON string, using the stdlib for anything unusual.
+func decodeBase64(bz []byte) ([]byte, error) {
+	if len(bz) >= 2 && bz[0] == '"' && bz[len(bz)-1] == '"' && bytes.IndexByte(bz, '\\') < 0 {
+		src := bz[1 : len(bz)-1]
+		buf := make([]byte, base64.StdEncoding.DecodedLen(len(src)))
+		if n, err := base64.StdEncoding.Decode(buf, src); err == nil {
+			return buf[:n], nil
+		}
+	}
+	var buf []byte
+	err := json.Unmarshal(bz, &buf)
+	return buf, err
 }
 
-func decodeReflectMap(bz []byte, rv reflect.Value) error {
-	if !rv.CanAddr() {
-		return errors.New("map value is not addressable")
+func newListDecoder(rt reflect.Type) decoderFunc {
+	elemDecoder := typeDecoder(rt.Elem())
+
+	if rt.Kind() == reflect.Array {
+		return func(d *decodeState, rv reflect.Value) error {
+			if err := d.openArray(); err != nil {
+				return err
+			}
+			n := 0
+			for ; ; n++ {
+				more, err := d.nextElem(n)
+				if err != nil {
+					return err
+				}
+				if !more {
+					break
+				}
+				if n >= rv.Len() { // arrays of wrong size
+					if _, err := d.skipValue(); err != nil {
+						return err
+					}
+					continue
+				}
+				if err := d.value(rv.Index(n), elemDecoder); err != nil {
+					return err
+				}
+			}
+			if n != rv.Len() {
+				return fmt.Errorf("got list of %v elements, expected %v", n, rv.Len())
+			}
+			return nil
+		}
 	}
 
-	// Decode into a raw JSON map, using string keys.
-	rawMap := make(map[string]json.RawMessage)
-	if err := json.Unmarshal(bz, &rawMap); err != nil {
-		return err
+	return func(d *decodeState, rv reflect.Value) error {
+		if err := d.openArray(); err != nil {
+			return err
+		}
+		// Decode into a new slice, growing it as elements are read.
+		slice := reflect.New(rt).Elem()
+		for i := 0; ; i++ {
+			more, err := d.nextElem(i)
+			if err != nil {
+				return err
+			}
+			if !more {
+				break
+			}
+			if i == slice.Cap() {
+				grown := reflect.MakeSlice(rt, i, max(4, 2*i))
+				reflect.Copy(grown, slice)
+				slice.Set(grown)
+			}
+			slice.SetLen(i + 1)
+			if err := d.value(slice.Index(i), elemDecoder); err != nil {
+				return err
+			}
+		}
+
+		// Empty slices are decoded as nil slices, for Amino compatibility
+		if slice.Len() == 0 {
+			rv.SetZero()
+		} else {
+			rv.Set(slice)
+		}
+		return nil
 	}
-	if rv.Type().Key().Kind() != reflect.String {
-		return fmt.Errorf("map keys must be strings, got %v", rv.Type().Key().String())
+}
+
+func newMapDecoder(rt reflect.Type) decoderFunc {
+	if rt.Key().Kind() != reflect.String {
+		return func(d *decodeState, rv reflect.Value) error {
+			return fmt.Errorf("map keys must be strings, got %v", rt.Key().String())
+		}
 	}
 
-	// Recursively decode values.
-	rv.Set(reflect.MakeMapWithSize(rv.Type(), len(rawMap)))
-	for key, bz := range rawMap {
-		value := reflect.New(rv.Type().Elem()).Elem()
-		if err := decodeReflect(bz, value); err != nil {
+	elemDecoder := typeDecoder(rt.Elem())
+	return func(d *decodeState, rv reflect.Value) error {
+		if err := d.openObject(); err != nil {
 			return err
 		}
-		rv.SetMapIndex(reflect.ValueOf(key), value)
+		m := reflect.MakeMap(rt)
+		value := reflect.New(rt.Elem()).Elem()
+		for i := 0; ; i++ {
+			rawKey, simple, more, err := d.nextKey(i)
+			if err != nil {
+				return err
+			}
+			if !more {
+				break
+			}
+			key, err := unquote(rawKey, simple)
+			if err != nil {
+				return err
+			}
+			value.SetZero()
+			if err := d.value(value, elemDecoder); err != nil {
+				return err
+			}
+			m.SetMapIndex(reflect.ValueOf(key).Convert(rt.Key()), value)
+		}
+		rv.Set(m)
+		return nil
 	}
-	return nil
 }
 
-func decodeReflectStruct(bz []byte, rv reflect.Value) error {
-	if !rv.CanAddr() {
-		return errors.New("struct value is not addressable")
+// structDecoder decodes a struct.
+type structDecoder struct {
+	fields []decodeField
+	byName map[string][]int // indexes in fields
+}
+
+// decodeField is a field of a struct, as decoded.
+type decodeField struct {
+	index     int
+	omitEmpty bool
+	decoder   decoderFunc
+}
+
+func newStructDecoder(rt reflect.Type) decoderFunc {
+	sInfo := makeStructInfo(rt)
+	sd := &structDecoder{byName: make(map[string][]int, len(sInfo.fields))}
+	for i, fInfo := range sInfo.fields {
+		if fInfo.hidden {
+			continue
+		}
+		sd.byName[fInfo.jsonName] = append(sd.byName[fInfo.jsonName], len(sd.fields))
+		sd.fields = append(sd.fields, decodeField{
+			index:     i,
+			omitEmpty: fInfo.omitEmpty,
+			decoder:   typeDecoder(rt.Field(i).Type),
+		})
 	}
-	sInfo := makeStructInfo(rv.Type())
+	return sd.decode
+}
 
-	// Decode raw JSON values into a string-keyed map.
-	rawMap := make(map[string]json.RawMessage)
-	if err := json.Unmarshal(bz, &rawMap); err != nil {
+func (sd *structDecoder) decode(d *decodeState, rv reflect.Value) error {
+	if err := d.openObject(); err != nil {
 		return err
 	}
-	for i, fInfo := range sInfo.fields {
-		if !fInfo.hidden {
-			frv := rv.Field(i)
-			bz := rawMap[fInfo.jsonName]
-			if len(bz) > 0 {
-				if err := decodeReflect(bz, frv); err != nil {
-					return err
-				}
-			} else if !fInfo.omitEmpty {
-				frv.Set(reflect.Zero(frv.Type()))
+
+	// Track the decoded fields, to zero the others.
+	var (
+		seen     uint64
+		seenMore []bool
+	)
+	if len(sd.fields) > 64 {
+		seenMore = make([]bool, len(sd.fields))
+	}
+
+	for i := 0; ; i++ {
+		rawKey, simple, more, err := d.nextKey(i)
+		if err != nil {
+			return err
+		}
+		if !more {
+			break
+		}
+		var fields []int
+		if simple {
+			fields = sd.byName[string(rawKey[1:len(rawKey)-1])]
+		} else {
+			key, err := unquote(rawKey, simple)
+			if err != nil {
+				return err
+			}
+			fields = sd.byName[key]
+		}
+		if len(fields) == 0 {
+			if _, err := d.skipValue(); err != nil {
+				return err
+			}
+			continue
+		}
+
+		start := d.off
+		for _, j := range fields {
+			d.off = start
+			field := sd.fields[j]
+			if err := d.value(rv.Field(field.index), field.decoder); err != nil {
+				return err
+			}
+			if seenMore != nil {
+				seenMore[j] = true
+			} else {
+				seen |= 1 << j
 			}
 		}
 	}
 
+	for j, field := range sd.fields {
+		if field.omitEmpty || (seenMore == nil && seen&(1<<j) != 0) || (seenMore != nil && seenMore[j]) {
+			continue
+		}
+		rv.Field(field.index).SetZero()
+	}
 	return nil
 }
 
-func decodeReflectInterface(bz []byte, rv reflect.Value) error {
-	if !rv.CanAddr() {
-		return errors.New("interface value not addressable")
-	}
-
-	// Decode the interface wrapper.
-	wrapper := interfaceWrapper{}
-	if err := json.Unmarshal(bz, &wrapper); err != nil {
-		return err
+func decodeInterface(d *decodeState, rv reflect.Value) error {
+	// Decode the interface wrapper, without decoding its value yet.
+	var (
+		typeName string
+		value    = -1
+		end      int
+	)
+	if !d.literal("null") {
+		if err := d.openObject(); err != nil {
+			return err
+		}
+		for i := 0; ; i++ {
+			rawKey, simple, more, err := d.nextKey(i)
+			if err != nil {
+				return err
+			}
+			if !more {
+				break
+			}
+			key, err := unquote(rawKey, simple)
+			if err != nil {
+				return err
+			}
+			switch {
+			case strings.EqualFold(key, "type"):
+				if d.literal("null") {
+					typeName = ""
+					break
+				}
+				if d.off >= len(d.data) || d.data[d.off] != '"' {
+					return errors.New("interface type must be a string")
+				}
+				rawType, simple, err := d.scanString()
+				if err != nil {
+					return err
+				}
+				if typeName, err = unquote(rawType, simple); err != nil {
+					return err
+				}
+			case strings.EqualFold(key, "value"):
+				value = d.off
+				if _, err := d.skipValue(); err != nil {
+					return err
+				}
+			default:
+				if _, err := d.skipValue(); err != nil {
+					return err
+				}
+			}
+		}
+		end = d.off
 	}
-	if wrapper.Type == "" {
+	if typeName == "" {
 		return errors.New("interface type cannot be empty")
 	}
-	if len(wrapper.Value) == 0 {
+	if value < 0 {
 		return errors.New("interface value cannot be empty")
 	}
 
@@ -227,52 +516,138 @@ func decodeReflectInterface(bz []byte, rv reflect.Value) error {
 	}
 
 	// Look up the interface type, and construct a concrete value.
-	rt, returnPtr := typeRegistry.lookup(wrapper.Type)
+	rt, returnPtr := typeRegistry.lookup(typeName)
 	if rt == nil {
-		return fmt.Errorf("unknown type %q", wrapper.Type)
+		return fmt.Errorf("unknown type %q", typeName)
 	}
 
 	cptr := reflect.New(rt)
 	crv := cptr.Elem()
-	if err := decodeReflect(wrapper.Value, crv); err != nil {
+	d.off = value
+	if err := d.value(crv, typeDecoder(rt)); err != nil {
 		return err
 	}
+	d.off = end
 
 	// This makes sure interface implementations with pointer receivers (e.g. func (c *Car)) are
 	// constructed as pointers behind the interface. The types must be registered as pointers with
 	// RegisterType().
 	if rv.Type().Kind() == reflect.Interface && returnPtr {
 		if !cptr.Type().AssignableTo(rv.Type()) {
-			return fmt.Errorf("invalid type %q for this value", wrapper.Type)
+			return fmt.Errorf("invalid type %q for this value", typeName)
 		}
 		rv.Set(cptr)
 	} else {
 		if !crv.Type().AssignableTo(rv.Type()) {
-			return fmt.Errorf("invalid type %q for this value", wrapper.Type)
+			return fmt.Errorf("invalid type %q for this value", typeName)
 		}
 		rv.Set(crv)
 	}
 	return nil
 }
 
-func decodeStdlib(bz []byte, rv reflect.Value) error {
-	if !rv.CanAddr() && rv.Kind() != reflect.Ptr {
-		return errors.New("value must be addressable or pointer")
+func decodeQuotedInt(d *decodeState, rv reflect.Value) error {
+	bz, err := d.skipValue()
+	if err != nil {
+		return err
+	}
+	if bz[0] != '"' || bz[len(bz)-1] != '"' {
+		return fmt.Errorf("invalid 64-bit integer encoding %q, expected string", string(bz))
 	}
+	bz = bytes.Trim(bz[1:len(bz)-1], " \t\n\r")
 
-	// Make sure we are unmarshaling into a pointer.
-	target := rv
-	if rv.Kind() != reflect.Ptr {
-		target = reflect.New(rv.Type())
+	switch {
+	case string(bz) == "null":
+		rv.SetZero()
+		return nil
+	case !isInteger(bz):
+	case rv.CanInt():
+		if n, err := strconv.ParseInt(string(bz), 10, rv.Type().Bits()); err == nil {
+			rv.SetInt(n)
+			return nil
+		}
+	default:
+		if n, err := strconv.ParseUint(string(bz), 10, rv.Type().Bits()); err == nil {
+			rv.SetUint(n)
+			return nil
+		}
 	}
-	if err := json.Unmarshal(bz, target.Interface()); err != nil {
+	// Defer to stdlib for the error.
+	return decodeStdlibBytes(bz, rv)
+}
+
+func decodeBool(d *decodeState, rv reflect.Value) error {
+	switch {
+	case d.literal("true"):
+		rv.SetBool(true)
+	case d.literal("false"):
+		rv.SetBool(false)
+	default:
+		return decodeStdlib(d, rv)
+	}
+	return nil
+}
+
+func decodeString(d *decodeState, rv reflect.Value) error {
+	if d.data[d.off] != '"' {
+		return decodeStdlib(d, rv)
+	}
+	raw, simple, err := d.scanString()
+	if err != nil {
 		return err
 	}
-	rv.Set(target.Elem())
+	if !simple {
+		return decodeStdlibBytes(raw, rv)
+	}
+	rv.SetString(string(raw[1 : len(raw)-1]))
 	return nil
 }
 
-type interfaceWrapper struct {
-	Type  string          `json:"type"`
-	Value json.RawMessage `json:"value"`
+func decodeNumber(d *decodeState, rv reflect.Value) error {
+	if c := d.data[d.off]; c != '-' && !isDigit(c) {
+		return decodeStdlib(d, rv)
+	}
+	num, integer, err := d.scanNumber()
+	if err != nil {
+		return err
+	}
+	switch {
+	case rv.CanFloat():
+		if f, err := strconv.ParseFloat(string(num), rv.Type().Bits()); err == nil {
+			rv.SetFloat(f)
+			return nil
+		}
+	case !integer:
+	case rv.CanInt():
+		if n, err := strconv.ParseInt(string(num), 10, rv.Type().Bits()); err == nil {
+			rv.SetInt(n)
+			return nil
+		}
+	default:
+		if n, err := strconv.ParseUint(string(num), 10, rv.Type().Bits()); err == nil {
+			rv.SetUint(n)
+			return nil
+		}
+	}
+	// Defer to stdlib for the error.
+	return decodeStdlibBytes(num, rv)
+}
+
+// decodeStdlib decodes the value at d.off with the stdlib.
+func decodeStdlib(d *decodeState, rv reflect.Value) error {
+	bz, err := d.skipValue()
+	if err != nil {
+		return err
+	}
+	return decodeStdlibBytes(bz, rv)
+}
+
+func decodeStdlibBytes(bz []byte, rv reflect.Value) error {
+	// Make sure we are unmarshaling into a pointer.
+	target := reflect.New(rv.Type())
+	if err := json.Unmarshal(bz, target.Interface()); err != nil {
+		return err
+	}
+	rv.Set(target.Elem())
+	return nil
 }
@@ -39,6 +39,8 @@ func TestUnmarshal(t *testing.T) {
 		"string":              {`"foo"`, "foo", false},
 		"string noend":        {`"foo`, "foo", true},
 		"string ptr":          {`"string"`, &str, false},
+		"string escaped":      {`"caf\u00e9 \"bar\""`, `café "bar"`, false},
+		"string trailing":     {`"foo" "bar"`, "foo", true},
 		"slice byte":          {`"AQID"`, []byte{1, 2, 3}, false},
 		"slice bytes":         {`["AQID"]`, [][]byte{{1, 2, 3}}, false},
 		"slice int32":         {`[1,2,3]`, []int32{1, 2, 3}, false},
@@ -61,6 +63,7 @@ func TestUnmarshal(t *testing.T) {
 		"map int64 empty":     {`{}`, map[string]int64{}, false},
 		"map int64 null":      {`null`, map[string]int64(nil), false},
 		"map int key":         {`{}`, map[int]int{}, true},
+		"map escaped key":     {`{"\u0061":"1"}`, map[string]int64{"a": 1}, false},
 		"time":                {`"2020-06-03T17:35:30Z"`, time.Date(2020, 6, 3, 17, 35, 30, 0, time.UTC), false},
 		"time non-utc":        {`"2020-06-03T17:35:30+02:00"`, time.Time{}, true},
 		"time nozone":         {`"2020-06-03T17:35:30"`, time.Time{}, true},
@@ -2,30 +2,37 @@ package json
 
 import (
 	"bytes"
+	"encoding"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
+	"math"
 	"reflect"
+	"slices"
 	"strconv"
+	"sync"
 	"time"
+	"unicode/utf8"
 )
 
 var (
 	timeType            = reflect.TypeOf(time.Time{})
 	jsonMarshalerType   = reflect.TypeOf(new(json.Marshaler)).Elem()
 	jsonUnmarshalerType = reflect.TypeOf(new(json.Unmarshaler)).Elem()
+	textMarshalerType   = reflect.TypeOf(new(encoding.TextMarshaler)).Elem()
 )
 
 // Marshal marshals the value as JSON, using Amino-compatible JSON encoding (strings for
 // 64-bit numbers, and type wrappers for registered types).
 func Marshal(v interface{}) ([]byte, error) {
-	buf := new(bytes.Buffer)
-	err := encode(buf, v)
-	if err != nil {
+	e := encodeStatePool.Get().(*encodeState)
+	defer e.release()
+
+	if err := encode(e, v); err != nil {
 		return nil, err
 	}
-	return buf.Bytes(), nil
+	return bytes.Clone(e.buf), nil
 }
 
 // MarshalIndent marshals the value as JSON, using the given prefix and indentation.
@@ -42,10 +49,29 @@ func MarshalIndent(v interface{}, prefix, indent string) ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
-func encode(w io.Writer, v interface{}) error {
+// encodeState is the output of an encoding.
+type encodeState struct {
+	buf []byte
+}
+
+var encodeStatePool = sync.Pool{
+	New: func() interface{} { return new(encodeState) },
+}
+
+// release returns the state to the pool, unless its buffer grew too large to be kept.
+func (e *encodeState) release() {
+	if cap(e.buf) > 1<<20 {
+		return
+	}
+	e.buf = e.buf[:0]
+	encodeStatePool.Put(e)
+}
+
+func encode(e *encodeState, v interface{}) error {
 	// Bare nil values can't be reflected, so we must handle them here.
 	if v == nil {
-		return writeStr(w, "null")
+		e.buf = append(e.buf, "null"...)
+		return nil
 	}
 	rv := reflect.ValueOf(v)
 
@@ -54,201 +80,481 @@ func encode(w io.Writer, v interface{}) error {
 	// behavior in structs where an interface field will get the type wrapper while a bare value
 	// field will not.
 	if typeRegistry.name(rv.Type()) != "" {
-		return encodeReflectInterface(w, rv)
+		return encodeInterface(e, rv)
 	}
 
-	return encodeReflect(w, rv)
+	return typeEncoder(rv.Type())(e, rv)
 }
 
-func encodeReflect(w io.Writer, rv reflect.Value) error {
-	if !rv.IsValid() {
-		return errors.New("invalid reflect value")
+// encoderFunc encodes rv.
+type encoderFunc func(e *encodeState, rv reflect.Value) error
+
+// encoderCache caches the encoderFunc of each type.
+var encoderCache sync.Map // map[reflect.Type]encoderFunc
+
+// typeEncoder returns the encoder of a type, compiling it on first use.
+func typeEncoder(rt reflect.Type) encoderFunc {
+	if f, ok := encoderCache.Load(rt); ok {
+		return f.(encoderFunc)
+	}
+
+	// Recursive types refer to their own encoder while it is compiled, so we store an
+	// indirect encoder first, which waits for the compiled one.
+	var (
+		wg sync.WaitGroup
+		f  encoderFunc
+	)
+	wg.Add(1)
+	fi, loaded := encoderCache.LoadOrStore(rt, encoderFunc(func(e *encodeState, rv reflect.Value) error {
+		wg.Wait()
+		return f(e, rv)
+	}))
+	if loaded {
+		return fi.(encoderFunc)
 	}
 
+	f = newTypeEncoder(rt)
+	wg.Done()
+	encoderCache.Store(rt, f)
+	return f
+}
+
+func newTypeEncoder(rt reflect.Type) encoderFunc {
 	// Recursively dereference if pointer.
-	for rv.Kind() == reflect.Ptr {
-		if rv.IsNil() {
-			return writeStr(w, "null")
-		}
-		rv = rv.Elem()
+	if rt.Kind() == reflect.Ptr {
+		return newPtrEncoder(rt)
 	}
 
 	// Convert times to UTC.
-	if rv.Type() == timeType {
-		rv = reflect.ValueOf(rv.Interface().(time.Time).Round(0).UTC())
+	if rt == timeType {
+		return encodeTime
 	}
 
-	// If the value implements json.Marshaler, defer to stdlib directly. Since we've already
-	// dereferenced, we try implementations with both value receiver and pointer receiver. We must
-	// do this after the time normalization above, and thus after dereferencing.
-	if rv.Type().Implements(jsonMarshalerType) {
-		return encodeStdlib(w, rv.Interface())
-	} else if rv.CanAddr() && rv.Addr().Type().Implements(jsonMarshalerType) {
-		return encodeStdlib(w, rv.Addr().Interface())
+	// If the value implements json.Marshaler, defer to it. Since pointers were dereferenced,
+	// implementations with pointer receivers are used when the value is addressable.
+	if rt.Implements(jsonMarshalerType) {
+		if rt.Kind() == reflect.Interface {
+			return encodeStdlib
+		}
+		return encodeMarshaler
 	}
+	kindEncoder := newKindEncoder(rt)
+	if reflect.PointerTo(rt).Implements(jsonMarshalerType) {
+		return func(e *encodeState, rv reflect.Value) error {
+			if rv.CanAddr() {
+				return encodeMarshaler(e, rv)
+			}
+			return kindEncoder(e, rv)
+		}
+	}
+	return kindEncoder
+}
 
-	switch rv.Type().Kind() {
+func newKindEncoder(rt reflect.Type) encoderFunc {
+	switch rt.Kind() {
 	// Complex types must be recursively encoded.
 	case reflect.Interface:
-		return encodeReflectInterface(w, rv)
+		return encodeInterface
 
 	case reflect.Array, reflect.Slice:
-		return encodeReflectList(w, rv)
+		if rt.Elem().Kind() == reflect.Uint8 {
+			return newBytesEncoder(rt)
+		}
+		return newListEncoder(rt)
 
 	case reflect.Map:
-		return encodeReflectMap(w, rv)
+		return newMapEncoder(rt)
 
 	case reflect.Struct:
-		return encodeReflectStruct(w, rv)
+		return newStructEncoder(rt)
 
 	// 64-bit integers are emitted as strings, to avoid precision problems with e.g.
 	// Javascript which uses 64-bit floats (having 53-bit precision).
 	case reflect.Int64, reflect.Int:
-		return writeStr(w, `"`+strconv.FormatInt(rv.Int(), 10)+`"`)
+		return func(e *encodeState, rv reflect.Value) error {
+			e.buf = append(e.buf, '"')
+			e.buf = strconv.AppendInt(e.buf, rv.Int(), 10)
+			e.buf = append(e.buf, '"')
+			return nil
+		}
 
 	case reflect.Uint64, reflect.Uint:
-		return writeStr(w, `"`+strconv.FormatUint(rv.Uint(), 10)+`"`)
+		return func(e *encodeState, rv reflect.Value) error {
+			e.buf = append(e.buf, '"')
+			e.buf = strconv.AppendUint(e.buf, rv.Uint(), 10)
+			e.buf = append(e.buf, '"')
+			return nil
+		}
+	}
+
+	// Types implementing encoding.TextMarshaler are encoded by the stdlib.
+	if rt.Implements(textMarshalerType) {
+		return encodeStdlib
+	}
+	switch rt.Kind() {
+	case reflect.Bool:
+		return encodeBool
+	case reflect.String:
+		return encodeString
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return func(e *encodeState, rv reflect.Value) error {
+			e.buf = strconv.AppendInt(e.buf, rv.Int(), 10)
+			return nil
+		}
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uintptr:
+		return func(e *encodeState, rv reflect.Value) error {
+			e.buf = strconv.AppendUint(e.buf, rv.Uint(), 10)
+			return nil
+		}
+	case reflect.Float32, reflect.Float64:
+		return encodeFloat
+	}
 
 	// For everything else, defer to the stdlib encoding/json encoder
-	default:
-		return encodeStdlib(w, rv.Interface())
+	return encodeStdlib
+}
+
+func newPtrEncoder(rt reflect.Type) encoderFunc {
+	elemEncoder := typeEncoder(rt.Elem())
+	return func(e *encodeState, rv reflect.Value) error {
+		if rv.IsNil() {
+			e.buf = append(e.buf, "null"...)
+			return nil
+		}
+		return elemEncoder(e, rv.Elem())
 	}
 }
 
-func encodeReflectList(w io.Writer, rv reflect.Value) error {
-	// Emit nil slices as null.
-	if rv.Kind() == reflect.Slice && rv.IsNil() {
-		return writeStr(w, "null")
+func encodeTime(e *encodeState, rv reflect.Value) error {
+	var t time.Time
+	if rv.CanAddr() {
+		t = *rv.Addr().Interface().(*time.Time)
+	} else {
+		t = rv.Interface().(time.Time)
 	}
+	t = t.Round(0).UTC()
+	bz, err := t.MarshalJSON()
+	if err != nil {
+		// Defer to stdlib for the error.
+		return e.stdlib(t)
+	}
+	e.buf = append(e.buf, bz...)
+	return nil
+}
 
-	// Encode byte slices as base64 with the stdlib encoder.
-	if rv.Type().Elem().Kind() == reflect.Uint8 {
-		// Stdlib does not base64-encode byte arrays, only slices, so we copy to slice.
-		if rv.Type().Kind() == reflect.Array {
-			slice := reflect.MakeSlice(reflect.SliceOf(rv.Type().Elem()), rv.Len(), rv.Len())
-			reflect.Copy(slice, rv)
-			rv = slice
+// encodeMarshaler encodes a json.Marshaler, which may have a pointer receiver if rv is
+// addressable. Its output is used as is if the stdlib would not change it.
+func encodeMarshaler(e *encodeState, rv reflect.Value) error {
+	var m json.Marshaler
+	if rv.CanAddr() {
+		m = rv.Addr().Interface().(json.Marshaler)
+	} else {
+		m = rv.Interface().(json.Marshaler)
+	}
+	bz, err := m.MarshalJSON()
+	if err != nil || !isCompact(bz) || !json.Valid(bz) {
+		return e.stdlib(m)
+	}
+	e.buf = append(e.buf, bz...)
+	return nil
+}
+
+// isCompact returns true if the stdlib would neither compact nor escape the given JSON.
+func isCompact(bz []byte) bool {
+	for _, c := range bz {
+		switch c {
+		case ' ', '\t', '\n', '\r', '<', '>', '&', 0xe2: // 0xe2 starts U+2028 and U+2029
+			return false
 		}
-		return encodeStdlib(w, rv.Interface())
 	}
+	return true
+}
 
-	// Anything else we recursively encode ourselves.
-	length := rv.Len()
-	if err := writeStr(w, "["); err != nil {
-		return err
+func newBytesEncoder(rt reflect.Type) encoderFunc {
+	// Encode byte slices as base64 ourselves, unless the stdlib would encode them differently.
+	elemPtr := reflect.PointerTo(rt.Elem())
+	if elemPtr.Implements(jsonMarshalerType) || elemPtr.Implements(textMarshalerType) ||
+		(rt.Kind() == reflect.Slice && rt.Implements(textMarshalerType)) {
+		return func(e *encodeState, rv reflect.Value) error {
+			// Stdlib does not base64-encode byte arrays, only slices, so we copy to slice.
+			if rv.Kind() == reflect.Array {
+				slice := reflect.MakeSlice(reflect.SliceOf(rt.Elem()), rv.Len(), rv.Len())
+				reflect.Copy(slice, rv)
+				rv = slice
+			}
+			return encodeStdlib(e, rv)
+		}
 	}
-	for i := 0; i < length; i++ {
-		if err := encodeReflect(w, rv.Index(i)); err != nil {
-			return err
+
+	return func(e *encodeState, rv reflect.Value) error {
+		// Emit nil slices as null.
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			e.buf = append(e.buf, "null"...)
+			return nil
 		}
-		if i < length-1 {
-			if err := writeStr(w, ","); err != nil {
-				return err
+
+		// Arrays are base64-encoded as well, through a slice.
+		var bz []byte
+		switch {
+		case rv.Kind() == reflect.Slice:
+			bz = rv.Bytes()
+		case rv.CanAddr():
+			bz = rv.Slice(0, rv.Len()).Bytes()
+		default:
+			bz = make([]byte, rv.Len())
+			for i := range bz {
+				bz[i] = byte(rv.Index(i).Uint())
 			}
 		}
+
+		n := base64.StdEncoding.EncodedLen(len(bz))
+		e.buf = slices.Grow(e.buf, n+2)
+		e.buf = append(e.buf, '"')
+		base64.StdEncoding.Encode(e.buf[len(e.buf):len(e.buf)+n], bz)
+		e.buf = e.buf[:len(e.buf)+n]
+		e.buf = append(e.buf, '"')
+		return nil
 	}
-	return writeStr(w, "]")
 }
 
-func encodeReflectMap(w io.Writer, rv reflect.Value) error {
-	if rv.Type().Key().Kind() != reflect.String {
-		return errors.New("map key must be string")
+func newListEncoder(rt reflect.Type) encoderFunc {
+	elemEncoder := typeEncoder(rt.Elem())
+	return func(e *encodeState, rv reflect.Value) error {
+		// Emit nil slices as null.
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			e.buf = append(e.buf, "null"...)
+			return nil
+		}
+
+		e.buf = append(e.buf, '[')
+		for i := 0; i < rv.Len(); i++ {
+			if i > 0 {
+				e.buf = append(e.buf, ',')
+			}
+			if err := elemEncoder(e, rv.Index(i)); err != nil {
+				return err
+			}
+		}
+		e.buf = append(e.buf, ']')
+		return nil
 	}
+}
 
-	// nil maps are not emitted as nil, to retain Amino compatibility.
+func newMapEncoder(rt reflect.Type) encoderFunc {
+	if rt.Key().Kind() != reflect.String {
+		return func(e *encodeState, rv reflect.Value) error {
+			return errors.New("map key must be string")
+		}
+	}
 
-	if err := writeStr(w, "{"); err != nil {
-		return err
+	keyEncoder := encodeString
+	if rt.Key().Implements(jsonMarshalerType) || rt.Key().Implements(textMarshalerType) {
+		keyEncoder = encodeStdlib
 	}
-	writeComma := false
-	for _, keyrv := range rv.MapKeys() {
-		if writeComma {
-			if err := writeStr(w, ","); err != nil {
+	elemEncoder := typeEncoder(rt.Elem())
+
+	// nil maps are not emitted as nil, to retain Amino compatibility.
+	return func(e *encodeState, rv reflect.Value) error {
+		e.buf = append(e.buf, '{')
+		iter := rv.MapRange()
+		for i := 0; iter.Next(); i++ {
+			if i > 0 {
+				e.buf = append(e.buf, ',')
+			}
+			if err := keyEncoder(e, iter.Key()); err != nil {
+				return err
+			}
+			e.buf = append(e.buf, ':')
+			if err := elemEncoder(e, iter.Value()); err != nil {
 				return err
 			}
 		}
-		if err := encodeStdlib(w, keyrv.Interface()); err != nil {
-			return err
-		}
-		if err := writeStr(w, ":"); err != nil {
-			return err
-		}
-		if err := encodeReflect(w, rv.MapIndex(keyrv)); err != nil {
-			return err
-		}
-		writeComma = true
+		e.buf = append(e.buf, '}')
+		return nil
 	}
-	return writeStr(w, "}")
 }
 
-func encodeReflectStruct(w io.Writer, rv reflect.Value) error {
-	sInfo := makeStructInfo(rv.Type())
-	if err := writeStr(w, "{"); err != nil {
-		return err
-	}
-	writeComma := false
+// encodeField is a field of a struct, as encoded.
+type encodeField struct {
+	index     int
+	key       []byte // the JSON name and a colon
+	omitEmpty bool
+	encoder   encoderFunc
+}
+
+func newStructEncoder(rt reflect.Type) encoderFunc {
+	sInfo := makeStructInfo(rt)
+	fields := make([]encodeField, 0, len(sInfo.fields))
 	for i, fInfo := range sInfo.fields {
-		frv := rv.Field(i)
-		if fInfo.hidden || (fInfo.omitEmpty && frv.IsZero()) {
+		if fInfo.hidden {
 			continue
 		}
+		key, err := json.Marshal(fInfo.jsonName)
+		if err != nil {
+			panic(err) // can't happen for a string
+		}
+		fields = append(fields, encodeField{
+			index:     i,
+			key:       append(key, ':'),
+			omitEmpty: fInfo.omitEmpty,
+			encoder:   typeEncoder(rt.Field(i).Type),
+		})
+	}
 
-		if writeComma {
-			if err := writeStr(w, ","); err != nil {
+	return func(e *encodeState, rv reflect.Value) error {
+		e.buf = append(e.buf, '{')
+		writeComma := false
+		for _, field := range fields {
+			frv := rv.Field(field.index)
+			if field.omitEmpty && frv.IsZero() {
+				continue
+			}
+			if writeComma {
+				e.buf = append(e.buf, ',')
+			}
+			e.buf = append(e.buf, field.key...)
+			if err := field.encoder(e, frv); err != nil {
 				return err
 			}
+			writeComma = true
 		}
-		if err := encodeStdlib(w, fInfo.jsonName); err != nil {
-			return err
-		}
-		if err := writeStr(w, ":"); err != nil {
-			return err
-		}
-		if err := encodeReflect(w, frv); err != nil {
-			return err
-		}
-		writeComma = true
+		e.buf = append(e.buf, '}')
+		return nil
 	}
-	return writeStr(w, "}")
 }
 
-func encodeReflectInterface(w io.Writer, rv reflect.Value) error {
+func encodeInterface(e *encodeState, rv reflect.Value) error {
 	// Get concrete value and dereference pointers.
 	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
 		if rv.IsNil() {
-			return writeStr(w, "null")
+			e.buf = append(e.buf, "null"...)
+			return nil
 		}
 		rv = rv.Elem()
 	}
 
 	// Look up the name of the concrete type
-	name := typeRegistry.name(rv.Type())
-	if name == "" {
+	tInfo := typeRegistry.info(rv.Type())
+	if tInfo == nil {
 		return fmt.Errorf("cannot encode unregistered type %v", rv.Type())
 	}
 
 	// Write value wrapped in interface envelope
-	if err := writeStr(w, fmt.Sprintf(`{"type":%q,"value":`, name)); err != nil {
+	e.buf = append(e.buf, tInfo.prefix...)
+	if err := typeEncoder(rv.Type())(e, rv); err != nil {
 		return err
 	}
-	if err := encodeReflect(w, rv); err != nil {
-		return err
+	e.buf = append(e.buf, '}')
+	return nil
+}
+
+func encodeBool(e *encodeState, rv reflect.Value) error {
+	e.buf = strconv.AppendBool(e.buf, rv.Bool())
+	return nil
+}
+
+func encodeString(e *encodeState, rv reflect.Value) error {
+	s := rv.String()
+	start := len(e.buf)
+	var ok bool
+	if e.buf, ok = appendString(e.buf, s); !ok {
+		// Defer to stdlib for anything unusual.
+		e.buf = e.buf[:start]
+		return e.stdlib(s)
 	}
-	return writeStr(w, "}")
+	return nil
 }
 
-func encodeStdlib(w io.Writer, v interface{}) error {
+// appendString appends s as a JSON string, escaped like the stdlib does, and returns false if
+// s has characters that this does not handle: control characters other than \n, \r and \t,
+// invalid UTF-8, and U+2028 and U+2029.
+func appendString(buf []byte, s string) ([]byte, bool) {
+	buf = append(buf, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c >= utf8.RuneSelf {
+			r, size := utf8.DecodeRuneInString(s[i:])
+			if r == utf8.RuneError && size == 1 || r == '\u2028' || r == '\u2029' {
+				return buf, false
+			}
+			i += size
+			continue
+		}
+
+		var esc string
+		switch {
+		case c == '"':
+			esc = `\"`
+		case c == '\\':
+			esc = `\\`
+		case c == '\n':
+			esc = `\n`
+		case c == '\r':
+			esc = `\r`
+		case c == '\t':
+			esc = `\t`
+		case c == '<':
+			esc = `\u003c`
+		case c == '>':
+			esc = `\u003e`
+		case c == '&':
+			esc = `\u0026`
+		case c < 0x20:
+			return buf, false
+		default:
+			i++
+			continue
+		}
+		buf = append(buf, s[start:i]...)
+		buf = append(buf, esc...)
+		i++
+		start = i
+	}
+	buf = append(buf, s[start:]...)
+	return append(buf, '"'), true
+}
+
+// encodeFloat encodes floats like the stdlib.
+func encodeFloat(e *encodeState, rv reflect.Value) error {
+	f := rv.Float()
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		// Defer to stdlib for the error.
+		return encodeStdlib(e, rv)
+	}
+
+	bits := rv.Type().Bits()
+	abs := math.Abs(f)
+	format := byte('f')
+	if abs != 0 {
+		if bits == 64 && (abs < 1e-6 || abs >= 1e21) || bits == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+			format = 'e'
+		}
+	}
+	e.buf = strconv.AppendFloat(e.buf, f, format, -1, bits)
+	if format == 'e' {
+		// Clean up e-09 to e-9.
+		n := len(e.buf)
+		if n >= 4 && e.buf[n-4] == 'e' && e.buf[n-3] == '-' && e.buf[n-2] == '0' {
+			e.buf[n-2] = e.buf[n-1]
+			e.buf = e.buf[:n-1]
+		}
+	}
+	return nil
+}
+
+func encodeStdlib(e *encodeState, rv reflect.Value) error {
+	return e.stdlib(rv.Interface())
+}
+
+// stdlib encodes v with the stdlib encoding/json encoder.
+func (e *encodeState) stdlib(v interface{}) error {
 	// Doesn't stream the output because that adds a newline, as per:
 	// https://golang.org/pkg/encoding/json/#Encoder.Encode
 	blob, err := json.Marshal(v)
 	if err != nil {
 		return err
 	}
-	_, err = w.Write(blob)
-	return err
-}
-
-func writeStr(w io.Writer, s string) error {
-	_, err := w.Write([]byte(s))
-	return err
+	e.buf = append(e.buf, blob...)
+	return nil
 }
@@ -1,6 +1,8 @@
 package json_test
 
 import (
+	stdjson "encoding/json"
+	"math"
 	"testing"
 	"time"
 
@@ -102,3 +104,44 @@ func TestMarshal(t *testing.T) {
 		})
 	}
 }
+
+func TestMarshalStdlibCompat(t *testing.T) {
+	// Strings and floats are encoded like the stdlib does.
+	strs := []string{
+		"", "foo", `"quoted"`, `back\slash`, "line\nbreak\r\t", "<a href='x'>&amp;</a>", "\x00\x01\x1f\x7f",
+		"héllo wörld 😀", "  ", "invalid \xff utf-8", "truncated \xe2\x80", "\b\f/",
+	}
+	for _, s := range strs {
+		expect, err := stdjson.Marshal(s)
+		require.NoError(t, err)
+		bz, err := json.Marshal(s)
+		require.NoError(t, err)
+		assert.Equal(t, string(expect), string(bz), "%q", s)
+
+		bz, err = json.Marshal(map[string]string{s: s})
+		require.NoError(t, err)
+		assert.Equal(t, "{"+string(expect)+":"+string(expect)+"}", string(bz), "%q", s)
+	}
+
+	floats := []float64{
+		0, math.Copysign(0, -1), 1, -1.5, 3.14, 1e-6, 1e-7, 123456789e-15, 1e20, 1e21, 1.5e300,
+		math.MaxFloat64, math.SmallestNonzeroFloat64, math.MaxFloat32, 1.0 / 3,
+	}
+	for _, f := range floats {
+		values := []interface{}{f}
+		if !math.IsInf(float64(float32(f)), 0) {
+			values = append(values, float32(f))
+		}
+		for _, v := range values {
+			expect, err := stdjson.Marshal(v)
+			require.NoError(t, err)
+			bz, err := json.Marshal(v)
+			require.NoError(t, err)
+			assert.Equal(t, string(expect), string(bz), "%T %v", v, v)
+		}
+	}
+	_, err := json.Marshal(math.NaN())
+	require.Error(t, err)
+	_, err = json.Marshal(math.Inf(1))
+	require.Error(t, err)
+}
@@ -0,0 +1,303 @@
+package json
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+// maxNestingDepth is the maximum nesting depth of arrays and objects, as in encoding/json.
+const maxNestingDepth = 10000
+
+// decodeState reads JSON values from data, starting at off. The decoders are called with off at
+// the first byte of a value, and leave it right after the value.
+type decodeState struct {
+	data  []byte
+	off   int
+	depth int
+}
+
+// skipWS advances off past any whitespace.
+func (d *decodeState) skipWS() {
+	for d.off < len(d.data) {
+		switch d.data[d.off] {
+		case ' ', '\t', '\n', '\r':
+			d.off++
+		default:
+			return
+		}
+	}
+}
+
+// syntaxError returns an error for the byte at off, found while looking for what.
+func (d *decodeState) syntaxError(what string) error {
+	if d.off >= len(d.data) {
+		return errors.New("unexpected end of JSON input")
+	}
+	return fmt.Errorf("invalid character %q at offset %d looking for %s", d.data[d.off], d.off, what)
+}
+
+// literal consumes lit if it is next in the input.
+func (d *decodeState) literal(lit string) bool {
+	if len(d.data)-d.off < len(lit) || string(d.data[d.off:d.off+len(lit)]) != lit {
+		return false
+	}
+	d.off += len(lit)
+	return true
+}
+
+// enter and leave track the nesting depth of arrays and objects.
+func (d *decodeState) enter() error {
+	d.depth++
+	if d.depth > maxNestingDepth {
+		return errors.New("exceeded max depth")
+	}
+	return nil
+}
+
+func (d *decodeState) leave() {
+	d.depth--
+}
+
+// openObject consumes the start of an object.
+func (d *decodeState) openObject() error {
+	if d.off >= len(d.data) || d.data[d.off] != '{' {
+		return d.syntaxError("beginning of object")
+	}
+	d.off++
+	return d.enter()
+}
+
+// nextKey reads the key of the i-th member of an object, and leaves off at its value. more is
+// false once the end of the object was consumed. The key is returned as a raw string, see
+// scanString.
+func (d *decodeState) nextKey(i int) (key []byte, simple bool, more bool, err error) {
+	d.skipWS()
+	if d.off < len(d.data) && d.data[d.off] == '}' {
+		d.off++
+		d.leave()
+		return nil, false, false, nil
+	}
+	if i > 0 {
+		if d.off >= len(d.data) || d.data[d.off] != ',' {
+			return nil, false, false, d.syntaxError("comma after object value")
+		}
+		d.off++
+		d.skipWS()
+	}
+	if d.off >= len(d.data) || d.data[d.off] != '"' {
+		return nil, false, false, d.syntaxError("beginning of object key string")
+	}
+	if key, simple, err = d.scanString(); err != nil {
+		return nil, false, false, err
+	}
+	d.skipWS()
+	if d.off >= len(d.data) || d.data[d.off] != ':' {
+		return nil, false, false, d.syntaxError("colon after object key")
+	}
+	d.off++
+	d.skipWS()
+	return key, simple, true, nil
+}
+
+// openArray consumes the start of an array.
+func (d *decodeState) openArray() error {
+	if d.off >= len(d.data) || d.data[d.off] != '[' {
+		return d.syntaxError("beginning of array")
+	}
+	d.off++
+	return d.enter()
+}
+
+// nextElem leaves off at the i-th element of an array. more is false once the end of the array
+// was consumed.
+func (d *decodeState) nextElem(i int) (more bool, err error) {
+	d.skipWS()
+	if d.off < len(d.data) && d.data[d.off] == ']' {
+		d.off++
+		d.leave()
+		return false, nil
+	}
+	if i > 0 {
+		if d.off >= len(d.data) || d.data[d.off] != ',' {
+			return false, d.syntaxError("comma after array element")
+		}
+		d.off++
+		d.skipWS()
+	}
+	return true, nil
+}
+
+// scanString consumes a string, and returns it with its quotes. simple is true if its contents
+// are valid UTF-8 without escapes, so that they can be used as is; otherwise the string must be
+// decoded with unquote.
+func (d *decodeState) scanString() (raw []byte, simple bool, err error) {
+	start := d.off
+	d.off++ // opening quote
+	simple = true
+	ascii := true
+	for d.off < len(d.data) {
+		c := d.data[d.off]
+		switch {
+		case c == '"':
+			d.off++
+			raw = d.data[start:d.off]
+			if !ascii && simple && !utf8.Valid(raw) {
+				simple = false
+			}
+			return raw, simple, nil
+		case c == '\\':
+			simple = false
+			d.off++
+			if d.off >= len(d.data) {
+				return nil, false, d.syntaxError("escaped character")
+			}
+			switch d.data[d.off] {
+			case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+				d.off++
+			case 'u':
+				d.off++
+				for i := 0; i < 4; i++ {
+					if d.off >= len(d.data) || !isHex(d.data[d.off]) {
+						return nil, false, d.syntaxError("hexadecimal digit in \\u escape")
+					}
+					d.off++
+				}
+			default:
+				return nil, false, d.syntaxError("escaped character")
+			}
+		case c < 0x20:
+			return nil, false, d.syntaxError("string character")
+		default:
+			if c >= utf8.RuneSelf {
+				ascii = false
+			}
+			d.off++
+		}
+	}
+	return nil, false, d.syntaxError("end of string")
+}
+
+// unquote decodes a raw string returned by scanString.
+func unquote(raw []byte, simple bool) (string, error) {
+	if simple {
+		return string(raw[1 : len(raw)-1]), nil
+	}
+	var s string
+	err := json.Unmarshal(raw, &s)
+	return s, err
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// scanNumber consumes a number, and returns it. integer is true if it has neither a fraction
+// nor an exponent.
+func (d *decodeState) scanNumber() (num []byte, integer bool, err error) {
+	start := d.off
+	if d.off < len(d.data) && d.data[d.off] == '-' {
+		d.off++
+	}
+	switch {
+	case d.off < len(d.data) && d.data[d.off] == '0':
+		d.off++
+	case d.off < len(d.data) && isDigit(d.data[d.off]):
+		for d.off < len(d.data) && isDigit(d.data[d.off]) {
+			d.off++
+		}
+	default:
+		return nil, false, d.syntaxError("digit in numeric literal")
+	}
+	integer = true
+	if d.off < len(d.data) && d.data[d.off] == '.' {
+		integer = false
+		d.off++
+		if d.off >= len(d.data) || !isDigit(d.data[d.off]) {
+			return nil, false, d.syntaxError("digit after decimal point in numeric literal")
+		}
+		for d.off < len(d.data) && isDigit(d.data[d.off]) {
+			d.off++
+		}
+	}
+	if d.off < len(d.data) && (d.data[d.off] == 'e' || d.data[d.off] == 'E') {
+		integer = false
+		d.off++
+		if d.off < len(d.data) && (d.data[d.off] == '+' || d.data[d.off] == '-') {
+			d.off++
+		}
+		if d.off >= len(d.data) || !isDigit(d.data[d.off]) {
+			return nil, false, d.syntaxError("digit in exponent of numeric literal")
+		}
+		for d.off < len(d.data) && isDigit(d.data[d.off]) {
+			d.off++
+		}
+	}
+	return d.data[start:d.off], integer, nil
+}
+
+// isInteger returns true if b is a JSON number without fraction or exponent.
+func isInteger(b []byte) bool {
+	d := decodeState{data: b}
+	_, integer, err := d.scanNumber()
+	return err == nil && integer && d.off == len(b)
+}
+
+// skipValue consumes a value, checking that it is valid JSON, and returns it.
+func (d *decodeState) skipValue() ([]byte, error) {
+	start := d.off
+	if d.off >= len(d.data) {
+		return nil, d.syntaxError("beginning of value")
+	}
+	switch c := d.data[d.off]; {
+	case c == '{':
+		if err := d.openObject(); err != nil {
+			return nil, err
+		}
+		for i := 0; ; i++ {
+			_, _, more, err := d.nextKey(i)
+			if err != nil {
+				return nil, err
+			}
+			if !more {
+				break
+			}
+			if _, err := d.skipValue(); err != nil {
+				return nil, err
+			}
+		}
+	case c == '[':
+		if err := d.openArray(); err != nil {
+			return nil, err
+		}
+		for i := 0; ; i++ {
+			more, err := d.nextElem(i)
+			if err != nil {
+				return nil, err
+			}
+			if !more {
+				break
+			}
+			if _, err := d.skipValue(); err != nil {
+				return nil, err
+			}
+		}
+	case c == '"':
+		if _, _, err := d.scanString(); err != nil {
+			return nil, err
+		}
+	case c == '-' || isDigit(c):
+		if _, _, err := d.scanNumber(); err != nil {
+			return nil, err
+		}
+	case d.literal("true"), d.literal("false"), d.literal("null"):
+	default:
+		return nil, d.syntaxError("beginning of value")
+	}
+	return d.data[start:d.off], nil
+}
@@ -0,0 +1,53 @@
+package json
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// Decoder reads and decodes JSON values from an input stream, using the same encoding as
+// Unmarshal. The values can be decoded one at a time while walking through the enclosing arrays
+// and objects with Token and More, so that a large array is decoded without buffering it whole.
+//
+// Each value passed to Decode is still buffered before it is decoded, as required by the
+// decoding of registered types.
+type Decoder struct {
+	dec *json.Decoder
+}
+
+// NewDecoder returns a new decoder that reads from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{dec: json.NewDecoder(r)}
+}
+
+// Decode reads the next JSON value from the input and decodes it into v.
+func (d *Decoder) Decode(v interface{}) error {
+	var raw json.RawMessage
+	if err := d.dec.Decode(&raw); err != nil {
+		return err
+	}
+	return decode(raw, v)
+}
+
+// Token returns the next JSON token of the input, see encoding/json.Decoder.Token.
+func (d *Decoder) Token() (json.Token, error) {
+	return d.dec.Token()
+}
+
+// More reports whether there is another element in the current array or object being parsed.
+func (d *Decoder) More() bool {
+	return d.dec.More()
+}
+
+// Skip reads the next JSON value from the input and discards it.
+func (d *Decoder) Skip() error {
+	var raw json.RawMessage
+	return d.dec.Decode(&raw)
+}
+
+// Raw reads the next JSON value from the input and returns it undecoded.
+func (d *Decoder) Raw() (json.RawMessage, error) {
+	var raw json.RawMessage
+	err := d.dec.Decode(&raw)
+	return raw, err
+}
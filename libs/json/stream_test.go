@@ -0,0 +1,55 @@
+package json_test
+
+import (
+	stdjson "encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/strangelove-ventures/cometbft-client/libs/json"
+)
+
+func TestDecoder(t *testing.T) {
+	input := `{"height":"7","vehicles":[{"type":"vehicle/car","value":{"Wheels":4}},{"type":"vehicle/boat","value":{"Sail":true}}],"skipped":{"a":[1,2]}}`
+	dec := json.NewDecoder(strings.NewReader(input))
+
+	tok, err := dec.Token()
+	require.NoError(t, err)
+	require.Equal(t, stdjson.Delim('{'), tok)
+
+	var (
+		height   int64
+		vehicles []Vehicle
+	)
+	for dec.More() {
+		tok, err := dec.Token()
+		require.NoError(t, err)
+		switch tok {
+		case "height":
+			require.NoError(t, dec.Decode(&height))
+		case "vehicles":
+			_, err := dec.Token()
+			require.NoError(t, err)
+			for dec.More() {
+				var v Vehicle
+				require.NoError(t, dec.Decode(&v))
+				vehicles = append(vehicles, v)
+			}
+			_, err = dec.Token()
+			require.NoError(t, err)
+		default:
+			raw, err := dec.Raw()
+			require.NoError(t, err)
+			require.JSONEq(t, `{"a":[1,2]}`, string(raw))
+		}
+	}
+
+	require.EqualValues(t, 7, height)
+	require.Equal(t, []Vehicle{&Car{Wheels: 4}, Boat{Sail: true}}, vehicles)
+
+	// 64-bit integers must be strings, as with Unmarshal.
+	dec = json.NewDecoder(strings.NewReader(`7 "7"`))
+	require.Error(t, dec.Decode(&height))
+	require.NoError(t, dec.Skip())
+}
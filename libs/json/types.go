@@ -35,6 +35,7 @@ type typeInfo struct {
 	name      string
 	rt        reflect.Type
 	returnPtr bool
+	prefix    []byte // start of the type wrapper, up to the value
 }
 
 // types is a type registry. It is safe for concurrent use.
@@ -68,6 +69,7 @@ func (t *types) register(name string, rt reflect.Type) error {
 		name:      name,
 		rt:        rt,
 		returnPtr: returnPtr,
+		prefix:    []byte(fmt.Sprintf(`{"type":%q,"value":`, name)),
 	}
 
 	t.Lock()
@@ -99,11 +101,16 @@ func (t *types) name(rt reflect.Type) string {
 	for rt.Kind() == reflect.Ptr {
 		rt = rt.Elem()
 	}
-	t.RLock()
-	defer t.RUnlock()
-	tInfo := t.byType[rt]
+	tInfo := t.info(rt)
 	if tInfo == nil {
 		return ""
 	}
 	return tInfo.name
 }
+
+// info looks up the info of a type, or nil if not registered.
+func (t *types) info(rt reflect.Type) *typeInfo {
+	t.RLock()
+	defer t.RUnlock()
+	return t.byType[rt]
+}
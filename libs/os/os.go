@@ -6,6 +6,8 @@ import (
 	"io"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"runtime"
 	"syscall"
 
 	"github.com/strangelove-ventures/cometbft-client/libs/log"
@@ -77,6 +79,53 @@ func WriteFile(filePath string, contents []byte, mode os.FileMode) error {
 	return os.WriteFile(filePath, contents, mode)
 }
 
+// WriteFileAtomic writes contents to a temporary file in the directory of filePath, syncs it, then
+// renames it to filePath and syncs the directory, so that a crash leaves either the previous file
+// or the new one, never a truncated one.
+func WriteFileAtomic(filePath string, contents []byte, mode os.FileMode) error {
+	dir := filepath.Dir(filePath)
+	tmp, err := os.CreateTemp(dir, filepath.Base(filePath)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(contents); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), filePath); err != nil {
+		return err
+	}
+	return syncDir(dir)
+}
+
+// syncDir persists the entries of dir, such as a renamed file.
+func syncDir(dir string) error {
+	// Directories can't be synced on Windows, where a rename is durable once it returns.
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
 func MustWriteFile(filePath string, contents []byte, mode os.FileMode) {
 	err := WriteFile(filePath, contents, mode)
 	if err != nil {
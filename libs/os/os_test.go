@@ -111,3 +111,24 @@ func TestTrickedTruncation(t *testing.T) {
 		t.Fatalf("Oops, the WAL's content was changed :(\nGot:  %q\nWant: %q", reReadWAL, originalWALContent)
 	}
 }
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "file")
+
+	require.NoError(t, WriteFileAtomic(path, []byte("first"), 0o600))
+	require.NoError(t, WriteFileAtomic(path, []byte("second"), 0o600))
+
+	bz, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "second", string(bz))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	// The temporary files are removed.
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+}
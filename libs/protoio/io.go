@@ -0,0 +1,90 @@
+// Protocol Buffers for Go with Gadgets
+//
+// Copyright (c) 2013, The GoGo Authors. All rights reserved.
+// http://github.com/gogo/protobuf
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//     * Redistributions of source code must retain the above copyright
+// notice, this list of conditions and the following disclaimer.
+//     * Redistributions in binary form must reproduce the above
+// copyright notice, this list of conditions and the following disclaimer
+// in the documentation and/or other materials provided with the
+// distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+// OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+// LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+// DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+// THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+// Modified to return number of bytes written by Writer.WriteMsg(), and added byteReader.
+// Modified to read and write the trimmed messages of this module, which are not proto.Message.
+
+// Package protoio reads and writes varint-delimited Protobuf messages, as CometBFT does in
+// its p2p handshakes. It is modified from the original GoGo Protobuf to work with the trimmed
+// messages of this module, which are not proto.Message, to not buffer the reader, and to
+// return the number of bytes read or written.
+package protoio
+
+import (
+	"io"
+)
+
+// Message is a Protobuf message with the code generated by gogoproto to marshal it.
+type Message interface {
+	Size() int
+	MarshalTo(data []byte) (int, error)
+	Unmarshal(data []byte) error
+}
+
+type Writer interface {
+	WriteMsg(Message) (int, error)
+}
+
+type WriteCloser interface {
+	Writer
+	io.Closer
+}
+
+type Reader interface {
+	ReadMsg(msg Message) (int, error)
+}
+
+type ReadCloser interface {
+	Reader
+	io.Closer
+}
+
+// byteReader wraps an io.Reader and implements io.ByteReader, required by
+// binary.ReadUvarint(). Reading one byte at a time is extremely slow, but the
+// caller can wrap the underlying reader in a bufio.Reader if appropriate.
+type byteReader struct {
+	reader    io.Reader
+	buf       []byte
+	bytesRead int // keeps track of bytes read via ReadByte()
+}
+
+func newByteReader(r io.Reader) *byteReader {
+	return &byteReader{
+		reader: r,
+		buf:    make([]byte, 1),
+	}
+}
+
+func (r *byteReader) ReadByte() (byte, error) {
+	n, err := r.reader.Read(r.buf)
+	r.bytesRead += n
+	if err != nil {
+		return 0x00, err
+	}
+	return r.buf[0], nil
+}
@@ -0,0 +1,46 @@
+package protoio_test
+
+import (
+	"bytes"
+	"testing"
+
+	gogotypes "github.com/cosmos/gogoproto/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/strangelove-ventures/cometbft-client/libs/protoio"
+)
+
+func TestDelimited(t *testing.T) {
+	var buf bytes.Buffer
+	w := protoio.NewDelimitedWriter(&buf)
+	msgs := [][]byte{nil, []byte("short"), bytes.Repeat([]byte{0xab}, 300)}
+	total := 0
+	for _, bz := range msgs {
+		n, err := w.WriteMsg(&gogotypes.BytesValue{Value: bz})
+		require.NoError(t, err)
+		total += n
+	}
+	assert.Equal(t, buf.Len(), total)
+
+	r := protoio.NewDelimitedReader(bytes.NewReader(buf.Bytes()), 1024)
+	read := 0
+	for _, bz := range msgs {
+		var msg gogotypes.BytesValue
+		n, err := r.ReadMsg(&msg)
+		require.NoError(t, err)
+		assert.Equal(t, bz, msg.Value)
+		read += n
+	}
+	assert.Equal(t, total, read)
+
+	// The length is checked before the message is read.
+	bz, err := protoio.MarshalDelimited(&gogotypes.BytesValue{Value: msgs[2]})
+	require.NoError(t, err)
+	_, err = protoio.NewDelimitedReader(bytes.NewReader(bz), 100).ReadMsg(&gogotypes.BytesValue{})
+	assert.ErrorContains(t, err, "exceeds max size")
+
+	var msg gogotypes.BytesValue
+	require.NoError(t, protoio.UnmarshalDelimited(bz, &msg))
+	assert.Equal(t, msgs[2], msg.Value)
+}
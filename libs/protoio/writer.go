@@ -0,0 +1,78 @@
+// Protocol Buffers for Go with Gadgets
+//
+// Copyright (c) 2013, The GoGo Authors. All rights reserved.
+// http://github.com/gogo/protobuf
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//     * Redistributions of source code must retain the above copyright
+// notice, this list of conditions and the following disclaimer.
+//     * Redistributions in binary form must reproduce the above
+// copyright notice, this list of conditions and the following disclaimer
+// in the documentation and/or other materials provided with the
+// distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+// OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+// LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+// DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+// THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+// Modified from original GoGo Protobuf to return number of bytes written.
+
+package protoio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+)
+
+// NewDelimitedWriter writes a varint-delimited Protobuf message to a writer. It is
+// equivalent to the gogoproto NewDelimitedWriter, except WriteMsg() also returns the
+// number of bytes written. Each message is written with a single call to Write.
+func NewDelimitedWriter(w io.Writer) WriteCloser {
+	return &varintWriter{w, nil}
+}
+
+type varintWriter struct {
+	w      io.Writer
+	buffer []byte
+}
+
+func (w *varintWriter) WriteMsg(msg Message) (int, error) {
+	n := msg.Size()
+	if n+binary.MaxVarintLen64 >= len(w.buffer) {
+		w.buffer = make([]byte, n+binary.MaxVarintLen64)
+	}
+	lenOff := binary.PutUvarint(w.buffer, uint64(n))
+	if _, err := msg.MarshalTo(w.buffer[lenOff:]); err != nil {
+		return 0, err
+	}
+	_, err := w.w.Write(w.buffer[:lenOff+n])
+	return lenOff + n, err
+}
+
+func (w *varintWriter) Close() error {
+	if closer, ok := w.w.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+func MarshalDelimited(msg Message) ([]byte, error) {
+	var buf bytes.Buffer
+	_, err := NewDelimitedWriter(&buf).WriteMsg(msg)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
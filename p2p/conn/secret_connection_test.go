@@ -0,0 +1,110 @@
+package conn
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/strangelove-ventures/cometbft-client/crypto"
+	"github.com/strangelove-ventures/cometbft-client/crypto/ed25519"
+	"github.com/strangelove-ventures/cometbft-client/crypto/sr25519"
+)
+
+// makeSecretConnPair performs the handshake of both ends of an in-memory pipe.
+func makeSecretConnPair(t *testing.T, fooPrvKey, barPrvKey crypto.PrivKey) (*SecretConnection, *SecretConnection, error) {
+	fooConn, barConn := net.Pipe()
+	t.Cleanup(func() {
+		fooConn.Close()
+		barConn.Close()
+	})
+
+	type result struct {
+		sc  *SecretConnection
+		err error
+	}
+	fooc := make(chan result, 1)
+	go func() {
+		sc, err := MakeSecretConnection(fooConn, fooPrvKey)
+		if err != nil {
+			// Unblock the other end.
+			fooConn.Close()
+		}
+		fooc <- result{sc, err}
+	}()
+	barSecConn, err := MakeSecretConnection(barConn, barPrvKey)
+	if err != nil {
+		barConn.Close()
+	}
+	foo := <-fooc
+	if foo.err != nil {
+		return nil, nil, foo.err
+	}
+	return foo.sc, barSecConn, err
+}
+
+func TestSecretConnectionHandshake(t *testing.T) {
+	fooPrvKey, barPrvKey := ed25519.GenPrivKey(), ed25519.GenPrivKey()
+	fooSecConn, barSecConn, err := makeSecretConnPair(t, fooPrvKey, barPrvKey)
+	require.NoError(t, err)
+	assert.True(t, fooSecConn.RemotePubKey().Equals(barPrvKey.PubKey()))
+	assert.True(t, barSecConn.RemotePubKey().Equals(fooPrvKey.PubKey()))
+}
+
+func TestSecretConnectionReadWrite(t *testing.T) {
+	fooSecConn, barSecConn, err := makeSecretConnPair(t, ed25519.GenPrivKey(), ed25519.GenPrivKey())
+	require.NoError(t, err)
+
+	// More than a frame, and a frame exactly.
+	for _, size := range []int{1, dataMaxSize, 3*dataMaxSize + 17} {
+		msg := bytes.Repeat([]byte{byte(size)}, size)
+		errc := make(chan error, 1)
+		go func() {
+			_, err := fooSecConn.Write(msg)
+			errc <- err
+		}()
+		got := make([]byte, size)
+		_, err := io.ReadFull(barSecConn, got)
+		require.NoError(t, err)
+		require.NoError(t, <-errc)
+		assert.Equal(t, msg, got)
+	}
+}
+
+func TestDeriveSecretsAndChallenge(t *testing.T) {
+	// Produced by CometBFT v0.38 for the same DH secrets, from its golden test vectors.
+	for _, tc := range []struct {
+		dhSecret   string
+		locIsLeast bool
+		recv, send string
+	}{
+		{
+			"9fe4a5a73df12dbd8659b1d9280873fe993caefec6b0ebc2686dd65027148e03", true,
+			"80a83ad6afcb6f8175192e41973aed31dd75e3c106f813d986d9567a4865eb2f",
+			"96362a04f628a0666d9866147326898bb0847b8db8680263ad19e6336d4eed9e",
+		},
+		{
+			"0716764b370d543fee692af03832c16410f0a56e4ddb79604ea093b10bb6f654", false,
+			"84f2b1e8658456529a2c324f46c3406c3c6fecd5fbbf9169f60bed8956a8b03d",
+			"cba357ae33d7234520d5742102a2a6cdb39b7db59c14a58fa8aadd310127630f",
+		},
+	} {
+		var dhSecret [32]byte
+		bz, err := hex.DecodeString(tc.dhSecret)
+		require.NoError(t, err)
+		copy(dhSecret[:], bz)
+
+		recvSecret, sendSecret := deriveSecrets(&dhSecret, tc.locIsLeast)
+		assert.Equal(t, tc.recv, hex.EncodeToString(recvSecret[:]))
+		assert.Equal(t, tc.send, hex.EncodeToString(sendSecret[:]))
+	}
+}
+
+func TestNonEd25519Pubkey(t *testing.T) {
+	_, _, err := makeSecretConnPair(t, ed25519.GenPrivKey(), sr25519.GenPrivKey())
+	require.Error(t, err)
+}
@@ -2,17 +2,25 @@ package p2p
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"reflect"
 
 	cmtbytes "github.com/strangelove-ventures/cometbft-client/libs/bytes"
 	cmtstrings "github.com/strangelove-ventures/cometbft-client/libs/strings"
+	tmp2p "github.com/strangelove-ventures/cometbft-client/proto/tendermint/p2p"
 )
 
 const (
-	maxNumChannels = 16 // plenty of room for upgrades, for now
+	maxNodeInfoSize = 10240 // 10KB
+	maxNumChannels  = 16    // plenty of room for upgrades, for now
 )
 
+// Max size of the NodeInfo struct
+func MaxNodeInfoSize() int {
+	return maxNodeInfoSize
+}
+
 //-------------------------------------------------------------
 
 // NodeInfo exposes basic info of a node
@@ -209,3 +217,51 @@ func (info DefaultNodeInfo) NetAddress() (*NetAddress, error) {
 func (info DefaultNodeInfo) HasChannel(chID byte) bool {
 	return bytes.Contains(info.Channels, []byte{chID})
 }
+
+func (info DefaultNodeInfo) ToProto() *tmp2p.DefaultNodeInfo {
+
+	dni := new(tmp2p.DefaultNodeInfo)
+	dni.ProtocolVersion = tmp2p.ProtocolVersion{
+		P2P:   info.ProtocolVersion.P2P,
+		Block: info.ProtocolVersion.Block,
+		App:   info.ProtocolVersion.App,
+	}
+
+	dni.DefaultNodeID = string(info.DefaultNodeID)
+	dni.ListenAddr = info.ListenAddr
+	dni.Network = info.Network
+	dni.Version = info.Version
+	dni.Channels = info.Channels
+	dni.Moniker = info.Moniker
+	dni.Other = tmp2p.DefaultNodeInfoOther{
+		TxIndex:    info.Other.TxIndex,
+		RPCAddress: info.Other.RPCAddress,
+	}
+
+	return dni
+}
+
+func DefaultNodeInfoFromToProto(pb *tmp2p.DefaultNodeInfo) (DefaultNodeInfo, error) {
+	if pb == nil {
+		return DefaultNodeInfo{}, errors.New("nil node info")
+	}
+	dni := DefaultNodeInfo{
+		ProtocolVersion: ProtocolVersion{
+			P2P:   pb.ProtocolVersion.P2P,
+			Block: pb.ProtocolVersion.Block,
+			App:   pb.ProtocolVersion.App,
+		},
+		DefaultNodeID: ID(pb.DefaultNodeID),
+		ListenAddr:    pb.ListenAddr,
+		Network:       pb.Network,
+		Version:       pb.Version,
+		Channels:      pb.Channels,
+		Moniker:       pb.Moniker,
+		Other: DefaultNodeInfoOther{
+			TxIndex:    pb.Other.TxIndex,
+			RPCAddress: pb.Other.RPCAddress,
+		},
+	}
+
+	return dni, nil
+}
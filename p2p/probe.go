@@ -0,0 +1,182 @@
+package p2p
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/strangelove-ventures/cometbft-client/libs/protoio"
+	"github.com/strangelove-ventures/cometbft-client/p2p/conn"
+	tmp2p "github.com/strangelove-ventures/cometbft-client/proto/tendermint/p2p"
+)
+
+// The protocol versions of CometBFT v0.38.
+const (
+	p2pProtocol   uint64 = 8
+	blockProtocol uint64 = 11
+)
+
+// probeChannels are the channels of a CometBFT v0.38 full node: blocksync, consensus,
+// mempool, evidence, statesync and pex. A peer is compatible if it has one of them.
+var probeChannels = []byte{0x40, 0x20, 0x21, 0x22, 0x23, 0x30, 0x38, 0x60, 0x61, 0x00}
+
+// NewProbeNodeInfo returns a NodeInfo to probe the peers of network with, which they accept
+// as the one of a CometBFT v0.38 full node. Its listen address is not routable, so that the
+// peers do not gossip it.
+func NewProbeNodeInfo(nodeKey *NodeKey, network string) DefaultNodeInfo {
+	return DefaultNodeInfo{
+		ProtocolVersion: NewProtocolVersion(p2pProtocol, blockProtocol, 0),
+		DefaultNodeID:   nodeKey.ID(),
+		ListenAddr:      "tcp://0.0.0.0:26656",
+		Network:         network,
+		Channels:        append([]byte(nil), probeChannels...),
+		Moniker:         "probe",
+		Other: DefaultNodeInfoOther{
+			TxIndex: "off",
+		},
+	}
+}
+
+// ProbeOptions configures Probe.
+type ProbeOptions struct {
+	// DialTimeout is the maximum duration of the dial of the peer.
+	DialTimeout time.Duration
+	// HandshakeTimeout is the maximum duration of the handshake, from the secret connection
+	// to the exchange of the NodeInfo.
+	HandshakeTimeout time.Duration
+}
+
+// DefaultProbeOptions are the default options of Probe, the timeouts of CometBFT.
+var DefaultProbeOptions = ProbeOptions{
+	DialTimeout:      3 * time.Second,
+	HandshakeTimeout: 20 * time.Second,
+}
+
+// ProbeResult is what a peer reported of itself during the handshake.
+type ProbeResult struct {
+	// ID is the ID of the key the peer authenticated with.
+	ID ID
+	// NodeInfo is the NodeInfo of the peer, with its version, network and channels. It is
+	// valid, and has the ID of the peer.
+	NodeInfo DefaultNodeInfo
+	// Incompatible is the error of our NodeInfo's CompatibleWith for the peer's, nil if the
+	// peer is compatible. An incompatible peer disconnects us once the handshake is done.
+	Incompatible error
+	// Latency is the duration of the dial and of the handshake.
+	Latency time.Duration
+}
+
+// Probe dials addr, performs the handshake of the CometBFT P2P protocol authenticated with
+// nodeKey, sending nodeInfo, and disconnects. If addr has an ID, the peer must authenticate
+// with it. Failures of the handshake are returned as ErrRejected.
+func Probe(ctx context.Context, addr *NetAddress, nodeKey *NodeKey, nodeInfo DefaultNodeInfo, opts ProbeOptions) (*ProbeResult, error) {
+	start := time.Now()
+	dialer := net.Dialer{Timeout: opts.DialTimeout}
+	c, err := dialer.DialContext(ctx, "tcp", addr.DialString())
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+	// Interrupt the handshake when the context is done.
+	stop := context.AfterFunc(ctx, func() { c.Close() })
+	defer stop()
+
+	sc, peerInfo, err := Handshake(c, nodeKey, nodeInfo, opts.HandshakeTimeout)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		return nil, err
+	}
+	latency := time.Since(start)
+
+	connID := PubKeyToID(sc.RemotePubKey())
+	if addr.ID != "" && addr.ID != connID {
+		return nil, ErrRejected{
+			conn:          c,
+			id:            connID,
+			err:           fmt.Errorf("conn.ID (%v) dialed ID (%v) mismatch", connID, addr.ID),
+			isAuthFailure: true,
+		}
+	}
+
+	return &ProbeResult{
+		ID:           connID,
+		NodeInfo:     peerInfo,
+		Incompatible: nodeInfo.CompatibleWith(peerInfo),
+		Latency:      latency,
+	}, nil
+}
+
+// Handshake upgrades c to a secret connection authenticated with nodeKey, and exchanges
+// nodeInfo with the peer, within timeout. It returns the secret connection and the NodeInfo
+// of the peer, which is checked to be valid and to have the ID of the key the peer
+// authenticated with. It is for both ends of the connection, and does not check their
+// compatibility.
+func Handshake(c net.Conn, nodeKey *NodeKey, nodeInfo DefaultNodeInfo, timeout time.Duration) (*conn.SecretConnection, DefaultNodeInfo, error) {
+	if err := c.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, DefaultNodeInfo{}, err
+	}
+
+	sc, err := conn.MakeSecretConnection(c, nodeKey.PrivKey)
+	if err != nil {
+		return nil, DefaultNodeInfo{}, ErrRejected{
+			conn:          c,
+			err:           fmt.Errorf("secret conn failed: %v", err),
+			isAuthFailure: true,
+		}
+	}
+	connID := PubKeyToID(sc.RemotePubKey())
+
+	peerInfo, err := exchangeNodeInfo(sc, nodeInfo)
+	if err != nil {
+		return nil, DefaultNodeInfo{}, ErrRejected{
+			conn:          c,
+			id:            connID,
+			err:           fmt.Errorf("handshake failed: %v", err),
+			isAuthFailure: true,
+		}
+	}
+	if err := peerInfo.Validate(); err != nil {
+		return nil, DefaultNodeInfo{}, ErrRejected{
+			conn:              c,
+			err:               err,
+			id:                peerInfo.ID(),
+			isNodeInfoInvalid: true,
+		}
+	}
+	// Ensure connection key matches self reported key.
+	if connID != peerInfo.ID() {
+		return nil, DefaultNodeInfo{}, ErrRejected{
+			conn: c,
+			id:   connID,
+			err: fmt.Errorf(
+				"conn.ID (%v) NodeInfo.ID (%v) mismatch",
+				connID,
+				peerInfo.ID(),
+			),
+			isAuthFailure: true,
+		}
+	}
+
+	return sc, peerInfo, c.SetDeadline(time.Time{})
+}
+
+// exchangeNodeInfo sends nodeInfo and receives the one of the peer in tandem.
+func exchangeNodeInfo(sc *conn.SecretConnection, nodeInfo DefaultNodeInfo) (DefaultNodeInfo, error) {
+	errc := make(chan error, 1)
+	go func() {
+		_, err := protoio.NewDelimitedWriter(sc).WriteMsg(nodeInfo.ToProto())
+		errc <- err
+	}()
+
+	var pbpeerNodeInfo tmp2p.DefaultNodeInfo
+	if _, err := protoio.NewDelimitedReader(sc, MaxNodeInfoSize()).ReadMsg(&pbpeerNodeInfo); err != nil {
+		return DefaultNodeInfo{}, err
+	}
+	if err := <-errc; err != nil {
+		return DefaultNodeInfo{}, err
+	}
+	return DefaultNodeInfoFromToProto(&pbpeerNodeInfo)
+}
@@ -0,0 +1,116 @@
+package p2p
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/strangelove-ventures/cometbft-client/crypto/ed25519"
+)
+
+// listenPeer accepts connections on loopback, and performs the handshake of each with
+// nodeKey and the NodeInfo returned by makeInfo for the listen address.
+func listenPeer(t *testing.T, nodeKey *NodeKey, makeInfo func(listenAddr string) DefaultNodeInfo) *NetAddress {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	nodeInfo := makeInfo(ln.Addr().String())
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer c.Close()
+				_, _, _ = Handshake(c, nodeKey, nodeInfo, time.Second)
+			}()
+		}
+	}()
+
+	addr, err := NewNetAddressString(IDAddressString(nodeKey.ID(), ln.Addr().String()))
+	require.NoError(t, err)
+	return addr
+}
+
+func TestProbe(t *testing.T) {
+	peerKey := &NodeKey{PrivKey: ed25519.GenPrivKey()}
+	addr := listenPeer(t, peerKey, func(listenAddr string) DefaultNodeInfo {
+		info := NewProbeNodeInfo(peerKey, "testing")
+		info.ListenAddr = "tcp://" + listenAddr
+		info.Version = "0.38.2"
+		info.Channels = []byte{0x40, 0x20}
+		info.Moniker = "sentry"
+		return info
+	})
+
+	nodeKey := &NodeKey{PrivKey: ed25519.GenPrivKey()}
+	ctx := context.Background()
+	res, err := Probe(ctx, addr, nodeKey, NewProbeNodeInfo(nodeKey, "testing"), DefaultProbeOptions)
+	require.NoError(t, err)
+	assert.Equal(t, peerKey.ID(), res.ID)
+	assert.Equal(t, "0.38.2", res.NodeInfo.Version)
+	assert.Equal(t, "sentry", res.NodeInfo.Moniker)
+	assert.Equal(t, "testing", res.NodeInfo.Network)
+	assert.True(t, res.NodeInfo.HasChannel(0x40))
+	assert.NoError(t, res.Incompatible)
+	assert.Positive(t, res.Latency)
+
+	// The peer of another network is reported as incompatible.
+	res, err = Probe(ctx, addr, nodeKey, NewProbeNodeInfo(nodeKey, "other"), DefaultProbeOptions)
+	require.NoError(t, err)
+	assert.ErrorContains(t, res.Incompatible, "different network")
+
+	// The peer must have the dialed ID.
+	wrongAddr := *addr
+	wrongAddr.ID = nodeKey.ID()
+	_, err = Probe(ctx, &wrongAddr, nodeKey, NewProbeNodeInfo(nodeKey, "testing"), DefaultProbeOptions)
+	var rejected ErrRejected
+	require.ErrorAs(t, err, &rejected)
+	assert.True(t, rejected.IsAuthFailure())
+}
+
+func TestProbeInvalidNodeInfo(t *testing.T) {
+	peerKey := &NodeKey{PrivKey: ed25519.GenPrivKey()}
+	otherKey := &NodeKey{PrivKey: ed25519.GenPrivKey()}
+	addr := listenPeer(t, peerKey, func(listenAddr string) DefaultNodeInfo {
+		// The NodeInfo of another node.
+		info := NewProbeNodeInfo(otherKey, "testing")
+		info.ListenAddr = listenAddr
+		return info
+	})
+
+	nodeKey := &NodeKey{PrivKey: ed25519.GenPrivKey()}
+	_, err := Probe(context.Background(), addr, nodeKey, NewProbeNodeInfo(nodeKey, "testing"), DefaultProbeOptions)
+	var rejected ErrRejected
+	require.ErrorAs(t, err, &rejected)
+	assert.True(t, rejected.IsAuthFailure())
+	assert.ErrorContains(t, err, "NodeInfo.ID")
+}
+
+func TestProbeContext(t *testing.T) {
+	// A peer which accepts the connection but never answers.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	go func() {
+		c, err := ln.Accept()
+		if err == nil {
+			defer c.Close()
+			time.Sleep(time.Second)
+		}
+	}()
+	tcpAddr := ln.Addr().(*net.TCPAddr)
+	addr := NewNetAddressIPPort(tcpAddr.IP, uint16(tcpAddr.Port))
+
+	nodeKey := &NodeKey{PrivKey: ed25519.GenPrivKey()}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = Probe(ctx, addr, nodeKey, NewProbeNodeInfo(nodeKey, "testing"), DefaultProbeOptions)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,137 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+
+	rpcclient "github.com/strangelove-ventures/cometbft-client/rpc/client"
+	ctypes "github.com/strangelove-ventures/cometbft-client/rpc/core/types"
+	jsonrpcclient "github.com/strangelove-ventures/cometbft-client/rpc/jsonrpc/client"
+)
+
+var _ rpcclient.BlockBatcher = (*Client)(nil)
+
+// NewBlockBatch returns a batch which serves the cached responses, and queues the others in a
+// batch of the decorated client. It returns nil if the decorated client can't batch requests.
+func (c *Client) NewBlockBatch() rpcclient.BlockBatch {
+	batcher, ok := c.Client.(rpcclient.BlockBatcher)
+	if !ok {
+		return nil
+	}
+	next := batcher.NewBlockBatch()
+	if next == nil {
+		return nil
+	}
+	return &blockBatch{c: c, next: next}
+}
+
+// blockBatch is the batch of a Client. The responses of the misses are cached once the batch
+// is sent.
+type blockBatch struct {
+	c    *Client
+	next rpcclient.BlockBatch
+	// queued are the requests of the batch, in order.
+	queued []batchRequest
+	// n is the number of requests queued in next.
+	n int
+}
+
+// batchRequest is a request of the batch, served from the cache or queued in the batch of the
+// decorated client.
+type batchRequest struct {
+	// res is the cached response, or the result of the queued request.
+	res any
+	// index is the index of the request in the batch of the decorated client, -1 if its
+	// response was cached.
+	index int
+	// key is the key of the response of a queued request, if it is cacheable.
+	key       key
+	cacheable bool
+}
+
+func (b *blockBatch) Block(ctx context.Context, height *int64) (*ctypes.ResultBlock, error) {
+	if height == nil {
+		return enqueue(b, key{}, false, func() (*ctypes.ResultBlock, error) {
+			return b.next.Block(ctx, height)
+		})
+	}
+	return batched(ctx, b, key{*height, "block"}, func() (*ctypes.ResultBlock, error) {
+		return b.next.Block(ctx, height)
+	})
+}
+
+func (b *blockBatch) BlockResults(ctx context.Context, height *int64) (*ctypes.ResultBlockResults, error) {
+	if height == nil {
+		return enqueue(b, key{}, false, func() (*ctypes.ResultBlockResults, error) {
+			return b.next.BlockResults(ctx, height)
+		})
+	}
+	return batched(ctx, b, key{*height, "block_results"}, func() (*ctypes.ResultBlockResults, error) {
+		return b.next.BlockResults(ctx, height)
+	})
+}
+
+// Send sends the requests of the misses, and caches their responses. It returns the result of
+// every queued request in order, the cached ones included, and sends nothing if every response
+// was cached. The batch is empty afterwards.
+func (b *blockBatch) Send(ctx context.Context) ([]jsonrpcclient.BatchResult, error) {
+	defer func() {
+		b.queued, b.n = nil, 0
+	}()
+
+	var sent []jsonrpcclient.BatchResult
+	if b.n > 0 {
+		var err error
+		if sent, err = b.next.Send(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	results := make([]jsonrpcclient.BatchResult, len(b.queued))
+	for i, req := range b.queued {
+		switch {
+		case req.index < 0:
+			results[i] = jsonrpcclient.BatchResult{Result: req.res}
+		case req.index >= len(sent):
+			results[i] = jsonrpcclient.BatchResult{
+				Result: req.res,
+				Err:    fmt.Errorf("%w for request %d", jsonrpcclient.ErrNoBatchResponse, i),
+			}
+		default:
+			results[i] = sent[req.index]
+			if req.cacheable && results[i].Err == nil {
+				if err := b.c.put(ctx, req.key, req.res); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return results, nil
+}
+
+// batched returns the cached response for k, or queues the request of queue, whose response
+// is cached once the batch is sent.
+func batched[T any](ctx context.Context, b *blockBatch, k key, queue func() (*T, error)) (*T, error) {
+	res, err := lookup[T](ctx, b.c, k)
+	if err != nil {
+		return nil, err
+	}
+	if res != nil {
+		b.queued = append(b.queued, batchRequest{res: res, index: -1})
+		return res, nil
+	}
+
+	b.c.misses.Add(1)
+	return enqueue(b, k, true, queue)
+}
+
+// enqueue queues the request of queue in the batch of the decorated client.
+func enqueue[T any](b *blockBatch, k key, cacheable bool, queue func() (*T, error)) (*T, error) {
+	res, err := queue()
+	if err != nil {
+		return nil, err
+	}
+	b.queued = append(b.queued, batchRequest{res: res, index: b.n, key: k, cacheable: cacheable})
+	b.n++
+	return res, nil
+}
@@ -0,0 +1,285 @@
+// Package cache provides a decorator of rpcclient.Client caching the responses that never change
+// once their height is committed: Block, BlockResults, Commit, Header, Validators and Tx.
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	_ "github.com/strangelove-ventures/cometbft-client/crypto/encoding"
+	cmtjson "github.com/strangelove-ventures/cometbft-client/libs/json"
+	rpcclient "github.com/strangelove-ventures/cometbft-client/rpc/client"
+	ctypes "github.com/strangelove-ventures/cometbft-client/rpc/core/types"
+)
+
+// Options configures the cache.
+type Options struct {
+	// MaxBytes is the maximum size of the encoded responses kept in memory.
+	// 0 means DefaultOptions.MaxBytes.
+	MaxBytes int64
+	// Dir is the directory where the responses are also stored on disk. They are not if it is empty.
+	Dir string
+	// ChainID is the chain ID under which the responses are stored on disk.
+	// It is fetched with Status on the first use if empty.
+	ChainID string
+}
+
+// DefaultOptions are the default options of the cache.
+var DefaultOptions = Options{
+	MaxBytes: 64 << 20, // 64 MiB
+}
+
+// Stats are the counters of the cache.
+type Stats struct {
+	// Hits is the number of responses served from the cache, including DiskHits.
+	Hits uint64
+	// DiskHits is the number of responses served from the disk store.
+	DiskHits uint64
+	// Misses is the number of cacheable requests sent to the node.
+	Misses uint64
+	// Evictions is the number of responses evicted from memory.
+	Evictions uint64
+	// Entries and Bytes are the number and size of the responses kept in memory.
+	Entries int
+	Bytes   int64
+}
+
+// key identifies a response.
+type key struct {
+	// height is 0 for the transactions, which are looked up by hash.
+	height int64
+	name   string
+}
+
+func (k key) String() string {
+	return k.name + "@" + strconv.FormatInt(k.height, 10)
+}
+
+// Client is a rpcclient.Client caching the responses at committed heights. Requests for the
+// latest height, i.e. with a nil height, are never cached, and neither are the errors nor
+// the commits which are not canonical yet.
+//
+// The cached responses are kept encoded, so they can't be modified by the callers.
+type Client struct {
+	rpcclient.Client
+
+	mem   *lru
+	store *store
+
+	mtx     sync.Mutex
+	chainID string
+
+	hits     atomic.Uint64
+	diskHits atomic.Uint64
+	misses   atomic.Uint64
+}
+
+var _ rpcclient.Client = (*Client)(nil)
+
+// New returns a client caching the responses of c.
+func New(c rpcclient.Client, opts Options) (*Client, error) {
+	if opts.MaxBytes < 0 {
+		return nil, errors.New("max bytes can't be negative")
+	}
+	if opts.MaxBytes == 0 {
+		opts.MaxBytes = DefaultOptions.MaxBytes
+	}
+	if opts.ChainID != "" && !validChainID(opts.ChainID) {
+		return nil, fmt.Errorf("chain ID %q can't be used as a directory name", opts.ChainID)
+	}
+
+	cc := &Client{
+		Client:  c,
+		mem:     newLRU(opts.MaxBytes),
+		chainID: opts.ChainID,
+	}
+	if opts.Dir != "" {
+		cc.store = &store{dir: opts.Dir}
+	}
+	return cc, nil
+}
+
+// Stats returns the counters of the cache.
+func (c *Client) Stats() Stats {
+	entries, bytes, evicted := c.mem.stats()
+	return Stats{
+		Hits:      c.hits.Load(),
+		DiskHits:  c.diskHits.Load(),
+		Misses:    c.misses.Load(),
+		Evictions: evicted,
+		Entries:   entries,
+		Bytes:     bytes,
+	}
+}
+
+func (c *Client) Block(ctx context.Context, height *int64) (*ctypes.ResultBlock, error) {
+	if height == nil {
+		return c.Client.Block(ctx, height)
+	}
+	return cached(ctx, c, key{*height, "block"}, func() (*ctypes.ResultBlock, bool, error) {
+		res, err := c.Client.Block(ctx, height)
+		return res, true, err
+	})
+}
+
+func (c *Client) BlockResults(ctx context.Context, height *int64) (*ctypes.ResultBlockResults, error) {
+	if height == nil {
+		return c.Client.BlockResults(ctx, height)
+	}
+	return cached(ctx, c, key{*height, "block_results"}, func() (*ctypes.ResultBlockResults, bool, error) {
+		res, err := c.Client.BlockResults(ctx, height)
+		return res, true, err
+	})
+}
+
+func (c *Client) Header(ctx context.Context, height *int64) (*ctypes.ResultHeader, error) {
+	if height == nil {
+		return c.Client.Header(ctx, height)
+	}
+	return cached(ctx, c, key{*height, "header"}, func() (*ctypes.ResultHeader, bool, error) {
+		res, err := c.Client.Header(ctx, height)
+		return res, true, err
+	})
+}
+
+// Commit caches the commit once it is canonical, i.e. once the next block was committed.
+// Until then, the node returns the commit it has seen, which may include other signatures.
+func (c *Client) Commit(ctx context.Context, height *int64) (*ctypes.ResultCommit, error) {
+	if height == nil {
+		return c.Client.Commit(ctx, height)
+	}
+	return cached(ctx, c, key{*height, "commit"}, func() (*ctypes.ResultCommit, bool, error) {
+		res, err := c.Client.Commit(ctx, height)
+		return res, err == nil && res.CanonicalCommit, err
+	})
+}
+
+func (c *Client) Validators(ctx context.Context, height *int64, page, perPage *int) (*ctypes.ResultValidators, error) {
+	if height == nil {
+		return c.Client.Validators(ctx, height, page, perPage)
+	}
+	name := fmt.Sprintf("validators-%d-%d", intOrZero(page), intOrZero(perPage))
+	return cached(ctx, c, key{*height, name}, func() (*ctypes.ResultValidators, bool, error) {
+		res, err := c.Client.Validators(ctx, height, page, perPage)
+		return res, true, err
+	})
+}
+
+func (c *Client) Tx(ctx context.Context, hash []byte, prove bool) (*ctypes.ResultTx, error) {
+	name := fmt.Sprintf("tx-%X", hash)
+	if prove {
+		name += "-prove"
+	}
+	return cached(ctx, c, key{0, name}, func() (*ctypes.ResultTx, bool, error) {
+		res, err := c.Client.Tx(ctx, hash, prove)
+		return res, true, err
+	})
+}
+
+// cached returns the cached response for k, or the response of fetch, which is cached if it
+// succeeded and is final. Failures of the disk store are returned, since they would otherwise
+// silently turn every request into a miss.
+func cached[T any](ctx context.Context, c *Client, k key, fetch func() (*T, bool, error)) (*T, error) {
+	res, err := lookup[T](ctx, c, k)
+	if res != nil || err != nil {
+		return res, err
+	}
+
+	c.misses.Add(1)
+	res, final, err := fetch()
+	if err != nil || !final {
+		return res, err
+	}
+	if err := c.put(ctx, k, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// lookup returns the cached response for k, or nil if it is not cached.
+func lookup[T any](ctx context.Context, c *Client, k key) (*T, error) {
+	if bz, ok := c.mem.get(k.String()); ok {
+		c.hits.Add(1)
+		return decode[T](bz)
+	}
+
+	if c.store != nil {
+		chainID, err := c.getChainID(ctx)
+		if err != nil {
+			return nil, err
+		}
+		bz, err := c.store.get(chainID, k)
+		if err != nil {
+			return nil, err
+		}
+		if bz != nil {
+			c.hits.Add(1)
+			c.diskHits.Add(1)
+			c.mem.add(k.String(), bz)
+			return decode[T](bz)
+		}
+	}
+	return nil, nil
+}
+
+// put caches the response res for k.
+func (c *Client) put(ctx context.Context, k key, res any) error {
+	bz, err := cmtjson.Marshal(res)
+	if err != nil {
+		return err
+	}
+	c.mem.add(k.String(), bz)
+	if c.store != nil {
+		chainID, err := c.getChainID(ctx)
+		if err != nil {
+			return err
+		}
+		if err := c.store.put(chainID, k, bz); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func decode[T any](bz []byte) (*T, error) {
+	res := new(T)
+	if err := cmtjson.Unmarshal(bz, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (c *Client) getChainID(ctx context.Context) (string, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if c.chainID != "" {
+		return c.chainID, nil
+	}
+
+	status, err := c.Client.Status(ctx)
+	if err != nil {
+		return "", fmt.Errorf("fetch chain ID: %w", err)
+	}
+	if !validChainID(status.NodeInfo.Network) {
+		return "", fmt.Errorf("chain ID %q can't be used as a directory name", status.NodeInfo.Network)
+	}
+	c.chainID = status.NodeInfo.Network
+	return c.chainID, nil
+}
+
+func validChainID(chainID string) bool {
+	return chainID != "" && chainID != "." && chainID != ".." && filepath.Base(chainID) == chainID
+}
+
+func intOrZero(i *int) int {
+	if i == nil {
+		return 0
+	}
+	return *i
+}
@@ -0,0 +1,284 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/strangelove-ventures/cometbft-client/p2p"
+	rpcclient "github.com/strangelove-ventures/cometbft-client/rpc/client"
+	ctypes "github.com/strangelove-ventures/cometbft-client/rpc/core/types"
+	jsonrpcclient "github.com/strangelove-ventures/cometbft-client/rpc/jsonrpc/client"
+	"github.com/strangelove-ventures/cometbft-client/types"
+)
+
+// countingClient serves blocks and commits, counting the requests.
+type countingClient struct {
+	rpcclient.Client
+
+	latest   int64
+	requests map[string]int
+}
+
+func newCountingClient(latest int64) *countingClient {
+	return &countingClient{latest: latest, requests: make(map[string]int)}
+}
+
+func (c *countingClient) Status(context.Context) (*ctypes.ResultStatus, error) {
+	c.requests["status"]++
+	return &ctypes.ResultStatus{NodeInfo: p2p.DefaultNodeInfo{Network: "test-chain"}}, nil
+}
+
+func (c *countingClient) Block(_ context.Context, height *int64) (*ctypes.ResultBlock, error) {
+	c.requests["block"]++
+	h := c.latest
+	if height != nil {
+		h = *height
+	}
+	if h > c.latest {
+		return nil, errors.New("height must be less than or equal to the current blockchain height")
+	}
+	return &ctypes.ResultBlock{Block: &types.Block{Header: types.Header{ChainID: "test-chain", Height: h}}}, nil
+}
+
+func (c *countingClient) Commit(_ context.Context, height *int64) (*ctypes.ResultCommit, error) {
+	c.requests["commit"]++
+	return &ctypes.ResultCommit{
+		SignedHeader:    types.SignedHeader{Header: &types.Header{Height: *height}},
+		CanonicalCommit: *height < c.latest,
+	}, nil
+}
+
+func (c *countingClient) Tx(_ context.Context, hash []byte, _ bool) (*ctypes.ResultTx, error) {
+	c.requests["tx"]++
+	return &ctypes.ResultTx{Hash: hash, Height: 3}, nil
+}
+
+func TestCache(t *testing.T) {
+	node := newCountingClient(10)
+	c, err := New(node, DefaultOptions)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	height := int64(5)
+	for i := 0; i < 3; i++ {
+		res, err := c.Block(ctx, &height)
+		require.NoError(t, err)
+		require.Equal(t, height, res.Block.Height)
+	}
+	require.Equal(t, 1, node.requests["block"])
+
+	// The cached responses can't be modified by the callers.
+	res, err := c.Block(ctx, &height)
+	require.NoError(t, err)
+	res.Block.Height = 42
+	res, err = c.Block(ctx, &height)
+	require.NoError(t, err)
+	require.Equal(t, height, res.Block.Height)
+
+	// The latest height is never cached, nor are the errors.
+	for i := 0; i < 2; i++ {
+		_, err := c.Block(ctx, nil)
+		require.NoError(t, err)
+	}
+	future := int64(11)
+	for i := 0; i < 2; i++ {
+		_, err := c.Block(ctx, &future)
+		require.Error(t, err)
+	}
+	require.Equal(t, 1+2+2, node.requests["block"])
+
+	// The commit of the latest height is not canonical yet.
+	latest := int64(10)
+	for i := 0; i < 2; i++ {
+		_, err := c.Commit(ctx, &latest)
+		require.NoError(t, err)
+		_, err = c.Commit(ctx, &height)
+		require.NoError(t, err)
+	}
+	require.Equal(t, 2+1, node.requests["commit"])
+
+	_, err = c.Tx(ctx, []byte{1}, false)
+	require.NoError(t, err)
+	_, err = c.Tx(ctx, []byte{1}, false)
+	require.NoError(t, err)
+	_, err = c.Tx(ctx, []byte{1}, true)
+	require.NoError(t, err)
+	require.Equal(t, 2, node.requests["tx"])
+
+	stats := c.Stats()
+	require.Equal(t, uint64(4+1+1), stats.Hits)
+	require.Equal(t, uint64(1+2+2+1+2), stats.Misses)
+	require.Zero(t, stats.DiskHits)
+	require.Equal(t, 4, stats.Entries)
+	require.Zero(t, node.requests["status"])
+}
+
+func TestCacheEviction(t *testing.T) {
+	node := newCountingClient(10)
+	c, err := New(node, DefaultOptions)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	height := int64(1)
+	_, err = c.Block(ctx, &height)
+	require.NoError(t, err)
+	size := c.Stats().Bytes
+
+	// The zero value is the default size.
+	c, err = New(node, Options{})
+	require.NoError(t, err)
+	_, err = c.Block(ctx, &height)
+	require.NoError(t, err)
+	_, err = c.Block(ctx, &height)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), c.Stats().Hits)
+
+	// Room for two blocks.
+	c, err = New(node, Options{MaxBytes: 2*size + 1})
+	require.NoError(t, err)
+	for _, h := range []int64{1, 2, 1, 3, 1, 2} {
+		h := h
+		_, err := c.Block(ctx, &h)
+		require.NoError(t, err)
+	}
+
+	stats := c.Stats()
+	require.Equal(t, uint64(2), stats.Hits)
+	require.Equal(t, uint64(4), stats.Misses)
+	require.Equal(t, uint64(2), stats.Evictions)
+	require.Equal(t, 2, stats.Entries)
+	require.LessOrEqual(t, stats.Bytes, 2*size+1)
+}
+
+func TestCacheStore(t *testing.T) {
+	dir := t.TempDir()
+	node := newCountingClient(10)
+	ctx := context.Background()
+	height := int64(5)
+
+	c, err := New(node, Options{MaxBytes: DefaultOptions.MaxBytes, Dir: dir})
+	require.NoError(t, err)
+	_, err = c.Block(ctx, &height)
+	require.NoError(t, err)
+	require.FileExists(t, c.store.path("test-chain", key{height, "block"}))
+	require.Equal(t, 1, node.requests["status"])
+
+	// A new cache, e.g. after a restart, finds the response on disk.
+	c, err = New(node, Options{MaxBytes: DefaultOptions.MaxBytes, Dir: dir, ChainID: "test-chain"})
+	require.NoError(t, err)
+	res, err := c.Block(ctx, &height)
+	require.NoError(t, err)
+	require.Equal(t, height, res.Block.Height)
+	_, err = c.Block(ctx, &height)
+	require.NoError(t, err)
+
+	require.Equal(t, 1, node.requests["block"])
+	require.Equal(t, 1, node.requests["status"])
+	require.Equal(t, Stats{Hits: 2, DiskHits: 1, Entries: 1, Bytes: c.Stats().Bytes}, c.Stats())
+
+	_, err = New(node, Options{Dir: dir, ChainID: "../chain"})
+	require.Error(t, err)
+}
+
+// batchingClient is a countingClient which batches the requests for blocks.
+type batchingClient struct {
+	*countingClient
+	sent int
+}
+
+func (c *batchingClient) NewBlockBatch() rpcclient.BlockBatch {
+	return &countingBatch{c: c}
+}
+
+type countingBatch struct {
+	rpcclient.BlockBatch
+
+	c       *batchingClient
+	heights []int64
+	blocks  []*ctypes.ResultBlock
+}
+
+func (b *countingBatch) Block(_ context.Context, height *int64) (*ctypes.ResultBlock, error) {
+	res := new(ctypes.ResultBlock)
+	b.heights = append(b.heights, *height)
+	b.blocks = append(b.blocks, res)
+	return res, nil
+}
+
+func (b *countingBatch) Send(ctx context.Context) ([]jsonrpcclient.BatchResult, error) {
+	b.c.sent++
+	results := make([]jsonrpcclient.BatchResult, len(b.blocks))
+	for i, h := range b.heights {
+		res, err := b.c.Block(ctx, &h)
+		if err == nil {
+			*b.blocks[i] = *res
+		}
+		results[i] = jsonrpcclient.BatchResult{Result: b.blocks[i], Err: err}
+	}
+	return results, nil
+}
+
+func TestCacheBlockBatch(t *testing.T) {
+	// A decorated client which can't batch requests can't batch them through the cache either.
+	c, err := New(newCountingClient(10), DefaultOptions)
+	require.NoError(t, err)
+	require.Nil(t, c.NewBlockBatch())
+
+	node := &batchingClient{countingClient: newCountingClient(10)}
+	c, err = New(node, DefaultOptions)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	for _, height := range []int64{1, 3} {
+		height := height
+		_, err = c.Block(ctx, &height)
+		require.NoError(t, err)
+	}
+
+	// The cached blocks are served at once, and the others are sent in a batch, then cached.
+	// Every request has its result, in order.
+	heights := []int64{1, 2, 11, 3}
+	batch := c.NewBlockBatch()
+	var blocks []*ctypes.ResultBlock
+	for _, h := range heights {
+		h := h
+		res, err := batch.Block(ctx, &h)
+		require.NoError(t, err)
+		blocks = append(blocks, res)
+	}
+	require.Equal(t, int64(1), blocks[0].Block.Height)
+	require.Equal(t, int64(3), blocks[3].Block.Height)
+	results, err := batch.Send(ctx)
+	require.NoError(t, err)
+	require.Len(t, results, len(heights))
+	for i, h := range heights {
+		require.Same(t, blocks[i], results[i].Result, "request %d", i)
+		if h > 10 {
+			require.Error(t, results[i].Err)
+			continue
+		}
+		require.NoError(t, results[i].Err)
+		require.Equal(t, h, blocks[i].Block.Height)
+	}
+	require.Equal(t, 4, node.requests["block"])
+
+	// Nothing is sent when every block is cached, and the results are still returned.
+	batch = c.NewBlockBatch()
+	for h := int64(1); h <= 3; h++ {
+		h := h
+		_, err := batch.Block(ctx, &h)
+		require.NoError(t, err)
+	}
+	results, err = batch.Send(ctx)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	for i, r := range results {
+		require.NoError(t, r.Err)
+		require.Equal(t, int64(i+1), r.Result.(*ctypes.ResultBlock).Block.Height)
+	}
+	require.Equal(t, 1, node.sent)
+	require.Equal(t, 4, node.requests["block"])
+}
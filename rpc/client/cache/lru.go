@@ -0,0 +1,81 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+)
+
+// lru is a least recently used cache of encoded responses, bounded by the total size of the keys and values.
+type lru struct {
+	mtx      sync.Mutex
+	maxBytes int64
+	bytes    int64
+	ll       *list.List
+	items    map[string]*list.Element
+	evicted  uint64
+}
+
+type lruEntry struct {
+	key   string
+	value []byte
+}
+
+func (e *lruEntry) size() int64 {
+	return int64(len(e.key) + len(e.value))
+}
+
+func newLRU(maxBytes int64) *lru {
+	return &lru{
+		maxBytes: maxBytes,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+func (c *lru) get(key string) ([]byte, bool) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	c.ll.MoveToFront(elem)
+	return elem.Value.(*lruEntry).value, true
+}
+
+// add inserts the value, evicting the least recently used entries to make room for it.
+// Values larger than the cache are not inserted.
+func (c *lru) add(key string, value []byte) {
+	entry := &lruEntry{key: key, value: value}
+	if entry.size() > c.maxBytes {
+		return
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if elem, ok := c.items[key]; ok {
+		c.remove(elem)
+	}
+	c.items[key] = c.ll.PushFront(entry)
+	c.bytes += entry.size()
+
+	for c.bytes > c.maxBytes {
+		c.remove(c.ll.Back())
+		c.evicted++
+	}
+}
+
+func (c *lru) remove(elem *list.Element) {
+	entry := c.ll.Remove(elem).(*lruEntry)
+	delete(c.items, entry.key)
+	c.bytes -= entry.size()
+}
+
+// stats returns the number of entries, their size and the number of evicted entries.
+func (c *lru) stats() (entries int, bytes int64, evicted uint64) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.ll.Len(), c.bytes, c.evicted
+}
@@ -0,0 +1,42 @@
+package cache
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	cmtos "github.com/strangelove-ventures/cometbft-client/libs/os"
+)
+
+// store persists encoded responses in a directory, as one file per response under
+// <dir>/<chain-id>/<height>/, or <dir>/<chain-id>/tx/ for the transactions.
+type store struct {
+	dir string
+}
+
+func (s *store) path(chainID string, k key) string {
+	sub := "tx"
+	if k.height > 0 {
+		sub = strconv.FormatInt(k.height, 10)
+	}
+	return filepath.Join(s.dir, chainID, sub, k.name+".json")
+}
+
+// get returns the stored response, or nil if there is none.
+func (s *store) get(chainID string, k key) ([]byte, error) {
+	bz, err := os.ReadFile(s.path(chainID, k))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return bz, err
+}
+
+func (s *store) put(chainID string, k key, bz []byte) error {
+	path := s.path(chainID, k)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	return cmtos.WriteFileAtomic(path, bz, 0o600)
+}
@@ -0,0 +1,154 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	cmtbytes "github.com/strangelove-ventures/cometbft-client/libs/bytes"
+	ctypes "github.com/strangelove-ventures/cometbft-client/rpc/core/types"
+	"github.com/strangelove-ventures/cometbft-client/types"
+)
+
+// ErrNotAccepted is returned by the fan-out broadcasts when no endpoint accepted the transaction.
+var ErrNotAccepted = errors.New("transaction not accepted by any endpoint")
+
+// IsErrTxInCache returns true if err is the error returned by a node when the transaction
+// is already in its mempool cache, i.e. it was received before.
+func IsErrTxInCache(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "tx already exists in cache")
+}
+
+// EndpointBroadcastResult is the result of broadcasting a transaction to a single endpoint.
+type EndpointBroadcastResult struct {
+	Remote string
+	// Result is nil if Err is not nil.
+	Result *ctypes.ResultBroadcastTx
+	Err    error
+	// InCache is true if the endpoint already had the transaction, which is considered a success.
+	InCache bool
+	// HashMismatch is true if the endpoint returned a hash different from the hash of the transaction.
+	HashMismatch bool
+	Latency      time.Duration
+}
+
+// Accepted returns true if the endpoint accepted the transaction, or already had it.
+func (r EndpointBroadcastResult) Accepted() bool {
+	if r.InCache {
+		return true
+	}
+	return r.Err == nil && r.Result.Code == 0 && !r.HashMismatch
+}
+
+// FanOutBroadcastResult reconciles the results of broadcasting a transaction to several endpoints.
+type FanOutBroadcastResult struct {
+	// Hash is the hash of the transaction.
+	Hash cmtbytes.HexBytes
+	// First is the index in Results of the first endpoint that accepted the transaction, or -1.
+	First int
+	// Results holds one result per endpoint, in the order of the clients.
+	Results []EndpointBroadcastResult
+}
+
+// Accepted returns the number of endpoints that accepted the transaction.
+func (r *FanOutBroadcastResult) Accepted() int {
+	n := 0
+	for _, res := range r.Results {
+		if res.Accepted() {
+			n++
+		}
+	}
+	return n
+}
+
+// Mismatched returns the endpoints that returned a different hash for the transaction.
+func (r *FanOutBroadcastResult) Mismatched() []string {
+	var remotes []string
+	for _, res := range r.Results {
+		if res.HashMismatch {
+			remotes = append(remotes, res.Remote)
+		}
+	}
+	return remotes
+}
+
+// BroadcastTxSyncFanOut submits tx to every client concurrently with broadcast_tx_sync and
+// waits for all of them to answer, or for ctx to be done.
+//
+// The result is always returned. The error is ErrNotAccepted, joined with the error of each
+// endpoint, if none accepted the transaction.
+func BroadcastTxSyncFanOut(ctx context.Context, clients []*HTTP, tx types.Tx) (*FanOutBroadcastResult, error) {
+	return broadcastTxFanOut(ctx, clients, "broadcast_tx_sync", tx)
+}
+
+// BroadcastTxAsyncFanOut is like BroadcastTxSyncFanOut, with broadcast_tx_async. Since the
+// endpoints do not run CheckTx before answering, the transaction may still be rejected.
+func BroadcastTxAsyncFanOut(ctx context.Context, clients []*HTTP, tx types.Tx) (*FanOutBroadcastResult, error) {
+	return broadcastTxFanOut(ctx, clients, "broadcast_tx_async", tx)
+}
+
+func broadcastTxFanOut(
+	ctx context.Context,
+	clients []*HTTP,
+	route string,
+	tx types.Tx,
+) (*FanOutBroadcastResult, error) {
+	res := &FanOutBroadcastResult{
+		Hash:    tx.Hash(),
+		First:   -1,
+		Results: make([]EndpointBroadcastResult, len(clients)),
+	}
+
+	var (
+		mtx sync.Mutex
+		wg  sync.WaitGroup
+	)
+	start := time.Now()
+	for i, c := range clients {
+		wg.Add(1)
+		go func(i int, c *HTTP) {
+			defer wg.Done()
+
+			result, err := c.broadcastTX(ctx, route, tx)
+			r := EndpointBroadcastResult{
+				Remote:  c.Remote(),
+				Result:  result,
+				Err:     err,
+				InCache: IsErrTxInCache(err),
+				Latency: time.Since(start),
+			}
+			if err == nil && !bytes.Equal(result.Hash, res.Hash) {
+				r.HashMismatch = true
+			}
+
+			mtx.Lock()
+			defer mtx.Unlock()
+			res.Results[i] = r
+			if r.Accepted() && res.First == -1 {
+				res.First = i
+			}
+		}(i, c)
+	}
+	wg.Wait()
+
+	if res.First != -1 {
+		return res, nil
+	}
+
+	errs := []error{ErrNotAccepted}
+	for _, r := range res.Results {
+		switch {
+		case r.Err != nil:
+			errs = append(errs, fmt.Errorf("%s: %w", r.Remote, r.Err))
+		case r.HashMismatch:
+			errs = append(errs, fmt.Errorf("%s: hash mismatch, got %v", r.Remote, r.Result.Hash))
+		default:
+			errs = append(errs, fmt.Errorf("%s: code %d (%s): %s", r.Remote, r.Result.Code, r.Result.Codespace, r.Result.Log))
+		}
+	}
+	return res, errors.Join(errs...)
+}
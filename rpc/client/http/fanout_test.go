@@ -0,0 +1,89 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	cmtbytes "github.com/strangelove-ventures/cometbft-client/libs/bytes"
+	ctypes "github.com/strangelove-ventures/cometbft-client/rpc/core/types"
+	rpctypes "github.com/strangelove-ventures/cometbft-client/rpc/jsonrpc/types"
+	"github.com/strangelove-ventures/cometbft-client/types"
+)
+
+// newBroadcastNode returns a client of a node answering every broadcast with res or rpcErr, after delay.
+func newBroadcastNode(t *testing.T, delay time.Duration, res *ctypes.ResultBroadcastTx, rpcErr error) *HTTP {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req rpctypes.RPCRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		time.Sleep(delay)
+
+		resp := rpctypes.NewRPCSuccessResponse(req.ID, res)
+		if rpcErr != nil {
+			resp = rpctypes.RPCInternalError(req.ID, rpcErr)
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := New(srv.URL, "/websocket")
+	require.NoError(t, err)
+	return c
+}
+
+func TestBroadcastTxSyncFanOut(t *testing.T) {
+	tx := types.Tx("tx")
+	hash := cmtbytes.HexBytes(tx.Hash())
+
+	clients := []*HTTP{
+		newBroadcastNode(t, 100*time.Millisecond, &ctypes.ResultBroadcastTx{Hash: hash}, nil),
+		newBroadcastNode(t, 0, nil, errors.New("tx already exists in cache")),
+		newBroadcastNode(t, 0, &ctypes.ResultBroadcastTx{Hash: cmtbytes.HexBytes{0x01}}, nil),
+		newBroadcastNode(t, 0, &ctypes.ResultBroadcastTx{Code: 5, Codespace: "sdk", Hash: hash}, nil),
+		newBroadcastNode(t, 0, nil, errors.New("mempool is full")),
+	}
+
+	res, err := BroadcastTxSyncFanOut(context.Background(), clients, tx)
+	require.NoError(t, err)
+	require.Equal(t, hash, res.Hash)
+	require.Len(t, res.Results, len(clients))
+	require.Equal(t, 1, res.First)
+	require.Equal(t, 2, res.Accepted())
+	require.Equal(t, []string{clients[2].Remote()}, res.Mismatched())
+
+	require.True(t, res.Results[0].Accepted())
+	require.True(t, res.Results[1].InCache)
+	require.False(t, res.Results[2].Accepted())
+	require.False(t, res.Results[3].Accepted())
+	require.Error(t, res.Results[4].Err)
+	for i, r := range res.Results {
+		require.Equal(t, clients[i].Remote(), r.Remote)
+	}
+}
+
+func TestBroadcastTxSyncFanOutNotAccepted(t *testing.T) {
+	tx := types.Tx("tx")
+
+	clients := []*HTTP{
+		newBroadcastNode(t, 0, &ctypes.ResultBroadcastTx{Code: 5, Hash: tx.Hash()}, nil),
+		newBroadcastNode(t, 0, nil, errors.New("mempool is full")),
+	}
+
+	res, err := BroadcastTxSyncFanOut(context.Background(), clients, tx)
+	require.ErrorIs(t, err, ErrNotAccepted)
+	require.ErrorContains(t, err, "mempool is full")
+	require.Equal(t, -1, res.First)
+	require.Zero(t, res.Accepted())
+}
@@ -2,11 +2,14 @@ package http
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"strings"
 	"time"
 
+	abci "github.com/strangelove-ventures/cometbft-client/abci/types"
 	"github.com/strangelove-ventures/cometbft-client/libs/bytes"
 	cmtjson "github.com/strangelove-ventures/cometbft-client/libs/json"
 	"github.com/strangelove-ventures/cometbft-client/libs/log"
@@ -16,6 +19,7 @@ import (
 	rpcclient "github.com/strangelove-ventures/cometbft-client/rpc/client"
 	ctypes "github.com/strangelove-ventures/cometbft-client/rpc/core/types"
 	jsonrpcclient "github.com/strangelove-ventures/cometbft-client/rpc/jsonrpc/client"
+	rpctypes "github.com/strangelove-ventures/cometbft-client/rpc/jsonrpc/types"
 	"github.com/strangelove-ventures/cometbft-client/types"
 )
 
@@ -156,6 +160,7 @@ func NewWithClient(remote, wsEndpoint string, client *http.Client) (*HTTP, error
 }
 
 var _ rpcclient.Client = (*HTTP)(nil)
+var _ rpcclient.BlockBatcher = (*HTTP)(nil)
 
 // SetLogger sets a logger.
 func (c *HTTP) SetLogger(l log.Logger) {
@@ -167,6 +172,141 @@ func (c *HTTP) Remote() string {
 	return c.remote
 }
 
+// SetCoalescing makes the client send its concurrent requests as JSON-RPC batches,
+// see jsonrpcclient.Client.SetCoalescing. It must be called before the client is used.
+func (c *HTTP) SetCoalescing(opts jsonrpcclient.CoalesceOptions) {
+	c.rpc.SetCoalescing(opts)
+}
+
+// SetDeduplication makes the concurrent identical requests of the client share a single
+// request, see jsonrpcclient.Client.SetDeduplication. It must be called before the client is used.
+func (c *HTTP) SetDeduplication(enabled bool) {
+	c.rpc.SetDeduplication(enabled)
+}
+
+// SetRateLimiter makes the client send its requests, including those of the event
+// subscriptions, within the limits of l. See jsonrpcclient.RateLimiter.
+// It must be called before the client is used or started.
+func (c *HTTP) SetRateLimiter(l *jsonrpcclient.RateLimiter) {
+	c.rpc.SetRateLimiter(l)
+	c.WSEvents.ws.SetRateLimiter(l)
+}
+
+// SetCompression makes the client accept compressed responses, decompressed up to a maximum
+// size, see jsonrpcclient.Client.SetCompression. It must be called before the client is used.
+func (c *HTTP) SetCompression(opts jsonrpcclient.CompressionOptions) error {
+	return c.rpc.SetCompression(opts)
+}
+
+// CompressionStats returns the statistics of the compressed responses received by the client.
+func (c *HTTP) CompressionStats() jsonrpcclient.CompressionStats {
+	return c.rpc.CompressionStats()
+}
+
+// SetMaxResponseSize makes the client fail as soon as a response is known to be larger than n
+// bytes, see jsonrpcclient.Client.SetMaxResponseSize. It must be called before the client is used.
+func (c *HTTP) SetMaxResponseSize(n int64) {
+	c.rpc.SetMaxResponseSize(n)
+}
+
+// SetCodec makes the client, including its event subscriptions, encode the params and decode
+// the results of its requests with codec, see jsonrpcclient.Client.SetCodec.
+// It must be called before the client is used or started.
+func (c *HTTP) SetCodec(codec rpctypes.Codec) {
+	c.rpc.SetCodec(codec)
+	c.WSEvents.ws.SetCodec(codec)
+}
+
+// BlockResultsStream is like BlockResults, but decodes the results of the transactions one at a
+// time while the response is received, and passes each one to onTx instead of storing it in
+// TxsResults. The memory used then does not depend on the number of transactions of the block.
+// An error returned by onTx aborts the request.
+func (c *HTTP) BlockResultsStream(
+	ctx context.Context,
+	height *int64,
+	onTx func(index int, tx *abci.ExecTxResult) error,
+) (*ctypes.ResultBlockResults, error) {
+	params := make(map[string]interface{})
+	if height != nil {
+		params["height"] = height
+	}
+
+	result := new(ctypes.ResultBlockResults)
+	err := c.rpc.CallStream(ctx, "block_results", params, func(dec *cmtjson.Decoder) error {
+		return decodeBlockResults(dec, result, onTx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// decodeBlockResults decodes the fields of a ResultBlockResults into result, except the results
+// of the transactions which are passed to onTx.
+func decodeBlockResults(
+	dec *cmtjson.Decoder,
+	result *ctypes.ResultBlockResults,
+	onTx func(index int, tx *abci.ExecTxResult) error,
+) error {
+	if tok, err := dec.Token(); err != nil {
+		return err
+	} else if tok != json.Delim('{') {
+		return fmt.Errorf("expected block results object, got %v", tok)
+	}
+
+	// The other fields are small, they are decoded together at the end.
+	fields := make(map[string]json.RawMessage)
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key, ok := tok.(string)
+		if !ok {
+			return fmt.Errorf("expected field name, got %v", tok)
+		}
+		if key != "txs_results" {
+			if fields[key], err = dec.Raw(); err != nil {
+				return err
+			}
+			continue
+		}
+
+		tok, err = dec.Token()
+		if err != nil {
+			return err
+		}
+		if tok == nil {
+			// No transactions.
+			continue
+		}
+		if tok != json.Delim('[') {
+			return fmt.Errorf("expected txs_results array, got %v", tok)
+		}
+		for i := 0; dec.More(); i++ {
+			tx := new(abci.ExecTxResult)
+			if err := dec.Decode(tx); err != nil {
+				return fmt.Errorf("tx result %d: %w", i, err)
+			}
+			if err := onTx(i, tx); err != nil {
+				return err
+			}
+		}
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+	}
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+
+	bz, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	return cmtjson.Unmarshal(bz, result)
+}
+
 // NewBatch creates a new batch client for this HTTP client.
 func (c *HTTP) NewBatch() *BatchHTTP {
 	rpcBatch := c.rpc.NewRequestBatch()
@@ -178,14 +318,20 @@ func (c *HTTP) NewBatch() *BatchHTTP {
 	}
 }
 
+// NewBlockBatch returns a new batch client for this HTTP client, as a rpcclient.BlockBatch.
+func (c *HTTP) NewBlockBatch() rpcclient.BlockBatch {
+	return c.NewBatch()
+}
+
 //-----------------------------------------------------------------------------
 // BatchHTTP
 
 // Send is a convenience function for an HTTP batch that will trigger the
 // compilation of the batched requests and send them off using the client as a
-// single request. On success, this returns a list of the deserialized results
-// from each request in the sent batch.
-func (b *BatchHTTP) Send(ctx context.Context) ([]interface{}, error) {
+// single request. On success, this returns the result or error of each request
+// in the sent batch, in the order of the calls. The results are also available
+// through the pointers returned by the calls, for the requests which succeeded.
+func (b *BatchHTTP) Send(ctx context.Context) ([]jsonrpcclient.BatchResult, error) {
 	return b.rpcBatch.Send(ctx)
 }
 
@@ -764,7 +910,7 @@ func (w *WSEvents) eventListener() {
 			}
 
 			result := new(ctypes.ResultEvent)
-			err := cmtjson.Unmarshal(resp.Result, result)
+			err := w.ws.Codec().Unmarshal(resp.Result, result)
 			if err != nil {
 				w.Logger.Error("failed to unmarshal response", "err", err)
 				continue
@@ -0,0 +1,90 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	abci "github.com/strangelove-ventures/cometbft-client/abci/types"
+	ctypes "github.com/strangelove-ventures/cometbft-client/rpc/core/types"
+	rpctypes "github.com/strangelove-ventures/cometbft-client/rpc/jsonrpc/types"
+)
+
+// newBlockResultsNode returns a client of a node answering block_results with res.
+func newBlockResultsNode(t *testing.T, res *ctypes.ResultBlockResults) *HTTP {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req rpctypes.RPCRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(rpctypes.NewRPCSuccessResponse(req.ID, res))
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := New(srv.URL, "/websocket")
+	require.NoError(t, err)
+	return c
+}
+
+func TestBlockResultsStream(t *testing.T) {
+	events := []abci.Event{{Type: "transfer", Attributes: []abci.EventAttribute{{Key: "amount", Value: "1stake"}}}}
+	c := newBlockResultsNode(t, &ctypes.ResultBlockResults{
+		Height: 12,
+		TxsResults: []*abci.ExecTxResult{
+			{Code: 0, GasUsed: 100, Events: events},
+			{Code: 5, Log: "out of gas"},
+			{Code: 0, Data: []byte{1, 2}},
+		},
+		FinalizeBlockEvents: events,
+		AppHash:             []byte{0xAB},
+	})
+
+	var txs []*abci.ExecTxResult
+	res, err := c.BlockResultsStream(context.Background(), nil, func(index int, tx *abci.ExecTxResult) error {
+		require.Equal(t, len(txs), index)
+		txs = append(txs, tx)
+		return nil
+	})
+	require.NoError(t, err)
+
+	require.EqualValues(t, 12, res.Height)
+	require.Nil(t, res.TxsResults)
+	require.Equal(t, events, res.FinalizeBlockEvents)
+	require.Equal(t, []byte{0xAB}, res.AppHash)
+
+	require.Len(t, txs, 3)
+	require.EqualValues(t, 100, txs[0].GasUsed)
+	require.Equal(t, events, txs[0].Events)
+	require.Equal(t, "out of gas", txs[1].Log)
+	require.Equal(t, []byte{1, 2}, txs[2].Data)
+
+	// An error of the callback aborts the request.
+	errStop := errors.New("stop")
+	calls := 0
+	_, err = c.BlockResultsStream(context.Background(), nil, func(int, *abci.ExecTxResult) error {
+		calls++
+		return errStop
+	})
+	require.ErrorIs(t, err, errStop)
+	require.Equal(t, 1, calls)
+}
+
+func TestBlockResultsStreamNoTxs(t *testing.T) {
+	c := newBlockResultsNode(t, &ctypes.ResultBlockResults{Height: 3})
+
+	height := int64(3)
+	res, err := c.BlockResultsStream(context.Background(), &height, func(int, *abci.ExecTxResult) error {
+		t.Fatal("unexpected tx")
+		return nil
+	})
+	require.NoError(t, err)
+	require.EqualValues(t, 3, res.Height)
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/strangelove-ventures/cometbft-client/libs/bytes"
 	"github.com/strangelove-ventures/cometbft-client/libs/service"
 	ctypes "github.com/strangelove-ventures/cometbft-client/rpc/core/types"
+	jsonrpcclient "github.com/strangelove-ventures/cometbft-client/rpc/jsonrpc/client"
 	"github.com/strangelove-ventures/cometbft-client/types"
 )
 
@@ -152,3 +153,21 @@ type RemoteClient interface {
 	// Remote returns the remote network address in a string form.
 	Remote() string
 }
+
+// BlockBatcher is a client which sends several requests for blocks and block results as a
+// single batch request, such as the HTTP client and the caching decorator.
+type BlockBatcher interface {
+	// NewBlockBatch returns an empty batch, or nil if the client can't batch requests, e.g. a
+	// decorator of a client which can't.
+	NewBlockBatch() BlockBatch
+}
+
+// BlockBatch queues requests for blocks and block results. The results returned when a
+// request is queued are filled in by Send, for the requests which succeeded.
+type BlockBatch interface {
+	Block(ctx context.Context, height *int64) (*ctypes.ResultBlock, error)
+	BlockResults(ctx context.Context, height *int64) (*ctypes.ResultBlockResults, error)
+	// Send sends the queued requests, and returns the result or error of each of them, in the
+	// order they were queued.
+	Send(ctx context.Context) ([]jsonrpcclient.BatchResult, error)
+}
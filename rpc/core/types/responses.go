@@ -5,6 +5,7 @@ import (
 	"time"
 
 	abci "github.com/strangelove-ventures/cometbft-client/abci/types"
+	cstypes "github.com/strangelove-ventures/cometbft-client/consensus/types"
 	"github.com/strangelove-ventures/cometbft-client/crypto"
 	"github.com/strangelove-ventures/cometbft-client/libs/bytes"
 	"github.com/strangelove-ventures/cometbft-client/p2p"
@@ -147,17 +148,44 @@ type ResultDumpConsensusState struct {
 	Peers      []PeerStateInfo `json:"peers"`
 }
 
+// DecodeRoundState decodes the raw round state into a typed RoundState.
+func (r *ResultDumpConsensusState) DecodeRoundState() (*cstypes.RoundState, error) {
+	rs := new(cstypes.RoundState)
+	if err := json.Unmarshal(r.RoundState, rs); err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
+
 // UNSTABLE
 type PeerStateInfo struct {
 	NodeAddress string          `json:"node_address"`
 	PeerState   json.RawMessage `json:"peer_state"`
 }
 
+// DecodePeerState decodes the raw peer state into a typed PeerState.
+func (p PeerStateInfo) DecodePeerState() (*cstypes.PeerState, error) {
+	ps := new(cstypes.PeerState)
+	if err := json.Unmarshal(p.PeerState, ps); err != nil {
+		return nil, err
+	}
+	return ps, nil
+}
+
 // UNSTABLE
 type ResultConsensusState struct {
 	RoundState json.RawMessage `json:"round_state"`
 }
 
+// DecodeRoundState decodes the raw round state summary into a typed RoundStateSimple.
+func (r *ResultConsensusState) DecodeRoundState() (*cstypes.RoundStateSimple, error) {
+	rs := new(cstypes.RoundStateSimple)
+	if err := json.Unmarshal(r.RoundState, rs); err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
+
 // CheckTx result
 type ResultBroadcastTx struct {
 	Code      uint32         `json:"code"`
@@ -5,6 +5,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/strangelove-ventures/cometbft-client/rpc/jsonrpc/types"
 )
 
 type Tx []byte
@@ -29,7 +31,7 @@ func TestArgToJSON(t *testing.T) {
 
 	for i, tc := range cases {
 		args := map[string]interface{}{"data": tc.input}
-		err := argsToJSON(args)
+		err := argsToJSON(types.DefaultCodec, args)
 		require.Nil(err, "%d: %+v", i, err)
 		require.Equal(1, len(args), "%d", i)
 		data, ok := args["data"].(string)
@@ -0,0 +1,171 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/strangelove-ventures/cometbft-client/rpc/jsonrpc/types"
+)
+
+// CoalesceOptions configures the coalescing of concurrent calls into batches, see Client.SetCoalescing.
+type CoalesceOptions struct {
+	// Window is the maximum time a call waits for other calls to join its batch.
+	Window time.Duration
+	// MaxBatchSize is the number of calls at which a batch is sent without waiting for the window to end.
+	MaxBatchSize int
+}
+
+// DefaultCoalesceOptions are the default coalescing options.
+var DefaultCoalesceOptions = CoalesceOptions{
+	Window:       2 * time.Millisecond,
+	MaxBatchSize: 20,
+}
+
+// SetCoalescing makes Call collect the concurrent calls and send them as a single batch once
+// opts.Window has passed since the first one, or once there are opts.MaxBatchSize of them.
+// A call alone in its window is sent as a single request. The result of every call is decoded
+// into its own result, and a call failing on the server returns its own *types.RPCError.
+//
+// It must be called before the client is used.
+func (c *Client) SetCoalescing(opts CoalesceOptions) {
+	if opts.Window <= 0 {
+		opts.Window = DefaultCoalesceOptions.Window
+	}
+	if opts.MaxBatchSize <= 0 {
+		opts.MaxBatchSize = DefaultCoalesceOptions.MaxBatchSize
+	}
+	c.coalescer = &coalescer{client: c, opts: opts}
+}
+
+// coalescer collects the concurrent calls of a client into batches.
+type coalescer struct {
+	client *Client
+	opts   CoalesceOptions
+
+	mtx     sync.Mutex
+	pending []*coalescedCall
+	timer   *time.Timer
+}
+
+type coalescedCall struct {
+	ctx     context.Context
+	request *jsonRPCBufferedRequest
+	// done receives the error of the call once its batch was sent.
+	done chan error
+}
+
+func (co *coalescer) call(
+	ctx context.Context,
+	method string,
+	params map[string]interface{},
+	result interface{},
+) (interface{}, error) {
+	request, err := types.MapToRequestWithCodec(co.client.codec, co.client.nextRequestID(), method, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode params: %w", err)
+	}
+
+	// The response is decoded into a copy of the result, so that a call returning early
+	// because of its context does not leave the result written concurrently.
+	decoded := result
+	rv := reflect.ValueOf(result)
+	if rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		decoded = reflect.New(rv.Type().Elem()).Interface()
+	}
+
+	call := &coalescedCall{
+		ctx:     ctx,
+		request: &jsonRPCBufferedRequest{request: request, result: decoded},
+		done:    make(chan error, 1),
+	}
+
+	co.mtx.Lock()
+	co.pending = append(co.pending, call)
+	switch {
+	case len(co.pending) >= co.opts.MaxBatchSize:
+		batch := co.take()
+		co.mtx.Unlock()
+		go co.send(batch)
+	case len(co.pending) == 1:
+		co.timer = time.AfterFunc(co.opts.Window, co.flush)
+		co.mtx.Unlock()
+	default:
+		co.mtx.Unlock()
+	}
+
+	select {
+	case err := <-call.done:
+		if err != nil {
+			return nil, err
+		}
+		if decoded != result {
+			rv.Elem().Set(reflect.ValueOf(decoded).Elem())
+		}
+		return result, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// take returns the pending calls and resets the window. It must be called with the lock held.
+func (co *coalescer) take() []*coalescedCall {
+	if co.timer != nil {
+		co.timer.Stop()
+		co.timer = nil
+	}
+	batch := co.pending
+	co.pending = nil
+	return batch
+}
+
+// flush sends the pending calls at the end of the window.
+func (co *coalescer) flush() {
+	co.mtx.Lock()
+	batch := co.take()
+	co.mtx.Unlock()
+
+	if len(batch) > 0 {
+		co.send(batch)
+	}
+}
+
+// send sends the batch and returns its error to every call. The request is canceled once every
+// call of the batch is canceled.
+func (co *coalescer) send(batch []*coalescedCall) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	remaining := int32(len(batch))
+	for _, call := range batch {
+		stop := context.AfterFunc(call.ctx, func() {
+			if atomic.AddInt32(&remaining, -1) == 0 {
+				cancel()
+			}
+		})
+		defer stop()
+	}
+
+	if len(batch) == 1 {
+		_, err := co.client.send(ctx, batch[0].request.request, batch[0].request.result)
+		batch[0].done <- err
+		return
+	}
+
+	requests := make([]*jsonRPCBufferedRequest, len(batch))
+	for i, call := range batch {
+		requests[i] = call.request
+	}
+
+	errs, err := co.client.sendBatch(ctx, requests)
+	for i, call := range batch {
+		if err != nil {
+			call.done <- err
+		} else {
+			call.done <- errs[i]
+		}
+	}
+}
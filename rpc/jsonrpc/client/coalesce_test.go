@@ -0,0 +1,153 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/strangelove-ventures/cometbft-client/rpc/jsonrpc/types"
+)
+
+type echoResult struct {
+	Value string `json:"value"`
+}
+
+// newEchoServer answers the "echo" method with its "value" param, and every other method with an error.
+// It counts the HTTP requests and the sizes of the batches.
+func newEchoServer(t *testing.T) (*httptest.Server, *atomic.Int32, chan int) {
+	var requests atomic.Int32
+	batches := make(chan int, 100)
+
+	answer := func(req types.RPCRequest) types.RPCResponse {
+		if req.Method != "echo" {
+			return types.RPCMethodNotFoundError(req.ID)
+		}
+		var params struct {
+			Value string `json:"value"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return types.RPCInvalidParamsError(req.ID, err)
+		}
+		return types.NewRPCSuccessResponse(req.ID, echoResult{Value: params.Value})
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		var reqs []types.RPCRequest
+		if err := json.Unmarshal(body, &reqs); err != nil {
+			var req types.RPCRequest
+			require.NoError(t, json.Unmarshal(body, &req))
+			batches <- 1
+			_ = json.NewEncoder(w).Encode(answer(req))
+			return
+		}
+
+		batches <- len(reqs)
+		// Answer in reverse order, the responses are matched by ID.
+		resps := make([]types.RPCResponse, len(reqs))
+		for i, req := range reqs {
+			resps[len(reqs)-1-i] = answer(req)
+		}
+		_ = json.NewEncoder(w).Encode(resps)
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, &requests, batches
+}
+
+func TestCoalescing(t *testing.T) {
+	srv, requests, batches := newEchoServer(t)
+	c, err := New(srv.URL)
+	require.NoError(t, err)
+	c.SetCoalescing(CoalesceOptions{Window: 50 * time.Millisecond, MaxBatchSize: 100})
+
+	const n = 10
+	var wg sync.WaitGroup
+	errs := make([]error, n)
+	results := make([]*echoResult, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			method := "echo"
+			if i == 3 {
+				method = "unknown"
+			}
+			results[i] = new(echoResult)
+			_, errs[i] = c.Call(context.Background(), method, map[string]interface{}{"value": strconv.Itoa(i)}, results[i])
+		}(i)
+	}
+	wg.Wait()
+
+	require.Equal(t, int32(1), requests.Load())
+	require.Equal(t, n, <-batches)
+	for i := 0; i < n; i++ {
+		if i == 3 {
+			var rpcErr *types.RPCError
+			require.True(t, errors.As(errs[i], &rpcErr), errs[i])
+			require.Equal(t, -32601, rpcErr.Code)
+			continue
+		}
+		require.NoError(t, errs[i])
+		require.Equal(t, strconv.Itoa(i), results[i].Value)
+	}
+
+	// A call alone in its window is not batched.
+	res := new(echoResult)
+	_, err = c.Call(context.Background(), "echo", map[string]interface{}{"value": "alone"}, res)
+	require.NoError(t, err)
+	require.Equal(t, "alone", res.Value)
+	require.Equal(t, 1, <-batches)
+}
+
+func TestCoalescingMaxBatchSize(t *testing.T) {
+	srv, _, batches := newEchoServer(t)
+	c, err := New(srv.URL)
+	require.NoError(t, err)
+	c.SetCoalescing(CoalesceOptions{Window: time.Hour, MaxBatchSize: 4})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			res := new(echoResult)
+			_, err := c.Call(context.Background(), "echo", map[string]interface{}{"value": strconv.Itoa(i)}, res)
+			require.NoError(t, err)
+			require.Equal(t, strconv.Itoa(i), res.Value)
+		}(i)
+	}
+	wg.Wait()
+
+	require.Equal(t, 4, <-batches)
+	require.Equal(t, 4, <-batches)
+}
+
+func TestCoalescingCanceled(t *testing.T) {
+	srv, requests, _ := newEchoServer(t)
+	c, err := New(srv.URL)
+	require.NoError(t, err)
+	c.SetCoalescing(CoalesceOptions{Window: 50 * time.Millisecond, MaxBatchSize: 100})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = c.Call(ctx, "echo", map[string]interface{}{"value": "x"}, new(echoResult))
+	require.ErrorIs(t, err, context.Canceled)
+
+	// The request of a batch whose calls are all canceled is not sent.
+	time.Sleep(100 * time.Millisecond)
+	require.Zero(t, requests.Load())
+}
@@ -0,0 +1,42 @@
+package client
+
+import (
+	"encoding/json"
+
+	"github.com/strangelove-ventures/cometbft-client/rpc/jsonrpc/types"
+)
+
+// SetCodec makes the client encode the params and decode the results of its calls with codec,
+// instead of types.DefaultCodec. CallStream still decodes its results with libs/json.
+//
+// It must be called before the client is used.
+func (c *Client) SetCodec(codec types.Codec) {
+	c.codec = codec
+}
+
+// SetCodec makes the client encode the params and decode the results of its calls with codec,
+// instead of types.DefaultCodec. It must be called before the client is used.
+func (c *URIClient) SetCodec(codec types.Codec) {
+	c.codec = codec
+}
+
+// SetCodec makes the client encode the params of its calls with codec, instead of
+// types.DefaultCodec. The results received on ResponsesCh are left undecoded, and can be
+// decoded with Codec. It must be called before the client is started.
+func (c *WSClient) SetCodec(codec types.Codec) {
+	c.codec = codec
+}
+
+// Codec returns the codec of the client.
+func (c *WSClient) Codec() types.Codec {
+	return c.codec
+}
+
+// unmarshalResult decodes a raw result into result with codec, unless result is itself a raw result.
+func unmarshalResult(codec types.Codec, raw json.RawMessage, result interface{}) error {
+	if r, ok := result.(*json.RawMessage); ok {
+		*r = append((*r)[:0], raw...)
+		return nil
+	}
+	return codec.Unmarshal(raw, result)
+}
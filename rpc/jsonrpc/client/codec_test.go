@@ -0,0 +1,117 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/strangelove-ventures/cometbft-client/libs/log"
+	"github.com/strangelove-ventures/cometbft-client/rpc/jsonrpc/server"
+	"github.com/strangelove-ventures/cometbft-client/rpc/jsonrpc/types"
+)
+
+// stdlibCodec encodes with encoding/json, which unlike libs/json does not quote 64-bit integers.
+// It counts its calls.
+type stdlibCodec struct {
+	marshals, unmarshals atomic.Int32
+}
+
+func (c *stdlibCodec) Marshal(v interface{}) ([]byte, error) {
+	c.marshals.Add(1)
+	return json.Marshal(v)
+}
+
+func (c *stdlibCodec) Unmarshal(bz []byte, v interface{}) error {
+	c.unmarshals.Add(1)
+	return json.Unmarshal(bz, v)
+}
+
+type sumResult struct {
+	Sum int64 `json:"sum"`
+}
+
+func newSumServer(t *testing.T, codec types.Codec) *httptest.Server {
+	funcMap := map[string]*server.RPCFunc{
+		"sum": server.NewRPCFunc(func(ctx *types.Context, a, b int64) (*sumResult, error) {
+			return &sumResult{Sum: a + b}, nil
+		}, "a,b"),
+	}
+	mux := http.NewServeMux()
+	server.RegisterRPCFuncsWithCodec(mux, funcMap, codec, log.NewTMLogger(new(bytes.Buffer)))
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestClientCodec(t *testing.T) {
+	serverCodec := new(stdlibCodec)
+	srv := newSumServer(t, serverCodec)
+
+	c, err := New(srv.URL)
+	require.NoError(t, err)
+	clientCodec := new(stdlibCodec)
+	c.SetCodec(clientCodec)
+
+	params := map[string]interface{}{"a": int64(1) << 60, "b": 1}
+	result := new(sumResult)
+	_, err = c.Call(context.Background(), "sum", params, result)
+	require.NoError(t, err)
+	require.Equal(t, int64(1)<<60+1, result.Sum)
+	require.Equal(t, int32(2), clientCodec.marshals.Load())
+	require.Equal(t, int32(1), clientCodec.unmarshals.Load())
+	require.Equal(t, int32(1), serverCodec.marshals.Load())
+	require.Equal(t, int32(2), serverCodec.unmarshals.Load())
+
+	// The raw result is as encoded by the server codec, with an unquoted integer.
+	var raw json.RawMessage
+	_, err = c.Call(context.Background(), "sum", params, &raw)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"sum":1152921504606846977}`, string(raw))
+
+	// The default codec quotes the integers, which the server codec does not accept.
+	c.SetCodec(types.DefaultCodec)
+	_, err = c.Call(context.Background(), "sum", params, result)
+	require.Error(t, err)
+}
+
+func TestRequestBatchCodec(t *testing.T) {
+	srv := newSumServer(t, new(stdlibCodec))
+
+	c, err := New(srv.URL)
+	require.NoError(t, err)
+	c.SetCodec(new(stdlibCodec))
+
+	batch := c.NewRequestBatch()
+	results := []*sumResult{new(sumResult), new(sumResult)}
+	for i, result := range results {
+		_, err := batch.Call(context.Background(), "sum", map[string]interface{}{"a": i, "b": 2}, result)
+		require.NoError(t, err)
+	}
+	_, err = batch.Send(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int64(2), results[0].Sum)
+	require.Equal(t, int64(3), results[1].Sum)
+}
+
+func TestURIClientCodec(t *testing.T) {
+	srv := newSumServer(t, new(stdlibCodec))
+
+	c, err := NewURI(srv.URL)
+	require.NoError(t, err)
+	codec := new(stdlibCodec)
+	c.SetCodec(codec)
+
+	params := map[string]interface{}{"a": int64(1) << 60, "b": 1}
+	result := new(sumResult)
+	_, err = c.Call(context.Background(), "sum", params, result)
+	require.NoError(t, err)
+	require.Equal(t, int64(1)<<60+1, result.Sum)
+	require.Equal(t, int32(2), codec.marshals.Load())
+	require.Equal(t, int32(1), codec.unmarshals.Load())
+}
@@ -0,0 +1,185 @@
+package client
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Decompressor returns a reader of the decompressed content of r.
+type Decompressor func(r io.Reader) (io.ReadCloser, error)
+
+var (
+	decompressorsMtx sync.RWMutex
+	decompressors    = map[string]Decompressor{
+		"gzip": func(r io.Reader) (io.ReadCloser, error) { return gzip.NewReader(r) },
+	}
+)
+
+// RegisterDecompressor registers the decompressor of a content encoding, so that it can be
+// accepted with CompressionOptions.Encodings. gzip is registered by default, and so is zstd
+// when cgo is enabled. Without cgo, zstd can be registered with the decoder of a pure Go
+// library, e.g.
+//
+//	client.RegisterDecompressor("zstd", func(r io.Reader) (io.ReadCloser, error) {
+//		d, err := zstd.NewReader(r)
+//		if err != nil {
+//			return nil, err
+//		}
+//		return d.IOReadCloser(), nil
+//	})
+func RegisterDecompressor(encoding string, d Decompressor) {
+	decompressorsMtx.Lock()
+	defer decompressorsMtx.Unlock()
+	decompressors[strings.ToLower(encoding)] = d
+}
+
+func decompressorFor(encoding string) (Decompressor, bool) {
+	decompressorsMtx.RLock()
+	defer decompressorsMtx.RUnlock()
+	d, ok := decompressors[strings.ToLower(encoding)]
+	return d, ok
+}
+
+// CompressionOptions configures the compression of the responses, see Client.SetCompression.
+type CompressionOptions struct {
+	// Encodings are the accepted content encodings, in order of preference. Each must have
+	// a registered Decompressor.
+	Encodings []string
+	// MaxDecompressedSize is the maximum size of a decompressed response, in bytes.
+	MaxDecompressedSize int64
+}
+
+// DefaultCompressionOptions are the default compression options. zstd is preferred to gzip
+// when it is registered by default, i.e. when cgo is enabled.
+var DefaultCompressionOptions = CompressionOptions{
+	Encodings:           []string{"gzip"},
+	MaxDecompressedSize: 64 << 20, // 64MiB
+}
+
+// CompressionStats are the statistics of the compressed responses received by a client.
+type CompressionStats struct {
+	// Responses is the number of responses received.
+	Responses int64
+	// CompressedResponses is the number of compressed responses received.
+	CompressedResponses int64
+	// CompressedBytes is the size of the compressed responses as received.
+	CompressedBytes int64
+	// DecompressedBytes is the size of the compressed responses once decompressed.
+	DecompressedBytes int64
+}
+
+// Ratio returns the compression ratio of the compressed responses, i.e. their decompressed size
+// divided by their compressed size, or 0 if none was received.
+func (s CompressionStats) Ratio() float64 {
+	if s.CompressedBytes == 0 {
+		return 0
+	}
+	return float64(s.DecompressedBytes) / float64(s.CompressedBytes)
+}
+
+// SetCompression makes the client accept compressed responses with the given encodings, by
+// default those of DefaultCompressionOptions: zstd and gzip, or only gzip without cgo.
+// The responses are decompressed up to opts.MaxDecompressedSize bytes; a larger response
+// fails with a *ResponseTooLargeError, so that a compression bomb can't exhaust the memory.
+// The transport must not decompress the responses itself, as is the case of DefaultHTTPClient.
+//
+// It must be called before the client is used.
+func (c *Client) SetCompression(opts CompressionOptions) error {
+	if len(opts.Encodings) == 0 {
+		opts.Encodings = DefaultCompressionOptions.Encodings
+	}
+	if opts.MaxDecompressedSize <= 0 {
+		opts.MaxDecompressedSize = DefaultCompressionOptions.MaxDecompressedSize
+	}
+	for _, encoding := range opts.Encodings {
+		if _, ok := decompressorFor(encoding); !ok {
+			return fmt.Errorf("no decompressor registered for encoding %q", encoding)
+		}
+	}
+
+	c.compression = &compression{
+		opts:           opts,
+		acceptEncoding: strings.Join(opts.Encodings, ", "),
+	}
+	return nil
+}
+
+// CompressionStats returns the statistics of the compressed responses, if compression is enabled.
+func (c *Client) CompressionStats() CompressionStats {
+	if c.compression == nil {
+		return CompressionStats{}
+	}
+	return CompressionStats{
+		Responses:           c.compression.responses.Load(),
+		CompressedResponses: c.compression.compressedResponses.Load(),
+		CompressedBytes:     c.compression.compressedBytes.Load(),
+		DecompressedBytes:   c.compression.decompressedBytes.Load(),
+	}
+}
+
+// compression is the state of the compression of the responses of a client.
+type compression struct {
+	opts           CompressionOptions
+	acceptEncoding string
+
+	responses           atomic.Int64
+	compressedResponses atomic.Int64
+	compressedBytes     atomic.Int64
+	decompressedBytes   atomic.Int64
+}
+
+// prepareRequest asks for a compressed response, if compression is enabled.
+func (c *Client) prepareRequest(req *http.Request) {
+	if c.compression != nil {
+		req.Header.Set("Accept-Encoding", c.compression.acceptEncoding)
+	}
+}
+
+// decompress returns a reader of the decompressed body of a response, if it is compressed.
+// The returned function must be called once the body was read, to update the statistics.
+func (co *compression) decompress(resp *http.Response, body io.Reader) (io.Reader, func(), error) {
+	co.responses.Add(1)
+
+	encoding := strings.TrimSpace(resp.Header.Get("Content-Encoding"))
+	if encoding == "" || strings.EqualFold(encoding, "identity") {
+		return body, func() {}, nil
+	}
+	decompress, ok := decompressorFor(encoding)
+	if !ok {
+		return nil, nil, fmt.Errorf("unsupported content encoding %q", encoding)
+	}
+
+	compressed := &countingReader{r: body}
+	r, err := decompress(compressed)
+	if err != nil {
+		return nil, nil, fmt.Errorf("decompress %s: %w", encoding, err)
+	}
+	decompressed := &countingReader{
+		r: &limitedReader{r: r, remaining: co.opts.MaxDecompressedSize, limit: co.opts.MaxDecompressedSize, decompressed: true},
+	}
+
+	done := func() {
+		r.Close()
+		co.compressedResponses.Add(1)
+		co.compressedBytes.Add(compressed.n)
+		co.decompressedBytes.Add(decompressed.n)
+	}
+	return decompressed, done, nil
+}
+
+// countingReader counts the bytes read from r.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n += int64(n)
+	return n, err
+}
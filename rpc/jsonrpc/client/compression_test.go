@@ -0,0 +1,117 @@
+package client
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/strangelove-ventures/cometbft-client/rpc/jsonrpc/types"
+)
+
+// newCompressingServer answers the "echo" method with its "value" param repeated "times" times,
+// compressed with gzip if the client accepts it.
+func newCompressingServer(t *testing.T) *httptest.Server {
+	answer := func(req types.RPCRequest) types.RPCResponse {
+		var params struct {
+			Value string `json:"value"`
+			Times int    `json:"times,string"`
+		}
+		require.NoError(t, json.Unmarshal(req.Params, &params))
+		return types.NewRPCSuccessResponse(req.ID, echoResult{Value: strings.Repeat(params.Value, params.Times)})
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body json.RawMessage
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		var resp interface{}
+		if body[0] == '[' {
+			var reqs []types.RPCRequest
+			require.NoError(t, json.Unmarshal(body, &reqs))
+			resps := make([]types.RPCResponse, len(reqs))
+			for i, req := range reqs {
+				resps[i] = answer(req)
+			}
+			resp = resps
+		} else {
+			var req types.RPCRequest
+			require.NoError(t, json.Unmarshal(body, &req))
+			resp = answer(req)
+		}
+
+		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			_ = json.NewEncoder(w).Encode(resp)
+			return
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		gw := gzip.NewWriter(w)
+		defer gw.Close()
+		_ = json.NewEncoder(gw).Encode(resp)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestCompression(t *testing.T) {
+	srv := newCompressingServer(t)
+
+	c, err := New(srv.URL)
+	require.NoError(t, err)
+
+	params := map[string]interface{}{"value": "abc", "times": 10000}
+
+	// Without compression, the response is not compressed.
+	result := new(echoResult)
+	_, err = c.Call(context.Background(), "echo", params, result)
+	require.NoError(t, err)
+	require.Len(t, result.Value, 30000)
+	require.Zero(t, c.CompressionStats())
+
+	require.NoError(t, c.SetCompression(CompressionOptions{}))
+	result = new(echoResult)
+	_, err = c.Call(context.Background(), "echo", params, result)
+	require.NoError(t, err)
+	require.Equal(t, strings.Repeat("abc", 10000), result.Value)
+
+	stats := c.CompressionStats()
+	require.EqualValues(t, 1, stats.Responses)
+	require.EqualValues(t, 1, stats.CompressedResponses)
+	require.Greater(t, stats.DecompressedBytes, int64(30000))
+	require.Greater(t, stats.Ratio(), 10.0)
+
+	// Batches are compressed too.
+	batch := c.NewRequestBatch()
+	_, err = batch.Call(context.Background(), "echo", params, new(echoResult))
+	require.NoError(t, err)
+	results, err := batch.Send(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, results[0].Err)
+	require.Len(t, results[0].Result.(*echoResult).Value, 30000)
+	require.EqualValues(t, 2, c.CompressionStats().CompressedResponses)
+}
+
+func TestCompressionMaxSize(t *testing.T) {
+	srv := newCompressingServer(t)
+
+	c, err := New(srv.URL)
+	require.NoError(t, err)
+	require.NoError(t, c.SetCompression(CompressionOptions{MaxDecompressedSize: 1024}))
+
+	// A response that large is refused, even if it compresses to a few bytes.
+	_, err = c.Call(context.Background(), "echo", map[string]interface{}{"value": "a", "times": 1 << 20}, new(echoResult))
+	require.ErrorIs(t, err, ErrResponseTooLarge)
+
+	_, err = c.Call(context.Background(), "echo", map[string]interface{}{"value": "a", "times": 100}, new(echoResult))
+	require.NoError(t, err)
+}
+
+func TestCompressionUnknownEncoding(t *testing.T) {
+	c, err := New("http://localhost:26657")
+	require.NoError(t, err)
+	require.Error(t, c.SetCompression(CompressionOptions{Encodings: []string{"br"}}))
+}
@@ -0,0 +1,18 @@
+//go:build cgo
+
+package client
+
+import (
+	"io"
+
+	"github.com/DataDog/zstd"
+)
+
+// zstd is registered by default and preferred to gzip when the library it requires, which
+// wraps the C implementation, can be built.
+func init() {
+	RegisterDecompressor("zstd", func(r io.Reader) (io.ReadCloser, error) {
+		return zstd.NewReader(r), nil
+	})
+	DefaultCompressionOptions.Encodings = append([]string{"zstd"}, DefaultCompressionOptions.Encodings...)
+}
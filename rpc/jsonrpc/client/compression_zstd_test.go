@@ -0,0 +1,54 @@
+//go:build cgo
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DataDog/zstd"
+	"github.com/stretchr/testify/require"
+
+	"github.com/strangelove-ventures/cometbft-client/rpc/jsonrpc/types"
+)
+
+func TestCompressionZstd(t *testing.T) {
+	require.Equal(t, []string{"zstd", "gzip"}, DefaultCompressionOptions.Encodings)
+
+	// The server answers with the first accepted encoding, which must be zstd.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req types.RPCRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		var params struct {
+			Times int `json:"times,string"`
+		}
+		require.NoError(t, json.Unmarshal(req.Params, &params))
+
+		if !strings.HasPrefix(r.Header.Get("Accept-Encoding"), "zstd") {
+			http.Error(w, "zstd not accepted", http.StatusNotAcceptable)
+			return
+		}
+		w.Header().Set("Content-Encoding", "zstd")
+		zw := zstd.NewWriter(w)
+		defer zw.Close()
+		_ = json.NewEncoder(zw).Encode(types.NewRPCSuccessResponse(req.ID, echoResult{Value: strings.Repeat("a", params.Times)}))
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL)
+	require.NoError(t, err)
+	require.NoError(t, c.SetCompression(CompressionOptions{MaxDecompressedSize: 1 << 20}))
+
+	result := new(echoResult)
+	_, err = c.Call(context.Background(), "echo", map[string]interface{}{"times": 10000}, result)
+	require.NoError(t, err)
+	require.Len(t, result.Value, 10000)
+	require.EqualValues(t, 1, c.CompressionStats().CompressedResponses)
+
+	_, err = c.Call(context.Background(), "echo", map[string]interface{}{"times": 2 << 20}, new(echoResult))
+	require.ErrorIs(t, err, ErrResponseTooLarge)
+}
@@ -4,12 +4,21 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 
 	cmtjson "github.com/strangelove-ventures/cometbft-client/libs/json"
 	"github.com/strangelove-ventures/cometbft-client/rpc/jsonrpc/types"
 )
 
+var (
+	// ErrNoBatchResponse is the error of a request of a batch which was not answered by the server.
+	ErrNoBatchResponse = errors.New("no response in batch")
+	// ErrDuplicateBatchResponse is the error of a request of a batch which was answered several times.
+	ErrDuplicateBatchResponse = errors.New("duplicate response in batch")
+)
+
 func unmarshalResponseBytes(
+	codec types.Codec,
 	responseBytes []byte,
 	expectedID types.JSONRPCIntID,
 	result interface{},
@@ -31,75 +40,109 @@ func unmarshalResponseBytes(
 	}
 
 	// Unmarshal the RawMessage into the result.
-	if err := cmtjson.Unmarshal(response.Result, result); err != nil {
+	if err := unmarshalResult(codec, response.Result, result); err != nil {
 		return nil, fmt.Errorf("error unmarshalling result: %w", err)
 	}
 
 	return result, nil
 }
 
-func unmarshalResponseBytesArray(
-	responseBytes []byte,
-	expectedIDs []types.JSONRPCIntID,
-	results []interface{},
-) ([]interface{}, error) {
-
-	var (
-		responses []types.RPCResponse
-	)
-
-	if err := json.Unmarshal(responseBytes, &responses); err != nil {
+// decodeBatchResponses decodes the responses of a batch from r into the results of the requests,
+// one response at a time, matching them by ID. It returns an error per request, which is a
+// *types.RPCError if the request failed on the server, ErrNoBatchResponse if the batch holds no
+// response for the request, or ErrDuplicateBatchResponse if it holds several.
+// An error is returned instead if the whole batch failed.
+func decodeBatchResponses(codec types.Codec, r io.Reader, requests []*jsonRPCBufferedRequest) ([]error, error) {
+	dec := cmtjson.NewDecoder(r)
+	tok, err := dec.Token()
+	if err != nil {
 		return nil, fmt.Errorf("error unmarshalling: %w", err)
 	}
+	switch tok {
+	case json.Delim('['):
+	case json.Delim('{'):
+		// The server answers with a single error if it could not handle the batch at all.
+		return nil, decodeBatchError(dec)
+	default:
+		return nil, fmt.Errorf("error unmarshalling: expected [, got %v", tok)
+	}
 
-	// No response error checking here as there may be a mixture of successful
-	// and unsuccessful responses.
-
-	if len(results) != len(responses) {
-		return nil, fmt.Errorf(
-			"expected %d result objects into which to inject responses, but got %d",
-			len(responses),
-			len(results),
-		)
+	indexes := make(map[types.JSONRPCIntID]int, len(requests))
+	for i, req := range requests {
+		indexes[req.request.ID.(types.JSONRPCIntID)] = i
 	}
 
-	// Intersect IDs from responses with expectedIDs.
-	ids := make([]types.JSONRPCIntID, len(responses))
-	var ok bool
-	for i, resp := range responses {
-		ids[i], ok = resp.ID.(types.JSONRPCIntID)
+	errs := make([]error, len(requests))
+	answered := make([]bool, len(requests))
+	for dec.More() {
+		raw, err := dec.Raw()
+		if err != nil {
+			return nil, fmt.Errorf("error unmarshalling: %w", err)
+		}
+		var response types.RPCResponse
+		if err := json.Unmarshal(raw, &response); err != nil {
+			return nil, fmt.Errorf("error unmarshalling: %w", err)
+		}
+
+		id, ok := response.ID.(types.JSONRPCIntID)
 		if !ok {
-			return nil, fmt.Errorf("expected JSONRPCIntID, got %T", resp.ID)
+			continue
+		}
+		i, ok := indexes[id]
+		if !ok {
+			continue
+		}
+		if answered[i] {
+			errs[i] = fmt.Errorf("%w for request %v", ErrDuplicateBatchResponse, id)
+			continue
+		}
+		answered[i] = true
+
+		if response.Error != nil {
+			errs[i] = response.Error
+			continue
+		}
+		if err := unmarshalResult(codec, response.Result, requests[i].result); err != nil {
+			errs[i] = fmt.Errorf("error unmarshalling result: %w", err)
 		}
 	}
-	if err := validateResponseIDs(ids, expectedIDs); err != nil {
-		return nil, fmt.Errorf("wrong IDs: %w", err)
+	if err := expectDelim(dec, ']'); err != nil {
+		return nil, fmt.Errorf("error unmarshalling: %w", err)
 	}
 
-	for i := 0; i < len(responses); i++ {
-		if err := cmtjson.Unmarshal(responses[i].Result, results[i]); err != nil {
-			return nil, fmt.Errorf("error unmarshalling #%d result: %w", i, err)
+	for i, req := range requests {
+		if !answered[i] {
+			errs[i] = fmt.Errorf("%w for request %v", ErrNoBatchResponse, req.request.ID)
 		}
 	}
-
-	return results, nil
+	return errs, nil
 }
 
-func validateResponseIDs(ids, expectedIDs []types.JSONRPCIntID) error {
-	m := make(map[types.JSONRPCIntID]bool, len(expectedIDs))
-	for _, expectedID := range expectedIDs {
-		m[expectedID] = true
-	}
-
-	for i, id := range ids {
-		if m[id] {
-			delete(m, id)
+// decodeBatchError decodes the error of a single response answering a whole batch, whose
+// opening brace was read.
+func decodeBatchError(dec *cmtjson.Decoder) error {
+	var rpcErr *types.RPCError
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return fmt.Errorf("error unmarshalling: %w", err)
+		}
+		if tok != "error" {
+			err = dec.Skip()
 		} else {
-			return fmt.Errorf("unsolicited ID #%d: %v", i, id)
+			var raw json.RawMessage
+			if raw, err = dec.Raw(); err == nil {
+				err = json.Unmarshal(raw, &rpcErr)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("error unmarshalling: %w", err)
 		}
 	}
-
-	return nil
+	if rpcErr == nil {
+		return errors.New("error unmarshalling: expected an array of responses")
+	}
+	return rpcErr
 }
 
 // From the JSON-RPC 2.0 spec:
@@ -5,16 +5,16 @@ import (
 	"net/url"
 	"reflect"
 
-	cmtjson "github.com/strangelove-ventures/cometbft-client/libs/json"
+	"github.com/strangelove-ventures/cometbft-client/rpc/jsonrpc/types"
 )
 
-func argsToURLValues(args map[string]interface{}) (url.Values, error) {
+func argsToURLValues(codec types.Codec, args map[string]interface{}) (url.Values, error) {
 	values := make(url.Values)
 	if len(args) == 0 {
 		return values, nil
 	}
 
-	err := argsToJSON(args)
+	err := argsToJSON(codec, args)
 	if err != nil {
 		return nil, err
 	}
@@ -26,7 +26,7 @@ func argsToURLValues(args map[string]interface{}) (url.Values, error) {
 	return values, nil
 }
 
-func argsToJSON(args map[string]interface{}) error {
+func argsToJSON(codec types.Codec, args map[string]interface{}) error {
 	for k, v := range args {
 		rt := reflect.TypeOf(v)
 		isByteSlice := rt.Kind() == reflect.Slice && rt.Elem().Kind() == reflect.Uint8
@@ -36,7 +36,7 @@ func argsToJSON(args map[string]interface{}) error {
 			continue
 		}
 
-		data, err := cmtjson.Marshal(v)
+		data, err := codec.Marshal(v)
 		if err != nil {
 			return err
 		}
@@ -11,6 +11,7 @@ import (
 	"net/url"
 	"strings"
 
+	cmtjson "github.com/strangelove-ventures/cometbft-client/libs/json"
 	cmtsync "github.com/strangelove-ventures/cometbft-client/libs/sync"
 	"github.com/strangelove-ventures/cometbft-client/rpc/jsonrpc/types"
 )
@@ -130,6 +131,14 @@ type Client struct {
 
 	mtx       cmtsync.Mutex
 	nextReqID int
+
+	singleflight *singleflight
+	coalescer    *coalescer
+	limiter      *RateLimiter
+	compression  *compression
+	codec        types.Codec
+
+	maxResponseSize int64
 }
 
 var _ HTTPClient = (*Client)(nil)
@@ -175,6 +184,7 @@ func NewWithHTTPClient(remote string, client *http.Client) (*Client, error) {
 		username: username,
 		password: password,
 		client:   client,
+		codec:    types.DefaultCodec,
 	}
 
 	return rpcClient, nil
@@ -188,52 +198,129 @@ func (c *Client) Call(
 	params map[string]interface{},
 	result interface{},
 ) (interface{}, error) {
+	if c.singleflight != nil && !IsMutatingMethod(method) {
+		return c.singleflight.call(ctx, method, params, result)
+	}
+	return c.call(ctx, method, params, result)
+}
+
+// call sends a call, as part of a batch if coalescing is enabled.
+func (c *Client) call(
+	ctx context.Context,
+	method string,
+	params map[string]interface{},
+	result interface{},
+) (interface{}, error) {
+	if c.coalescer != nil {
+		return c.coalescer.call(ctx, method, params, result)
+	}
+
 	id := c.nextRequestID()
 
-	request, err := types.MapToRequest(id, method, params)
+	request, err := types.MapToRequestWithCodec(c.codec, id, method, params)
 	if err != nil {
 		return nil, fmt.Errorf("failed to encode params: %w", err)
 	}
 
+	return c.send(ctx, request, result)
+}
+
+// send sends a single request and decodes its result into result.
+func (c *Client) send(ctx context.Context, request types.RPCRequest, result interface{}) (interface{}, error) {
+	err := c.post(ctx, request, func(dec *cmtjson.Decoder) error {
+		raw, err := dec.Raw()
+		if err != nil {
+			return err
+		}
+		return unmarshalResult(c.codec, raw, result)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// post sends a single request, and decodes its result with decodeResult while the response
+// is received.
+func (c *Client) post(ctx context.Context, request types.RPCRequest, decodeResult func(dec *cmtjson.Decoder) error) error {
+	id := request.ID.(types.JSONRPCIntID)
+
 	requestBytes, err := json.Marshal(request)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
+		return fmt.Errorf("failed to marshal request: %w", err)
 	}
 
 	requestBuf := bytes.NewBuffer(requestBytes)
 	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodPost, c.address, requestBuf)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		return fmt.Errorf("request failed: %w", err)
 	}
 
 	httpRequest.Header.Set("Content-Type", "application/json")
+	c.prepareRequest(httpRequest)
 
 	if c.username != "" || c.password != "" {
 		httpRequest.SetBasicAuth(c.username, c.password)
 	}
 
+	class := ClassOf(request.Method)
+	release, err := c.acquire(ctx, class)
+	if err != nil {
+		return err
+	}
+	defer release()
+
 	httpResponse, err := c.client.Do(httpRequest)
 	if err != nil {
-		return nil, fmt.Errorf("post failed: %w", err)
+		return fmt.Errorf("post failed: %w", err)
 	}
 	defer httpResponse.Body.Close()
+	c.observe(class, httpResponse)
 
-	responseBytes, err := io.ReadAll(httpResponse.Body)
+	body, done, err := c.openBody(httpResponse)
 	if err != nil {
-		return nil, fmt.Errorf("%s. Failed to read response body: %w", getHTTPRespErrPrefix(httpResponse), err)
+		return fmt.Errorf("%s. Failed to read response body: %w", getHTTPRespErrPrefix(httpResponse), err)
 	}
+	defer done()
 
-	res, err := unmarshalResponseBytes(responseBytes, id, result)
-	if err != nil {
-		return nil, fmt.Errorf("%s. %w", getHTTPRespErrPrefix(httpResponse), err)
+	if err := decodeResponse(body, id, decodeResult); err != nil {
+		return fmt.Errorf("%s. %w", getHTTPRespErrPrefix(httpResponse), wrapHTTPStatusError(httpResponse, err))
 	}
-	return res, nil
+	return nil
 }
 
 func getHTTPRespErrPrefix(resp *http.Response) string {
 	return fmt.Sprintf("error in json rpc client, with http response metadata: (Status: %s, Protocol %s)", resp.Status, resp.Proto)
 }
 
+// HTTPStatusError is returned when the server answered with a non-2xx HTTP status and a body which
+// is not a JSON-RPC response, e.g. a proxy rate limiting the requests or failing to reach the node.
+type HTTPStatusError struct {
+	StatusCode int
+	Err        error
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("http status %d %s: %v", e.StatusCode, http.StatusText(e.StatusCode), e.Err)
+}
+
+func (e *HTTPStatusError) Unwrap() error {
+	return e.Err
+}
+
+// Temporary returns true for the statuses worth retrying later: 429 Too Many Requests and 5xx.
+func (e *HTTPStatusError) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= http.StatusInternalServerError
+}
+
+// wrapHTTPStatusError wraps the error of decoding the response in a HTTPStatusError if the status is not 2xx.
+func wrapHTTPStatusError(resp *http.Response, err error) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return err
+	}
+	return &HTTPStatusError{StatusCode: resp.StatusCode, Err: err}
+}
+
 func (c *Client) String() string {
 	return fmt.Sprintf("&Client{user=%v, addr=%v, client=%v, nextReqID=%v}", c.username, c.address, c.client, c.nextReqID)
 }
@@ -246,50 +333,102 @@ func (c *Client) NewRequestBatch() *RequestBatch {
 	}
 }
 
-func (c *Client) sendBatch(ctx context.Context, requests []*jsonRPCBufferedRequest) ([]interface{}, error) {
+// sendBatch sends the requests as a batch and decodes the response of each request into its result.
+// It returns an error per request, for the requests which failed, or an error if the whole batch failed.
+func (c *Client) sendBatch(ctx context.Context, requests []*jsonRPCBufferedRequest) ([]error, error) {
+	var errs []error
+	err := c.postBatch(ctx, requests, func(body io.Reader) (err error) {
+		errs, err = decodeBatchResponses(c.codec, body, requests)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return errs, nil
+}
+
+// postBatch posts the requests as a batch and decodes the body of the response with decodeBody,
+// while it is received.
+func (c *Client) postBatch(ctx context.Context, requests []*jsonRPCBufferedRequest, decodeBody func(body io.Reader) error) error {
 	reqs := make([]types.RPCRequest, 0, len(requests))
-	results := make([]interface{}, 0, len(requests))
 	for _, req := range requests {
 		reqs = append(reqs, req.request)
-		results = append(results, req.result)
 	}
 
 	// serialize the array of requests into a single JSON object
 	requestBytes, err := json.Marshal(reqs)
 	if err != nil {
-		return nil, fmt.Errorf("json marshal: %w", err)
+		return fmt.Errorf("json marshal: %w", err)
 	}
 
 	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodPost, c.address, bytes.NewBuffer(requestBytes))
 	if err != nil {
-		return nil, fmt.Errorf("new request: %w", err)
+		return fmt.Errorf("new request: %w", err)
 	}
 
 	httpRequest.Header.Set("Content-Type", "application/json")
+	c.prepareRequest(httpRequest)
 
 	if c.username != "" || c.password != "" {
 		httpRequest.SetBasicAuth(c.username, c.password)
 	}
 
+	// A batch is limited as a single request of its most restricted class.
+	class := ClassQuery
+	for _, req := range reqs {
+		if reqClass := ClassOf(req.Method); reqClass > class {
+			class = reqClass
+		}
+	}
+	release, err := c.acquire(ctx, class)
+	if err != nil {
+		return err
+	}
+	defer release()
+
 	httpResponse, err := c.client.Do(httpRequest)
 	if err != nil {
-		return nil, fmt.Errorf("post: %w", err)
+		return fmt.Errorf("post: %w", err)
 	}
 
 	defer httpResponse.Body.Close()
+	c.observe(class, httpResponse)
 
-	responseBytes, err := io.ReadAll(httpResponse.Body)
+	body, done, err := c.openBody(httpResponse)
 	if err != nil {
-		return nil, fmt.Errorf("read response body: %w", err)
+		return fmt.Errorf("read response body: %w", err)
+	}
+	defer done()
+
+	if err := decodeBody(body); err != nil {
+		return wrapHTTPStatusError(httpResponse, err)
 	}
+	return nil
+}
+
+// SetRateLimiter makes the client send its requests within the limits of l, which may be shared
+// with the other clients of the same endpoint. The limits apply to the HTTP requests, so that a
+// batch counts as a single request.
+//
+// It must be called before the client is used.
+func (c *Client) SetRateLimiter(l *RateLimiter) {
+	c.limiter = l
+}
 
-	// collect ids to check responses IDs in unmarshalResponseBytesArray
-	ids := make([]types.JSONRPCIntID, len(requests))
-	for i, req := range requests {
-		ids[i] = req.request.ID.(types.JSONRPCIntID)
+// acquire waits until a request of class can be sent, if rate limiting is enabled.
+// The returned function must be called once the response was read.
+func (c *Client) acquire(ctx context.Context, class MethodClass) (func(), error) {
+	if c.limiter == nil {
+		return func() {}, nil
 	}
+	return c.limiter.Wait(ctx, class)
+}
 
-	return unmarshalResponseBytesArray(responseBytes, ids, results)
+// observe applies the rate limit headers of a response, if rate limiting is enabled.
+func (c *Client) observe(class MethodClass, resp *http.Response) {
+	if c.limiter != nil {
+		c.limiter.Observe(class, resp.Header)
+	}
 }
 
 func (c *Client) nextRequestID() types.JSONRPCIntID {
@@ -345,16 +484,38 @@ func (b *RequestBatch) clear() int {
 	return count
 }
 
+// BatchResult is the outcome of a request of a batch.
+type BatchResult struct {
+	// Result is the result passed to Call, into which the response was decoded if Err is nil.
+	Result interface{}
+	// Err is the error of the request: a *types.RPCError if it failed on the server,
+	// ErrNoBatchResponse or ErrDuplicateBatchResponse if it was not answered exactly once,
+	// or the error of decoding its result.
+	Err error
+}
+
 // Send will attempt to send the current batch of enqueued requests, and then
 // will clear out the requests once done. On success, this returns the
-// deserialized list of results from each of the enqueued requests.
-func (b *RequestBatch) Send(ctx context.Context) ([]interface{}, error) {
+// result of each of the enqueued requests, in the order of the calls, so that
+// the failed requests can be retried on their own. An error is returned
+// instead if the whole batch failed.
+func (b *RequestBatch) Send(ctx context.Context) ([]BatchResult, error) {
 	b.mtx.Lock()
 	defer func() {
 		b.clear()
 		b.mtx.Unlock()
 	}()
-	return b.client.sendBatch(ctx, b.requests)
+
+	errs, err := b.client.sendBatch(ctx, b.requests)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]BatchResult, len(b.requests))
+	for i, req := range b.requests {
+		results[i] = BatchResult{Result: req.result, Err: errs[i]}
+	}
+	return results, nil
 }
 
 // Call enqueues a request to call the given RPC method with the specified
@@ -366,7 +527,7 @@ func (b *RequestBatch) Call(
 	result interface{},
 ) (interface{}, error) {
 	id := b.client.nextRequestID()
-	request, err := types.MapToRequest(id, method, params)
+	request, err := types.MapToRequestWithCodec(b.client.codec, id, method, params)
 	if err != nil {
 		return nil, err
 	}
@@ -409,7 +570,8 @@ func DefaultHTTPClient(remoteAddr string) (*http.Client, error) {
 
 	client := &http.Client{
 		Transport: &http.Transport{
-			// Set to true to prevent GZIP-bomb DoS attacks
+			// Set to true to prevent GZIP-bomb DoS attacks. Compressed responses can be
+			// accepted with a bounded size with Client.SetCompression instead.
 			DisableCompression: true,
 			Dial:               dialFn,
 		},
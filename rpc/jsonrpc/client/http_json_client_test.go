@@ -1,6 +1,9 @@
 package client
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"io"
 	"log"
 	"net/http"
@@ -8,6 +11,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/strangelove-ventures/cometbft-client/rpc/jsonrpc/types"
 )
 
 func TestHTTPClientMakeHTTPDialer(t *testing.T) {
@@ -84,3 +89,82 @@ func Test_parsedURL(t *testing.T) {
 		})
 	}
 }
+
+func TestHTTPStatusError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "slow down", http.StatusTooManyRequests)
+	}))
+	defer ts.Close()
+
+	c, err := New(ts.URL)
+	require.NoError(t, err)
+
+	var statusErr *HTTPStatusError
+	_, err = c.Call(context.Background(), "status", map[string]interface{}{}, new(struct{}))
+	require.True(t, errors.As(err, &statusErr), err)
+	require.Equal(t, http.StatusTooManyRequests, statusErr.StatusCode)
+	require.True(t, statusErr.Temporary())
+
+	batch := c.NewRequestBatch()
+	_, err = batch.Call(context.Background(), "status", map[string]interface{}{}, new(struct{}))
+	require.NoError(t, err)
+	_, err = batch.Send(context.Background())
+	require.True(t, errors.As(err, &statusErr), err)
+	require.Equal(t, http.StatusTooManyRequests, statusErr.StatusCode)
+}
+
+func TestRequestBatchSend(t *testing.T) {
+	// The server answers "ok" with its "value" param, "fail" with an error, does not answer "missing"
+	// and answers "twice" twice.
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqs []types.RPCRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&reqs))
+
+		var resps []types.RPCResponse
+		for _, req := range reqs {
+			switch req.Method {
+			case "ok":
+				var params map[string]string
+				require.NoError(t, json.Unmarshal(req.Params, &params))
+				resps = append(resps, types.NewRPCSuccessResponse(req.ID, params["value"]))
+			case "fail":
+				resps = append(resps, types.RPCInternalError(req.ID, errors.New("failed")))
+			case "twice":
+				resps = append(resps,
+					types.NewRPCSuccessResponse(req.ID, "first"),
+					types.NewRPCSuccessResponse(req.ID, "second"))
+			}
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(resps))
+	}))
+	defer ts.Close()
+
+	c, err := New(ts.URL)
+	require.NoError(t, err)
+
+	batch := c.NewRequestBatch()
+	methods := []string{"ok", "fail", "missing", "twice", "ok"}
+	for i, method := range methods {
+		_, err := batch.Call(context.Background(), method, map[string]interface{}{"value": method + string(rune('0'+i))}, new(string))
+		require.NoError(t, err)
+	}
+	require.Equal(t, len(methods), batch.Count())
+
+	results, err := batch.Send(context.Background())
+	require.NoError(t, err)
+	require.Len(t, results, len(methods))
+	require.Zero(t, batch.Count())
+
+	require.NoError(t, results[0].Err)
+	require.Equal(t, "ok0", *results[0].Result.(*string))
+
+	var rpcErr *types.RPCError
+	require.True(t, errors.As(results[1].Err, &rpcErr), results[1].Err)
+	require.Equal(t, -32603, rpcErr.Code)
+
+	require.ErrorIs(t, results[2].Err, ErrNoBatchResponse)
+	require.ErrorIs(t, results[3].Err, ErrDuplicateBatchResponse)
+
+	require.NoError(t, results[4].Err)
+	require.Equal(t, "ok4", *results[4].Result.(*string))
+}
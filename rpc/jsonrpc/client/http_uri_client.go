@@ -22,6 +22,7 @@ const (
 type URIClient struct {
 	address string
 	client  *http.Client
+	codec   types.Codec
 }
 
 var _ HTTPClient = (*URIClient)(nil)
@@ -45,6 +46,7 @@ func NewURI(remote string) (*URIClient, error) {
 	uriClient := &URIClient{
 		address: parsedURL.GetTrimmedURL(),
 		client:  httpClient,
+		codec:   types.DefaultCodec,
 	}
 
 	return uriClient, nil
@@ -54,7 +56,7 @@ func NewURI(remote string) (*URIClient, error) {
 func (c *URIClient) Call(ctx context.Context, method string,
 	params map[string]interface{}, result interface{}) (interface{}, error) {
 
-	values, err := argsToURLValues(params)
+	values, err := argsToURLValues(c.codec, params)
 	if err != nil {
 		return nil, fmt.Errorf("failed to encode params: %w", err)
 	}
@@ -81,5 +83,5 @@ func (c *URIClient) Call(ctx context.Context, method string,
 		return nil, fmt.Errorf("read response body: %w", err)
 	}
 
-	return unmarshalResponseBytes(responseBytes, URIClientRequestID, result)
+	return unmarshalResponseBytes(c.codec, responseBytes, URIClientRequestID, result)
 }
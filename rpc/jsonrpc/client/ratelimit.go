@@ -0,0 +1,285 @@
+package client
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// MethodClass is a class of methods with its own rate limiting budget.
+type MethodClass uint8
+
+const (
+	// ClassQuery - the methods reading the state of the node, e.g. status or block.
+	ClassQuery MethodClass = iota
+	// ClassBroadcast - the methods changing the state of the node, see IsMutatingMethod.
+	ClassBroadcast
+	// ClassSubscription - the subscribe, unsubscribe and unsubscribe_all methods.
+	ClassSubscription
+
+	numMethodClasses = iota
+)
+
+func (mc MethodClass) String() string {
+	switch mc {
+	case ClassQuery:
+		return "query"
+	case ClassBroadcast:
+		return "broadcast"
+	case ClassSubscription:
+		return "subscription"
+	default:
+		return "unknown"
+	}
+}
+
+// ClassOf returns the class of method.
+func ClassOf(method string) MethodClass {
+	switch {
+	case method == "subscribe", method == "unsubscribe", method == "unsubscribe_all":
+		return ClassSubscription
+	case IsMutatingMethod(method):
+		return ClassBroadcast
+	default:
+		return ClassQuery
+	}
+}
+
+// Limits are the limits of a class of methods. A zero field means no limit.
+type Limits struct {
+	// RequestsPerSecond is the sustained rate of requests.
+	RequestsPerSecond float64
+	// Burst is the number of requests which can be sent at once after a quiet period.
+	// It is at least 1.
+	Burst int
+	// MaxInFlight is the maximum number of requests waiting for their response.
+	MaxInFlight int
+}
+
+// RateLimitOptions are the limits of every class of methods.
+type RateLimitOptions struct {
+	Query        Limits
+	Broadcast    Limits
+	Subscription Limits
+}
+
+// DefaultRateLimitOptions are limits suitable for most public RPC providers.
+var DefaultRateLimitOptions = RateLimitOptions{
+	Query:        Limits{RequestsPerSecond: 10, Burst: 20, MaxInFlight: 8},
+	Broadcast:    Limits{RequestsPerSecond: 5, Burst: 5, MaxInFlight: 4},
+	Subscription: Limits{RequestsPerSecond: 2, Burst: 5},
+}
+
+// RateLimiter limits the rate and the concurrency of the requests sent to an endpoint, with a
+// token bucket and a semaphore per class of methods. It can be shared by the clients of the
+// same endpoint, see Client.SetRateLimiter and WSClient.SetRateLimiter.
+//
+// The limits can be changed at any time with SetLimits. The requests of a class are also held
+// back as requested by the Retry-After and rate limit headers of the responses.
+//
+// RateLimiter is safe for concurrent use by multiple goroutines.
+type RateLimiter struct {
+	mtx     sync.Mutex
+	buckets [numMethodClasses]*bucket
+}
+
+// bucket is the state of a class of methods.
+type bucket struct {
+	limits Limits
+
+	tokens   float64
+	last     time.Time
+	inFlight int
+	// pausedUntil is set by the Retry-After and rate limit headers.
+	pausedUntil time.Time
+
+	// changed is closed and replaced when a request may proceed earlier than expected.
+	changed chan struct{}
+}
+
+// NewRateLimiter returns a RateLimiter enforcing opts.
+func NewRateLimiter(opts RateLimitOptions) *RateLimiter {
+	l := &RateLimiter{}
+	now := time.Now()
+	for i, limits := range []Limits{opts.Query, opts.Broadcast, opts.Subscription} {
+		limits = limits.normalize()
+		l.buckets[i] = &bucket{
+			limits:  limits,
+			tokens:  float64(limits.Burst),
+			last:    now,
+			changed: make(chan struct{}),
+		}
+	}
+	return l
+}
+
+func (lim Limits) normalize() Limits {
+	if lim.Burst < 1 {
+		lim.Burst = 1
+	}
+	return lim
+}
+
+// Limits returns the current limits of class.
+func (l *RateLimiter) Limits(class MethodClass) Limits {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	return l.buckets[class].limits
+}
+
+// SetLimits changes the limits of class. The requests waiting for the previous limits are
+// re-evaluated immediately.
+func (l *RateLimiter) SetLimits(class MethodClass, limits Limits) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	b := l.buckets[class]
+	b.refill(time.Now())
+	b.limits = limits.normalize()
+	b.tokens = math.Min(b.tokens, float64(b.limits.Burst))
+	b.notify()
+}
+
+// Pause holds back the requests of class for d, e.g. after the server asked to retry later.
+// It does not shorten an earlier pause.
+func (l *RateLimiter) Pause(class MethodClass, d time.Duration) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	b := l.buckets[class]
+	if until := time.Now().Add(d); until.After(b.pausedUntil) {
+		b.pausedUntil = until
+	}
+}
+
+// Wait blocks until a request of class can be sent, or ctx is done. On success, the returned
+// function must be called once the response was received, to release the in-flight slot.
+func (l *RateLimiter) Wait(ctx context.Context, class MethodClass) (release func(), err error) {
+	for {
+		l.mtx.Lock()
+		b := l.buckets[class]
+		now := time.Now()
+		b.refill(now)
+
+		var delay time.Duration
+		switch {
+		case now.Before(b.pausedUntil):
+			delay = b.pausedUntil.Sub(now)
+		case b.limits.MaxInFlight > 0 && b.inFlight >= b.limits.MaxInFlight:
+			// Wait for a release.
+		case b.limits.RequestsPerSecond > 0 && b.tokens < 1:
+			delay = time.Duration((1 - b.tokens) / b.limits.RequestsPerSecond * float64(time.Second))
+		default:
+			if b.limits.RequestsPerSecond > 0 {
+				b.tokens--
+			}
+			b.inFlight++
+			l.mtx.Unlock()
+			return l.releaseFunc(class), nil
+		}
+		changed := b.changed
+		l.mtx.Unlock()
+
+		if err := waitFor(ctx, delay, changed); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// waitFor waits until delay has passed, or changed is closed if delay is 0.
+func waitFor(ctx context.Context, delay time.Duration, changed <-chan struct{}) error {
+	var timeout <-chan time.Time
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case <-timeout:
+	case <-changed:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
+func (l *RateLimiter) releaseFunc(class MethodClass) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mtx.Lock()
+			defer l.mtx.Unlock()
+			b := l.buckets[class]
+			b.inFlight--
+			b.notify()
+		})
+	}
+}
+
+// Observe pauses the requests of class as requested by the headers of a response:
+// Retry-After, or a remaining budget of 0 in the RateLimit-Remaining or X-RateLimit-Remaining
+// header, until the time given by the matching Reset header.
+func (l *RateLimiter) Observe(class MethodClass, header http.Header) {
+	if d, ok := retryAfter(header); ok {
+		l.Pause(class, d)
+	}
+	if d, ok := rateLimitReset(header); ok {
+		l.Pause(class, d)
+	}
+}
+
+// retryAfter parses the Retry-After header, given in seconds or as an HTTP date.
+func retryAfter(header http.Header) (time.Duration, bool) {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds >= 0 {
+		return time.Duration(seconds * float64(time.Second)), true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return time.Until(date), true
+	}
+	return 0, false
+}
+
+// rateLimitReset returns the time until the budget is reset if it was exhausted. The reset is
+// given in seconds, or as a Unix time by the providers using the X-RateLimit-Reset header.
+func rateLimitReset(header http.Header) (time.Duration, bool) {
+	for _, prefix := range []string{"RateLimit-", "X-RateLimit-"} {
+		remaining := header.Get(prefix + "Remaining")
+		if remaining == "" {
+			continue
+		}
+		if n, err := strconv.ParseInt(remaining, 10, 64); err != nil || n > 0 {
+			return 0, false
+		}
+		reset, err := strconv.ParseInt(header.Get(prefix+"Reset"), 10, 64)
+		if err != nil || reset < 0 {
+			return 0, false
+		}
+		// A number of seconds that large is a Unix time.
+		if reset > 1e9 {
+			return time.Until(time.Unix(reset, 0)), true
+		}
+		return time.Duration(reset) * time.Second, true
+	}
+	return 0, false
+}
+
+// refill adds the tokens earned since the last refill. It must be called with the lock held.
+func (b *bucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last); elapsed > 0 && b.limits.RequestsPerSecond > 0 {
+		b.tokens = math.Min(float64(b.limits.Burst), b.tokens+elapsed.Seconds()*b.limits.RequestsPerSecond)
+	}
+	b.last = now
+}
+
+// notify wakes up the waiting requests. It must be called with the lock held.
+func (b *bucket) notify() {
+	close(b.changed)
+	b.changed = make(chan struct{})
+}
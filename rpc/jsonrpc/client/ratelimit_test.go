@@ -0,0 +1,224 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/strangelove-ventures/cometbft-client/rpc/jsonrpc/types"
+)
+
+func TestClassOf(t *testing.T) {
+	require.Equal(t, ClassQuery, ClassOf("block"))
+	require.Equal(t, ClassBroadcast, ClassOf("broadcast_tx_sync"))
+	require.Equal(t, ClassBroadcast, ClassOf("check_tx"))
+	require.Equal(t, ClassSubscription, ClassOf("subscribe"))
+	require.Equal(t, ClassSubscription, ClassOf("unsubscribe_all"))
+}
+
+func TestRateLimiterRate(t *testing.T) {
+	l := NewRateLimiter(RateLimitOptions{Query: Limits{RequestsPerSecond: 50, Burst: 2}})
+
+	start := time.Now()
+	for i := 0; i < 7; i++ {
+		release, err := l.Wait(context.Background(), ClassQuery)
+		require.NoError(t, err)
+		release()
+	}
+	// The burst is sent at once, then a request every 20ms.
+	require.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+
+	// The other classes have their own budget.
+	start = time.Now()
+	for i := 0; i < 10; i++ {
+		release, err := l.Wait(context.Background(), ClassBroadcast)
+		require.NoError(t, err)
+		release()
+	}
+	require.Less(t, time.Since(start), 50*time.Millisecond)
+}
+
+func TestRateLimiterMaxInFlight(t *testing.T) {
+	l := NewRateLimiter(RateLimitOptions{Query: Limits{MaxInFlight: 1}})
+
+	release, err := l.Wait(context.Background(), ClassQuery)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = l.Wait(ctx, ClassQuery)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	acquired := make(chan struct{})
+	go func() {
+		release, err := l.Wait(context.Background(), ClassQuery)
+		require.NoError(t, err)
+		release()
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("acquired more than MaxInFlight")
+	case <-time.After(20 * time.Millisecond):
+	}
+	release()
+	// Releasing twice has no effect.
+	release()
+	<-acquired
+}
+
+func TestRateLimiterSetLimits(t *testing.T) {
+	l := NewRateLimiter(RateLimitOptions{Query: Limits{MaxInFlight: 1}})
+
+	release, err := l.Wait(context.Background(), ClassQuery)
+	require.NoError(t, err)
+	defer release()
+
+	acquired := make(chan struct{})
+	go func() {
+		release, err := l.Wait(context.Background(), ClassQuery)
+		require.NoError(t, err)
+		release()
+		close(acquired)
+	}()
+
+	l.SetLimits(ClassQuery, Limits{MaxInFlight: 2})
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("the new limits were not applied")
+	}
+	require.Equal(t, Limits{MaxInFlight: 2, Burst: 1}, l.Limits(ClassQuery))
+}
+
+func TestRateLimiterObserve(t *testing.T) {
+	d, ok := retryAfter(http.Header{"Retry-After": []string{"3"}})
+	require.True(t, ok)
+	require.Equal(t, 3*time.Second, d)
+
+	date := time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)
+	d, ok = retryAfter(http.Header{"Retry-After": []string{date}})
+	require.True(t, ok)
+	require.InDelta(t, time.Minute, d, float64(2*time.Second))
+
+	_, ok = rateLimitReset(http.Header{"X-Ratelimit-Remaining": []string{"5"}, "X-Ratelimit-Reset": []string{"10"}})
+	require.False(t, ok)
+
+	d, ok = rateLimitReset(http.Header{"Ratelimit-Remaining": []string{"0"}, "Ratelimit-Reset": []string{"10"}})
+	require.True(t, ok)
+	require.Equal(t, 10*time.Second, d)
+
+	reset := strconv.FormatInt(time.Now().Add(time.Minute).Unix(), 10)
+	d, ok = rateLimitReset(http.Header{"X-Ratelimit-Remaining": []string{"0"}, "X-Ratelimit-Reset": []string{reset}})
+	require.True(t, ok)
+	require.InDelta(t, time.Minute, d, float64(2*time.Second))
+
+	l := NewRateLimiter(RateLimitOptions{})
+	l.Observe(ClassBroadcast, http.Header{"Retry-After": []string{"0.1"}})
+
+	start := time.Now()
+	release, err := l.Wait(context.Background(), ClassBroadcast)
+	require.NoError(t, err)
+	release()
+	require.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+}
+
+func TestClientRateLimit(t *testing.T) {
+	var requests, inFlight, maxInFlight atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			max := maxInFlight.Load()
+			if n <= max || maxInFlight.CompareAndSwap(max, n) {
+				break
+			}
+		}
+
+		var req types.RPCRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		if requests.Add(1) == 1 {
+			w.Header().Set("Retry-After", "0.1")
+			http.Error(w, "slow down", http.StatusTooManyRequests)
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+		_ = json.NewEncoder(w).Encode(types.NewRPCSuccessResponse(req.ID, echoResult{Value: "ok"}))
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL)
+	require.NoError(t, err)
+	c.SetRateLimiter(NewRateLimiter(RateLimitOptions{Query: Limits{MaxInFlight: 2}}))
+
+	_, err = c.Call(context.Background(), "echo", nil, new(echoResult))
+	require.Error(t, err)
+
+	// The next requests wait for the Retry-After delay, at most 2 at a time.
+	start := time.Now()
+	errs := make(chan error, 6)
+	for i := 0; i < 6; i++ {
+		go func() {
+			_, err := c.Call(context.Background(), "echo", nil, new(echoResult))
+			errs <- err
+		}()
+	}
+	for i := 0; i < 6; i++ {
+		require.NoError(t, <-errs)
+	}
+	require.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+	require.LessOrEqual(t, maxInFlight.Load(), int32(2))
+}
+
+func TestWSClientRateLimit(t *testing.T) {
+	// The server answers a request only once the next one was received.
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		var last *types.RPCRequest
+		for {
+			var req types.RPCRequest
+			if err := conn.ReadJSON(&req); err != nil {
+				return
+			}
+			if last != nil {
+				_ = conn.WriteJSON(types.RPCResponse{JSONRPC: "2.0", ID: last.ID, Result: json.RawMessage(`{}`)})
+			}
+			last = &req
+		}
+	}))
+	defer s.Close()
+
+	c, err := NewWS("//"+s.Listener.Addr().String(), "/websocket")
+	require.NoError(t, err)
+	c.SetRateLimiter(NewRateLimiter(RateLimitOptions{
+		Query:        Limits{MaxInFlight: 1},
+		Subscription: Limits{MaxInFlight: 1},
+	}))
+	require.NoError(t, c.Start())
+	defer c.Stop() //nolint:errcheck // ignore for tests
+
+	require.NoError(t, c.Subscribe(context.Background(), "tm.event='NewBlock'"))
+
+	// The second subscription waits for the response to the first one.
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, c.Subscribe(ctx, "tm.event='Tx'"), context.DeadlineExceeded)
+
+	// A query has its own budget, and its receipt makes the server answer the subscription.
+	require.NoError(t, c.Call(context.Background(), "status", nil))
+	resp := <-c.ResponsesCh
+	require.Equal(t, types.JSONRPCIntID(0), resp.ID)
+	require.NoError(t, c.Subscribe(context.Background(), "tm.event='Tx'"))
+}
@@ -0,0 +1,138 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/strangelove-ventures/cometbft-client/rpc/jsonrpc/types"
+)
+
+// SetDeduplication makes Call share a single request among the concurrent calls of the same
+// method with the same params. The response is decoded into the result of every call, and a
+// call returns as soon as its own context is done. The request is canceled once every call
+// sharing it is canceled.
+//
+// The calls which change the state of the node, e.g. broadcast_tx_sync or check_tx, are never
+// shared, see IsMutatingMethod.
+//
+// It must be called before the client is used.
+func (c *Client) SetDeduplication(enabled bool) {
+	if !enabled {
+		c.singleflight = nil
+		return
+	}
+	c.singleflight = &singleflight{client: c, flights: make(map[string]*flight)}
+}
+
+// IsMutatingMethod returns true if calling method changes the state of the node, in which case
+// identical calls must all be sent.
+func IsMutatingMethod(method string) bool {
+	switch {
+	case strings.HasPrefix(method, "broadcast_"), strings.HasPrefix(method, "unsafe_"):
+		return true
+	case method == "check_tx", method == "dial_seeds", method == "dial_peers":
+		return true
+	default:
+		return false
+	}
+}
+
+// singleflight shares the in-flight requests of a client among the identical concurrent calls.
+type singleflight struct {
+	client *Client
+
+	mtx     sync.Mutex
+	flights map[string]*flight
+}
+
+// flight is an in-flight request shared by several calls.
+type flight struct {
+	// waiters is the number of calls waiting for the request, guarded by the singleflight lock.
+	waiters int
+	cancel  context.CancelFunc
+
+	// done is closed once raw and err are set.
+	done chan struct{}
+	raw  json.RawMessage
+	err  error
+}
+
+func (sf *singleflight) call(
+	ctx context.Context,
+	method string,
+	params map[string]interface{},
+	result interface{},
+) (interface{}, error) {
+	key, err := flightKey(sf.client.codec, method, params)
+	if err != nil {
+		return nil, err
+	}
+
+	sf.mtx.Lock()
+	f, ok := sf.flights[key]
+	if !ok {
+		// The request outlives the call which started it, as long as other calls wait for it.
+		flightCtx, cancel := context.WithCancel(context.Background())
+		f = &flight{cancel: cancel, done: make(chan struct{})}
+		sf.flights[key] = f
+		go sf.run(flightCtx, key, f, method, params)
+	}
+	f.waiters++
+	sf.mtx.Unlock()
+
+	select {
+	case <-f.done:
+		if f.err != nil {
+			return nil, f.err
+		}
+		// Every call decodes its own copy of the result, so that the callers can't see each
+		// other's changes.
+		if err := unmarshalResult(sf.client.codec, f.raw, result); err != nil {
+			return nil, fmt.Errorf("error unmarshalling result: %w", err)
+		}
+		return result, nil
+
+	case <-ctx.Done():
+		sf.mtx.Lock()
+		f.waiters--
+		if f.waiters == 0 {
+			f.cancel()
+			// A later call must not join the canceled request.
+			if sf.flights[key] == f {
+				delete(sf.flights, key)
+			}
+		}
+		sf.mtx.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+// run sends the request of a flight and releases its calls.
+func (sf *singleflight) run(ctx context.Context, key string, f *flight, method string, params map[string]interface{}) {
+	defer f.cancel()
+
+	var raw json.RawMessage
+	_, err := sf.client.call(ctx, method, params, &raw)
+
+	sf.mtx.Lock()
+	if sf.flights[key] == f {
+		delete(sf.flights, key)
+	}
+	sf.mtx.Unlock()
+
+	f.raw, f.err = raw, err
+	close(f.done)
+}
+
+// flightKey returns the key identifying the identical calls: the method and the encoded params,
+// whose names are sorted.
+func flightKey(codec types.Codec, method string, params map[string]interface{}) (string, error) {
+	request, err := types.MapToRequestWithCodec(codec, types.JSONRPCIntID(0), method, params)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode params: %w", err)
+	}
+	return method + "\x00" + string(request.Params), nil
+}
@@ -0,0 +1,205 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/strangelove-ventures/cometbft-client/rpc/jsonrpc/types"
+)
+
+// newBlockingServer answers every method with its "value" param once release is closed.
+// It counts the requests per method and the requests canceled by the client.
+func newBlockingServer(t *testing.T) (*httptest.Server, chan struct{}, *sync.Map, *atomic.Int32) {
+	release := make(chan struct{})
+	var requests sync.Map
+	var canceled atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req types.RPCRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		count, _ := requests.LoadOrStore(req.Method, new(atomic.Int32))
+		count.(*atomic.Int32).Add(1)
+
+		select {
+		case <-release:
+		case <-r.Context().Done():
+			canceled.Add(1)
+			return
+		}
+
+		var params struct {
+			Value string `json:"value"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			_ = json.NewEncoder(w).Encode(types.RPCInvalidParamsError(req.ID, err))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(types.NewRPCSuccessResponse(req.ID, echoResult{Value: params.Value}))
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, release, &requests, &canceled
+}
+
+func requestCount(requests *sync.Map, method string) int32 {
+	count, ok := requests.Load(method)
+	if !ok {
+		return 0
+	}
+	return count.(*atomic.Int32).Load()
+}
+
+// waitForWaiters waits until n calls wait for an in-flight request.
+func waitForWaiters(t *testing.T, c *Client, n int) {
+	require.Eventually(t, func() bool {
+		c.singleflight.mtx.Lock()
+		defer c.singleflight.mtx.Unlock()
+		waiters := 0
+		for _, f := range c.singleflight.flights {
+			waiters += f.waiters
+		}
+		return waiters == n
+	}, 5*time.Second, time.Millisecond)
+}
+
+func TestDeduplicationSharesRequest(t *testing.T) {
+	srv, release, requests, _ := newBlockingServer(t)
+
+	c, err := New(srv.URL)
+	require.NoError(t, err)
+	c.SetDeduplication(true)
+
+	const calls = 10
+	results := make([]*echoResult, calls)
+	errs := make([]error, calls)
+	var wg sync.WaitGroup
+	for i := 0; i < calls; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = new(echoResult)
+			_, errs[i] = c.Call(context.Background(), "echo", map[string]interface{}{"value": "a"}, results[i])
+		}(i)
+	}
+	// A call with other params is not shared.
+	wg.Add(1)
+	other := new(echoResult)
+	var otherErr error
+	go func() {
+		defer wg.Done()
+		_, otherErr = c.Call(context.Background(), "echo", map[string]interface{}{"value": "b"}, other)
+	}()
+
+	waitForWaiters(t, c, calls+1)
+	close(release)
+	wg.Wait()
+
+	require.EqualValues(t, 2, requestCount(requests, "echo"))
+	for i := 0; i < calls; i++ {
+		require.NoError(t, errs[i])
+		require.Equal(t, "a", results[i].Value)
+	}
+	require.NoError(t, otherErr)
+	require.Equal(t, "b", other.Value)
+
+	// Every caller got its own copy of the result.
+	results[0].Value = "changed"
+	require.Equal(t, "a", results[1].Value)
+	require.Empty(t, c.singleflight.flights)
+}
+
+func TestDeduplicationCancel(t *testing.T) {
+	srv, release, requests, canceled := newBlockingServer(t)
+
+	c, err := New(srv.URL)
+	require.NoError(t, err)
+	c.SetDeduplication(true)
+
+	params := map[string]interface{}{"value": "a"}
+
+	// A canceled call returns without waiting, and without canceling the other calls.
+	ctx, cancel := context.WithCancel(context.Background())
+	canceledErr := make(chan error, 1)
+	go func() {
+		_, err := c.Call(ctx, "echo", params, new(echoResult))
+		canceledErr <- err
+	}()
+	result := new(echoResult)
+	resultErr := make(chan error, 1)
+	go func() {
+		_, err := c.Call(context.Background(), "echo", params, result)
+		resultErr <- err
+	}()
+
+	waitForWaiters(t, c, 2)
+	cancel()
+	require.ErrorIs(t, <-canceledErr, context.Canceled)
+	waitForWaiters(t, c, 1)
+
+	close(release)
+	require.NoError(t, <-resultErr)
+	require.Equal(t, "a", result.Value)
+	require.EqualValues(t, 1, requestCount(requests, "echo"))
+	require.Zero(t, canceled.Load())
+}
+
+func TestDeduplicationCancelAll(t *testing.T) {
+	srv, _, requests, canceled := newBlockingServer(t)
+
+	c, err := New(srv.URL)
+	require.NoError(t, err)
+	c.SetDeduplication(true)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			_, err := c.Call(ctx, "echo", map[string]interface{}{"value": "a"}, new(echoResult))
+			errs <- err
+		}()
+	}
+	waitForWaiters(t, c, 2)
+	require.Eventually(t, func() bool { return requestCount(requests, "echo") == 1 }, 5*time.Second, time.Millisecond)
+
+	// The request is canceled once every call is canceled.
+	cancel()
+	require.ErrorIs(t, <-errs, context.Canceled)
+	require.ErrorIs(t, <-errs, context.Canceled)
+	require.Eventually(t, func() bool { return canceled.Load() == 1 }, 5*time.Second, time.Millisecond)
+}
+
+func TestDeduplicationMutatingMethods(t *testing.T) {
+	srv, release, requests, _ := newBlockingServer(t)
+
+	c, err := New(srv.URL)
+	require.NoError(t, err)
+	c.SetDeduplication(true)
+
+	const calls = 3
+	var wg sync.WaitGroup
+	for i := 0; i < calls; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := c.Call(context.Background(), "broadcast_tx_sync", map[string]interface{}{"value": "tx"}, new(echoResult))
+			require.NoError(t, err)
+		}()
+	}
+
+	require.Eventually(t, func() bool {
+		return requestCount(requests, "broadcast_tx_sync") == calls
+	}, 5*time.Second, time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	require.True(t, IsMutatingMethod("check_tx"))
+	require.False(t, IsMutatingMethod("block"))
+}
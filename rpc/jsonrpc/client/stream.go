@@ -0,0 +1,178 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	cmtjson "github.com/strangelove-ventures/cometbft-client/libs/json"
+	"github.com/strangelove-ventures/cometbft-client/rpc/jsonrpc/types"
+)
+
+// ErrResponseTooLarge is matched by the *ResponseTooLargeError errors.
+var ErrResponseTooLarge = errors.New("response too large")
+
+// ResponseTooLargeError is returned when a response is larger than the maximum size set with
+// Client.SetMaxResponseSize, or decompresses to more than CompressionOptions.MaxDecompressedSize.
+type ResponseTooLargeError struct {
+	// Limit is the maximum size, in bytes.
+	Limit int64
+	// Decompressed is true if the limit is on the decompressed size of the response.
+	Decompressed bool
+}
+
+func (e *ResponseTooLargeError) Error() string {
+	if e.Decompressed {
+		return fmt.Sprintf("%v: decompresses to more than %d bytes", ErrResponseTooLarge, e.Limit)
+	}
+	return fmt.Sprintf("%v: more than %d bytes", ErrResponseTooLarge, e.Limit)
+}
+
+// Is returns true for ErrResponseTooLarge.
+func (e *ResponseTooLargeError) Is(target error) bool {
+	return target == ErrResponseTooLarge
+}
+
+// SetMaxResponseSize makes the client fail with a *ResponseTooLargeError as soon as a response
+// is known to be larger than n bytes, as received. 0 means no limit.
+//
+// It must be called before the client is used.
+func (c *Client) SetMaxResponseSize(n int64) {
+	c.maxResponseSize = n
+}
+
+// limitedReader reads from r until limit bytes were read, and then fails with a
+// *ResponseTooLargeError if there is more to read.
+type limitedReader struct {
+	r            io.Reader
+	remaining    int64
+	limit        int64
+	decompressed bool
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.remaining <= 0 {
+		var b [1]byte
+		if n, err := l.r.Read(b[:]); n == 0 {
+			return 0, err
+		}
+		return 0, &ResponseTooLargeError{Limit: l.limit, Decompressed: l.decompressed}
+	}
+	if int64(len(p)) > l.remaining {
+		p = p[:l.remaining]
+	}
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	return n, err
+}
+
+// openBody returns a reader of the body of a response, decompressed if needed and limited to the
+// maximum sizes. The returned function must be called once the body was read.
+func (c *Client) openBody(resp *http.Response) (io.Reader, func(), error) {
+	var body io.Reader = resp.Body
+	if c.maxResponseSize > 0 {
+		if resp.ContentLength > c.maxResponseSize {
+			return nil, nil, &ResponseTooLargeError{Limit: c.maxResponseSize}
+		}
+		body = &limitedReader{r: body, remaining: c.maxResponseSize, limit: c.maxResponseSize}
+	}
+	if c.compression == nil {
+		return body, func() {}, nil
+	}
+	return c.compression.decompress(resp, body)
+}
+
+// CallStream calls method like Call, but decodes the result while the response is received:
+// decodeResult is given a decoder positioned at the start of the result, and must read exactly
+// one value from it. A large array of the result can then be decoded one element at a time.
+//
+// The call is neither coalesced nor shared with identical calls.
+func (c *Client) CallStream(
+	ctx context.Context,
+	method string,
+	params map[string]interface{},
+	decodeResult func(dec *cmtjson.Decoder) error,
+) error {
+	request, err := types.MapToRequestWithCodec(c.codec, c.nextRequestID(), method, params)
+	if err != nil {
+		return fmt.Errorf("failed to encode params: %w", err)
+	}
+	return c.post(ctx, request, decodeResult)
+}
+
+// decodeResponse decodes a response from r, checking its ID, and decodes its result with
+// decodeResult. The error of the response is returned as a *types.RPCError.
+func decodeResponse(r io.Reader, expectedID types.JSONRPCIntID, decodeResult func(dec *cmtjson.Decoder) error) error {
+	dec := cmtjson.NewDecoder(r)
+	if err := expectDelim(dec, '{'); err != nil {
+		return fmt.Errorf("error unmarshalling: %w", err)
+	}
+
+	var (
+		rawID   json.RawMessage
+		rpcErr  *types.RPCError
+		decoded bool
+	)
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return fmt.Errorf("error unmarshalling: %w", err)
+		}
+		switch tok {
+		case "id":
+			rawID, err = dec.Raw()
+		case "error":
+			var raw json.RawMessage
+			if raw, err = dec.Raw(); err == nil {
+				err = json.Unmarshal(raw, &rpcErr)
+			}
+		case "result":
+			if err := decodeResult(dec); err != nil {
+				return fmt.Errorf("error unmarshalling result: %w", err)
+			}
+			decoded = true
+		default:
+			err = dec.Skip()
+		}
+		if err != nil {
+			return fmt.Errorf("error unmarshalling: %w", err)
+		}
+	}
+	if err := expectDelim(dec, '}'); err != nil {
+		return fmt.Errorf("error unmarshalling: %w", err)
+	}
+
+	if rpcErr != nil {
+		return rpcErr
+	}
+
+	response := &types.RPCResponse{}
+	if rawID != nil {
+		if err := json.Unmarshal([]byte(`{"id":`+string(rawID)+`}`), response); err != nil {
+			return fmt.Errorf("error unmarshalling: %w", err)
+		}
+	}
+	if err := validateAndVerifyID(response, expectedID); err != nil {
+		return fmt.Errorf("wrong ID: %w", err)
+	}
+
+	if !decoded {
+		return errors.New("error unmarshalling result: no result")
+	}
+	return nil
+}
+
+// expectDelim reads the next token, which must be delim.
+func expectDelim(dec *cmtjson.Decoder, delim json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != delim {
+		return fmt.Errorf("expected %v, got %v", delim, tok)
+	}
+	return nil
+}
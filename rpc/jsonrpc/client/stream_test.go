@@ -0,0 +1,172 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	cmtjson "github.com/strangelove-ventures/cometbft-client/libs/json"
+	"github.com/strangelove-ventures/cometbft-client/rpc/jsonrpc/types"
+)
+
+func TestMaxResponseSize(t *testing.T) {
+	srv := newCompressingServer(t)
+
+	c, err := New(srv.URL)
+	require.NoError(t, err)
+	c.SetMaxResponseSize(1024)
+
+	// The response is refused from its Content-Length.
+	_, err = c.Call(context.Background(), "echo", map[string]interface{}{"value": "a", "times": 2000}, new(echoResult))
+	require.ErrorIs(t, err, ErrResponseTooLarge)
+	var tooLarge *ResponseTooLargeError
+	require.ErrorAs(t, err, &tooLarge)
+	require.EqualValues(t, 1024, tooLarge.Limit)
+	require.False(t, tooLarge.Decompressed)
+
+	// A compressed response has no Content-Length, it is refused once the limit is reached.
+	require.NoError(t, c.SetCompression(CompressionOptions{}))
+	_, err = c.Call(context.Background(), "echo", map[string]interface{}{"value": "a", "times": 1 << 20}, new(echoResult))
+	require.ErrorIs(t, err, ErrResponseTooLarge)
+
+	result := new(echoResult)
+	_, err = c.Call(context.Background(), "echo", map[string]interface{}{"value": "a", "times": 100}, result)
+	require.NoError(t, err)
+	require.Len(t, result.Value, 100)
+}
+
+func TestMaxResponseSizeBatch(t *testing.T) {
+	// The server sends the responses of the batch as they come, and then hangs.
+	hang := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqs []types.RPCRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&reqs))
+
+		_, _ = w.Write([]byte("["))
+		for i, req := range reqs {
+			if i > 0 {
+				_, _ = w.Write([]byte(","))
+			}
+			_ = json.NewEncoder(w).Encode(types.NewRPCSuccessResponse(req.ID, strings.Repeat("a", 512)))
+			w.(http.Flusher).Flush()
+		}
+		select {
+		case <-hang:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(hang)
+
+	c, err := New(srv.URL)
+	require.NoError(t, err)
+	c.SetMaxResponseSize(1024)
+
+	batch := c.NewRequestBatch()
+	for i := 0; i < 4; i++ {
+		_, err := batch.Call(context.Background(), "echo", nil, new(string))
+		require.NoError(t, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err = batch.Send(ctx)
+	require.NoError(t, ctx.Err(), "the batch was not refused before the end of the response")
+	var tooLarge *ResponseTooLargeError
+	require.ErrorAs(t, err, &tooLarge)
+	require.EqualValues(t, 1024, tooLarge.Limit)
+}
+
+func TestDecodeBatchResponses(t *testing.T) {
+	requests := []*jsonRPCBufferedRequest{
+		{request: types.RPCRequest{ID: types.JSONRPCIntID(1)}, result: new(string)},
+		{request: types.RPCRequest{ID: types.JSONRPCIntID(2)}, result: new(string)},
+	}
+
+	body := `[{"jsonrpc":"2.0","id":2,"result":"b"},{"jsonrpc":"2.0","id":1,"result":"a"}]`
+	errs, err := decodeBatchResponses(types.DefaultCodec, strings.NewReader(body), requests)
+	require.NoError(t, err)
+	require.Equal(t, []error{nil, nil}, errs)
+	require.Equal(t, "a", *requests[0].result.(*string))
+	require.Equal(t, "b", *requests[1].result.(*string))
+
+	// The server answers with a single error if it could not handle the batch at all.
+	body = `{"jsonrpc":"2.0","id":-1,"error":{"code":-32600,"message":"Invalid Request"}}`
+	_, err = decodeBatchResponses(types.DefaultCodec, strings.NewReader(body), requests)
+	var rpcErr *types.RPCError
+	require.ErrorAs(t, err, &rpcErr)
+	require.Equal(t, -32600, rpcErr.Code)
+
+	_, err = decodeBatchResponses(types.DefaultCodec, strings.NewReader(`[{"jsonrpc":"2.0","id":1`), requests)
+	require.ErrorContains(t, err, "error unmarshalling")
+}
+
+func TestCallStream(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req types.RPCRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		switch req.Method {
+		case "numbers":
+			_ = json.NewEncoder(w).Encode(types.NewRPCSuccessResponse(req.ID, []int64{1, 2, 3}))
+		case "wrong_id":
+			_ = json.NewEncoder(w).Encode(types.NewRPCSuccessResponse(types.JSONRPCIntID(1000), []int64{}))
+		default:
+			_ = json.NewEncoder(w).Encode(types.RPCMethodNotFoundError(req.ID))
+		}
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL)
+	require.NoError(t, err)
+
+	var sum int64
+	err = c.CallStream(context.Background(), "numbers", nil, func(dec *cmtjson.Decoder) error {
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+		for dec.More() {
+			var n int64
+			if err := dec.Decode(&n); err != nil {
+				return err
+			}
+			sum += n
+		}
+		_, err := dec.Token()
+		return err
+	})
+	require.NoError(t, err)
+	require.EqualValues(t, 6, sum)
+
+	skip := func(dec *cmtjson.Decoder) error { return dec.Skip() }
+
+	err = c.CallStream(context.Background(), "wrong_id", nil, skip)
+	require.ErrorContains(t, err, "wrong ID")
+
+	err = c.CallStream(context.Background(), "unknown", nil, skip)
+	var rpcErr *types.RPCError
+	require.ErrorAs(t, err, &rpcErr)
+	require.Equal(t, -32601, rpcErr.Code)
+}
+
+func TestDecodeResponse(t *testing.T) {
+	decode := func(dec *cmtjson.Decoder) error { return dec.Skip() }
+
+	// The fields can come in any order.
+	err := decodeResponse(strings.NewReader(`{"result":{},"id":7,"jsonrpc":"2.0"}`), 7, decode)
+	require.NoError(t, err)
+
+	err = decodeResponse(strings.NewReader(`{"jsonrpc":"2.0","result":{}}`), 7, decode)
+	require.ErrorContains(t, err, "no ID")
+
+	err = decodeResponse(strings.NewReader(`{"jsonrpc":"2.0","id":7}`), 7, decode)
+	require.ErrorContains(t, err, "no result")
+
+	err = decodeResponse(strings.NewReader(`<html>too many requests</html>`), 7, decode)
+	require.ErrorContains(t, err, "error unmarshalling")
+}
@@ -64,6 +64,14 @@ type WSClient struct { //nolint: maligned
 	nextReqID      int
 	// sentIDs        map[types.JSONRPCIntID]bool // IDs of the requests currently in flight
 
+	// Rate limiter of the requests, nil if disabled. The in-flight requests are released
+	// once answered, or when the connection is lost.
+	limiter *RateLimiter
+	pending map[types.JSONRPCIntID]func()
+
+	// Codec of the params of the requests.
+	codec types.Codec
+
 	// Time allowed to write a message to the server. 0 means block until operation succeeds.
 	writeWait time.Duration
 
@@ -112,6 +120,7 @@ func NewWS(remoteAddr, endpoint string, options ...func(*WSClient)) (*WSClient,
 		writeWait:            defaultWriteWait,
 		pingPeriod:           defaultPingPeriod,
 		protocol:             parsedURL.Scheme,
+		codec:                types.DefaultCodec,
 
 		// sentIDs: make(map[types.JSONRPCIntID]bool),
 	}
@@ -162,6 +171,13 @@ func OnReconnect(cb func()) func(*WSClient) {
 	}
 }
 
+// SetRateLimiter makes the client send its requests within the limits of l, which may be shared
+// with the other clients of the same endpoint. It must be called before the client is started.
+func (c *WSClient) SetRateLimiter(l *RateLimiter) {
+	c.limiter = l
+	c.pending = make(map[types.JSONRPCIntID]func())
+}
+
 // String returns WS client full address.
 func (c *WSClient) String() string {
 	return fmt.Sprintf("WSClient{%s (%s)}", c.Address, c.Endpoint)
@@ -200,6 +216,7 @@ func (c *WSClient) Stop() error {
 	// only close user-facing channels when we can't write to them
 	c.wg.Wait()
 	close(c.ResponsesCh)
+	c.releasePending()
 
 	return nil
 }
@@ -220,6 +237,11 @@ func (c *WSClient) IsActive() bool {
 // ResponsesCh, errors, if any, on ErrorsCh. Will block until send succeeds or
 // ctx.Done is closed.
 func (c *WSClient) Send(ctx context.Context, request types.RPCRequest) error {
+	release, err := c.acquire(ctx, request)
+	if err != nil {
+		return err
+	}
+
 	select {
 	case c.send <- request:
 		c.Logger.Info("sent a request", "req", request)
@@ -228,13 +250,66 @@ func (c *WSClient) Send(ctx context.Context, request types.RPCRequest) error {
 		// c.mtx.Unlock()
 		return nil
 	case <-ctx.Done():
+		release()
 		return ctx.Err()
 	}
 }
 
+// acquire waits until the request can be sent, if rate limiting is enabled. The request is
+// released once answered, or with the returned function if it could not be sent.
+func (c *WSClient) acquire(ctx context.Context, request types.RPCRequest) (func(), error) {
+	if c.limiter == nil {
+		return func() {}, nil
+	}
+	release, err := c.limiter.Wait(ctx, ClassOf(request.Method))
+	if err != nil {
+		return nil, err
+	}
+
+	id, ok := request.ID.(types.JSONRPCIntID)
+	if !ok {
+		// The response can't be matched, don't wait for it.
+		release()
+		return func() {}, nil
+	}
+	c.mtx.Lock()
+	c.pending[id] = release
+	c.mtx.Unlock()
+
+	return func() { c.released(id) }, nil
+}
+
+// released releases the in-flight request with the given ID, if any.
+func (c *WSClient) released(id types.JSONRPCIntID) {
+	c.mtx.Lock()
+	release, ok := c.pending[id]
+	delete(c.pending, id)
+	c.mtx.Unlock()
+
+	if ok {
+		release()
+	}
+}
+
+// releasePending releases every in-flight request, which won't be answered after the
+// connection was lost.
+func (c *WSClient) releasePending() {
+	if c.limiter == nil {
+		return
+	}
+	c.mtx.Lock()
+	pending := c.pending
+	c.pending = make(map[types.JSONRPCIntID]func())
+	c.mtx.Unlock()
+
+	for _, release := range pending {
+		release()
+	}
+}
+
 // Call enqueues a call request onto the Send queue. Requests are JSON encoded.
 func (c *WSClient) Call(ctx context.Context, method string, params map[string]interface{}) error {
-	request, err := types.MapToRequest(c.nextRequestID(), method, params)
+	request, err := types.MapToRequestWithCodec(c.codec, c.nextRequestID(), method, params)
 	if err != nil {
 		return err
 	}
@@ -244,7 +319,7 @@ func (c *WSClient) Call(ctx context.Context, method string, params map[string]in
 // CallWithArrayParams enqueues a call request onto the Send queue. Params are
 // in a form of array (e.g. []interface{}{"abcd"}). Requests are JSON encoded.
 func (c *WSClient) CallWithArrayParams(ctx context.Context, method string, params []interface{}) error {
-	request, err := types.ArrayToRequest(c.nextRequestID(), method, params)
+	request, err := types.ArrayToRequestWithCodec(c.codec, c.nextRequestID(), method, params)
 	if err != nil {
 		return err
 	}
@@ -267,7 +342,11 @@ func (c *WSClient) dial() error {
 		Proxy:   http.ProxyFromEnvironment,
 	}
 	rHeader := http.Header{}
-	conn, _, err := dialer.Dial(c.protocol+"://"+c.Address+c.Endpoint, rHeader) //nolint:bodyclose
+	conn, resp, err := dialer.Dial(c.protocol+"://"+c.Address+c.Endpoint, rHeader) //nolint:bodyclose
+	if resp != nil && c.limiter != nil {
+		// The connection is mostly used by the subscriptions.
+		c.limiter.Observe(ClassSubscription, resp.Header)
+	}
 	if err != nil {
 		return err
 	}
@@ -283,6 +362,7 @@ func (c *WSClient) reconnect() error {
 	c.mtx.Lock()
 	c.reconnecting = true
 	c.mtx.Unlock()
+	c.releasePending()
 	defer func() {
 		c.mtx.Lock()
 		c.reconnecting = false
@@ -496,6 +576,9 @@ func (c *WSClient) readRoutine() {
 			c.Logger.Error("error in response ID", "id", response.ID, "err", err)
 			continue
 		}
+		if c.limiter != nil {
+			c.released(response.ID.(types.JSONRPCIntID))
+		}
 
 		// TODO: events resulting from /subscribe do not work with ->
 		// because they are implemented as responses with the subscribe request's
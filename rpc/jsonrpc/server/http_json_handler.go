@@ -9,7 +9,6 @@ import (
 	"reflect"
 	"sort"
 
-	cmtjson "github.com/strangelove-ventures/cometbft-client/libs/json"
 	"github.com/strangelove-ventures/cometbft-client/libs/log"
 	types "github.com/strangelove-ventures/cometbft-client/rpc/jsonrpc/types"
 )
@@ -17,7 +16,7 @@ import (
 // HTTP + JSON handler
 
 // jsonrpc calls grab the given method's function info and runs reflect.Call
-func makeJSONRPCHandler(funcMap map[string]*RPCFunc, logger log.Logger) http.HandlerFunc {
+func makeJSONRPCHandler(funcMap map[string]*RPCFunc, codec types.Codec, logger log.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		b, err := io.ReadAll(r.Body)
 		if err != nil {
@@ -89,7 +88,7 @@ func makeJSONRPCHandler(funcMap map[string]*RPCFunc, logger log.Logger) http.Han
 			ctx := &types.Context{JSONReq: &request, HTTPReq: r}
 			args := []reflect.Value{reflect.ValueOf(ctx)}
 			if len(request.Params) > 0 {
-				fnArgs, err := jsonParamsToArgs(rpcFunc, request.Params)
+				fnArgs, err := jsonParamsToArgs(rpcFunc, codec, request.Params)
 				if err != nil {
 					responses = append(
 						responses,
@@ -111,7 +110,7 @@ func makeJSONRPCHandler(funcMap map[string]*RPCFunc, logger log.Logger) http.Han
 				responses = append(responses, types.RPCInternalError(request.ID, err))
 				continue
 			}
-			responses = append(responses, types.NewRPCSuccessResponse(request.ID, result))
+			responses = append(responses, types.NewRPCSuccessResponseWithCodec(codec, request.ID, result))
 		}
 
 		if len(responses) > 0 {
@@ -143,6 +142,7 @@ func handleInvalidJSONRPCPaths(next http.HandlerFunc) http.HandlerFunc {
 
 func mapParamsToArgs(
 	rpcFunc *RPCFunc,
+	codec types.Codec,
 	params map[string]json.RawMessage,
 	argsOffset int,
 ) ([]reflect.Value, error) {
@@ -152,7 +152,7 @@ func mapParamsToArgs(
 
 		if p, ok := params[argName]; ok && p != nil && len(p) > 0 {
 			val := reflect.New(argType)
-			err := cmtjson.Unmarshal(p, val.Interface())
+			err := codec.Unmarshal(p, val.Interface())
 			if err != nil {
 				return nil, err
 			}
@@ -167,6 +167,7 @@ func mapParamsToArgs(
 
 func arrayParamsToArgs(
 	rpcFunc *RPCFunc,
+	codec types.Codec,
 	params []json.RawMessage,
 	argsOffset int,
 ) ([]reflect.Value, error) {
@@ -179,7 +180,7 @@ func arrayParamsToArgs(
 	for i, p := range params {
 		argType := rpcFunc.args[i+argsOffset]
 		val := reflect.New(argType)
-		err := cmtjson.Unmarshal(p, val.Interface())
+		err := codec.Unmarshal(p, val.Interface())
 		if err != nil {
 			return nil, err
 		}
@@ -195,7 +196,7 @@ func arrayParamsToArgs(
 //
 //	rpcFunc.args = [rpctypes.Context string]
 //	rpcFunc.argNames = ["arg"]
-func jsonParamsToArgs(rpcFunc *RPCFunc, raw []byte) ([]reflect.Value, error) {
+func jsonParamsToArgs(rpcFunc *RPCFunc, codec types.Codec, raw []byte) ([]reflect.Value, error) {
 	const argsOffset = 1
 
 	// TODO: Make more efficient, perhaps by checking the first character for '{' or '['?
@@ -203,14 +204,14 @@ func jsonParamsToArgs(rpcFunc *RPCFunc, raw []byte) ([]reflect.Value, error) {
 	var m map[string]json.RawMessage
 	err := json.Unmarshal(raw, &m)
 	if err == nil {
-		return mapParamsToArgs(rpcFunc, m, argsOffset)
+		return mapParamsToArgs(rpcFunc, codec, m, argsOffset)
 	}
 
 	// Otherwise, try an array.
 	var a []json.RawMessage
 	err = json.Unmarshal(raw, &a)
 	if err == nil {
-		return arrayParamsToArgs(rpcFunc, a, argsOffset)
+		return arrayParamsToArgs(rpcFunc, codec, a, argsOffset)
 	}
 
 	// Otherwise, bad format, we cannot parse
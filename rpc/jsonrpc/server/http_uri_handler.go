@@ -18,7 +18,7 @@ import (
 var reInt = regexp.MustCompile(`^-?[0-9]+$`)
 
 // convert from a function name to the http handler
-func makeHTTPHandler(rpcFunc *RPCFunc, logger log.Logger) func(http.ResponseWriter, *http.Request) {
+func makeHTTPHandler(rpcFunc *RPCFunc, codec types.Codec, logger log.Logger) func(http.ResponseWriter, *http.Request) {
 	// Always return -1 as there's no ID here.
 	dummyID := types.JSONRPCIntID(-1) // URIClientRequestID
 
@@ -39,7 +39,7 @@ func makeHTTPHandler(rpcFunc *RPCFunc, logger log.Logger) func(http.ResponseWrit
 		ctx := &types.Context{HTTPReq: r}
 		args := []reflect.Value{reflect.ValueOf(ctx)}
 
-		fnArgs, err := httpParamsToArgs(rpcFunc, r)
+		fnArgs, err := httpParamsToArgs(rpcFunc, codec, r)
 		if err != nil {
 			res := types.RPCInvalidParamsError(dummyID,
 				fmt.Errorf("error converting http params to arguments: %w", err),
@@ -64,7 +64,7 @@ func makeHTTPHandler(rpcFunc *RPCFunc, logger log.Logger) func(http.ResponseWrit
 			return
 		}
 
-		resp := types.NewRPCSuccessResponse(dummyID, result)
+		resp := types.NewRPCSuccessResponseWithCodec(codec, dummyID, result)
 		if rpcFunc.cacheableWithArgs(args) {
 			err = WriteCacheableRPCResponseHTTP(w, resp)
 		} else {
@@ -79,7 +79,7 @@ func makeHTTPHandler(rpcFunc *RPCFunc, logger log.Logger) func(http.ResponseWrit
 
 // Covert an http query to a list of properly typed values.
 // To be properly decoded the arg must be a concrete type from CometBFT (if its an interface).
-func httpParamsToArgs(rpcFunc *RPCFunc, r *http.Request) ([]reflect.Value, error) {
+func httpParamsToArgs(rpcFunc *RPCFunc, codec types.Codec, r *http.Request) ([]reflect.Value, error) {
 	// skip types.Context
 	const argsOffset = 1
 
@@ -106,7 +106,7 @@ func httpParamsToArgs(rpcFunc *RPCFunc, r *http.Request) ([]reflect.Value, error
 			continue
 		}
 
-		values[i], err = jsonStringToArg(argType, arg)
+		values[i], err = jsonStringToArg(codec, argType, arg)
 		if err != nil {
 			return nil, err
 		}
@@ -115,9 +115,9 @@ func httpParamsToArgs(rpcFunc *RPCFunc, r *http.Request) ([]reflect.Value, error
 	return values, nil
 }
 
-func jsonStringToArg(rt reflect.Type, arg string) (reflect.Value, error) {
+func jsonStringToArg(codec types.Codec, rt reflect.Type, arg string) (reflect.Value, error) {
 	rv := reflect.New(rt)
-	err := cmtjson.Unmarshal([]byte(arg), rv.Interface())
+	err := codec.Unmarshal([]byte(arg), rv.Interface())
 	if err != nil {
 		return rv, err
 	}
@@ -169,8 +169,9 @@ func _nonJSONStringToArg(rt reflect.Type, arg string) (reflect.Value, bool, erro
 	}
 
 	if isIntString && expectingInt {
+		// Integers are quoted as in Amino JSON, which decodes them whatever the codec.
 		qarg := `"` + arg + `"`
-		rv, err := jsonStringToArg(rt, qarg)
+		rv, err := jsonStringToArg(types.AminoCodec{}, rt, qarg)
 		if err != nil {
 			return rv, false, err
 		}
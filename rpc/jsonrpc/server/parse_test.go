@@ -156,7 +156,7 @@ func TestParseJSONRPC(t *testing.T) {
 	for idx, tc := range cases {
 		i := strconv.Itoa(idx)
 		data := []byte(tc.raw)
-		vals, err := jsonParamsToArgs(call, data)
+		vals, err := jsonParamsToArgs(call, types.DefaultCodec, data)
 		if tc.fail {
 			assert.NotNil(t, err, i)
 		} else {
@@ -198,7 +198,7 @@ func TestParseURI(t *testing.T) {
 			tc.raw[0], tc.raw[1])
 		req, err := http.NewRequest("GET", url, nil)
 		assert.NoError(t, err)
-		vals, err := httpParamsToArgs(call, req)
+		vals, err := httpParamsToArgs(call, types.DefaultCodec, req)
 		if tc.fail {
 			assert.NotNil(t, err, i)
 		} else {
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/strangelove-ventures/cometbft-client/libs/log"
+	types "github.com/strangelove-ventures/cometbft-client/rpc/jsonrpc/types"
 )
 
 // RegisterRPCFuncs adds a route for each function in the funcMap, as well as
@@ -14,13 +15,19 @@ import (
 // interface on which the result objects are registered, and is popualted with
 // every RPCResponse
 func RegisterRPCFuncs(mux *http.ServeMux, funcMap map[string]*RPCFunc, logger log.Logger) {
+	RegisterRPCFuncsWithCodec(mux, funcMap, types.DefaultCodec, logger)
+}
+
+// RegisterRPCFuncsWithCodec is like RegisterRPCFuncs, but decodes the params and encodes the
+// results of the calls with codec.
+func RegisterRPCFuncsWithCodec(mux *http.ServeMux, funcMap map[string]*RPCFunc, codec types.Codec, logger log.Logger) {
 	// HTTP endpoints
 	for funcName, rpcFunc := range funcMap {
-		mux.HandleFunc("/"+funcName, makeHTTPHandler(rpcFunc, logger))
+		mux.HandleFunc("/"+funcName, makeHTTPHandler(rpcFunc, codec, logger))
 	}
 
 	// JSONRPC endpoints
-	mux.HandleFunc("/", handleInvalidJSONRPCPaths(makeJSONRPCHandler(funcMap, logger)))
+	mux.HandleFunc("/", handleInvalidJSONRPCPaths(makeJSONRPCHandler(funcMap, codec, logger)))
 }
 
 type Option func(*RPCFunc)
@@ -118,6 +118,9 @@ type wsConnection struct {
 
 	funcMap map[string]*RPCFunc
 
+	// codec of the params and results of the calls
+	codec types.Codec
+
 	// write channel capacity
 	writeChanCapacity int
 
@@ -155,6 +158,7 @@ func newWSConnection(
 		remoteAddr:        baseConn.RemoteAddr().String(),
 		baseConn:          baseConn,
 		funcMap:           funcMap,
+		codec:             types.DefaultCodec,
 		writeWait:         defaultWSWriteWait,
 		writeChanCapacity: defaultWSWriteChanCapacity,
 		readWait:          defaultWSReadWait,
@@ -209,6 +213,14 @@ func PingPeriod(pingPeriod time.Duration) func(*wsConnection) {
 	}
 }
 
+// Codec sets the codec of the params and results of the calls, instead of types.DefaultCodec.
+// It should only be used in the constructor - not Goroutine-safe.
+func Codec(codec types.Codec) func(*wsConnection) {
+	return func(wsc *wsConnection) {
+		wsc.codec = codec
+	}
+}
+
 // ReadLimit sets the maximum size for reading message.
 // It should only be used in the constructor - not Goroutine-safe.
 func ReadLimit(readLimit int64) func(*wsConnection) {
@@ -366,7 +378,7 @@ func (wsc *wsConnection) readRoutine() {
 			ctx := &types.Context{JSONReq: &request, WSConn: wsc}
 			args := []reflect.Value{reflect.ValueOf(ctx)}
 			if len(request.Params) > 0 {
-				fnArgs, err := jsonParamsToArgs(rpcFunc, request.Params)
+				fnArgs, err := jsonParamsToArgs(rpcFunc, wsc.codec, request.Params)
 				if err != nil {
 					if err := wsc.WriteRPCResponse(writeCtx,
 						types.RPCInternalError(request.ID, fmt.Errorf("error converting json params to arguments: %w", err)),
@@ -391,7 +403,7 @@ func (wsc *wsConnection) readRoutine() {
 				continue
 			}
 
-			if err := wsc.WriteRPCResponse(writeCtx, types.NewRPCSuccessResponse(request.ID, result)); err != nil {
+			if err := wsc.WriteRPCResponse(writeCtx, types.NewRPCSuccessResponseWithCodec(wsc.codec, request.ID, result)); err != nil {
 				wsc.Logger.Error("Error writing RPC response", "err", err)
 			}
 		}
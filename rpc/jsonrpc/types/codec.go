@@ -0,0 +1,36 @@
+package types
+
+import (
+	cmtjson "github.com/strangelove-ventures/cometbft-client/libs/json"
+)
+
+// Codec encodes and decodes the params and results of the JSON-RPC calls, which are embedded as
+// raw JSON in the requests and responses. The requests and responses themselves are encoded with
+// encoding/json, as they only hold the JSON-RPC envelope.
+//
+// A codec must be safe for concurrent use, and must encode the values in a way the other end
+// can decode, which is the Amino-compatible JSON of libs/json for CometBFT nodes.
+type Codec interface {
+	// Marshal encodes v as JSON.
+	Marshal(v interface{}) ([]byte, error)
+	// Unmarshal decodes the JSON bz into v, which is a pointer.
+	Unmarshal(bz []byte, v interface{}) error
+}
+
+// AminoCodec is the Amino-compatible codec of libs/json.
+type AminoCodec struct{}
+
+var _ Codec = AminoCodec{}
+
+// Marshal implements Codec.
+func (AminoCodec) Marshal(v interface{}) ([]byte, error) {
+	return cmtjson.Marshal(v)
+}
+
+// Unmarshal implements Codec.
+func (AminoCodec) Unmarshal(bz []byte, v interface{}) error {
+	return cmtjson.Unmarshal(bz, v)
+}
+
+// DefaultCodec is the codec of the clients and handlers which were not given another one.
+var DefaultCodec Codec = AminoCodec{}
@@ -0,0 +1,195 @@
+// Package codectest checks that a JSON-RPC codec encodes and decodes the results of the RPC
+// methods exactly as the Amino-compatible codec does, so that it can replace it.
+package codectest
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/strangelove-ventures/cometbft-client/crypto"
+	"github.com/strangelove-ventures/cometbft-client/crypto/ed25519"
+	ctypes "github.com/strangelove-ventures/cometbft-client/rpc/core/types"
+	rpctypes "github.com/strangelove-ventures/cometbft-client/rpc/jsonrpc/types"
+	"github.com/strangelove-ventures/cometbft-client/types"
+)
+
+// maxDepth bounds the nesting of the values built by fill, for recursive types.
+const maxDepth = 10
+
+// Results returns a value of each result of the RPC methods, with every field set.
+func Results() []interface{} {
+	results := []interface{}{
+		new(ctypes.ResultBlockchainInfo),
+		new(ctypes.ResultGenesis),
+		new(ctypes.ResultGenesisChunk),
+		new(ctypes.ResultBlock),
+		new(ctypes.ResultHeader),
+		new(ctypes.ResultCommit),
+		new(ctypes.ResultBlockResults),
+		new(ctypes.ResultStatus),
+		new(ctypes.ResultNetInfo),
+		new(ctypes.ResultDialSeeds),
+		new(ctypes.ResultDialPeers),
+		new(ctypes.ResultValidators),
+		new(ctypes.ResultConsensusParams),
+		new(ctypes.ResultDumpConsensusState),
+		new(ctypes.ResultConsensusState),
+		new(ctypes.ResultBroadcastTx),
+		new(ctypes.ResultBroadcastTxCommit),
+		new(ctypes.ResultCheckTx),
+		new(ctypes.ResultTx),
+		new(ctypes.ResultTxSearch),
+		new(ctypes.ResultBlockSearch),
+		new(ctypes.ResultUnconfirmedTxs),
+		new(ctypes.ResultABCIInfo),
+		new(ctypes.ResultABCIQuery),
+		new(ctypes.ResultBroadcastEvidence),
+		new(ctypes.ResultUnsafeFlushMempool),
+		new(ctypes.ResultUnsafeProfile),
+		new(ctypes.ResultSubscribe),
+		new(ctypes.ResultUnsubscribe),
+		new(ctypes.ResultHealth),
+		new(ctypes.ResultEvent),
+	}
+	f := &filler{}
+	for _, r := range results {
+		f.fill(reflect.ValueOf(r).Elem(), 0)
+	}
+	return results
+}
+
+// TestCodec checks that codec encodes each of the Results as types.AminoCodec does, and
+// decodes the encoding of types.AminoCodec to the same value.
+func TestCodec(t *testing.T, codec rpctypes.Codec) {
+	amino := rpctypes.AminoCodec{}
+	for _, result := range Results() {
+		rt := reflect.TypeOf(result).Elem()
+		t.Run(rt.Name(), func(t *testing.T) {
+			want, err := amino.Marshal(result)
+			require.NoError(t, err)
+
+			got, err := codec.Marshal(result)
+			require.NoError(t, err)
+			require.JSONEq(t, string(want), string(got), "encoding differs")
+
+			wantValue := reflect.New(rt).Interface()
+			require.NoError(t, amino.Unmarshal(want, wantValue))
+			gotValue := reflect.New(rt).Interface()
+			require.NoError(t, codec.Unmarshal(want, gotValue))
+			require.Equal(t, wantValue, gotValue, "decoding differs")
+
+			// And the decoded value is encoded back as it was received.
+			again, err := codec.Marshal(gotValue)
+			require.NoError(t, err)
+			require.JSONEq(t, string(want), string(again), "round-trip differs")
+		})
+	}
+}
+
+// filler sets every field of a value to a distinct value, which is not the zero value.
+type filler struct {
+	n int
+}
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	rawType       = reflect.TypeOf(json.RawMessage{})
+	pubKeyType    = reflect.TypeOf((*crypto.PubKey)(nil)).Elem()
+	eventType     = reflect.TypeOf((*types.TMEventData)(nil)).Elem()
+	escapedString = "<&>\"\\ é"
+)
+
+func (f *filler) fill(v reflect.Value, depth int) {
+	f.n++
+	n := f.n
+
+	switch v.Type() {
+	case timeType:
+		v.Set(reflect.ValueOf(time.Date(2024, 1, 2, 3, 4, 5, n, time.UTC)))
+		return
+	case rawType:
+		v.SetBytes([]byte(fmt.Sprintf(`{"height":"%d","round":%d}`, n, n)))
+		return
+	case pubKeyType:
+		key := make(ed25519.PubKey, ed25519.PubKeySize)
+		for i := range key {
+			key[i] = byte(n + i)
+		}
+		v.Set(reflect.ValueOf(key))
+		return
+	case eventType:
+		event := new(types.EventDataTx)
+		f.fill(reflect.ValueOf(event).Elem(), depth+1)
+		v.Set(reflect.ValueOf(*event))
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		v.SetBool(true)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
+		v.SetInt(int64(n % 100))
+	case reflect.Int64:
+		// Larger than the integers which float64 represents exactly.
+		v.SetInt(1<<60 + int64(n))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		v.SetUint(uint64(n % 100))
+	case reflect.Uint64:
+		v.SetUint(1<<63 + uint64(n))
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(float64(n) + 0.5)
+	case reflect.String:
+		v.SetString(fmt.Sprintf("%s%d", escapedString, n))
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			bz := make([]byte, 20)
+			for i := range bz {
+				bz[i] = byte(n + i)
+			}
+			v.SetBytes(bz)
+			return
+		}
+		if depth >= maxDepth || v.Type().Elem().Kind() == reflect.Interface && v.Type().Elem() != eventType {
+			// No registered type implements the other interfaces, such as types.Evidence.
+			return
+		}
+		s := reflect.MakeSlice(v.Type(), 2, 2)
+		for i := 0; i < s.Len(); i++ {
+			f.fill(s.Index(i), depth+1)
+		}
+		v.Set(s)
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			f.fill(v.Index(i), depth+1)
+		}
+	case reflect.Map:
+		if depth >= maxDepth {
+			return
+		}
+		m := reflect.MakeMap(v.Type())
+		key := reflect.New(v.Type().Key()).Elem()
+		f.fill(key, depth+1)
+		elem := reflect.New(v.Type().Elem()).Elem()
+		f.fill(elem, depth+1)
+		m.SetMapIndex(key, elem)
+		v.Set(m)
+	case reflect.Ptr:
+		if depth >= maxDepth {
+			return
+		}
+		p := reflect.New(v.Type().Elem())
+		f.fill(p.Elem(), depth+1)
+		v.Set(p)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				f.fill(v.Field(i), depth+1)
+			}
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package codectest
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	rpctypes "github.com/strangelove-ventures/cometbft-client/rpc/jsonrpc/types"
+)
+
+func TestAminoCodec(t *testing.T) {
+	TestCodec(t, rpctypes.AminoCodec{})
+}
+
+func TestResultsFilled(t *testing.T) {
+	for _, result := range Results() {
+		v := reflect.ValueOf(result).Elem()
+		if v.NumField() == 0 {
+			continue
+		}
+		require.False(t, v.IsZero(), "%s", v.Type().Name())
+	}
+}
@@ -7,8 +7,6 @@ import (
 	"net/http"
 	"reflect"
 	"strings"
-
-	cmtjson "github.com/strangelove-ventures/cometbft-client/libs/json"
 )
 
 // a wrapper to emulate a sum type: jsonrpcid = string | int
@@ -99,9 +97,14 @@ func (req RPCRequest) String() string {
 }
 
 func MapToRequest(id jsonrpcid, method string, params map[string]interface{}) (RPCRequest, error) {
+	return MapToRequestWithCodec(AminoCodec{}, id, method, params)
+}
+
+// MapToRequestWithCodec returns a request of named params, each encoded with codec.
+func MapToRequestWithCodec(codec Codec, id jsonrpcid, method string, params map[string]interface{}) (RPCRequest, error) {
 	var paramsMap = make(map[string]json.RawMessage, len(params))
 	for name, value := range params {
-		valueJSON, err := cmtjson.Marshal(value)
+		valueJSON, err := codec.Marshal(value)
 		if err != nil {
 			return RPCRequest{}, err
 		}
@@ -117,9 +120,14 @@ func MapToRequest(id jsonrpcid, method string, params map[string]interface{}) (R
 }
 
 func ArrayToRequest(id jsonrpcid, method string, params []interface{}) (RPCRequest, error) {
+	return ArrayToRequestWithCodec(AminoCodec{}, id, method, params)
+}
+
+// ArrayToRequestWithCodec returns a request of positional params, each encoded with codec.
+func ArrayToRequestWithCodec(codec Codec, id jsonrpcid, method string, params []interface{}) (RPCRequest, error) {
 	var paramsMap = make([]json.RawMessage, len(params))
 	for i, value := range params {
-		valueJSON, err := cmtjson.Marshal(value)
+		valueJSON, err := codec.Marshal(value)
 		if err != nil {
 			return RPCRequest{}, err
 		}
@@ -185,11 +193,16 @@ func (resp *RPCResponse) UnmarshalJSON(data []byte) error {
 }
 
 func NewRPCSuccessResponse(id jsonrpcid, res interface{}) RPCResponse {
+	return NewRPCSuccessResponseWithCodec(AminoCodec{}, id, res)
+}
+
+// NewRPCSuccessResponseWithCodec returns a response of the result res, encoded with codec.
+func NewRPCSuccessResponseWithCodec(codec Codec, id jsonrpcid, res interface{}) RPCResponse {
 	var rawMsg json.RawMessage
 
 	if res != nil {
 		var js []byte
-		js, err := cmtjson.Marshal(res)
+		js, err := codec.Marshal(res)
 		if err != nil {
 			return RPCInternalError(id, fmt.Errorf("error marshaling response: %w", err))
 		}
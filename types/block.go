@@ -1,10 +1,14 @@
 package types
 
 import (
+	"errors"
+	"fmt"
 	"time"
 
 	cmtbytes "github.com/strangelove-ventures/cometbft-client/libs/bytes"
 	cmtsync "github.com/strangelove-ventures/cometbft-client/libs/sync"
+	cmtproto "github.com/strangelove-ventures/cometbft-client/proto/tendermint/types"
+	cmtversion "github.com/strangelove-ventures/cometbft-client/proto/tendermint/version"
 )
 
 // Block defines the atomic unit of a CometBFT blockchain.
@@ -17,6 +21,60 @@ type Block struct {
 	LastCommit *Commit      `json:"last_commit"`
 }
 
+// ToProto converts Block to protobuf
+func (b *Block) ToProto() (*cmtproto.Block, error) {
+	if b == nil {
+		return nil, errors.New("nil Block")
+	}
+
+	pb := new(cmtproto.Block)
+
+	pb.Header = *b.Header.ToProto()
+	pb.LastCommit = b.LastCommit.ToProto()
+	pb.Data = b.Data.ToProto()
+
+	protoEvidence, err := b.Evidence.ToProto()
+	if err != nil {
+		return nil, err
+	}
+	pb.Evidence = *protoEvidence
+
+	return pb, nil
+}
+
+// BlockFromProto converts a protobuf Block to a Block. Unlike CometBFT, the
+// block is not validated.
+func BlockFromProto(bp *cmtproto.Block) (*Block, error) {
+	if bp == nil {
+		return nil, errors.New("nil block")
+	}
+
+	b := new(Block)
+	h, err := HeaderFromProto(&bp.Header)
+	if err != nil {
+		return nil, err
+	}
+	b.Header = h
+	data, err := DataFromProto(&bp.Data)
+	if err != nil {
+		return nil, err
+	}
+	b.Data = data
+	if err := b.Evidence.FromProto(&bp.Evidence); err != nil {
+		return nil, err
+	}
+
+	if bp.LastCommit != nil {
+		lc, err := CommitFromProto(bp.LastCommit)
+		if err != nil {
+			return nil, err
+		}
+		b.LastCommit = lc
+	}
+
+	return b, nil
+}
+
 //-----------------------------------------------------------------------------
 
 // Header defines the structure of a CometBFT block header.
@@ -26,10 +84,10 @@ type Block struct {
 // - https://github.com/cometbft/cometbft/blob/v0.38.x/spec/blockchain/blockchain.md
 type Header struct {
 	// basic block info
-	//Version cmtversion.Consensus `json:"version"`
-	ChainID string    `json:"chain_id"`
-	Height  int64     `json:"height"`
-	Time    time.Time `json:"time"`
+	Version cmtversion.Consensus `json:"version"`
+	ChainID string               `json:"chain_id"`
+	Height  int64                `json:"height"`
+	Time    time.Time            `json:"time"`
 
 	// prev block info
 	LastBlockID BlockID `json:"last_block_id"`
@@ -52,11 +110,74 @@ type Header struct {
 	ProposerAddress Address           `json:"proposer_address"` // original proposer of the block
 }
 
+// ToProto converts Header to protobuf
+func (h *Header) ToProto() *cmtproto.Header {
+	if h == nil {
+		return nil
+	}
+
+	return &cmtproto.Header{
+		Version:            h.Version,
+		ChainID:            h.ChainID,
+		Height:             h.Height,
+		Time:               h.Time,
+		LastBlockId:        h.LastBlockID.ToProto(),
+		ValidatorsHash:     h.ValidatorsHash,
+		NextValidatorsHash: h.NextValidatorsHash,
+		ConsensusHash:      h.ConsensusHash,
+		AppHash:            h.AppHash,
+		DataHash:           h.DataHash,
+		EvidenceHash:       h.EvidenceHash,
+		LastResultsHash:    h.LastResultsHash,
+		LastCommitHash:     h.LastCommitHash,
+		ProposerAddress:    h.ProposerAddress,
+	}
+}
+
+// HeaderFromProto converts a protobuf Header to a Header. Unlike CometBFT, the
+// header is not validated.
+func HeaderFromProto(ph *cmtproto.Header) (Header, error) {
+	if ph == nil {
+		return Header{}, errors.New("nil Header")
+	}
+
+	bi, err := BlockIDFromProto(&ph.LastBlockId)
+	if err != nil {
+		return Header{}, err
+	}
+
+	return Header{
+		Version:            ph.Version,
+		ChainID:            ph.ChainID,
+		Height:             ph.Height,
+		Time:               ph.Time,
+		LastBlockID:        *bi,
+		ValidatorsHash:     ph.ValidatorsHash,
+		NextValidatorsHash: ph.NextValidatorsHash,
+		ConsensusHash:      ph.ConsensusHash,
+		AppHash:            ph.AppHash,
+		DataHash:           ph.DataHash,
+		EvidenceHash:       ph.EvidenceHash,
+		LastResultsHash:    ph.LastResultsHash,
+		LastCommitHash:     ph.LastCommitHash,
+		ProposerAddress:    ph.ProposerAddress,
+	}, nil
+}
+
 //-------------------------------------
 
 // BlockIDFlag indicates which BlockID the signature is for.
 type BlockIDFlag byte
 
+const (
+	// BlockIDFlagAbsent - no vote was received from a validator.
+	BlockIDFlagAbsent BlockIDFlag = iota + 1
+	// BlockIDFlagCommit - voted for the Commit.BlockID.
+	BlockIDFlagCommit
+	// BlockIDFlagNil - voted for nil.
+	BlockIDFlagNil
+)
+
 // CommitSig is a part of the Vote included in a Commit.
 type CommitSig struct {
 	BlockIDFlag      BlockIDFlag `json:"block_id_flag"`
@@ -65,6 +186,30 @@ type CommitSig struct {
 	Signature        []byte      `json:"signature"`
 }
 
+// ToProto converts CommitSig to protobuf
+func (cs *CommitSig) ToProto() *cmtproto.CommitSig {
+	if cs == nil {
+		return nil
+	}
+
+	return &cmtproto.CommitSig{
+		BlockIdFlag:      cmtproto.BlockIDFlag(cs.BlockIDFlag),
+		ValidatorAddress: cs.ValidatorAddress,
+		Timestamp:        cs.Timestamp,
+		Signature:        cs.Signature,
+	}
+}
+
+// FromProto sets a protobuf CommitSig to the given pointer.
+func (cs *CommitSig) FromProto(csp cmtproto.CommitSig) error {
+	cs.BlockIDFlag = BlockIDFlag(csp.BlockIdFlag)
+	cs.ValidatorAddress = csp.ValidatorAddress
+	cs.Timestamp = csp.Timestamp
+	cs.Signature = csp.Signature
+
+	return nil
+}
+
 //-------------------------------------
 
 // ExtendedCommitSig contains a commit signature along with its corresponding
@@ -90,6 +235,55 @@ type Commit struct {
 	Signatures []CommitSig `json:"signatures"`
 }
 
+// ToProto converts Commit to protobuf
+func (commit *Commit) ToProto() *cmtproto.Commit {
+	if commit == nil {
+		return nil
+	}
+
+	c := new(cmtproto.Commit)
+	sigs := make([]cmtproto.CommitSig, len(commit.Signatures))
+	for i := range commit.Signatures {
+		sigs[i] = *commit.Signatures[i].ToProto()
+	}
+	c.Signatures = sigs
+
+	c.Height = commit.Height
+	c.Round = commit.Round
+	c.BlockID = commit.BlockID.ToProto()
+
+	return c
+}
+
+// CommitFromProto converts a protobuf Commit to a Commit. Unlike CometBFT, the
+// commit is not validated.
+func CommitFromProto(cp *cmtproto.Commit) (*Commit, error) {
+	if cp == nil {
+		return nil, errors.New("nil Commit")
+	}
+
+	commit := new(Commit)
+
+	bi, err := BlockIDFromProto(&cp.BlockID)
+	if err != nil {
+		return nil, err
+	}
+
+	sigs := make([]CommitSig, len(cp.Signatures))
+	for i := range cp.Signatures {
+		if err := sigs[i].FromProto(cp.Signatures[i]); err != nil {
+			return nil, err
+		}
+	}
+	commit.Signatures = sigs
+
+	commit.Height = cp.Height
+	commit.Round = cp.Round
+	commit.BlockID = *bi
+
+	return commit, nil
+}
+
 //-------------------------------------
 
 // ExtendedCommit is similar to Commit, except that its signatures also retain
@@ -114,6 +308,42 @@ type Data struct {
 	hash cmtbytes.HexBytes
 }
 
+// ToProto converts Data to protobuf
+func (data *Data) ToProto() cmtproto.Data {
+	tp := new(cmtproto.Data)
+
+	if len(data.Txs) > 0 {
+		txBzs := make([][]byte, len(data.Txs))
+		for i := range data.Txs {
+			txBzs[i] = data.Txs[i]
+		}
+		tp.Txs = txBzs
+	}
+
+	return *tp
+}
+
+// DataFromProto takes a protobuf representation of Data &
+// returns the native type.
+func DataFromProto(dp *cmtproto.Data) (Data, error) {
+	if dp == nil {
+		return Data{}, errors.New("nil data")
+	}
+	data := new(Data)
+
+	if len(dp.Txs) > 0 {
+		txBzs := make(Txs, len(dp.Txs))
+		for i := range dp.Txs {
+			txBzs[i] = Tx(dp.Txs[i])
+		}
+		data.Txs = txBzs
+	} else {
+		data.Txs = Txs{}
+	}
+
+	return *data, nil
+}
+
 //-----------------------------------------------------------------------------
 
 // EvidenceData contains any evidence of malicious wrong-doing by validators
@@ -125,6 +355,36 @@ type EvidenceData struct {
 	byteSize int64
 }
 
+// ToProto converts EvidenceData to protobuf. None of the types of evidence is
+// known to this package, so an error is returned if there is any evidence.
+func (data *EvidenceData) ToProto() (*cmtproto.EvidenceList, error) {
+	if data == nil {
+		return nil, errors.New("nil evidence data")
+	}
+	if len(data.Evidence) > 0 {
+		return nil, fmt.Errorf("toproto: evidence type %T is not supported", data.Evidence[0])
+	}
+
+	return &cmtproto.EvidenceList{Evidence: []cmtproto.Evidence{}}, nil
+}
+
+// FromProto sets a protobuf EvidenceData to the given pointer. None of the
+// types of evidence is known to this package, so an error is returned if
+// there is any evidence.
+func (data *EvidenceData) FromProto(eviData *cmtproto.EvidenceList) error {
+	if eviData == nil {
+		return errors.New("nil evidenceData")
+	}
+	if len(eviData.Evidence) > 0 {
+		return fmt.Errorf("fromproto: evidence type %T is not supported", eviData.Evidence[0].Sum)
+	}
+
+	data.Evidence = EvidenceList{}
+	data.byteSize = int64(eviData.Size())
+
+	return nil
+}
+
 //--------------------------------------------------------------------------------
 
 // BlockID
@@ -132,3 +392,33 @@ type BlockID struct {
 	Hash          cmtbytes.HexBytes `json:"hash"`
 	PartSetHeader PartSetHeader     `json:"parts"`
 }
+
+// ToProto converts BlockID to protobuf
+func (blockID *BlockID) ToProto() cmtproto.BlockID {
+	if blockID == nil {
+		return cmtproto.BlockID{}
+	}
+
+	return cmtproto.BlockID{
+		Hash:          blockID.Hash,
+		PartSetHeader: blockID.PartSetHeader.ToProto(),
+	}
+}
+
+// BlockIDFromProto converts a protobuf BlockID to a BlockID.
+func BlockIDFromProto(bID *cmtproto.BlockID) (*BlockID, error) {
+	if bID == nil {
+		return nil, errors.New("nil BlockID")
+	}
+
+	blockID := new(BlockID)
+	ph, err := PartSetHeaderFromProto(&bID.PartSetHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	blockID.PartSetHeader = *ph
+	blockID.Hash = bID.Hash
+
+	return blockID, nil
+}
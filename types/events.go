@@ -1,6 +1,88 @@
 package types
 
+import (
+	"fmt"
+
+	abci "github.com/strangelove-ventures/cometbft-client/abci/types"
+	cmtjson "github.com/strangelove-ventures/cometbft-client/libs/json"
+	cmtpubsub "github.com/strangelove-ventures/cometbft-client/libs/pubsub"
+	cmtquery "github.com/strangelove-ventures/cometbft-client/libs/pubsub/query"
+)
+
+// Reserved event types (alphabetically sorted).
+const (
+	EventNewBlock            = "NewBlock"
+	EventNewBlockEvents      = "NewBlockEvents"
+	EventNewBlockHeader      = "NewBlockHeader"
+	EventTx                  = "Tx"
+	EventValidatorSetUpdates = "ValidatorSetUpdates"
+)
+
 // TMEventData implements events.EventData.
 type TMEventData interface {
 	// empty interface
 }
+
+func init() {
+	cmtjson.RegisterType(EventDataNewBlock{}, "tendermint/event/NewBlock")
+	cmtjson.RegisterType(EventDataNewBlockHeader{}, "tendermint/event/NewBlockHeader")
+	cmtjson.RegisterType(EventDataNewBlockEvents{}, "tendermint/event/NewBlockEvents")
+	cmtjson.RegisterType(EventDataTx{}, "tendermint/event/Tx")
+	cmtjson.RegisterType(EventDataValidatorSetUpdates{}, "tendermint/event/ValidatorSetUpdates")
+}
+
+// Most event messages are basic types (a block, a transaction)
+// but some (an input to a call tx or a receive) are more exotic
+
+type EventDataNewBlock struct {
+	Block               *Block                     `json:"block"`
+	BlockID             BlockID                    `json:"block_id"`
+	ResultFinalizeBlock abci.ResponseFinalizeBlock `json:"result_finalize_block"`
+}
+
+type EventDataNewBlockHeader struct {
+	Header Header `json:"header"`
+}
+
+type EventDataNewBlockEvents struct {
+	Height int64        `json:"height"`
+	Events []abci.Event `json:"events"`
+	NumTxs int64        `json:"num_txs"` // Number of txs in a block
+}
+
+// EventDataTx is the data of the events fired by every transaction.
+type EventDataTx struct {
+	abci.TxResult
+}
+
+type EventDataValidatorSetUpdates struct {
+	ValidatorUpdates []*Validator `json:"validator_updates"`
+}
+
+// Reserved keys for event queries.
+const (
+	// EventTypeKey is a reserved composite key for event name.
+	EventTypeKey = "tm.event"
+	// TxHashKey is a reserved key, used to specify transaction's hash.
+	// see EventBus#PublishEventTx
+	TxHashKey = "tx.hash"
+	// TxHeightKey is a reserved key, used to specify transaction block's height.
+	// see EventBus#PublishEventTx
+	TxHeightKey = "tx.height"
+
+	// BlockHeightKey is a reserved key used for indexing FinalizeBlock events.
+	BlockHeightKey = "block.height"
+)
+
+// EventQueryTx matches the events of every transaction.
+var EventQueryTx = QueryForEvent(EventTx)
+
+// EventQueryTxFor returns the query matching the event fired by tx.
+func EventQueryTxFor(tx Tx) cmtpubsub.Query {
+	return cmtquery.MustCompile(fmt.Sprintf("%s='%s' AND %s='%X'", EventTypeKey, EventTx, TxHashKey, tx.Hash()))
+}
+
+// QueryForEvent returns the query matching the events of the given type.
+func QueryForEvent(eventType string) cmtpubsub.Query {
+	return cmtquery.MustCompile(fmt.Sprintf("%s='%s'", EventTypeKey, eventType))
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/strangelove-ventures/cometbft-client/crypto"
+	"github.com/strangelove-ventures/cometbft-client/crypto/tmhash"
 	cmtbytes "github.com/strangelove-ventures/cometbft-client/libs/bytes"
 	cmtjson "github.com/strangelove-ventures/cometbft-client/libs/json"
 	cmtos "github.com/strangelove-ventures/cometbft-client/libs/os"
@@ -19,6 +20,9 @@ const (
 	MaxChainIDLen = 50
 )
 
+// ErrGenesisMismatch is returned when a block does not belong to the chain defined by a genesis document.
+var ErrGenesisMismatch = errors.New("genesis does not match chain")
+
 //------------------------------------------------------------
 // core types for a genesis definition
 // NOTE: any changes to the genesis definition should
@@ -53,15 +57,78 @@ func (genDoc *GenesisDoc) SaveAs(file string) error {
 	return cmtos.WriteFile(file, genDocBytes, 0644)
 }
 
-// ValidatorHash returns the hash of the validator set contained in the GenesisDoc
-func (genDoc *GenesisDoc) ValidatorHash() []byte {
-	//vals := make([]*Validator, len(genDoc.Validators))
-	//for i, v := range genDoc.Validators {
-	//	vals[i] = NewValidator(v.PubKey, v.Power)
-	//}
-	//vset := NewValidatorSet(vals)
-	//return vset.Hash()
-	return []byte{}
+// ValidatorHash returns the hash of the validator set contained in the GenesisDoc. It fails if
+// the validators are invalid, or if one of their keys has no protobuf encoding to be hashed.
+func (genDoc *GenesisDoc) ValidatorHash() ([]byte, error) {
+	vset, err := genDoc.ValidatorSet()
+	if err != nil {
+		return nil, err
+	}
+	return vset.Hash()
+}
+
+// ValidatorSet returns the validator set contained in the GenesisDoc.
+func (genDoc *GenesisDoc) ValidatorSet() (*ValidatorSet, error) {
+	vals := make([]*Validator, len(genDoc.Validators))
+	for i, v := range genDoc.Validators {
+		if v.PubKey == nil {
+			return nil, fmt.Errorf("genesis validator %d does not have a public key", i)
+		}
+		vals[i] = NewValidator(v.PubKey, v.Power)
+	}
+	return NewValidatorSet(vals)
+}
+
+// Hash returns the SHA256 hash of the JSON encoding of the GenesisDoc, including the app state.
+// Two genesis documents with the same hash define the same chain, regardless of the
+// formatting of the files they were loaded from.
+func (genDoc *GenesisDoc) Hash() ([]byte, error) {
+	genDocBytes, err := cmtjson.Marshal(genDoc)
+	if err != nil {
+		return nil, err
+	}
+	return tmhash.Sum(genDocBytes), nil
+}
+
+// VerifyInitialHeader checks that header is the first block of the chain defined by the GenesisDoc.
+// It compares the chain ID, the initial height and, if the GenesisDoc lists any validators,
+// the validators hash.
+//
+// Chains whose validators are set by the application in InitChain (e.g. Cosmos SDK chains
+// using gentxs) have no genesis validators, so only the other fields can be checked.
+func (genDoc *GenesisDoc) VerifyInitialHeader(header *Header) error {
+	if header == nil {
+		return errors.New("nil header")
+	}
+
+	initialHeight := genDoc.InitialHeight
+	if initialHeight == 0 {
+		initialHeight = 1
+	}
+
+	if header.ChainID != genDoc.ChainID {
+		return fmt.Errorf("%w: chain ID %q does not match genesis chain ID %q",
+			ErrGenesisMismatch, header.ChainID, genDoc.ChainID)
+	}
+	if header.Height != initialHeight {
+		return fmt.Errorf("%w: header height %d does not match genesis initial height %d",
+			ErrGenesisMismatch, header.Height, initialHeight)
+	}
+
+	if len(genDoc.Validators) == 0 {
+		return nil
+	}
+
+	hash, err := genDoc.ValidatorHash()
+	if err != nil {
+		return fmt.Errorf("invalid genesis validators: %w", err)
+	}
+	if !bytes.Equal(header.ValidatorsHash, hash) {
+		return fmt.Errorf("%w: header validators hash %X does not match genesis validators hash %X",
+			ErrGenesisMismatch, header.ValidatorsHash, hash)
+	}
+
+	return nil
 }
 
 // ValidateAndComplete checks that all necessary fields are present
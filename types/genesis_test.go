@@ -0,0 +1,70 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/strangelove-ventures/cometbft-client/crypto/ed25519"
+	"github.com/strangelove-ventures/cometbft-client/crypto/sr25519"
+)
+
+func TestGenesisDocVerifyInitialHeader(t *testing.T) {
+	pk := ed25519.GenPrivKeyFromSecret([]byte("val1")).PubKey()
+	genDoc := &GenesisDoc{
+		ChainID:       "test-chain",
+		InitialHeight: 5,
+		Validators:    []GenesisValidator{{PubKey: pk, Power: 10}},
+	}
+
+	valHash, err := genDoc.ValidatorHash()
+	require.NoError(t, err)
+	header := &Header{
+		ChainID:        "test-chain",
+		Height:         5,
+		ValidatorsHash: valHash,
+	}
+	require.NoError(t, genDoc.VerifyInitialHeader(header))
+
+	wrongHeight := *header
+	wrongHeight.Height = 1
+	require.ErrorIs(t, genDoc.VerifyInitialHeader(&wrongHeight), ErrGenesisMismatch)
+
+	wrongChain := *header
+	wrongChain.ChainID = "other-chain"
+	require.ErrorIs(t, genDoc.VerifyInitialHeader(&wrongChain), ErrGenesisMismatch)
+
+	wrongVals := *header
+	wrongVals.ValidatorsHash = []byte{1, 2, 3}
+	require.ErrorIs(t, genDoc.VerifyInitialHeader(&wrongVals), ErrGenesisMismatch)
+
+	// Without genesis validators only the chain ID and height can be checked.
+	genDoc.Validators = nil
+	require.NoError(t, genDoc.VerifyInitialHeader(&wrongVals))
+}
+
+func TestGenesisDocValidatorHashInvalid(t *testing.T) {
+	for _, vals := range [][]GenesisValidator{
+		{{Power: 10}},
+		// sr25519 keys have no protobuf encoding to be hashed.
+		{{PubKey: sr25519.GenPrivKeyFromSecret([]byte("val1")).PubKey(), Power: 10}},
+	} {
+		genDoc := &GenesisDoc{ChainID: "test-chain", Validators: vals}
+		_, err := genDoc.ValidatorHash()
+		require.Error(t, err)
+		require.Error(t, genDoc.VerifyInitialHeader(&Header{ChainID: "test-chain", Height: 1}))
+	}
+}
+
+func TestGenesisDocHash(t *testing.T) {
+	genDoc := &GenesisDoc{ChainID: "test-chain", AppState: []byte(`{"a":1}`)}
+
+	hash, err := genDoc.Hash()
+	require.NoError(t, err)
+	require.Len(t, hash, 32)
+
+	genDoc.AppState = []byte(`{"a":2}`)
+	other, err := genDoc.Hash()
+	require.NoError(t, err)
+	require.NotEqual(t, hash, other)
+}
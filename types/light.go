@@ -1,5 +1,11 @@
 package types
 
+import (
+	"errors"
+
+	cmtproto "github.com/strangelove-ventures/cometbft-client/proto/tendermint/types"
+)
+
 // LightBlock is a SignedHeader and a ValidatorSet.
 // It is the basis of the light client
 type LightBlock struct {
@@ -7,9 +13,102 @@ type LightBlock struct {
 	ValidatorSet  *ValidatorSet `json:"validator_set"`
 }
 
+// ToProto converts the LightBlock to protobuf
+func (lb *LightBlock) ToProto() (*cmtproto.LightBlock, error) {
+	if lb == nil {
+		return nil, nil
+	}
+
+	lbp := new(cmtproto.LightBlock)
+	var err error
+	if lb.SignedHeader != nil {
+		lbp.SignedHeader = lb.SignedHeader.ToProto()
+	}
+	if lb.ValidatorSet != nil {
+		lbp.ValidatorSet, err = lb.ValidatorSet.ToProto()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return lbp, nil
+}
+
+// LightBlockFromProto converts from protobuf back into the Lightblock.
+// An error is returned if either the validator set or signed header are invalid
+func LightBlockFromProto(pb *cmtproto.LightBlock) (*LightBlock, error) {
+	if pb == nil {
+		return nil, errors.New("nil light block")
+	}
+
+	lb := new(LightBlock)
+
+	if pb.SignedHeader != nil {
+		sh, err := SignedHeaderFromProto(pb.SignedHeader)
+		if err != nil {
+			return nil, err
+		}
+		lb.SignedHeader = sh
+	}
+
+	if pb.ValidatorSet != nil {
+		vals, err := ValidatorSetFromProto(pb.ValidatorSet)
+		if err != nil {
+			return nil, err
+		}
+		lb.ValidatorSet = vals
+	}
+
+	return lb, nil
+}
+
 // SignedHeader is a header along with the commits that prove it.
 type SignedHeader struct {
 	*Header `json:"header"`
 
 	Commit *Commit `json:"commit"`
 }
+
+// ToProto converts SignedHeader to protobuf
+func (sh *SignedHeader) ToProto() *cmtproto.SignedHeader {
+	if sh == nil {
+		return nil
+	}
+
+	psh := new(cmtproto.SignedHeader)
+	if sh.Header != nil {
+		psh.Header = sh.Header.ToProto()
+	}
+	if sh.Commit != nil {
+		psh.Commit = sh.Commit.ToProto()
+	}
+
+	return psh
+}
+
+// SignedHeaderFromProto converts a protobuf SignedHeader to a SignedHeader.
+func SignedHeaderFromProto(shp *cmtproto.SignedHeader) (*SignedHeader, error) {
+	if shp == nil {
+		return nil, errors.New("nil SignedHeader")
+	}
+
+	sh := new(SignedHeader)
+
+	if shp.Header != nil {
+		h, err := HeaderFromProto(shp.Header)
+		if err != nil {
+			return nil, err
+		}
+		sh.Header = &h
+	}
+
+	if shp.Commit != nil {
+		c, err := CommitFromProto(shp.Commit)
+		if err != nil {
+			return nil, err
+		}
+		sh.Commit = c
+	}
+
+	return sh, nil
+}
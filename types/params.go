@@ -7,6 +7,7 @@ import (
 
 	"github.com/strangelove-ventures/cometbft-client/crypto/ed25519"
 	"github.com/strangelove-ventures/cometbft-client/crypto/secp256k1"
+	cmtproto "github.com/strangelove-ventures/cometbft-client/proto/tendermint/types"
 )
 
 const (
@@ -196,3 +197,52 @@ func (params ConsensusParams) ValidateBasic() error {
 
 	return nil
 }
+
+// ToProto converts ConsensusParams to protobuf
+func (params *ConsensusParams) ToProto() cmtproto.ConsensusParams {
+	return cmtproto.ConsensusParams{
+		Block: &cmtproto.BlockParams{
+			MaxBytes: params.Block.MaxBytes,
+			MaxGas:   params.Block.MaxGas,
+		},
+		Evidence: &cmtproto.EvidenceParams{
+			MaxAgeNumBlocks: params.Evidence.MaxAgeNumBlocks,
+			MaxAgeDuration:  params.Evidence.MaxAgeDuration,
+			MaxBytes:        params.Evidence.MaxBytes,
+		},
+		Validator: &cmtproto.ValidatorParams{
+			PubKeyTypes: params.Validator.PubKeyTypes,
+		},
+		Version: &cmtproto.VersionParams{
+			App: params.Version.App,
+		},
+		Abci: &cmtproto.ABCIParams{
+			VoteExtensionsEnableHeight: params.ABCI.VoteExtensionsEnableHeight,
+		},
+	}
+}
+
+// ConsensusParamsFromProto converts protobuf ConsensusParams to ConsensusParams.
+// The params which are missing are left zero.
+func ConsensusParamsFromProto(pbParams cmtproto.ConsensusParams) ConsensusParams {
+	return ConsensusParams{
+		Block: BlockParams{
+			MaxBytes: pbParams.Block.GetMaxBytes(),
+			MaxGas:   pbParams.Block.GetMaxGas(),
+		},
+		Evidence: EvidenceParams{
+			MaxAgeNumBlocks: pbParams.Evidence.GetMaxAgeNumBlocks(),
+			MaxAgeDuration:  pbParams.Evidence.GetMaxAgeDuration(),
+			MaxBytes:        pbParams.Evidence.GetMaxBytes(),
+		},
+		Validator: ValidatorParams{
+			PubKeyTypes: pbParams.Validator.GetPubKeyTypes(),
+		},
+		Version: VersionParams{
+			App: pbParams.Version.GetApp(),
+		},
+		ABCI: ABCIParams{
+			VoteExtensionsEnableHeight: pbParams.Abci.GetVoteExtensionsEnableHeight(),
+		},
+	}
+}
@@ -2,9 +2,11 @@ package types
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 
 	cmtbytes "github.com/strangelove-ventures/cometbft-client/libs/bytes"
+	cmtproto "github.com/strangelove-ventures/cometbft-client/proto/tendermint/types"
 )
 
 type PartSetHeader struct {
@@ -27,3 +29,27 @@ func (psh PartSetHeader) IsZero() bool {
 func (psh PartSetHeader) Equals(other PartSetHeader) bool {
 	return psh.Total == other.Total && bytes.Equal(psh.Hash, other.Hash)
 }
+
+// ToProto converts PartSetHeader to protobuf
+func (psh *PartSetHeader) ToProto() cmtproto.PartSetHeader {
+	if psh == nil {
+		return cmtproto.PartSetHeader{}
+	}
+
+	return cmtproto.PartSetHeader{
+		Total: psh.Total,
+		Hash:  psh.Hash,
+	}
+}
+
+// PartSetHeaderFromProto converts a protobuf PartSetHeader to a PartSetHeader.
+func PartSetHeaderFromProto(ppsh *cmtproto.PartSetHeader) (*PartSetHeader, error) {
+	if ppsh == nil {
+		return nil, errors.New("nil PartSetHeader")
+	}
+	psh := new(PartSetHeader)
+	psh.Total = ppsh.Total
+	psh.Hash = ppsh.Hash
+
+	return psh, nil
+}
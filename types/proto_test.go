@@ -0,0 +1,247 @@
+package types
+
+import (
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/strangelove-ventures/cometbft-client/crypto/ed25519"
+	"github.com/strangelove-ventures/cometbft-client/crypto/secp256k1"
+	cmtproto "github.com/strangelove-ventures/cometbft-client/proto/tendermint/types"
+	cmtversion "github.com/strangelove-ventures/cometbft-client/proto/tendermint/version"
+)
+
+// The expected encodings were produced by CometBFT v0.38 for the same values.
+var (
+	headerProtoHex = "0a04080b1001120b636f736d6f736875622d341880d1ca08220808a5facdac0610062a480a200102030405060708090a" +
+		"0b0c0d0e0f101112131415161718191a1b1c1d1e1f2012240803122002030405060708090a0b0c0d0e0f101112131415" +
+		"161718191a1b1c1d1e1f20213220030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f2021223a20" +
+		"0405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f20212223422005060708090a0b0c0d0e0f101112" +
+		"131415161718191a1b1c1d1e1f20212223244a20060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f2021" +
+		"2223242552200708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f202122232425265a2008090a0b0c0d0e0f" +
+		"101112131415161718191a1b1c1d1e1f20212223242526276220090a0b0c0d0e0f101112131415161718191a1b1c1d1e" +
+		"1f2021222324252627286a200a0b0c0d0e0f101112131415161718191a1b1c1d1e1f2021222324252627282972140b0c" +
+		"0d0e0f101112131415161718191a1b1c1d1e"
+	commitProtoHex = "08ffd0ca0810011a480a200102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f201224080312" +
+		"2002030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f20212244080212140c0d0e0f1011121314" +
+		"15161718191a1b1c1d1e1f1a0808a5facdac06100622200d0e0f101112131415161718191a1b1c1d1e1f202122232425" +
+		"262728292a2b2c220f08011a0b088092b8c398feffffff012244080312140e0f101112131415161718191a1b1c1d1e1f" +
+		"20211a0808a6facdac06100622200f101112131415161718191a1b1c1d1e1f202122232425262728292a2b2c2d2e"
+	voteProtoHex = "08021080d1ca08180222480a200102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f20122408" +
+		"03122002030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f20212a0808a5facdac061006321410" +
+		"1112131415161718191a1b1c1d1e1f20212223380742201112131415161718191a1b1c1d1e1f20212223242526272829" +
+		"2a2b2c2d2e2f304a09657874656e73696f6e522012131415161718191a1b1c1d1e1f202122232425262728292a2b2c2d" +
+		"2e2f3031"
+	validatorProtoHex = "0a14b3688d0fd292280ab43e0577b0d30d57496e69b712220a20a9029ed540e459a0e71c43141225302a58efd26c404d" +
+		"1805c72b62044ae79662180a20fbffffffffffffffff01"
+	validatorSetProtoHex = "0a3f0a14f16a4a93346afcfa3c6d34d7e562c95754c6cb6b1223122103a119768d663fa681f9d4f5abd218514f3d81f2" +
+		"aa88f157f5698df3608cf1d0c3181420050a470a14b3688d0fd292280ab43e0577b0d30d57496e69b712220a20a9029e" +
+		"d540e459a0e71c43141225302a58efd26c404d1805c72b62044ae79662180a20fbffffffffffffffff01123f0a14f16a" +
+		"4a93346afcfa3c6d34d7e562c95754c6cb6b1223122103a119768d663fa681f9d4f5abd218514f3d81f2aa88f157f569" +
+		"8df3608cf1d0c318142005"
+	paramsProtoHex = "0a10088080c00a10ffffffffffffffffff01120e08a08d0612040880c60a188080401a140a07656432353531390a0973" +
+		"6563703235366b3122002a020864"
+	blockProtoHex = "0a92030a04080b1001120b636f736d6f736875622d341880d1ca08220808a5facdac0610062a480a2001020304050607" +
+		"08090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f2012240803122002030405060708090a0b0c0d0e0f101112" +
+		"131415161718191a1b1c1d1e1f20213220030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f2021" +
+		"223a200405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f20212223422005060708090a0b0c0d0e0f" +
+		"101112131415161718191a1b1c1d1e1f20212223244a20060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e" +
+		"1f20212223242552200708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f202122232425265a2008090a0b0c" +
+		"0d0e0f101112131415161718191a1b1c1d1e1f20212223242526276220090a0b0c0d0e0f101112131415161718191a1b" +
+		"1c1d1e1f2021222324252627286a200a0b0c0d0e0f101112131415161718191a1b1c1d1e1f2021222324252627282972" +
+		"140b0c0d0e0f101112131415161718191a1b1c1d1e120a0a037478310a037478321a0022ee0108ffd0ca0810011a480a" +
+		"200102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f2012240803122002030405060708090a" +
+		"0b0c0d0e0f101112131415161718191a1b1c1d1e1f20212244080212140c0d0e0f101112131415161718191a1b1c1d1e" +
+		"1f1a0808a5facdac06100622200d0e0f101112131415161718191a1b1c1d1e1f202122232425262728292a2b2c220f08" +
+		"011a0b088092b8c398feffffff012244080312140e0f101112131415161718191a1b1c1d1e1f20211a0808a6facdac06" +
+		"100622200f101112131415161718191a1b1c1d1e1f202122232425262728292a2b2c2d2e"
+)
+
+func protoHash(b byte) []byte {
+	h := make([]byte, 32)
+	for i := range h {
+		h[i] = b + byte(i)
+	}
+	return h
+}
+
+var (
+	protoTime    = time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+	protoBlockID = BlockID{Hash: protoHash(1), PartSetHeader: PartSetHeader{Total: 3, Hash: protoHash(2)}}
+)
+
+func protoHeader() Header {
+	return Header{
+		Version:            cmtversion.Consensus{Block: 11, App: 1},
+		ChainID:            "cosmoshub-4",
+		Height:             18000000,
+		Time:               protoTime,
+		LastBlockID:        protoBlockID,
+		LastCommitHash:     protoHash(3),
+		DataHash:           protoHash(4),
+		ValidatorsHash:     protoHash(5),
+		NextValidatorsHash: protoHash(6),
+		ConsensusHash:      protoHash(7),
+		AppHash:            protoHash(8),
+		LastResultsHash:    protoHash(9),
+		EvidenceHash:       protoHash(10),
+		ProposerAddress:    protoHash(11)[:20],
+	}
+}
+
+func protoCommit() *Commit {
+	return &Commit{
+		Height:  17999999,
+		Round:   1,
+		BlockID: protoBlockID,
+		Signatures: []CommitSig{
+			{BlockIDFlag: BlockIDFlagCommit, ValidatorAddress: protoHash(12)[:20], Timestamp: protoTime, Signature: protoHash(13)},
+			{BlockIDFlag: BlockIDFlagAbsent},
+			{BlockIDFlag: BlockIDFlagNil, ValidatorAddress: protoHash(14)[:20], Timestamp: protoTime.Add(time.Second), Signature: protoHash(15)},
+		},
+	}
+}
+
+// unhex decodes the hex encoding of a message.
+func unhex(t *testing.T, s string) []byte {
+	bz, err := hex.DecodeString(s)
+	require.NoError(t, err)
+	return bz
+}
+
+// marshaler is a protobuf message.
+type marshaler interface {
+	Marshal() ([]byte, error)
+}
+
+func requireEncoding(t *testing.T, expected string, m marshaler) {
+	t.Helper()
+	bz, err := m.Marshal()
+	require.NoError(t, err)
+	require.Equal(t, expected, hex.EncodeToString(bz))
+}
+
+func TestHeaderProto(t *testing.T) {
+	h := protoHeader()
+	requireEncoding(t, headerProtoHex, h.ToProto())
+
+	var ph cmtproto.Header
+	require.NoError(t, ph.Unmarshal(unhex(t, headerProtoHex)))
+	h2, err := HeaderFromProto(&ph)
+	require.NoError(t, err)
+	require.Equal(t, h, h2)
+}
+
+func TestCommitProto(t *testing.T) {
+	c := protoCommit()
+	requireEncoding(t, commitProtoHex, c.ToProto())
+
+	var pc cmtproto.Commit
+	require.NoError(t, pc.Unmarshal(unhex(t, commitProtoHex)))
+	c2, err := CommitFromProto(&pc)
+	require.NoError(t, err)
+	require.Equal(t, c, c2)
+}
+
+func TestVoteProto(t *testing.T) {
+	v := &Vote{
+		Type:               cmtproto.PrecommitType,
+		Height:             18000000,
+		Round:              2,
+		BlockID:            protoBlockID,
+		Timestamp:          protoTime,
+		ValidatorAddress:   protoHash(16)[:20],
+		ValidatorIndex:     7,
+		Signature:          protoHash(17),
+		Extension:          []byte("extension"),
+		ExtensionSignature: protoHash(18),
+	}
+	requireEncoding(t, voteProtoHex, v.ToProto())
+
+	var pv cmtproto.Vote
+	require.NoError(t, pv.Unmarshal(unhex(t, voteProtoHex)))
+	v2, err := VoteFromProto(&pv)
+	require.NoError(t, err)
+	require.Equal(t, v, v2)
+}
+
+func TestValidatorSetProto(t *testing.T) {
+	pk1 := ed25519.GenPrivKeyFromSecret([]byte("val1")).PubKey()
+	pk2 := secp256k1.GenPrivKeySecp256k1([]byte("val2")).PubKey()
+	v1 := &Validator{Address: pk1.Address(), PubKey: pk1, VotingPower: 10, ProposerPriority: -5}
+	v2 := &Validator{Address: pk2.Address(), PubKey: pk2, VotingPower: 20, ProposerPriority: 5}
+
+	pv, err := v1.ToProto()
+	require.NoError(t, err)
+	requireEncoding(t, validatorProtoHex, pv)
+
+	var pv2 cmtproto.Validator
+	require.NoError(t, pv2.Unmarshal(unhex(t, validatorProtoHex)))
+	v, err := ValidatorFromProto(&pv2)
+	require.NoError(t, err)
+	require.Equal(t, v1, v)
+
+	vals := &ValidatorSet{Validators: []*Validator{v2, v1}, Proposer: v2}
+	require.Equal(t, int64(30), vals.TotalVotingPower())
+	pvs, err := vals.ToProto()
+	require.NoError(t, err)
+	requireEncoding(t, validatorSetProtoHex, pvs)
+
+	var pvs2 cmtproto.ValidatorSet
+	require.NoError(t, pvs2.Unmarshal(unhex(t, validatorSetProtoHex)))
+	vals2, err := ValidatorSetFromProto(&pvs2)
+	require.NoError(t, err)
+	require.Equal(t, vals, vals2)
+
+	// A set without a proposer, as returned by NewValidatorSet, is encoded without one.
+	vals3, err := NewValidatorSet([]*Validator{v1, v2})
+	require.NoError(t, err)
+	pvs3, err := vals3.ToProto()
+	require.NoError(t, err)
+	require.Nil(t, pvs3.Proposer)
+	vals4, err := ValidatorSetFromProto(pvs3)
+	require.NoError(t, err)
+	require.Equal(t, vals3, vals4)
+}
+
+func TestConsensusParamsProto(t *testing.T) {
+	params := DefaultConsensusParams()
+	params.ABCI.VoteExtensionsEnableHeight = 100
+	params.Validator.PubKeyTypes = []string{ABCIPubKeyTypeEd25519, ABCIPubKeyTypeSecp256k1}
+	pp := params.ToProto()
+	requireEncoding(t, paramsProtoHex, &pp)
+
+	var pp2 cmtproto.ConsensusParams
+	require.NoError(t, pp2.Unmarshal(unhex(t, paramsProtoHex)))
+	require.Equal(t, *params, ConsensusParamsFromProto(pp2))
+
+	// The missing params are left zero.
+	require.Equal(t, ConsensusParams{}, ConsensusParamsFromProto(cmtproto.ConsensusParams{}))
+}
+
+func TestBlockProto(t *testing.T) {
+	b := &Block{
+		Header:     protoHeader(),
+		Data:       Data{Txs: Txs{Tx("tx1"), Tx("tx2")}},
+		Evidence:   EvidenceData{Evidence: EvidenceList{}},
+		LastCommit: protoCommit(),
+	}
+	pb, err := b.ToProto()
+	require.NoError(t, err)
+	requireEncoding(t, blockProtoHex, pb)
+
+	var pb2 cmtproto.Block
+	require.NoError(t, pb2.Unmarshal(unhex(t, blockProtoHex)))
+	b2, err := BlockFromProto(&pb2)
+	require.NoError(t, err)
+	require.Equal(t, b.Header, b2.Header)
+	require.Equal(t, b.Data, b2.Data)
+	require.Equal(t, b.LastCommit, b2.LastCommit)
+	require.Empty(t, b2.Evidence.Evidence)
+
+	// Truncated messages are rejected.
+	bz := unhex(t, blockProtoHex)
+	require.Error(t, new(cmtproto.Block).Unmarshal(bz[:len(bz)-1]))
+}
@@ -0,0 +1,59 @@
+package types
+
+import (
+	abci "github.com/strangelove-ventures/cometbft-client/abci/types"
+	cryptoenc "github.com/strangelove-ventures/cometbft-client/crypto/encoding"
+)
+
+// TM2PB is used for converting CometBFT ABCI to protobuf ABCI.
+// UNSTABLE
+var TM2PB = tm2pb{}
+
+type tm2pb struct{}
+
+// ValidatorUpdate returns the update of the validator, with its public key and voting power.
+func (tm2pb) ValidatorUpdate(val *Validator) (abci.ValidatorUpdate, error) {
+	pk, err := cryptoenc.PubKeyToProto(val.PubKey)
+	if err != nil {
+		return abci.ValidatorUpdate{}, err
+	}
+	return abci.ValidatorUpdate{
+		PubKey: pk,
+		Power:  val.VotingPower,
+	}, nil
+}
+
+// ValidatorUpdates returns the updates of the validators of vals.
+func (tm2pb) ValidatorUpdates(vals *ValidatorSet) ([]abci.ValidatorUpdate, error) {
+	validators := make([]abci.ValidatorUpdate, vals.Size())
+	for i, val := range vals.Validators {
+		update, err := TM2PB.ValidatorUpdate(val)
+		if err != nil {
+			return nil, err
+		}
+		validators[i] = update
+	}
+	return validators, nil
+}
+
+//----------------------------------------------------------------------------
+
+// PB2TM is used for converting protobuf ABCI to CometBFT ABCI.
+// UNSTABLE
+var PB2TM = pb2tm{}
+
+type pb2tm struct{}
+
+// ValidatorUpdates returns the validators of the updates, such as those of
+// ResultBlockResults, whose addresses are derived from their public keys.
+func (pb2tm) ValidatorUpdates(vals []abci.ValidatorUpdate) ([]*Validator, error) {
+	tmVals := make([]*Validator, len(vals))
+	for i, v := range vals {
+		pub, err := cryptoenc.PubKeyFromProto(v.PubKey)
+		if err != nil {
+			return nil, err
+		}
+		tmVals[i] = NewValidator(pub, v.Power)
+	}
+	return tmVals, nil
+}
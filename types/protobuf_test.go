@@ -0,0 +1,42 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	abci "github.com/strangelove-ventures/cometbft-client/abci/types"
+	"github.com/strangelove-ventures/cometbft-client/crypto/bls12381"
+	"github.com/strangelove-ventures/cometbft-client/crypto/ed25519"
+	"github.com/strangelove-ventures/cometbft-client/crypto/secp256k1"
+	cmtjson "github.com/strangelove-ventures/cometbft-client/libs/json"
+)
+
+func TestValidatorUpdates(t *testing.T) {
+	vset, err := NewValidatorSet([]*Validator{
+		NewValidator(ed25519.GenPrivKeyFromSecret([]byte("val1")).PubKey(), 10),
+		NewValidator(secp256k1.GenPrivKeySecp256k1([]byte("val2")).PubKey(), 20),
+		NewValidator(bls12381.GenPrivKeyFromSecret([]byte("val3")).PubKey(), 30),
+	})
+	require.NoError(t, err)
+
+	updates, err := TM2PB.ValidatorUpdates(vset)
+	require.NoError(t, err)
+
+	// As received in the block results.
+	bz, err := cmtjson.Marshal(updates)
+	require.NoError(t, err)
+	var received []abci.ValidatorUpdate
+	require.NoError(t, cmtjson.Unmarshal(bz, &received))
+
+	vals, err := PB2TM.ValidatorUpdates(received)
+	require.NoError(t, err)
+	require.Len(t, vals, vset.Size())
+	for i, val := range vals {
+		_, want := vset.GetByAddress(val.Address)
+		require.NotNil(t, want, "validator %d", i)
+		assert.Equal(t, want.PubKey, val.PubKey)
+		assert.Equal(t, want.VotingPower, val.VotingPower)
+	}
+}
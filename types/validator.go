@@ -1,6 +1,14 @@
 package types
 
-import "github.com/strangelove-ventures/cometbft-client/crypto"
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/strangelove-ventures/cometbft-client/crypto"
+	ce "github.com/strangelove-ventures/cometbft-client/crypto/encoding"
+	cmtproto "github.com/strangelove-ventures/cometbft-client/proto/tendermint/types"
+)
 
 // Volatile state for each Validator
 // NOTE: The ProposerPriority is not included in Validator.Hash();
@@ -12,3 +20,118 @@ type Validator struct {
 
 	ProposerPriority int64 `json:"proposer_priority"`
 }
+
+// NewValidator returns a new validator with the given pubkey and voting power.
+func NewValidator(pubKey crypto.PubKey, votingPower int64) *Validator {
+	return &Validator{
+		Address:          pubKey.Address(),
+		PubKey:           pubKey,
+		VotingPower:      votingPower,
+		ProposerPriority: 0,
+	}
+}
+
+// ValidateBasic performs basic validation.
+func (v *Validator) ValidateBasic() error {
+	if v == nil {
+		return errors.New("nil validator")
+	}
+	if v.PubKey == nil {
+		return errors.New("validator does not have a public key")
+	}
+
+	if v.VotingPower < 0 {
+		return errors.New("validator has negative voting power")
+	}
+
+	addr := v.PubKey.Address()
+	if !bytes.Equal(v.Address, addr) {
+		return fmt.Errorf("validator address is incorrectly derived from pubkey. Exp: %v, got %v", addr, v.Address)
+	}
+
+	return nil
+}
+
+// Copy creates a new copy of the validator so we can mutate ProposerPriority.
+// Panics if the validator is nil.
+func (v *Validator) Copy() *Validator {
+	vCopy := *v
+	return &vCopy
+}
+
+// String returns a string representation of String.
+//
+// 1. address
+// 2. public key
+// 3. voting power
+// 4. proposer priority
+func (v *Validator) String() string {
+	if v == nil {
+		return "nil-Validator"
+	}
+	return fmt.Sprintf("Validator{%v %v VP:%v A:%v}",
+		v.Address,
+		v.PubKey,
+		v.VotingPower,
+		v.ProposerPriority)
+}
+
+// Bytes computes the unique encoding of a validator with a given voting power.
+// These are the bytes that gets hashed in consensus. It excludes address
+// as its redundant with the pubkey. This also excludes ProposerPriority
+// which changes every round.
+// It fails if the public key has no protobuf encoding, such as an sr25519 key.
+func (v *Validator) Bytes() ([]byte, error) {
+	pk, err := ce.PubKeyToProto(v.PubKey)
+	if err != nil {
+		return nil, err
+	}
+
+	pbv := cmtproto.SimpleValidator{
+		PubKey:      &pk,
+		VotingPower: v.VotingPower,
+	}
+
+	return pbv.Marshal()
+}
+
+// ToProto converts Validator to protobuf
+func (v *Validator) ToProto() (*cmtproto.Validator, error) {
+	if v == nil {
+		return nil, errors.New("nil validator")
+	}
+
+	pk, err := ce.PubKeyToProto(v.PubKey)
+	if err != nil {
+		return nil, err
+	}
+
+	vp := cmtproto.Validator{
+		Address:          v.Address,
+		PubKey:           pk,
+		VotingPower:      v.VotingPower,
+		ProposerPriority: v.ProposerPriority,
+	}
+
+	return &vp, nil
+}
+
+// ValidatorFromProto converts a protobuf Validator to a Validator.
+// It returns an error if the public key is invalid.
+func ValidatorFromProto(vp *cmtproto.Validator) (*Validator, error) {
+	if vp == nil {
+		return nil, errors.New("nil validator")
+	}
+
+	pk, err := ce.PubKeyFromProto(vp.PubKey)
+	if err != nil {
+		return nil, err
+	}
+	v := new(Validator)
+	v.Address = vp.GetAddress()
+	v.PubKey = pk
+	v.VotingPower = vp.GetVotingPower()
+	v.ProposerPriority = vp.GetProposerPriority()
+
+	return v, nil
+}
@@ -1,18 +1,35 @@
 package types
 
-// // ValidatorSet represent a set of *Validator at a given height.
-// //
-// // The validators can be fetched by address or index.
-// // The index is in order of .VotingPower, so the indices are fixed for all
-// // rounds of a given blockchain height - ie. the validators are sorted by their
-// // voting power (descending). Secondary index - .Address (ascending).
-// //
-// // On the other hand, the .ProposerPriority of each validator and the
-// // designated .GetProposer() of a set changes every round, upon calling
-// // .IncrementProposerPriority().
-// //
-// // NOTE: Not goroutine-safe.
-// // NOTE: All get/set to validators should copy the value for safety.
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/strangelove-ventures/cometbft-client/crypto/merkle"
+	cmtproto "github.com/strangelove-ventures/cometbft-client/proto/tendermint/types"
+)
+
+const (
+	// MaxTotalVotingPower - the maximum allowed total voting power.
+	// This is the same limit CometBFT enforces on its validator sets.
+	MaxTotalVotingPower = int64(math.MaxInt64) / 8
+)
+
+// ValidatorSet represent a set of *Validator at a given height.
+//
+// The validators can be fetched by address or index.
+// The index is in order of .VotingPower, so the indices are fixed for all
+// rounds of a given blockchain height - ie. the validators are sorted by their
+// voting power (descending). Secondary index - .Address (ascending).
+//
+// On the other hand, the .ProposerPriority of each validator and the
+// designated .Proposer of a set changes every round.
+//
+// NOTE: Not goroutine-safe.
+// NOTE: All get/set to validators should copy the value for safety.
 type ValidatorSet struct {
 	// NOTE: persisted via reflect, must be exported.
 	Validators []*Validator `json:"validators"`
@@ -21,3 +38,300 @@ type ValidatorSet struct {
 	// cached (unexported)
 	totalVotingPower int64
 }
+
+// NewValidatorSet initializes a ValidatorSet by copying over the values from
+// `valz`, a list of Validators. The validators are sorted by voting power
+// (descending) and address (ascending), which is the order used for hashing.
+//
+// Unlike CometBFT, proposer priorities are not computed and Proposer is left
+// unset, since they are only needed to drive consensus.
+//
+// The returned error is not nil if any of the validators is invalid, if
+// there are duplicate addresses or if the total voting power exceeds
+// MaxTotalVotingPower.
+func NewValidatorSet(valz []*Validator) (*ValidatorSet, error) {
+	vals := &ValidatorSet{
+		Validators: validatorListCopy(valz),
+	}
+
+	seen := make(map[string]struct{}, len(vals.Validators))
+	for i, val := range vals.Validators {
+		if err := val.ValidateBasic(); err != nil {
+			return nil, fmt.Errorf("invalid validator #%d: %w", i, err)
+		}
+		if _, ok := seen[string(val.Address)]; ok {
+			return nil, fmt.Errorf("duplicate validator %v", val)
+		}
+		seen[string(val.Address)] = struct{}{}
+	}
+
+	sort.Sort(ValidatorsByVotingPower(vals.Validators))
+
+	if err := vals.updateTotalVotingPower(); err != nil {
+		return nil, err
+	}
+
+	return vals, nil
+}
+
+// ValidateBasic performs basic validation of the validator set.
+func (vals *ValidatorSet) ValidateBasic() error {
+	if vals.IsNilOrEmpty() {
+		return errors.New("validator set is nil or empty")
+	}
+
+	for idx, val := range vals.Validators {
+		if err := val.ValidateBasic(); err != nil {
+			return fmt.Errorf("invalid validator #%d: %w", idx, err)
+		}
+	}
+
+	if vals.Proposer != nil {
+		if err := vals.Proposer.ValidateBasic(); err != nil {
+			return fmt.Errorf("proposer failed validate basic, error: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// IsNilOrEmpty returns true if validator set is nil or empty.
+func (vals *ValidatorSet) IsNilOrEmpty() bool {
+	return vals == nil || len(vals.Validators) == 0
+}
+
+// Copy each validator into a new ValidatorSet.
+func (vals *ValidatorSet) Copy() *ValidatorSet {
+	return &ValidatorSet{
+		Validators:       validatorListCopy(vals.Validators),
+		Proposer:         vals.Proposer,
+		totalVotingPower: vals.totalVotingPower,
+	}
+}
+
+// HasAddress returns true if address given is in the validator set, false -
+// otherwise.
+func (vals *ValidatorSet) HasAddress(address []byte) bool {
+	for _, val := range vals.Validators {
+		if bytes.Equal(val.Address, address) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetByAddress returns an index of the validator with address and validator
+// itself (copy) if found. Otherwise, -1 and nil are returned.
+func (vals *ValidatorSet) GetByAddress(address []byte) (index int32, val *Validator) {
+	for idx, val := range vals.Validators {
+		if bytes.Equal(val.Address, address) {
+			return int32(idx), val.Copy()
+		}
+	}
+	return -1, nil
+}
+
+// GetByIndex returns the validator's address and validator itself (copy) by
+// index.
+// It returns nil values if index is less than 0 or greater or equal to
+// len(ValidatorSet.Validators).
+func (vals *ValidatorSet) GetByIndex(index int32) (address []byte, val *Validator) {
+	if index < 0 || int(index) >= len(vals.Validators) {
+		return nil, nil
+	}
+	val = vals.Validators[index]
+	return val.Address, val.Copy()
+}
+
+// Size returns the length of the validator set.
+func (vals *ValidatorSet) Size() int {
+	return len(vals.Validators)
+}
+
+// Forces recalculation of the set's total voting power.
+// Returns an error if the total voting power exceeds MaxTotalVotingPower.
+func (vals *ValidatorSet) updateTotalVotingPower() error {
+	sum := int64(0)
+	for _, val := range vals.Validators {
+		// mind overflow
+		sum += val.VotingPower
+		if sum > MaxTotalVotingPower || sum < 0 {
+			return fmt.Errorf(
+				"total voting power of resulting valset exceeds max %d",
+				MaxTotalVotingPower)
+		}
+	}
+
+	vals.totalVotingPower = sum
+	return nil
+}
+
+// TotalVotingPower returns the sum of the voting powers of all validators.
+// It recomputes the total voting power if required.
+func (vals *ValidatorSet) TotalVotingPower() int64 {
+	if vals.totalVotingPower == 0 {
+		if err := vals.updateTotalVotingPower(); err != nil {
+			panic(err)
+		}
+	}
+	return vals.totalVotingPower
+}
+
+// Hash returns the Merkle root hash build using validators (as leaves) in the
+// set.
+//
+// See merkle.HashFromByteSlices.
+//
+// It fails if a validator has a public key with no protobuf encoding, such as an sr25519 key,
+// which the Amino JSON of the RPC decodes.
+func (vals *ValidatorSet) Hash() ([]byte, error) {
+	bzs := make([][]byte, len(vals.Validators))
+	for i, val := range vals.Validators {
+		bz, err := val.Bytes()
+		if err != nil {
+			return nil, fmt.Errorf("validator #%d: %w", i, err)
+		}
+		bzs[i] = bz
+	}
+	return merkle.HashFromByteSlices(bzs), nil
+}
+
+// Iterate will run the given function over the set.
+func (vals *ValidatorSet) Iterate(fn func(index int, val *Validator) bool) {
+	for i, val := range vals.Validators {
+		stop := fn(i, val.Copy())
+		if stop {
+			break
+		}
+	}
+}
+
+// String returns a string representation of ValidatorSet.
+//
+// See StringIndented.
+func (vals *ValidatorSet) String() string {
+	return vals.StringIndented("")
+}
+
+// StringIndented returns an intended String.
+//
+// See Validator#String.
+func (vals *ValidatorSet) StringIndented(indent string) string {
+	if vals == nil {
+		return "nil-ValidatorSet"
+	}
+	var valStrings []string
+	vals.Iterate(func(index int, val *Validator) bool {
+		valStrings = append(valStrings, val.String())
+		return false
+	})
+	return fmt.Sprintf(`ValidatorSet{
+%s  Proposer: %v
+%s  Validators:
+%s    %v
+%s}`,
+		indent, vals.Proposer.String(),
+		indent,
+		indent, strings.Join(valStrings, "\n"+indent+"    "),
+		indent)
+}
+
+//-------------------------------------
+
+// ValidatorsByVotingPower implements sort.Interface for []*Validator based on
+// the VotingPower and Address fields.
+type ValidatorsByVotingPower []*Validator
+
+func (valz ValidatorsByVotingPower) Len() int { return len(valz) }
+
+func (valz ValidatorsByVotingPower) Less(i, j int) bool {
+	if valz[i].VotingPower == valz[j].VotingPower {
+		return bytes.Compare(valz[i].Address, valz[j].Address) == -1
+	}
+	return valz[i].VotingPower > valz[j].VotingPower
+}
+
+func (valz ValidatorsByVotingPower) Swap(i, j int) {
+	valz[i], valz[j] = valz[j], valz[i]
+}
+
+// Makes a copy of the validator list.
+func validatorListCopy(valsList []*Validator) []*Validator {
+	if valsList == nil {
+		return nil
+	}
+	valsCopy := make([]*Validator, len(valsList))
+	for i, val := range valsList {
+		valsCopy[i] = val.Copy()
+	}
+	return valsCopy
+}
+
+// ToProto converts ValidatorSet to protobuf. The proposer is left unset if
+// the set has none.
+func (vals *ValidatorSet) ToProto() (*cmtproto.ValidatorSet, error) {
+	if vals.IsNilOrEmpty() {
+		return &cmtproto.ValidatorSet{}, nil // validator set should never be nil
+	}
+
+	vp := new(cmtproto.ValidatorSet)
+	valsProto := make([]*cmtproto.Validator, len(vals.Validators))
+	for i := 0; i < len(vals.Validators); i++ {
+		valp, err := vals.Validators[i].ToProto()
+		if err != nil {
+			return nil, err
+		}
+		valsProto[i] = valp
+	}
+	vp.Validators = valsProto
+
+	if vals.Proposer != nil {
+		valProposer, err := vals.Proposer.ToProto()
+		if err != nil {
+			return nil, fmt.Errorf("toProto: validatorSet proposer error: %w", err)
+		}
+		vp.Proposer = valProposer
+	}
+
+	// NOTE: Sometimes we use the bytes of the proto form as a hash. This means that we need to
+	// be consistent with cached data
+	vp.TotalVotingPower = 0
+
+	return vp, nil
+}
+
+// ValidatorSetFromProto converts a protobuf ValidatorSet to a ValidatorSet.
+// It returns an error if any of the validators from the set or the proposer
+// is invalid.
+func ValidatorSetFromProto(vp *cmtproto.ValidatorSet) (*ValidatorSet, error) {
+	if vp == nil {
+		return nil, errors.New("nil validator set") // validator set should never be nil, bigger issues are at play if empty
+	}
+	vals := new(ValidatorSet)
+
+	valsProto := make([]*Validator, len(vp.Validators))
+	for i := 0; i < len(vp.Validators); i++ {
+		v, err := ValidatorFromProto(vp.Validators[i])
+		if err != nil {
+			return nil, err
+		}
+		valsProto[i] = v
+	}
+	vals.Validators = valsProto
+
+	if vp.Proposer != nil {
+		p, err := ValidatorFromProto(vp.GetProposer())
+		if err != nil {
+			return nil, fmt.Errorf("fromProto: validatorSet proposer error: %w", err)
+		}
+		vals.Proposer = p
+	}
+
+	// NOTE: We can't trust the total voting power given to us by other peers,
+	// hence we need to recompute it.
+	if err := vals.updateTotalVotingPower(); err != nil {
+		return nil, err
+	}
+
+	return vals, vals.ValidateBasic()
+}
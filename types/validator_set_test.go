@@ -0,0 +1,57 @@
+package types
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/strangelove-ventures/cometbft-client/crypto/ed25519"
+	ce "github.com/strangelove-ventures/cometbft-client/crypto/encoding"
+	"github.com/strangelove-ventures/cometbft-client/crypto/secp256k1"
+	"github.com/strangelove-ventures/cometbft-client/crypto/sr25519"
+)
+
+func TestValidatorSetHash(t *testing.T) {
+	pk1 := ed25519.GenPrivKeyFromSecret([]byte("val1")).PubKey()
+	pk2 := secp256k1.GenPrivKeySecp256k1([]byte("val2")).PubKey()
+
+	// The validators are passed in the wrong order on purpose, NewValidatorSet must sort them.
+	vset, err := NewValidatorSet([]*Validator{NewValidator(pk1, 10), NewValidator(pk2, 20)})
+	require.NoError(t, err)
+
+	assert.Equal(t, pk2.Address(), vset.Validators[0].Address)
+	assert.Equal(t, int64(30), vset.TotalVotingPower())
+
+	// Produced by CometBFT v0.38 for the same validators.
+	hash, err := vset.Hash()
+	require.NoError(t, err)
+	assert.Equal(t,
+		"4803AD04706C49CBAFCBC35B1282C5E43EA518F7D1562679919F02ADB6C47267",
+		strings.ToUpper(hex.EncodeToString(hash)),
+	)
+
+	// An sr25519 key has no protobuf encoding to be hashed with.
+	vset, err = NewValidatorSet([]*Validator{
+		NewValidator(pk1, 10),
+		NewValidator(sr25519.GenPrivKeyFromSecret([]byte("val3")).PubKey(), 30),
+	})
+	require.NoError(t, err)
+	_, err = vset.Hash()
+	assert.ErrorIs(t, err, ce.ErrUnsupportedKeyType)
+}
+
+func TestNewValidatorSetErrors(t *testing.T) {
+	pk := ed25519.GenPrivKeyFromSecret([]byte("val1")).PubKey()
+
+	_, err := NewValidatorSet([]*Validator{NewValidator(pk, 10), NewValidator(pk, 20)})
+	require.Error(t, err)
+
+	_, err = NewValidatorSet([]*Validator{NewValidator(pk, -1)})
+	require.Error(t, err)
+
+	_, err = NewValidatorSet([]*Validator{NewValidator(pk, MaxTotalVotingPower+1)})
+	require.Error(t, err)
+}
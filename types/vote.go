@@ -1,6 +1,73 @@
 package types
 
-import "github.com/strangelove-ventures/cometbft-client/crypto"
+import (
+	"errors"
+	"time"
+
+	"github.com/strangelove-ventures/cometbft-client/crypto"
+	cmtproto "github.com/strangelove-ventures/cometbft-client/proto/tendermint/types"
+)
 
 // // Address is hex bytes.
 type Address = crypto.Address
+
+// Vote represents a prevote, precommit, or commit vote from validators for
+// consensus.
+type Vote struct {
+	Type               cmtproto.SignedMsgType `json:"type"`
+	Height             int64                  `json:"height"`
+	Round              int32                  `json:"round"`    // assume there will not be greater than 2_147_483_647 rounds
+	BlockID            BlockID                `json:"block_id"` // zero if vote is nil.
+	Timestamp          time.Time              `json:"timestamp"`
+	ValidatorAddress   Address                `json:"validator_address"`
+	ValidatorIndex     int32                  `json:"validator_index"`
+	Signature          []byte                 `json:"signature"`
+	Extension          []byte                 `json:"extension"`
+	ExtensionSignature []byte                 `json:"extension_signature"`
+}
+
+// VoteFromProto attempts to convert the given serialization (Protobuf) type to
+// our Vote domain type. No validation is performed on the resulting vote.
+func VoteFromProto(pv *cmtproto.Vote) (*Vote, error) {
+	if pv == nil {
+		return nil, errors.New("nil vote")
+	}
+
+	blockID, err := BlockIDFromProto(&pv.BlockID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Vote{
+		Type:               pv.Type,
+		Height:             pv.Height,
+		Round:              pv.Round,
+		BlockID:            *blockID,
+		Timestamp:          pv.Timestamp,
+		ValidatorAddress:   pv.ValidatorAddress,
+		ValidatorIndex:     pv.ValidatorIndex,
+		Signature:          pv.Signature,
+		Extension:          pv.Extension,
+		ExtensionSignature: pv.ExtensionSignature,
+	}, nil
+}
+
+// ToProto converts the handwritten type to proto generated type
+func (vote *Vote) ToProto() *cmtproto.Vote {
+	if vote == nil {
+		return nil
+	}
+
+	return &cmtproto.Vote{
+		Type:               vote.Type,
+		Height:             vote.Height,
+		Round:              vote.Round,
+		BlockID:            vote.BlockID.ToProto(),
+		Timestamp:          vote.Timestamp,
+		ValidatorAddress:   vote.ValidatorAddress,
+		ValidatorIndex:     vote.ValidatorIndex,
+		Signature:          vote.Signature,
+		Extension:          vote.Extension,
+		ExtensionSignature: vote.ExtensionSignature,
+	}
+}